@@ -0,0 +1,294 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"strings"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/text/text"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// annotationsHiddenProperty is the scene property
+// set when its annotations are hidden
+const annotationsHiddenProperty = "annotationsHidden"
+
+// AnnotationName is the name of the [Annotation] of a solid
+const AnnotationName = "annotation"
+
+// annotationTailName is the name of the mesh of the tail
+// of the speech bubble of an [Annotation]
+const annotationTailName = "__AnnotationTail"
+
+// Annotation is a note pinned to a solid, shown as a speech bubble
+// above the top of its world bounding box, with a tail pointing down
+// at it. It is a billboard child of the solid, but only follows its
+// bounding box, not its rotation or scale, so the Pose scale is the
+// size of the text in world units. It is shown while the annotations
+// of its scene are, as set by [SetAnnotationsVisible], and its solid
+// is not hidden. Clicking it opens a dialog to edit it, once an
+// [AnnotationTool] is attached to the scene widget, and the scene
+// widget can not select it. It is drawn over the rest of the
+// scene, without DepthTest, so that it is not hidden by other solids.
+type Annotation struct {
+	Text2D
+
+	// world matrix of the tail made by PreRender,
+	// and whether it was shown on the last render
+	tail  math32.Matrix4
+	shown bool
+}
+
+// PinAnnotation pins a note with the given text to the given solid,
+// replacing the text of its note if it already has one
+func PinAnnotation(sld *xyz.Solid, txt string) *Annotation {
+	if an := SolidAnnotation(sld); an != nil {
+		an.SetText(txt)
+		an.RenderText()
+		return an
+	}
+	an := tree.New[Annotation](sld.This)
+	an.SetName(AnnotationName)
+	an.Billboard = true
+	an.DepthTest = false
+	an.Styles.Text.Align = text.Center
+	an.Styles.Text.AlignV = text.End
+	an.Styles.Color = colors.Uniform(colors.Black)
+	an.BackgroundColor = color.RGBA{255, 245, 170, 255}
+	an.Padding.Set(0.3, 0.2)
+	an.Pose.Scale.SetScalar(0.15)
+	an.Text = txt
+	if sc := sld.Scene; sc != nil && sc.TextShaper != nil { // already configured
+		an.Config()
+	}
+	if sld.Scene != nil {
+		sld.Scene.SetNeedsUpdate()
+	}
+	return an
+}
+
+// SolidAnnotation returns the note pinned to the given solid, if any
+func SolidAnnotation(sld *xyz.Solid) *Annotation {
+	an, _ := sld.ChildByName(AnnotationName, 0).(*Annotation)
+	return an
+}
+
+// UnpinAnnotation removes the note pinned to the given solid, if any
+func UnpinAnnotation(sld *xyz.Solid) {
+	if an := SolidAnnotation(sld); an != nil {
+		an.Delete()
+		if sld.Scene != nil {
+			sld.Scene.SetNeedsUpdate()
+		}
+	}
+}
+
+// SetAnnotationsVisible shows or hides the annotations of the given scene
+func SetAnnotationsVisible(sc *xyz.Scene, visible bool) {
+	if visible {
+		sc.DeleteProperty(annotationsHiddenProperty)
+	} else {
+		sc.SetProperty(annotationsHiddenProperty, true)
+	}
+	sc.SetNeedsRender()
+}
+
+// AnnotationsVisible returns whether the annotations
+// of the given scene are shown
+func AnnotationsVisible(sc *xyz.Scene) bool {
+	return sc.Property(annotationsHiddenProperty) == nil
+}
+
+// solid returns the node the note is pinned to
+func (an *Annotation) solid() xyz.Node {
+	nd, _ := an.Parent.(xyz.Node)
+	return nd
+}
+
+func (an *Annotation) UpdateWorldMatrix(parWorld *math32.Matrix4) {
+	an.place()
+	an.Text2D.UpdateWorldMatrix(math32.Identity4())
+}
+
+// place puts the bottom of the tail at the top of the world
+// bounding box of the solid, in world space
+func (an *Annotation) place() {
+	nd := an.solid()
+	if nd == nil {
+		return
+	}
+	bb := nd.AsNodeBase().WorldBBox.BBox
+	an.Pose.Pos = math32.Vec3(0.5*(bb.Min.X+bb.Max.X), bb.Max.Y+an.tailLength(), 0.5*(bb.Min.Z+bb.Max.Z))
+}
+
+// tailLength returns the length of the tail, in world units
+func (an *Annotation) tailLength() float32 {
+	return 0.8 * an.Pose.Scale.Y
+}
+
+// PreRender places the bubble above the solid, for the camera that
+// the scene has just updated, and clears its window bounding box so
+// that clicks on it do not select it
+func (an *Annotation) PreRender() {
+	an.SceneBBox = image.Rectangle{}
+	nd := an.solid()
+	an.shown = nd != nil && AnnotationsVisible(an.Scene) && !isHidden(nd)
+	if !an.shown {
+		return
+	}
+	an.place()
+	an.Pose.ParMatrix.SetIdentity()
+	an.Text2D.PreRender()
+	sc := an.Scene
+	if _, err := sc.MeshByName(annotationTailName); err != nil {
+		xyz.NewCone(sc, annotationTailName, 1, 0.5, 12, 1, true)
+	}
+	// the cone points up along +Y, so it is turned over,
+	// with its tip at the top of the bounding box
+	l := an.tailLength()
+	down := math32.NewQuatAxisAngle(math32.Vec3(1, 0, 0), math32.Pi)
+	var tail math32.Matrix4
+	tail.SetTransform(an.Pose.Pos.Sub(math32.Vec3(0, 0.5*l, 0)), down, math32.Vec3(l, l, l))
+	an.tail.MulMatrices(&an.pull, &tail) // in front of the scene along with the bubble
+	clr := phong.NewColors(an.BackgroundColor, colors.Black, an.Material.Shiny, an.Material.Reflective, an.Material.Bright)
+	sc.Phong.SetObject(an.tailPath(), phong.NewObject(&an.tail, clr))
+}
+
+// Render renders the tail, the bubble and the text
+func (an *Annotation) Render(rp *wgpu.RenderPassEncoder) {
+	if !an.shown {
+		return
+	}
+	ph := an.Scene.Phong
+	ph.UseObject(an.tailPath())
+	ph.UseMesh(annotationTailName)
+	ph.UseNoTexture()
+	ph.Render(rp)
+	an.Text2D.Render(rp)
+}
+
+// tailPath returns the name of the render object for the tail
+func (an *Annotation) tailPath() string {
+	return an.Path() + "/__tail"
+}
+
+// AnnotationTool opens a dialog to edit the annotations
+// clicked in a scene widget, with a button to unpin them
+type AnnotationTool struct {
+	// OnChange is called after an annotation is edited or unpinned
+	OnChange func() `display:"-"`
+
+	// Scene widget whose annotations are edited
+	SceneWidget *xyzcore.Scene `set:"-"`
+
+	// annotation that the mouse was pressed on
+	pressed *Annotation
+}
+
+// NewAnnotationTool returns a new annotation tool
+func NewAnnotationTool() *AnnotationTool {
+	return &AnnotationTool{}
+}
+
+// Attach lets clicks on the annotations of the
+// given scene widget open a dialog to edit them
+func (at *AnnotationTool) Attach(sw *xyzcore.Scene) {
+	if at.SceneWidget != nil {
+		return
+	}
+	at.SceneWidget = sw
+	sw.On(events.MouseDown, func(e events.Event) {
+		at.pressed = nil
+		if e.MouseButton() != events.Left {
+			return
+		}
+		if an := at.pick(e); an != nil {
+			e.SetHandled() // not selecting what is behind it
+			at.pressed = an
+		}
+	})
+	sw.On(events.Click, func(e events.Event) {
+		an := at.pressed
+		at.pressed = nil
+		if an == nil || e.MouseButton() != events.Left {
+			return
+		}
+		e.SetHandled()
+		at.editDialog(an)
+	})
+}
+
+// pick returns the nearest annotation shown
+// under the mouse of the given event, if any
+func (at *AnnotationTool) pick(e events.Event) *Annotation {
+	ray, ok := eventRay(at.SceneWidget, e)
+	if !ok {
+		return nil
+	}
+	var hit *Annotation
+	var dist float32
+	at.SceneWidget.SceneXYZ().WalkDown(func(k tree.Node) bool {
+		an, ok := k.(*Annotation)
+		if !ok || !an.shown {
+			return tree.Continue
+		}
+		if _, d, ok := pickSolid(&an.Solid, ray); ok && (hit == nil || d < dist) {
+			hit, dist = an, d
+		}
+		return tree.Continue
+	})
+	return hit
+}
+
+// editDialog opens a dialog to edit the text of the given annotation
+func (at *AnnotationTool) editDialog(an *Annotation) {
+	sw := at.SceneWidget
+	d := core.NewBody("Annotation")
+	tf := core.NewTextField(d).SetText(an.Text)
+	tf.Styler(func(s *styles.Style) {
+		s.Min.X.Ch(40)
+	})
+	d.AddBottomBar(func(bar *core.Frame) {
+		d.AddCancel(bar)
+		core.NewButton(bar).SetType(core.ButtonOutlined).SetText("Unpin").OnClick(func(e events.Event) {
+			e.SetHandled()
+			d.Close()
+			if nd := an.solid(); nd != nil {
+				UnpinAnnotation(nd.AsSolid())
+			}
+			at.changed()
+		})
+		d.AddOK(bar).OnClick(func(e events.Event) {
+			txt := strings.TrimSpace(tf.Text())
+			if txt == "" || txt == an.Text {
+				return
+			}
+			an.SetText(txt)
+			an.RenderText()
+			an.Scene.SetNeedsUpdate()
+			at.changed()
+		})
+	})
+	d.RunDialog(sw)
+}
+
+// changed updates the scene widget and calls OnChange, if any
+func (at *AnnotationTool) changed() {
+	at.SceneWidget.NeedsRender()
+	if at.OnChange != nil {
+		at.OnChange()
+	}
+}
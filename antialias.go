@@ -0,0 +1,145 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"image"
+	"log"
+
+	"cogentcore.org/core/gpu"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/system"
+	"cogentcore.org/core/xyz"
+)
+
+// antiAliasProperty is the scene property holding its [AntiAlias]
+const antiAliasProperty = "antiAlias"
+
+// AAModes are the ways of smoothing the edges of rendered solids
+type AAModes int32
+
+const (
+	// AANone renders each pixel with one sample, with jagged edges
+	AANone AAModes = iota
+
+	// AAMSAA renders with several samples for each pixel
+	// at the edges, which are blended
+	AAMSAA
+
+	// AAFXAA blurs the edges that it finds in the rendered image
+	AAFXAA
+)
+
+// AntiAlias is the anti-aliasing of a scene. For MSAA, the frame is
+// made again with the number of Samples, which WebGPU only supports
+// as 4, so others are made 4. FXAA is only applied to the images made
+// by [RenderToPNG], which are otherwise rendered without multisampling,
+// and not to the view in the scene widget, which is drawn without any
+// anti-aliasing with it: the widget draws the frame texture straight
+// to the window, with no pass after the render to run FXAA in.
+type AntiAlias struct {
+	// Mode of anti-aliasing
+	Mode AAModes
+
+	// Samples for each pixel with AAMSAA
+	Samples int
+}
+
+// SetAntiAlias sets the anti-aliasing of the given scene, replacing
+// its frame with one with the needed number of samples, if it has
+// been rendered. The old frame is used until the new one is made,
+// all at once on the main thread, so the view does not flicker.
+// Frames drawn straight to a web canvas can not be changed.
+func SetAntiAlias(sc *xyz.Scene, aa AntiAlias) error {
+	samples := 1
+	if aa.Mode == AAMSAA {
+		samples = 4
+		if aa.Samples != 4 {
+			log.Printf("AntiAlias: using 4 samples instead of %d, as WebGPU only supports 4\n", aa.Samples)
+		}
+	}
+	sc.SetProperty(antiAliasProperty, &aa)
+	sc.MultiSample = samples
+	if sc.Frame == nil {
+		return nil // made with the samples on the first render
+	}
+	rt, ok := sc.Frame.(*gpu.RenderTexture)
+	if !ok {
+		return errors.New("SetAntiAlias: the scene frame can not be changed")
+	}
+	if rt.Format.Samples == samples {
+		return nil
+	}
+	system.TheApp.RunOnMain(func() {
+		oldFrame, oldPhong := sc.Frame, sc.Phong
+		dev := *rt.Device()
+		sc.Frame = nil
+		sc.ConfigOffscreen(rt.GPU, &dev)
+		oldPhong.Release()
+		oldFrame.Release()
+	})
+	sc.SetNeedsRender()
+	return nil
+}
+
+// SceneAntiAlias returns the anti-aliasing of the given scene,
+// which is the default MSAA if it has not been set
+func SceneAntiAlias(sc *xyz.Scene) AntiAlias {
+	if aa, ok := sc.Property(antiAliasProperty).(*AntiAlias); ok {
+		return *aa
+	}
+	return AntiAlias{Mode: AAMSAA, Samples: sc.MultiSample}
+}
+
+// fxaa smooths the edges in the given image with a single pass of
+// FXAA, which blends each pixel on an edge with the pixels along it
+func fxaa(img *image.NRGBA) {
+	b := img.Bounds()
+	src := newFloatImage(b.Dx(), b.Dy())
+	for y := range src.h {
+		for x := range src.w {
+			i := img.PixOffset(b.Min.X+x, b.Min.Y+y)
+			src.pix[y*src.w+x] = math32.Vec3(float32(img.Pix[i]), float32(img.Pix[i+1]), float32(img.Pix[i+2])).DivScalar(255)
+		}
+	}
+	luma := func(c math32.Vector3) float32 { return 0.299*c.X + 0.587*c.Y + 0.114*c.Z }
+	const (
+		reduceMul = 1.0 / 8
+		reduceMin = 1.0 / 128
+		spanMax   = 8
+	)
+	for y := range src.h {
+		for x := range src.w {
+			cx, cy := float32(x)+0.5, float32(y)+0.5
+			lm := luma(src.at(cx, cy))
+			lnw, lne := luma(src.at(cx-1, cy-1)), luma(src.at(cx+1, cy-1))
+			lsw, lse := luma(src.at(cx-1, cy+1)), luma(src.at(cx+1, cy+1))
+			lmin := min(lm, lnw, lne, lsw, lse)
+			lmax := max(lm, lnw, lne, lsw, lse)
+			if lmax-lmin < max(0.0312, 0.125*lmax) {
+				continue // not on an edge
+			}
+			// the direction along the edge, with Y down
+			dir := math32.Vec2(-((lnw + lne) - (lsw + lse)), (lnw+lsw)-(lne+lse))
+			reduce := max((lnw+lne+lsw+lse)*0.25*reduceMul, reduceMin)
+			rcp := 1 / (min(math32.Abs(dir.X), math32.Abs(dir.Y)) + reduce)
+			dir = dir.MulScalar(rcp)
+			dir.X = math32.Clamp(dir.X, -spanMax, spanMax)
+			dir.Y = math32.Clamp(dir.Y, -spanMax, spanMax)
+			tap := func(t float32) math32.Vector3 { return src.at(cx+dir.X*t, cy+dir.Y*t) }
+			ca := tap(1.0/3 - 0.5).Add(tap(2.0/3 - 0.5)).MulScalar(0.5)
+			cb := ca.MulScalar(0.5).Add(tap(-0.5).Add(tap(0.5)).MulScalar(0.25))
+			c := cb
+			if lb := luma(cb); lb < lmin || lb > lmax {
+				c = ca
+			}
+			i := img.PixOffset(b.Min.X+x, b.Min.Y+y)
+			img.Pix[i] = uint8(math32.Clamp(c.X, 0, 1) * 255)
+			img.Pix[i+1] = uint8(math32.Clamp(c.Y, 0, 1) * 255)
+			img.Pix[i+2] = uint8(math32.Clamp(c.Z, 0, 1) * 255)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// ArrayMesh is a triangle mesh of vertex arrays, as loaded from
+// model files. Missing normals are made smooth from the triangles,
+// and missing texture coordinates are 0.
+type ArrayMesh struct {
+	xyz.MeshBase
+
+	// Vertex has three coordinates for each vertex
+	Vertex math32.ArrayF32
+
+	// Normal has three coordinates for each vertex, or is empty
+	Normal math32.ArrayF32 `json:",omitempty"`
+
+	// TexCoord has two texture coordinates for each vertex, or is empty
+	TexCoord math32.ArrayF32 `json:",omitempty"`
+
+	// Colors has four color components for each vertex, or is empty
+	// if the mesh has no vertex colors
+	Colors math32.ArrayF32 `json:",omitempty"`
+
+	// Index has the vertex indexes of the triangles, three per
+	// triangle. If empty, each three vertices in order make a triangle.
+	Index math32.ArrayU32 `json:",omitempty"`
+}
+
+// NewArrayMesh returns a new mesh of the given vertex arrays,
+// set on the given scene under the given name
+func NewArrayMesh(sc *xyz.Scene, name string, vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) *ArrayMesh {
+	am := &ArrayMesh{Vertex: vertex, Normal: normal, TexCoord: texcoord, Colors: clrs, Index: index}
+	am.Name = name
+	sc.SetMesh(am)
+	return am
+}
+
+func (am *ArrayMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	am.NumVertex = len(am.Vertex) / 3
+	am.NumIndex = len(am.Index)
+	if am.NumIndex == 0 {
+		am.NumIndex = am.NumVertex - am.NumVertex%3
+	}
+	am.HasColor = len(am.Colors) >= 4*am.NumVertex && am.NumVertex > 0
+	am.Transparent = false
+	if am.HasColor {
+		for i := 3; i < 4*am.NumVertex; i += 4 {
+			if am.Colors[i] < 1 {
+				am.Transparent = true
+				break
+			}
+		}
+	}
+	return am.NumVertex, am.NumIndex, am.HasColor
+}
+
+func (am *ArrayMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	nv := am.NumVertex
+	copy(vertex, am.Vertex[:3*nv])
+	if len(am.TexCoord) >= 2*nv {
+		copy(texcoord, am.TexCoord[:2*nv])
+	}
+	if am.HasColor {
+		copy(clrs, am.Colors[:4*nv])
+	}
+	if len(am.Index) > 0 {
+		copy(index, am.Index)
+	} else {
+		for i := range am.NumIndex {
+			index[i] = uint32(i)
+		}
+	}
+	if len(am.Normal) >= 3*nv {
+		copy(normal, am.Normal[:3*nv])
+	} else {
+		smoothNormals(vertex, normal, index[:am.NumIndex], nv)
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, nv)
+	am.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// smoothNormals sets the normals of the given number of vertices
+// to the sum of the normals of the triangles around them
+func smoothNormals(vertex, normal math32.ArrayF32, index math32.ArrayU32, nv int) {
+	nrms := make([]math32.Vector3, nv)
+	vtx := func(i uint32) math32.Vector3 {
+		var v math32.Vector3
+		vertex.GetVector3(3*int(i), &v)
+		return v
+	}
+	for t := 0; t+2 < len(index); t += 3 {
+		i0, i1, i2 := index[t], index[t+1], index[t+2]
+		if int(max(i0, i1, i2)) >= nv {
+			continue
+		}
+		fn := math32.Normal(vtx(i0), vtx(i1), vtx(i2))
+		nrms[i0] = nrms[i0].Add(fn)
+		nrms[i1] = nrms[i1].Add(fn)
+		nrms[i2] = nrms[i2].Add(fn)
+	}
+	for i, n := range nrms {
+		if n != (math32.Vector3{}) {
+			n = n.Normal()
+		}
+		normal.SetVector3(3*i, n)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+const (
+	// SkyName is the name of the solid showing the background gradient
+	SkyName = "__Sky"
+
+	// SkyMeshName is the name of the mesh of the background gradient
+	SkyMeshName = "__SkyMesh"
+)
+
+// BackgroundGradient is a vertical background gradient for a scene,
+// blending from the Bottom color at the bottom of the view to the
+// Top color at the top
+type BackgroundGradient struct {
+	Top    color.RGBA
+	Bottom color.RGBA
+}
+
+// IsZero returns whether the gradient is unset
+func (bg BackgroundGradient) IsZero() bool {
+	return bg == BackgroundGradient{}
+}
+
+// SetBackgroundGradient shows the given gradient behind everything
+// else in the scene. The xyz renderer only supports a solid clear
+// color, so the gradient is drawn as a [Sky] quad with per-vertex
+// colors just inside the far plane of the camera, made as bright as
+// the lights of the scene take away, as for a [Skybox], so that it
+// shows the colors as they are. A zero gradient removes it, and the
+// scene Background is then used as before.
+func SetBackgroundGradient(sc *xyz.Scene, bg BackgroundGradient) {
+	sky, _ := sc.ChildByName(SkyName, 0).(*Sky)
+	if bg.IsZero() {
+		if sky != nil {
+			sky.Delete()
+		}
+		sc.SetNeedsUpdate()
+		return
+	}
+	sm := &SkyMesh{Gradient: bg}
+	sm.Name = SkyMeshName
+	sc.SetMesh(sm)
+	if sky == nil {
+		sky = tree.New[Sky](sc)
+		sky.SetName(SkyName)
+		sky.Material.Reflective = 0
+		sky.Material.Shiny = 0
+	}
+	sky.SetMesh(sm)
+	sc.SetNeedsUpdate()
+}
+
+// Sky is the solid showing a [BackgroundGradient]. It follows
+// the camera so that it always fills the view, and it can not
+// be selected.
+type Sky struct {
+	xyz.Solid
+}
+
+// PreRender moves the sky in front of the camera, which the scene
+// has just updated, sets its brightness for the light falling on it,
+// and clears its window bounding box so that clicks on it do not
+// select it
+func (sky *Sky) PreRender() {
+	cm := &sky.Scene.Camera
+	dist := 0.95 * cm.Far
+	ht := 2 * dist * math32.Tan(math32.DegToRad(cm.FOV)/2)
+	wd := ht * max(cm.Aspect, 1) * 2 // extra room for window resizes
+	sky.Pose.Quat = cm.Pose.Quat
+	sky.Pose.Pos = cm.Pose.Pos.Add(math32.Vec3(0, 0, -dist).MulQuat(cm.Pose.Quat))
+	sky.Pose.Scale.Set(wd, ht, 1)
+	sky.Pose.UpdateMatrix()
+	sky.Pose.UpdateWorldMatrix(nil)
+	facing := math32.Vec3(0, 0, 1).MulQuat(cm.Pose.Quat)
+	sky.Material.Bright = 1 / max(skyboxLight(sky.Scene, sky.Pose.Pos, facing), 1e-3)
+	sky.SceneBBox = image.Rectangle{}
+	sky.Solid.PreRender()
+}
+
+// SkyMesh is a unit quad in the XY plane facing +Z, with
+// the colors of a [BackgroundGradient] at its vertices
+type SkyMesh struct {
+	xyz.MeshBase
+
+	// Gradient colors
+	Gradient BackgroundGradient
+}
+
+func (sm *SkyMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	sm.NumVertex, sm.NumIndex = 4, 6
+	sm.HasColor = true
+	return sm.NumVertex, sm.NumIndex, sm.HasColor
+}
+
+func (sm *SkyMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	bot := math32.NewVector4Color(sm.Gradient.Bottom)
+	top := math32.NewVector4Color(sm.Gradient.Top)
+	vtxs := []math32.Vector3{{X: -0.5, Y: -0.5}, {X: 0.5, Y: -0.5}, {X: 0.5, Y: 0.5}, {X: -0.5, Y: 0.5}}
+	vclrs := []math32.Vector4{bot, bot, top, top}
+	for i, v := range vtxs {
+		vertex.SetVector3(3*i, v)
+		normal.SetVector3(3*i, math32.Vec3(0, 0, 1))
+		texcoord.SetVector2(2*i, math32.Vec2(v.X+0.5, v.Y+0.5))
+		clrs.SetVector4(4*i, vclrs[i])
+	}
+	index.Set(0, 0, 1, 2, 0, 2, 3)
+	bb := shape.BBoxFromVtxs(vertex, 0, 4)
+	sm.BBox.SetBounds(bb.Min, bb.Max)
+}
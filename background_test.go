@@ -0,0 +1,54 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"cogentcore.org/core/gpu"
+	"cogentcore.org/core/xyz"
+)
+
+// renderOffscreen renders the given scene offscreen at the given size,
+// skipping the test if there is no GPU to render with. The lights are
+// sent to the GPU as they are now. The frame is not multisampled, as
+// the software renderers of CI machines may not resolve it.
+func renderOffscreen(t *testing.T, sc *xyz.Scene, size image.Point) *image.NRGBA {
+	gp, dev, err := gpu.NoDisplayGPU()
+	if err != nil {
+		t.Skipf("there is no GPU to render with: %v", err)
+	}
+	sc.SetSize(size)
+	sc.MultiSample = 1
+	sc.ConfigOffscreen(gp, dev)
+	img, err := renderImage(sc, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestBackgroundGradientColors(t *testing.T) {
+	sc := newTestScene()
+	xyz.NewAmbient(sc, "ambient", 0.3, xyz.DirectSun)
+	sun := xyz.NewDirectional(sc, "sun", 1, xyz.DirectSun)
+	sun.Pos.Set(1, 2, 3)
+	bg := BackgroundGradient{Top: color.RGBA{40, 80, 200, 255}, Bottom: color.RGBA{230, 150, 60, 255}}
+	SetBackgroundGradient(sc, bg)
+	img := renderOffscreen(t, sc, image.Pt(64, 64))
+	near := func(c color.NRGBA, want color.RGBA) bool {
+		d := func(a, b uint8) int { return max(int(a), int(b)) - min(int(a), int(b)) }
+		return d(c.R, want.R) <= 3 && d(c.G, want.G) <= 3 && d(c.B, want.B) <= 3
+	}
+	// the gradient spans the view, so its ends are at the edges
+	if c := img.NRGBAAt(32, 0); !near(c, bg.Top) {
+		t.Errorf("the top of the view is %v, not the top color %v", c, bg.Top)
+	}
+	if c := img.NRGBAAt(32, 63); !near(c, bg.Bottom) {
+		t.Errorf("the bottom of the view is %v, not the bottom color %v", c, bg.Bottom)
+	}
+}
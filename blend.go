@@ -0,0 +1,107 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/xyz"
+)
+
+// BlendModes are the ways that the color of a demo [Solid]
+// is blended with what is already drawn behind it
+type BlendModes int32
+
+const (
+	// BlendAlpha mixes the color over what is behind it
+	// by its alpha, which is the default
+	BlendAlpha BlendModes = iota
+
+	// BlendAdditive adds the color, times its alpha, to what is behind
+	// it, which only ever brightens it, as for particles and sparkles
+	BlendAdditive
+
+	// BlendMultiply multiplies what is behind by the brightness of
+	// the color, mixed with no change by its alpha, which only ever
+	// darkens it, as for tinted glass and decal shadows. The blending
+	// of the phong pipelines takes a single factor for all channels,
+	// so it can not tint by the hue of the color.
+	BlendMultiply
+
+	// BlendPremultiplied takes the color as already multiplied
+	// by its alpha, adding it to what is behind it faded by 1
+	// minus its alpha, so an alpha of 0 adds it in full
+	BlendPremultiplied
+)
+
+// blendMinAlpha is the opacity given to the colors added in full, as
+// the shaders multiply the color by the opacity before blending, and
+// a larger Bright then makes up for it
+const blendMinAlpha = 1e-3
+
+// preRenderColors uploads the colors of the solid blended with the fog
+// of its scene and set up for its BlendMode, if either changes them
+func (sld *Solid) preRenderColors() {
+	mt := &sld.Material
+	clr := phong.NewColors(mt.Color, mt.Emissive, mt.Shiny, mt.Reflective, mt.Bright)
+	clr.Tiling = mt.Tiling
+	fog := sld.fogColors(clr)
+	blend := sld.usesBlendMode()
+	if !fog && !blend {
+		return
+	}
+	if blend {
+		sld.blendColors(clr)
+	}
+	sld.Scene.Phong.SetObject(sld.Path(), phong.NewObject(&sld.Pose.WorldMatrix, clr))
+}
+
+// blendColors sets the given colors of the solid up for its BlendMode.
+// The render pipelines all blend the premultiplied color from the shader
+// with what is behind it faded by 1 minus the opacity, and the shader
+// multiplies the lit color by the opacity of the material color, adding
+// its specular highlights after its Bright. The modes are made from that
+// by setting the opacity and scaling the Bright and Reflective, which
+// only works for solids drawn with their material color, not those with
+// a texture or vertex colors, which take their opacity from those.
+func (sld *Solid) blendColors(clr *phong.Colors) {
+	a := clr.Color.W
+	scale := func(s float32) {
+		clr.ShinyBright.Y *= s
+		clr.ShinyBright.Z *= s
+	}
+	switch sld.BlendMode {
+	case BlendAdditive:
+		clr.Color.W = blendMinAlpha
+		scale(a / blendMinAlpha)
+	case BlendPremultiplied:
+		clr.Color.W = max(a, blendMinAlpha)
+		scale(1 / clr.Color.W)
+	case BlendMultiply:
+		c := clr.Color
+		lum := 0.2126*c.X + 0.7152*c.Y + 0.0722*c.Z // the colors are linear
+		clr.Color.W = a * (1 - min(lum, 1))
+		scale(0) // black, so nothing is added
+		clr.Emissive.SetScalar(0)
+	}
+}
+
+// usesBlendMode returns whether the BlendMode of the solid
+// applies to it, as it is drawn with its material color
+func (sld *Solid) usesBlendMode() bool {
+	if sld.BlendMode == BlendAlpha || sld.Mesh == nil || sld.Material.TextureName != "" {
+		return false
+	}
+	return !sld.Mesh.AsMeshBase().HasColor || sld.OverrideVertexColors
+}
+
+// RenderClass sorts the solid with the transparent solids, drawn back
+// to front after the opaque ones, if it has a BlendMode other than
+// BlendAlpha or no DepthWrite, whatever the opacity of its color
+func (sld *Solid) RenderClass() xyz.RenderClasses {
+	if sld.usesBlendMode() || !sld.DepthWrite {
+		return xyz.RClassTransUniform
+	}
+	return sld.Solid.RenderClass()
+}
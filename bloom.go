@@ -0,0 +1,159 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// bloomProperty is the scene property holding its [Bloom]
+const bloomProperty = "bloom"
+
+// Bloom makes bright parts of rendered images of a scene bleed light
+// into the pixels around them. The pixels brighter than Threshold are
+// blurred with a dual Kawase blur, halving the image down and doubling
+// it back up, and added over the image. It is only added to the images
+// made by [RenderToPNG], not to the view in the scene widget: the widget
+// draws the frame texture straight to the window, with no pass after
+// the render to add the bloom in, and blurring on the CPU needs the
+// frame read back from the GPU, which is too slow on every frame.
+type Bloom struct {
+	// Enabled turns the bloom on
+	Enabled bool
+
+	// Threshold is the brightness, from 0 to 1, above which pixels bloom
+	Threshold float32 `min:"0" max:"1" step:"0.05"`
+
+	// Intensity scales the bloom added to the image
+	Intensity float32 `min:"0" step:"0.1"`
+
+	// Radius is about how far the bloom spreads, in pixels,
+	// which sets the number of halvings of the blur
+	Radius float32 `min:"1"`
+}
+
+// NewBloom returns a new enabled bloom with default settings
+func NewBloom() *Bloom {
+	return &Bloom{Enabled: true, Threshold: 0.8, Intensity: 1, Radius: 8}
+}
+
+// SetBloom sets the bloom of the given scene, or turns it off if nil
+func SetBloom(sc *xyz.Scene, bl *Bloom) {
+	if bl == nil {
+		sc.DeleteProperty(bloomProperty)
+	} else {
+		sc.SetProperty(bloomProperty, bl)
+	}
+}
+
+// SceneBloom returns the bloom of the given scene, or nil if none
+func SceneBloom(sc *xyz.Scene) *Bloom {
+	bl, _ := sc.Property(bloomProperty).(*Bloom)
+	return bl
+}
+
+// floatImage is an image of RGB colors from 0 to 1, for filtering
+type floatImage struct {
+	w, h int
+	pix  []math32.Vector3
+}
+
+func newFloatImage(w, h int) *floatImage {
+	return &floatImage{w: w, h: h, pix: make([]math32.Vector3, w*h)}
+}
+
+// at returns the color at the given point in pixels, where the center
+// of the pixel at x, y is at x+0.5, y+0.5, blending the nearest pixels
+// and clamping to the edges
+func (bi *floatImage) at(x, y float32) math32.Vector3 {
+	x, y = x-0.5, y-0.5
+	x0, y0 := math32.Floor(x), math32.Floor(y)
+	fx, fy := x-x0, y-y0
+	px := func(ix, iy int) math32.Vector3 {
+		return bi.pix[min(max(iy, 0), bi.h-1)*bi.w+min(max(ix, 0), bi.w-1)]
+	}
+	ix, iy := int(x0), int(y0)
+	top := px(ix, iy).Lerp(px(ix+1, iy), fx)
+	bot := px(ix, iy+1).Lerp(px(ix+1, iy+1), fx)
+	return top.Lerp(bot, fy)
+}
+
+// down returns the image halved with the Kawase downsampling filter
+func (bi *floatImage) down() *floatImage {
+	d := newFloatImage(max(bi.w/2, 1), max(bi.h/2, 1))
+	sx, sy := float32(bi.w)/float32(d.w), float32(bi.h)/float32(d.h)
+	for y := range d.h {
+		for x := range d.w {
+			cx, cy := (float32(x)+0.5)*sx, (float32(y)+0.5)*sy
+			sum := bi.at(cx, cy).MulScalar(4)
+			for _, o := range [4][2]float32{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+				sum.SetAdd(bi.at(cx+o[0], cy+o[1]))
+			}
+			d.pix[y*d.w+x] = sum.DivScalar(8)
+		}
+	}
+	return d
+}
+
+// up returns the image at the given size with the Kawase upsampling filter
+func (bi *floatImage) up(w, h int) *floatImage {
+	u := newFloatImage(w, h)
+	sx, sy := float32(bi.w)/float32(w), float32(bi.h)/float32(h)
+	for y := range h {
+		for x := range w {
+			cx, cy := (float32(x)+0.5)*sx, (float32(y)+0.5)*sy
+			var sum math32.Vector3
+			for _, o := range [4][2]float32{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				sum.SetAdd(bi.at(cx+o[0], cy+o[1]))
+			}
+			for _, o := range [4][2]float32{{-0.5, -0.5}, {0.5, -0.5}, {-0.5, 0.5}, {0.5, 0.5}} {
+				sum.SetAdd(bi.at(cx+o[0], cy+o[1]).MulScalar(2))
+			}
+			u.pix[y*w+x] = sum.DivScalar(12)
+		}
+	}
+	return u
+}
+
+// Apply adds the bloom to the given image, if it is enabled
+func (bl *Bloom) Apply(img *image.NRGBA) {
+	b := img.Bounds()
+	if !bl.Enabled || bl.Intensity <= 0 || b.Empty() {
+		return
+	}
+	// the bright pixels, by how much they are over the threshold
+	bright := newFloatImage(b.Dx(), b.Dy())
+	for y := range bright.h {
+		for x := range bright.w {
+			i := img.PixOffset(b.Min.X+x, b.Min.Y+y)
+			c := math32.Vec3(float32(img.Pix[i]), float32(img.Pix[i+1]), float32(img.Pix[i+2])).DivScalar(255)
+			lum := 0.2126*c.X + 0.7152*c.Y + 0.0722*c.Z
+			if lum > bl.Threshold {
+				bright.pix[y*bright.w+x] = c.MulScalar((lum - bl.Threshold) / lum)
+			}
+		}
+	}
+	passes := max(int(math32.Round(math32.Log2(max(bl.Radius, 1)))), 1)
+	levels := []*floatImage{bright}
+	for range passes {
+		levels = append(levels, levels[len(levels)-1].down())
+	}
+	blur := levels[passes]
+	for i := passes - 1; i >= 0; i-- {
+		blur = blur.up(levels[i].w, levels[i].h)
+	}
+	for y := range blur.h {
+		for x := range blur.w {
+			i := img.PixOffset(b.Min.X+x, b.Min.Y+y)
+			add := blur.pix[y*blur.w+x].MulScalar(255 * bl.Intensity)
+			img.Pix[i] = uint8(min(float32(img.Pix[i])+add.X, 255))
+			img.Pix[i+1] = uint8(min(float32(img.Pix[i+1])+add.Y, 255))
+			img.Pix[i+2] = uint8(min(float32(img.Pix[i+2])+add.Z, 255))
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// CameraBookmark is a saved pose of the camera of a scene
+type CameraBookmark struct {
+	Name   string
+	Pos    math32.Vector3
+	Quat   math32.Quat
+	Target math32.Vector3
+}
+
+// CameraBookmarks are named camera poses of a scene editor, which can
+// be saved and smoothly returned to. They persist in a JSON file
+// beside the SceneFile, if it is set, as given by [BookmarksFilename].
+type CameraBookmarks struct {
+	// SceneFile is the file the scene was opened from or saved to;
+	// use [CameraBookmarks.SetSceneFile] to open its bookmarks
+	SceneFile string `set:"-"`
+
+	// Duration of returning to a bookmark
+	Duration time.Duration
+
+	// Easing applied to the normalized return time; nil means linear
+	Easing func(t float32) float32 `display:"-"`
+
+	// Bookmarks in the order they were first saved
+	Bookmarks []CameraBookmark `edit:"-"`
+
+	// OnChange is called after bookmarks are saved or opened
+	OnChange func() `display:"-"`
+
+	// Scene editor whose camera is bookmarked
+	SceneEditor *xyzcore.SceneEditor `set:"-"`
+
+	// animation returning to a bookmark
+	anim *core.Animation
+}
+
+// NewCameraBookmarks returns new bookmarks for the camera of the given
+// scene editor, returning to them over half a second
+func NewCameraBookmarks(se *xyzcore.SceneEditor) *CameraBookmarks {
+	return &CameraBookmarks{SceneEditor: se, Duration: 500 * time.Millisecond, Easing: EaseInOutCubic}
+}
+
+// BookmarksFilename returns the name of the file holding
+// the camera bookmarks of the given scene file
+func BookmarksFilename(sceneFile string) string {
+	return strings.TrimSuffix(sceneFile, filepath.Ext(sceneFile)) + ".bookmarks.json"
+}
+
+// SetSceneFile sets the scene file and opens its bookmarks, replacing
+// the current ones; a missing bookmarks file means there are none
+func (cb *CameraBookmarks) SetSceneFile(filename string) error {
+	cb.SceneFile = filename
+	cb.Bookmarks = nil
+	defer cb.changed()
+	if filename == "" {
+		return nil
+	}
+	b, err := os.ReadFile(BookmarksFilename(filename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &cb.Bookmarks)
+}
+
+// SaveSceneFile sets the scene file that the scene was just saved to,
+// as with Save As, and writes the current bookmarks beside it
+func (cb *CameraBookmarks) SaveSceneFile(filename string) error {
+	cb.SceneFile = filename
+	return cb.write()
+}
+
+// Names returns the names of the bookmarks
+func (cb *CameraBookmarks) Names() []string {
+	names := make([]string, len(cb.Bookmarks))
+	for i, bm := range cb.Bookmarks {
+		names[i] = bm.Name
+	}
+	return names
+}
+
+// Bookmark returns the bookmark with the given name, if any
+func (cb *CameraBookmarks) Bookmark(name string) (CameraBookmark, bool) {
+	if i := cb.index(name); i >= 0 {
+		return cb.Bookmarks[i], true
+	}
+	return CameraBookmark{}, false
+}
+
+// SaveCameraBookmark saves the current camera pose under the given
+// name, replacing any bookmark with that name, and writes the
+// bookmarks file if there is a SceneFile
+func (cb *CameraBookmarks) SaveCameraBookmark(name string) error {
+	if name == "" {
+		return errors.New("SaveCameraBookmark: name is empty")
+	}
+	cm := &cb.SceneEditor.SceneXYZ().Camera
+	bm := CameraBookmark{Name: name, Pos: cm.Pose.Pos, Quat: cm.Pose.Quat, Target: cm.Target}
+	if i := cb.index(name); i >= 0 {
+		cb.Bookmarks[i] = bm
+	} else {
+		cb.Bookmarks = append(cb.Bookmarks, bm)
+	}
+	defer cb.changed()
+	return cb.write()
+}
+
+// RestoreCameraBookmark moves the camera from where it is to the
+// bookmark with the given name over the Duration, turning with a
+// slerp, while the position and target are lerped
+func (cb *CameraBookmarks) RestoreCameraBookmark(name string) error {
+	to, ok := cb.Bookmark(name)
+	if !ok {
+		return fmt.Errorf("RestoreCameraBookmark: no bookmark named %q", name)
+	}
+	sw := cb.SceneEditor.SceneWidget()
+	cm := &sw.XYZ.Camera
+	from := CameraBookmark{Pos: cm.Pose.Pos, Quat: cm.Pose.Quat, Target: cm.Target}
+	if cb.anim != nil {
+		cb.anim.Done = true
+		cb.anim = nil
+	}
+	if cb.Duration <= 0 {
+		cb.set(from, to, 1)
+		return nil
+	}
+	elapsed := float32(0)
+	sw.Animate(func(a *core.Animation) {
+		elapsed += a.Dt / 1000
+		t := min(elapsed/float32(cb.Duration.Seconds()), 1)
+		cb.set(from, to, t)
+		if t >= 1 {
+			a.Done = true
+			cb.anim = nil
+		}
+	})
+	// the animation is only run at the next tick, so it is kept now
+	// for another restore before then to stop
+	cb.anim = sw.Scene.Animations[len(sw.Scene.Animations)-1]
+	return nil
+}
+
+// set sets the camera at normalized time t of moving between the given poses
+func (cb *CameraBookmarks) set(from, to CameraBookmark, t float32) {
+	if cb.Easing != nil {
+		t = cb.Easing(t)
+	}
+	sw := cb.SceneEditor.SceneWidget()
+	cm := &sw.XYZ.Camera
+	cm.Pose.Pos = from.Pos.Lerp(to.Pos, t)
+	cm.Target = from.Target.Lerp(to.Target, t)
+	cm.Pose.Quat = Slerp(from.Quat, to.Quat, t)
+	cm.UpdateMatrix()
+	sw.XYZ.SetNeedsRender()
+	sw.NeedsRender()
+}
+
+// index returns the index of the bookmark with the given name, or -1
+func (cb *CameraBookmarks) index(name string) int {
+	return slices.IndexFunc(cb.Bookmarks, func(bm CameraBookmark) bool { return bm.Name == name })
+}
+
+// write writes the bookmarks file of the SceneFile, if there is one
+func (cb *CameraBookmarks) write() error {
+	if cb.SceneFile == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(cb.Bookmarks, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(BookmarksFilename(cb.SceneFile), b, 0666)
+}
+
+// changed calls OnChange, if any
+func (cb *CameraBookmarks) changed() {
+	if cb.OnChange != nil {
+		cb.OnChange()
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/math32"
+)
+
+func TestBookmarksSaveSceneFile(t *testing.T) {
+	cb := NewCameraBookmarks(nil)
+	cb.Bookmarks = []CameraBookmark{{Name: "front", Pos: math32.Vec3(0, 0, 5)}, {Name: "top", Pos: math32.Vec3(0, 5, 0)}}
+	changed := 0
+	cb.OnChange = func() { changed++ }
+	path := filepath.Join(t.TempDir(), "scene.glb")
+	if err := cb.SaveSceneFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// the bookmarks made before saving are opened with the new file
+	op := NewCameraBookmarks(nil)
+	op.OnChange = func() { changed++ }
+	if err := op.SetSceneFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := op.Names(); !slices.Equal(got, []string{"front", "top"}) {
+		t.Errorf("opened the bookmarks %v after saving the scene as a new file", got)
+	}
+	if bm, _ := op.Bookmark("top"); bm.Pos != math32.Vec3(0, 5, 0) {
+		t.Errorf("the top bookmark is at %v", bm.Pos)
+	}
+	if changed != 1 {
+		t.Errorf("OnChange was called %d times instead of once on opening", changed)
+	}
+
+	if err := op.SetSceneFile(filepath.Join(t.TempDir(), "other.glb")); err != nil || len(op.Bookmarks) != 0 {
+		t.Errorf("opening a scene without bookmarks gave %v and the error %v", op.Names(), err)
+	}
+}
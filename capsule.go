@@ -0,0 +1,101 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// CapsuleMesh is a capsule along the Y axis, centered on the origin:
+// a cylinder with a hemisphere at each end, sized like the capsule
+// shapes of physics engines, by the radius and the Height between the
+// centers of the hemispheres. Unlike [xyz.Capsule], it is one surface
+// with a single texture mapping along its whole length, so that the
+// cylinder maps like a cylinder and each end like a sphere cap.
+type CapsuleMesh struct {
+	xyz.MeshBase
+
+	// Radius of the cylinder and the hemispheres
+	Radius float32
+
+	// Height between the centers of the hemispheres,
+	// so the whole capsule is Height + 2 * Radius tall
+	Height float32
+
+	// RadialSegments is the number of segments around the capsule,
+	// and HeightSegments along the cylinder. Each hemisphere has a
+	// quarter of the radial segments from its pole to the cylinder.
+	RadialSegments, HeightSegments int
+}
+
+// NewCapsule returns a new capsule mesh with the given radius, height
+// between the centers of its ends and numbers of segments around it
+// and along its cylinder, set on the given scene under the given name
+func NewCapsule(sc *xyz.Scene, name string, radius, height float32, radialSegments, heightSegments int) *CapsuleMesh {
+	cp := &CapsuleMesh{Radius: radius, Height: height, RadialSegments: radialSegments, HeightSegments: heightSegments}
+	cp.Name = name
+	sc.SetMesh(cp)
+	return cp
+}
+
+// capSegments returns the number of segments of each hemisphere
+func (cp *CapsuleMesh) capSegments() int {
+	return max(cp.RadialSegments/4, 2)
+}
+
+func (cp *CapsuleMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	cp.RadialSegments, cp.HeightSegments = max(cp.RadialSegments, 3), max(cp.HeightSegments, 1)
+	rings := 2*cp.capSegments() + cp.HeightSegments
+	cp.NumVertex = (rings + 1) * (cp.RadialSegments + 1) // the seam is repeated for the texture
+	cp.NumIndex = rings * cp.RadialSegments * 6
+	return cp.NumVertex, cp.NumIndex, false
+}
+
+func (cp *CapsuleMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	nc, nh, nr := cp.capSegments(), cp.HeightSegments, cp.RadialSegments
+	r, h := cp.Radius, cp.Height
+	length := math32.Pi*r + h // along the surface, for the texture
+	rings := 2*nc + nh
+	for k := range rings + 1 {
+		// the elevation of the normal, the center height of the ring,
+		// and the distance to it along the surface from the bottom
+		var elev, y, dist float32
+		switch {
+		case k < nc:
+			elev = math32.Pi / 2 * (float32(k)/float32(nc) - 1)
+			y = -h / 2
+			dist = r * (elev + math32.Pi/2)
+		case k <= nc+nh:
+			y = h * (float32(k-nc)/float32(nh) - 0.5)
+			dist = math32.Pi*r/2 + y + h/2
+		default:
+			elev = math32.Pi / 2 * float32(k-nc-nh) / float32(nc)
+			y = h / 2
+			dist = math32.Pi*r/2 + h + r*elev
+		}
+		ce, se := math32.Cos(elev), math32.Sin(elev)
+		for j := range nr + 1 {
+			a := 2 * math32.Pi * float32(j) / float32(nr)
+			nrm := math32.Vec3(ce*math32.Cos(a), se, -ce*math32.Sin(a))
+			i := k*(nr+1) + j
+			vertex.SetVector3(3*i, nrm.MulScalar(r).Add(math32.Vec3(0, y, 0)))
+			normal.SetVector3(3*i, nrm)
+			texcoord.Set(2*i, float32(j)/float32(nr), 1-dist/length)
+		}
+	}
+	ii := 0
+	for k := range rings {
+		for j := range nr {
+			a := uint32(k*(nr+1) + j)
+			b, c, d := a+uint32(nr+1), a+1, a+uint32(nr+1)+1
+			index.Set(ii, a, c, b, b, c, d)
+			ii += 6
+		}
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, cp.NumVertex)
+	cp.BBox.SetBounds(bb.Min, bb.Max)
+}
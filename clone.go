@@ -0,0 +1,90 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// CloneScene returns a new offline scene with a copy of the camera,
+// lights and nodes of the given scene. See [CopySceneFrom] for
+// what is shared between the two scenes.
+func CloneScene(src *xyz.Scene) *xyz.Scene {
+	sc := xyz.NewScene()
+	CopySceneFrom(sc, src)
+	return sc
+}
+
+// CopySceneFrom replaces the contents of the destination scene with
+// a copy of the camera, lights and nodes of the source scene.
+// Nodes and lights are deep copied, so poses and materials can
+// be changed independently in each scene. Meshes and textures are
+// shared: they are only read when configuring the GPU, and solids
+// refer to them by name, so to change the shape of a solid in only
+// one scene, set a new mesh with a different name on that scene.
+// Each [InstancedSolid] gets its own copy of its instances and of the
+// mesh combining them, since those change with the instances.
+func CopySceneFrom(sc, src *xyz.Scene) {
+	sc.DeleteChildren()
+	sc.ResetMeshes()
+	sc.Lights.Reset()
+	sc.Textures.Reset()
+	for _, kv := range src.Meshes.Order {
+		if _, ok := kv.Value.(*InstancedMesh); !ok { // copied by relinkSolid
+			sc.SetMesh(kv.Value)
+		}
+	}
+	for _, kv := range src.Textures.Order {
+		sc.SetTexture(kv.Value)
+	}
+	for _, kv := range src.Lights.Order {
+		sc.AddLight(cloneLight(kv.Value))
+	}
+	sc.Background = src.Background
+	sc.Camera = src.Camera
+	sc.Camera.UpdateMatrix()
+	for _, k := range src.Children {
+		kc := k.AsTree().Clone()
+		sc.AddChild(kc)
+		kc.AsTree().WalkDown(func(n tree.Node) bool {
+			nd, nb := xyz.AsNode(n)
+			if nb == nil {
+				return tree.Continue
+			}
+			nb.Scene = sc
+			if sld := nd.AsSolid(); sld != nil {
+				relinkSolid(sc, sld)
+			}
+			return tree.Continue
+		})
+	}
+	sc.Rebuild()
+	sc.SetNeedsUpdate()
+}
+
+// relinkSolid points the cloned mesh and texture of the given solid
+// back to the ones shared by the scene, and gives a cloned
+// [InstancedSolid] its own instances
+func relinkSolid(sc *xyz.Scene, sld *xyz.Solid) {
+	if is, ok := sld.This.(*InstancedSolid); ok {
+		is.relink(sc)
+	} else {
+		sld.SetMeshName(string(sld.MeshName))
+	}
+	if sld.Material.TextureName != "" {
+		sld.Material.SetTextureName(sc, string(sld.Material.TextureName))
+	}
+}
+
+// cloneLight returns a copy of the given light
+func cloneLight(lt xyz.Light) xyz.Light {
+	v := reflect.ValueOf(lt).Elem()
+	nv := reflect.New(v.Type())
+	nv.Elem().Set(v)
+	return nv.Interface().(xyz.Light)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+func TestCloneScene(t *testing.T) {
+	src := newDemoTestScene()
+	cl := CloneScene(src)
+	updateTestScene(cl)
+
+	want, got := testSolids(src), testSolids(cl)
+	if len(got) != len(want) {
+		t.Fatalf("cloned %d solids instead of %d", len(got), len(want))
+	}
+	for name, ws := range want {
+		gs := got[name]
+		if gs == nil || gs == ws {
+			t.Errorf("solid %s was not copied", name)
+			continue
+		}
+		if gs.Scene != cl {
+			t.Errorf("the copy of solid %s is not in the cloned scene", name)
+		}
+		if gs.Mesh != ws.Mesh {
+			t.Errorf("the copy of solid %s does not share the mesh", name)
+		}
+		if gs.WorldBBox.BBox != ws.WorldBBox.BBox {
+			t.Errorf("the copy of solid %s has the bounds %v instead of %v", name, gs.WorldBBox.BBox, ws.WorldBBox.BBox)
+		}
+	}
+	if _, ok := got["torus"].Parent.(*xyz.Group); !ok {
+		t.Error("the copy of the torus is not in a group")
+	}
+
+	// changing the clone leaves the source as it was
+	got["cube"].Pose.Pos.Set(5, 5, 5)
+	got["cube"].Material.Color = colors.Black
+	cl.Lights.ValueByKey("directional").(*xyz.Directional).Pos.Set(1, 0, 0)
+	cl.Camera.Pose.Pos.Set(0, 0, 1)
+	if want["cube"].Pose.Pos == math32.Vec3(5, 5, 5) || want["cube"].Material.Color == colors.Black {
+		t.Error("changing the copy of the cube changed the cube")
+	}
+	if src.Lights.ValueByKey("directional").(*xyz.Directional).Pos == math32.Vec3(1, 0, 0) {
+		t.Error("changing the copy of the light changed the light")
+	}
+	if src.Camera.Pose.Pos == math32.Vec3(0, 0, 1) {
+		t.Error("changing the camera of the clone changed the camera")
+	}
+}
+
+func TestCloneInstances(t *testing.T) {
+	src := newTestScene()
+	box := xyz.NewBox(src, "box", 1, 1, 1)
+	small := xyz.NewBox(src, "small", 0.5, 0.5, 0.5)
+	insts := []InstanceData{
+		NewInstance(math32.Vec3(0, 0, 0), colors.Red),
+		NewInstance(math32.Vec3(2, 0, 0), colors.Green),
+	}
+	is := NewInstancedSolid(src, "boxes", box, insts)
+	is.AddLOD(10, small)
+	cl := CloneScene(src)
+	cis := cl.ChildByName("boxes").(*InstancedSolid)
+
+	cis.SetInstance(0, NewInstance(math32.Vec3(0, 3, 0), colors.Blue))
+	cis.updateInstances()
+	if is.Instances[0].Pos.Y != 0 || is.mesh.Instances[0].Pos.Y != 0 {
+		t.Error("changing an instance of the clone changed the instance of the original")
+	}
+	if cis.mesh == nil || cis.mesh == is.mesh || cis.mesh.Instances[0].Pos.Y != 3 {
+		t.Fatal("the clone does not draw its own instances")
+	}
+	if ms, _ := cl.MeshByName(string(cis.MeshName)); ms != cis.mesh {
+		t.Error("the clone does not draw the mesh of its instances in its scene")
+	}
+	if len(cis.lodMeshes) != 1 || cis.lodMeshes[0] == is.lodMeshes[0] || cis.lodMeshes[0].Instances[0].Pos.Y != 3 {
+		t.Error("the clone does not have its own level of detail")
+	}
+	if is.lodMeshes[0].Instances[0].Pos.Y != 0 {
+		t.Error("changing an instance of the clone changed the level of detail of the original")
+	}
+
+	cis.SetInstances(append(cis.Instances, NewInstance(math32.Vec3(4, 0, 0), colors.Blue)))
+	cis.updateInstances()
+	if len(is.Instances) != 2 || len(is.mesh.Instances) != 2 {
+		t.Error("adding an instance to the clone added it to the original")
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/keymap"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// UndoableCommand is an operation on a scene that can be undone
+type UndoableCommand interface {
+	// Execute does, or redoes, the operation
+	Execute()
+
+	// Undo reverses the operation
+	Undo()
+}
+
+// CommandStack records the commands done in a scene editor so that
+// they can be undone and redone. Once attached to an editor, it
+// records dragging objects with the manipulation box, and Ctrl+Z and
+// Ctrl+Shift+Z undo and redo. Dragged objects can snap to a grid,
+// with a [SnapGhost] showing where they would be without snapping.
+type CommandStack struct {
+	// Depth is the maximum number of commands that can be undone
+	Depth int
+
+	// SnapToGrid snaps the world position of dragged objects
+	// to multiples of the SnapSize
+	SnapToGrid bool
+
+	// SnapSize is the grid spacing that positions snap to
+	SnapSize float32
+
+	// Scene editor that the stack is attached to
+	SceneEditor *xyzcore.SceneEditor `set:"-"`
+
+	// commands that can be undone, most recent last
+	undo []UndoableCommand
+
+	// commands that can be redone, most recently undone last
+	redo []UndoableCommand
+
+	// pose of the selected node when dragging started
+	dragNode xyz.Node
+	dragPose xyz.Pose
+
+	// ghost of the node being dragged with snapping, with its
+	// unsnapped position and the snapped position it is shown at
+	ghost      *SnapGhost
+	raw, shown math32.Vector3
+}
+
+// NewCommandStack returns a new command stack with the default
+// depth of 50 and snap size of 0.5
+func NewCommandStack() *CommandStack {
+	return &CommandStack{Depth: 50, SnapSize: 0.5}
+}
+
+// Do executes the given command and records it
+func (cs *CommandStack) Do(cmd UndoableCommand) {
+	cmd.Execute()
+	cs.Push(cmd)
+}
+
+// Push records the given command, which has already been done,
+// dropping the oldest command beyond the Depth and clearing the
+// commands that could be redone
+func (cs *CommandStack) Push(cmd UndoableCommand) {
+	cs.undo = append(cs.undo, cmd)
+	if cs.Depth > 0 && len(cs.undo) > cs.Depth {
+		cs.undo = slices.Delete(cs.undo, 0, len(cs.undo)-cs.Depth)
+	}
+	cs.redo = nil
+}
+
+// Undo undoes the most recent command, returning false if there is none
+func (cs *CommandStack) Undo() bool {
+	if len(cs.undo) == 0 {
+		return false
+	}
+	cmd := cs.undo[len(cs.undo)-1]
+	cs.undo = cs.undo[:len(cs.undo)-1]
+	cmd.Undo()
+	cs.redo = append(cs.redo, cmd)
+	cs.changed()
+	return true
+}
+
+// Redo redoes the most recently undone command, returning false if there is none
+func (cs *CommandStack) Redo() bool {
+	if len(cs.redo) == 0 {
+		return false
+	}
+	cmd := cs.redo[len(cs.redo)-1]
+	cs.redo = cs.redo[:len(cs.redo)-1]
+	cmd.Execute()
+	cs.undo = append(cs.undo, cmd)
+	cs.changed()
+	return true
+}
+
+// CanUndo returns whether there is a command to undo
+func (cs *CommandStack) CanUndo() bool {
+	return len(cs.undo) > 0
+}
+
+// CanRedo returns whether there is a command to redo
+func (cs *CommandStack) CanRedo() bool {
+	return len(cs.redo) > 0
+}
+
+// Clear forgets all of the commands
+func (cs *CommandStack) Clear() {
+	cs.undo, cs.redo = nil, nil
+}
+
+// Attach records the commands done in the given scene editor and
+// lets Ctrl+Z and Ctrl+Shift+Z undo and redo them
+func (cs *CommandStack) Attach(se *xyzcore.SceneEditor) {
+	if cs.SceneEditor != nil {
+		return
+	}
+	cs.SceneEditor = se
+	sw := se.SceneWidget()
+	RegisterKeymapShortcut(se.Scene.Body, keymap.Undo, "Undo the last change to the scene", func() { cs.Undo() })
+	RegisterKeymapShortcut(se.Scene.Body, keymap.Redo, "Redo the last change undone", func() { cs.Redo() })
+	sw.On(events.SlideStart, func(e events.Event) {
+		cs.dragNode = nil
+		if sw.CurrentManipPoint != nil && sw.CurrentSelected != nil {
+			cs.dragNode = sw.CurrentSelected
+			cs.dragPose = cs.dragNode.AsNodeBase().Pose
+			cs.raw, cs.shown = cs.dragPose.Pos, cs.dragPose.Pos
+			if sld := cs.dragNode.AsSolid(); sld != nil && cs.snapSize() > 0 {
+				cs.ghost = newSnapGhost(sld)
+			}
+		}
+	})
+	// The manipulation box moves the node by each slide delta after
+	// this handler, so the unsnapped position is restored first
+	sw.On(events.SlideMove, func(e events.Event) {
+		if cs.ghost != nil && cs.dragNode.AsNodeBase().Pose.Pos == cs.shown {
+			cs.dragNode.AsNodeBase().Pose.Pos = cs.raw
+		}
+	})
+	sw.Animate(func(a *core.Animation) {
+		cs.snapDrag()
+	})
+	sw.On(events.SlideStop, func(e events.Event) {
+		nd := cs.dragNode
+		if nd == nil {
+			return
+		}
+		cs.dragNode = nil
+		snap := float32(0)
+		if cs.ghost != nil {
+			snap = cs.snapSize()
+			if nd.AsNodeBase().Pose.Pos == cs.shown {
+				nd.AsNodeBase().Pose.Pos = cs.raw
+			}
+			cs.ghost.Delete()
+			cs.ghost = nil
+		}
+		from, to := cs.dragPose, nd.AsNodeBase().Pose
+		if snap > 0 || from.Pos != to.Pos {
+			mc := &MoveSolidCommand{Node: nd, From: from.Pos, To: to.Pos, Snap: snap}
+			mc.Execute()
+			if nd.AsNodeBase().Pose.Pos != from.Pos {
+				cs.Push(mc)
+			}
+		}
+		if from.Quat != to.Quat {
+			cs.Push(&RotateSolidCommand{Node: nd, From: from.Quat, To: to.Quat})
+		}
+		if from.Scale != to.Scale {
+			cs.Push(&ScaleSolidCommand{Node: nd, From: from.Scale, To: to.Scale})
+		}
+	})
+}
+
+// snapSize returns the size to snap dragged objects to, or 0 for none
+func (cs *CommandStack) snapSize() float32 {
+	if !cs.SnapToGrid {
+		return 0
+	}
+	return max(cs.SnapSize, 0)
+}
+
+// snapDrag shows the node being dragged at its snapped position
+// and the ghost at its unsnapped position, on each frame
+func (cs *CommandStack) snapDrag() {
+	if cs.ghost == nil {
+		return
+	}
+	nb := cs.dragNode.AsNodeBase()
+	if nb.Pose.Pos == cs.shown {
+		return
+	}
+	cs.raw = nb.Pose.Pos
+	cs.shown = snapPosition(cs.dragNode, cs.raw, cs.snapSize())
+	nb.Pose.Pos = cs.shown
+	cs.ghost.Pos = cs.raw
+	nb.Scene.SetNeedsUpdate()
+	octreeMoved(cs.dragNode)
+	cs.SceneEditor.NeedsRender()
+}
+
+// changed updates the editor after undoing or redoing.
+// The manipulation box does not follow the selected node,
+// and it may have been deleted, so the selection is reset.
+func (cs *CommandStack) changed() {
+	se := cs.SceneEditor
+	if se == nil {
+		return
+	}
+	se.SceneWidget().SetSelected(nil)
+	se.SceneXYZ().SetNeedsUpdate()
+	se.NeedsRender()
+}
+
+// CommandGroup is a list of commands done and undone as one,
+// undoing them in reverse order
+type CommandGroup []UndoableCommand
+
+func (cg CommandGroup) Execute() {
+	for _, cmd := range cg {
+		cmd.Execute()
+	}
+}
+
+func (cg CommandGroup) Undo() {
+	for _, cmd := range slices.Backward(cg) {
+		cmd.Undo()
+	}
+}
+
+// MoveSolidCommand moves a node from one position to another
+type MoveSolidCommand struct {
+	Node     xyz.Node
+	From, To math32.Vector3
+
+	// Snap rounds the world position of To to multiples of
+	// this size when executing, unless it is 0
+	Snap float32
+}
+
+func (mc *MoveSolidCommand) Execute() {
+	to := snapPosition(mc.Node, mc.To, mc.Snap)
+	setPose(mc.Node, func(p *xyz.Pose) { p.Pos = to })
+}
+
+func (mc *MoveSolidCommand) Undo() { setPose(mc.Node, func(p *xyz.Pose) { p.Pos = mc.From }) }
+
+// RotateSolidCommand rotates a node from one orientation to another
+type RotateSolidCommand struct {
+	Node     xyz.Node
+	From, To math32.Quat
+}
+
+func (rc *RotateSolidCommand) Execute() { setPose(rc.Node, func(p *xyz.Pose) { p.Quat = rc.To }) }
+func (rc *RotateSolidCommand) Undo()    { setPose(rc.Node, func(p *xyz.Pose) { p.Quat = rc.From }) }
+
+// ScaleSolidCommand scales a node from one scale to another
+type ScaleSolidCommand struct {
+	Node     xyz.Node
+	From, To math32.Vector3
+}
+
+func (ss *ScaleSolidCommand) Execute() { setPose(ss.Node, func(p *xyz.Pose) { p.Scale = ss.To }) }
+func (ss *ScaleSolidCommand) Undo()    { setPose(ss.Node, func(p *xyz.Pose) { p.Scale = ss.From }) }
+
+// setPose changes the pose of the given node and updates its scene
+func setPose(nd xyz.Node, fun func(p *xyz.Pose)) {
+	nb := nd.AsNodeBase()
+	fun(&nb.Pose)
+	if nb.Scene != nil {
+		nb.Scene.SetNeedsUpdate()
+	}
+	octreeMoved(nd)
+}
+
+// AddSolidCommand adds a node to a parent at the given index in its
+// children, or at the end if Index is negative
+type AddSolidCommand struct {
+	Parent tree.Node
+	Node   xyz.Node
+	Index  int
+}
+
+func (ac *AddSolidCommand) Execute() { insertNode(ac.Parent, ac.Node, ac.Index) }
+func (ac *AddSolidCommand) Undo()    { removeNode(ac.Parent, ac.Node) }
+
+// DeleteSolidCommand removes a node from its parent, keeping the
+// node so that undoing puts it back where it was
+type DeleteSolidCommand struct {
+	Node xyz.Node
+
+	// parent and index of the node before it was removed
+	parent tree.Node
+	index  int
+}
+
+func (dc *DeleteSolidCommand) Execute() {
+	dc.parent = dc.Node.AsTree().Parent
+	dc.index = dc.Node.AsTree().IndexInParent()
+	removeNode(dc.parent, dc.Node)
+}
+
+func (dc *DeleteSolidCommand) Undo() { insertNode(dc.parent, dc.Node, dc.index) }
+
+// insertNode inserts the given node into the children of the parent
+// at the given index, or at the end if it is negative or beyond the end
+func insertNode(parent tree.Node, nd xyz.Node, index int) {
+	if parent == nil {
+		return
+	}
+	pt := parent.AsTree()
+	if index < 0 || index > len(pt.Children) {
+		index = len(pt.Children)
+	}
+	pt.InsertChild(nd, index)
+	if nb := nd.AsNodeBase(); nb.Scene != nil {
+		nb.Scene.SetNeedsUpdate()
+	}
+	octreeMoved(nd)
+}
+
+// removeNode removes the given node from the children of the parent
+// without destroying it, unlike [tree.NodeBase.DeleteChild]
+func removeNode(parent tree.Node, nd xyz.Node) {
+	if parent == nil {
+		return
+	}
+	pt := parent.AsTree()
+	idx := tree.IndexOf(pt.Children, nd)
+	if idx < 0 {
+		return
+	}
+	pt.Children = slices.Delete(pt.Children, idx, idx+1)
+	nd.AsTree().Parent = nil
+	if nb := nd.AsNodeBase(); nb.Scene != nil {
+		nb.Scene.SetNeedsUpdate()
+	}
+	octreeMoved(nd)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// ConeMesh is a cone along the Y axis, with its base centered on the
+// origin and its apex at the Height, where a single vertex is shared by
+// all of the triangles around it. The sides have the smooth normals of
+// a cone, and the normal at the apex is along the axis.
+type ConeMesh struct {
+	xyz.MeshBase
+
+	// Height of the apex above the base
+	Height float32
+
+	// Radius of the base
+	Radius float32
+
+	// RadialSegments is the number of segments around
+	// the cone, and HeightSegments along its sides
+	RadialSegments, HeightSegments int
+
+	// OpenEnded leaves out the disk of the base
+	OpenEnded bool
+}
+
+// NewCone returns a new cone mesh of the given height and base radius,
+// with the given numbers of segments around it and along its sides,
+// and its base left open if openEnded, set on the given scene under
+// the given name. Unlike [xyz.NewCone], the base is at the origin.
+func NewCone(sc *xyz.Scene, name string, height, radius float32, radialSegments, heightSegments int, openEnded bool) *ConeMesh {
+	cn := &ConeMesh{Height: height, Radius: radius, RadialSegments: radialSegments, HeightSegments: heightSegments, OpenEnded: openEnded}
+	cn.Name = name
+	sc.SetMesh(cn)
+	return cn
+}
+
+func (cn *ConeMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	cn.RadialSegments, cn.HeightSegments = max(cn.RadialSegments, 3), max(cn.HeightSegments, 1)
+	nr, nh := cn.RadialSegments, cn.HeightSegments
+	// the rings below the apex, with the seam repeated for the texture, and the apex
+	cn.NumVertex = nh*(nr+1) + 1
+	cn.NumIndex = (nh-1)*nr*6 + nr*3
+	if !cn.OpenEnded {
+		cn.NumVertex += nr + 2
+		cn.NumIndex += nr * 3
+	}
+	return cn.NumVertex, cn.NumIndex, false
+}
+
+func (cn *ConeMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	nr, nh := cn.RadialSegments, cn.HeightSegments
+	h, r := cn.Height, cn.Radius
+	// the normals of the sides lean in by the slope of the cone
+	slant := math32.Vec2(h, r).Normal()
+	radial := func(j int) math32.Vector3 {
+		a := 2 * math32.Pi * float32(j) / float32(nr)
+		return math32.Vec3(math32.Cos(a), 0, -math32.Sin(a))
+	}
+	for k := range nh {
+		f := float32(k) / float32(nh)
+		for j := range nr + 1 {
+			rd := radial(j)
+			i := k*(nr+1) + j
+			vertex.SetVector3(3*i, rd.MulScalar(r*(1-f)).Add(math32.Vec3(0, h*f, 0)))
+			normal.SetVector3(3*i, rd.MulScalar(slant.X).Add(math32.Vec3(0, slant.Y, 0)))
+			texcoord.Set(2*i, float32(j)/float32(nr), 1-f)
+		}
+	}
+	apex := uint32(nh * (nr + 1))
+	vertex.Set(3*int(apex), 0, h, 0)
+	normal.Set(3*int(apex), 0, 1, 0)
+	texcoord.Set(2*int(apex), 0.5, 0)
+
+	ii := 0
+	for k := range nh {
+		for j := range nr {
+			a := uint32(k*(nr+1) + j)
+			c := a + 1
+			if k == nh-1 {
+				index.Set(ii, a, c, apex)
+				ii += 3
+				continue
+			}
+			b, d := a+uint32(nr+1), a+uint32(nr+1)+1
+			index.Set(ii, a, c, b, b, c, d)
+			ii += 6
+		}
+	}
+	if !cn.OpenEnded {
+		ctr := int(apex) + 1
+		vertex.Set(3*ctr, 0, 0, 0)
+		normal.Set(3*ctr, 0, -1, 0)
+		texcoord.Set(2*ctr, 0.5, 0.5)
+		for j := range nr + 1 {
+			rd := radial(j)
+			i := ctr + 1 + j
+			vertex.SetVector3(3*i, rd.MulScalar(r))
+			normal.Set(3*i, 0, -1, 0)
+			texcoord.Set(2*i, 0.5+rd.X/2, 0.5+rd.Z/2)
+			if j < nr {
+				index.Set(ii, uint32(ctr), uint32(i+1), uint32(i))
+				ii += 3
+			}
+		}
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, cn.NumVertex)
+	cn.BBox.SetBounds(bb.Min, bb.Max)
+}
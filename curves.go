@@ -0,0 +1,189 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// curveWidth is the width of the lines of new curve meshes
+var curveWidth = math32.Vec2(0.02, 0.02)
+
+// NewCatmullRomSpline returns a new lines mesh of a Catmull-Rom spline
+// through the given points, set on the given scene under the given
+// name, with the given number of segments between each two points.
+// If closed, the spline goes back through the first point. Like all
+// lines, it is drawn for the XY plane; set its Width to change the
+// width of the line, or use [NewTube] with [CatmullRomPoints] for a
+// round tube along it.
+func NewCatmullRomSpline(sc *xyz.Scene, name string, points []math32.Vector3, segments int, closed bool) *xyz.Lines {
+	pts, _ := CatmullRomPoints(points, segments, closed)
+	return xyz.NewLines(sc, name, pts, curveWidth, closed)
+}
+
+// CatmullRomPoints returns the points of a Catmull-Rom spline through
+// the given points, with the given number of segments between each two
+// points, and the unit tangent of the spline at each, for orienting
+// shapes along it. If closed, the spline goes back to the first point,
+// which is not repeated at the end. Open splines start and end with
+// the slope toward the next and from the previous points.
+func CatmullRomPoints(points []math32.Vector3, segments int, closed bool) (pts, tangents []math32.Vector3) {
+	n := len(points)
+	if n < 2 {
+		return append(pts, points...), make([]math32.Vector3, n)
+	}
+	segments = max(segments, 1)
+	ctrl := func(i int) math32.Vector3 {
+		switch {
+		case closed:
+			return points[(i%n+n)%n]
+		case i < 0: // reflected, so the ends have no curvature
+			return points[0].MulScalar(2).Sub(points[1])
+		case i >= n:
+			return points[n-1].MulScalar(2).Sub(points[n-2])
+		}
+		return points[i]
+	}
+	spans := n - 1
+	if closed {
+		spans = n
+	}
+	for s := range spans {
+		p0, p1, p2, p3 := ctrl(s-1), ctrl(s), ctrl(s+1), ctrl(s+2)
+		// the polynomial coefficients, times two
+		b := p2.Sub(p0)
+		c := p0.MulScalar(2).Sub(p1.MulScalar(5)).Add(p2.MulScalar(4)).Sub(p3)
+		d := p1.Sub(p2).MulScalar(3).Add(p3).Sub(p0)
+		last := segments
+		if closed || s < spans-1 {
+			last-- // the next span starts with its end
+		}
+		for i := 0; i <= last; i++ {
+			t := float32(i) / float32(segments)
+			pt := p1.MulScalar(2).Add(b.MulScalar(t)).Add(c.MulScalar(t * t)).Add(d.MulScalar(t * t * t))
+			tan := b.Add(c.MulScalar(2 * t)).Add(d.MulScalar(3 * t * t))
+			pts = append(pts, pt.MulScalar(0.5))
+			tangents = append(tangents, tan.Normal())
+		}
+	}
+	return
+}
+
+// NewBezierCurve returns a new lines mesh of the cubic Bezier curve
+// from p0 to p3 with the control points p1 and p2, set on the given
+// scene under the given name, with the given number of segments.
+// Use [NewTube] with [BezierPoints] for a tube along it instead.
+func NewBezierCurve(sc *xyz.Scene, name string, p0, p1, p2, p3 math32.Vector3, segments int) *xyz.Lines {
+	return xyz.NewLines(sc, name, BezierPoints(p0, p1, p2, p3, segments), curveWidth, xyz.OpenLines)
+}
+
+// BezierPoints returns the segments+1 points of the cubic Bezier
+// curve from p0 to p3 with the control points p1 and p2
+func BezierPoints(p0, p1, p2, p3 math32.Vector3, segments int) []math32.Vector3 {
+	segments = max(segments, 1)
+	pts := make([]math32.Vector3, segments+1)
+	for i := range pts {
+		t := float32(i) / float32(segments)
+		u := 1 - t
+		pts[i] = p0.MulScalar(u * u * u).Add(p1.MulScalar(3 * u * u * t)).
+			Add(p2.MulScalar(3 * u * t * t)).Add(p3.MulScalar(t * t * t))
+	}
+	return pts
+}
+
+// NewBezierPath returns a new lines mesh of a path of cubic Bezier
+// curves, set on the given scene under the given name, with the given
+// number of segments for each curve. See [BezierPathPoints] for the
+// points defining the curves.
+func NewBezierPath(sc *xyz.Scene, name string, points []math32.Vector3, segments int) *xyz.Lines {
+	return xyz.NewLines(sc, name, BezierPathPoints(points, segments), curveWidth, xyz.OpenLines)
+}
+
+// BezierPathPoints returns the points of a path of cubic Bezier curves
+// with the given number of segments for each curve. The first four of
+// the given points are the first curve, p0 to p3, and each two after
+// them are the second control point and end of the next curve. Its
+// first control point mirrors the second one of the curve before across
+// their shared end point, so the path has no kinks. Any last point
+// without a pair is ignored.
+func BezierPathPoints(points []math32.Vector3, segments int) []math32.Vector3 {
+	if len(points) < 4 {
+		return append([]math32.Vector3(nil), points...)
+	}
+	pts := BezierPoints(points[0], points[1], points[2], points[3], segments)
+	for i := 4; i+1 < len(points); i += 2 {
+		p0, prev := points[i-1], points[i-2]
+		p1 := p0.MulScalar(2).Sub(prev)
+		pts = append(pts, BezierPoints(p0, p1, points[i], points[i+1], segments)[1:]...)
+	}
+	return pts
+}
+
+// NewBSpline returns a new lines mesh of a uniform B-spline of the
+// given degree with the given control points, set on the given scene
+// under the given name, with the given number of segments for each
+// span between knots. It is clamped, so it starts and ends at the
+// first and last control points; use [NewPeriodicBSpline] for a
+// closed one. Use [NewTube] with [BSplinePoints] for a tube along it.
+func NewBSpline(sc *xyz.Scene, name string, controlPoints []math32.Vector3, degree, resolution int) *xyz.Lines {
+	return xyz.NewLines(sc, name, BSplinePoints(controlPoints, degree, resolution, false), curveWidth, xyz.OpenLines)
+}
+
+// NewPeriodicBSpline returns a new lines mesh of a closed uniform
+// B-spline, like [NewBSpline], which goes around near the control
+// points without passing through them.
+func NewPeriodicBSpline(sc *xyz.Scene, name string, controlPoints []math32.Vector3, degree, resolution int) *xyz.Lines {
+	return xyz.NewLines(sc, name, BSplinePoints(controlPoints, degree, resolution, true), curveWidth, xyz.CloseLines)
+}
+
+// BSplinePoints returns the points of a uniform B-spline of the given
+// degree with the given control points, with the given number of
+// segments for each span between knots. The degree is at most one
+// less than the number of control points, and a degree of 1 gives
+// the lines between them. If periodic, the spline is closed, and its
+// first point is not repeated at the end; otherwise it is clamped,
+// starting and ending at the first and last control points.
+func BSplinePoints(controlPoints []math32.Vector3, degree, resolution int, periodic bool) []math32.Vector3 {
+	n := len(controlPoints)
+	if n < 2 {
+		return append([]math32.Vector3(nil), controlPoints...)
+	}
+	p := min(max(degree, 1), n-1)
+	resolution = max(resolution, 1)
+	ctrl := controlPoints
+	var knot func(i int) float32
+	spans := n - p
+	if periodic {
+		// the first p points are repeated at the end, with uniform knots
+		ctrl = append(append([]math32.Vector3(nil), controlPoints...), controlPoints[:p]...)
+		knot = func(i int) float32 { return float32(i - p) }
+		spans = n
+	} else {
+		// p+1 knots at each end, so the ends are at the first and last points
+		knot = func(i int) float32 { return float32(min(max(i-p, 0), spans)) }
+	}
+	last := spans * resolution
+	if periodic {
+		last--
+	}
+	pts := make([]math32.Vector3, 0, last+1)
+	d := make([]math32.Vector3, p+1)
+	for s := 0; s <= last; s++ {
+		t := float32(s) / float32(resolution)
+		k := min(s/resolution, spans-1) + p // the knot span of t
+		// de Boor's algorithm
+		copy(d, ctrl[k-p:k+1])
+		for r := 1; r <= p; r++ {
+			for j := p; j >= r; j-- {
+				t0, t1 := knot(j+k-p), knot(j+1+k-r)
+				a := (t - t0) / (t1 - t0)
+				d[j] = d[j-1].MulScalar(1 - a).Add(d[j].MulScalar(a))
+			}
+		}
+		pts = append(pts, d[p])
+	}
+	return pts
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/math32"
+)
+
+// testCurvePoints are the control points of the test curves
+var testCurvePoints = []math32.Vector3{{X: 0, Y: 0, Z: 0}, {X: 1, Y: 2, Z: 0}, {X: 3, Y: 2, Z: 1}, {X: 4, Y: 0, Z: 1}}
+
+// nearVec returns whether the given vectors are within 1e-5 of each other
+func nearVec(a, b math32.Vector3) bool {
+	return a.Sub(b).Length() < 1e-5
+}
+
+func TestCatmullRomPoints(t *testing.T) {
+	pts, tans := CatmullRomPoints(testCurvePoints, 8, false)
+	if len(pts) != 3*8+1 || len(tans) != len(pts) {
+		t.Fatalf("the open spline has %d points and %d tangents instead of %d", len(pts), len(tans), 3*8+1)
+	}
+	for i, p := range testCurvePoints {
+		if !nearVec(pts[8*i], p) {
+			t.Errorf("the spline goes through %v instead of control point %d at %v", pts[8*i], i, p)
+		}
+	}
+	// the tangent at each inner point is along the line between its neighbors
+	for i := 1; i < 3; i++ {
+		want := testCurvePoints[i+1].Sub(testCurvePoints[i-1]).Normal()
+		if !nearVec(tans[8*i], want) {
+			t.Errorf("the tangent at control point %d is %v instead of %v", i, tans[8*i], want)
+		}
+	}
+	for i := 1; i < len(pts); i++ {
+		if step := pts[i].Sub(pts[i-1]); step.Length() > 0.5 || step.Normal().Dot(tans[i]) < 0.5 {
+			t.Errorf("the spline jumps by %v to point %d, against its tangent %v", step, i, tans[i])
+		}
+	}
+
+	closed, _ := CatmullRomPoints(testCurvePoints, 8, true)
+	if len(closed) != 4*8 || !nearVec(closed[0], testCurvePoints[0]) {
+		t.Errorf("the closed spline has %d points instead of %d, starting at %v", len(closed), 4*8, closed[0])
+	}
+	if step := closed[0].Sub(closed[len(closed)-1]).Length(); step > 0.5 {
+		t.Errorf("the closed spline has a gap of %g back to its start", step)
+	}
+}
+
+func TestBezierPoints(t *testing.T) {
+	p := testCurvePoints
+	pts := BezierPoints(p[0], p[1], p[2], p[3], 10)
+	if len(pts) != 11 || !nearVec(pts[0], p[0]) || !nearVec(pts[10], p[3]) {
+		t.Fatalf("the curve has %d points from %v to %v", len(pts), pts[0], pts[len(pts)-1])
+	}
+	// the middle of the curve is the mean of the points weighted 1 3 3 1
+	mid := p[0].Add(p[1].MulScalar(3)).Add(p[2].MulScalar(3)).Add(p[3]).DivScalar(8)
+	if !nearVec(pts[5], mid) {
+		t.Errorf("the middle of the curve is %v instead of %v", pts[5], mid)
+	}
+	// the curve leaves along the line to the first control point
+	if d := pts[1].Sub(pts[0]).Normal().Dot(p[1].Sub(p[0]).Normal()); d < 0.99 {
+		t.Errorf("the curve starts at an angle of %g to its first control point", math32.Acos(d))
+	}
+}
+
+func TestBezierPathPoints(t *testing.T) {
+	path := slices.Concat(testCurvePoints, []math32.Vector3{{X: 6, Y: -1}, {X: 7, Y: 1}, {X: 9, Y: 9, Z: 9}})
+	pts := BezierPathPoints(path, 10)
+	if len(pts) != 21 || !nearVec(pts[10], path[3]) || !nearVec(pts[20], path[5]) {
+		t.Fatalf("the path of two curves has %d points, through %v to %v", len(pts), pts[10], pts[len(pts)-1])
+	}
+	// no kink where the curves meet
+	in, out := pts[10].Sub(pts[9]).Normal(), pts[11].Sub(pts[10]).Normal()
+	if in.Dot(out) < 0.99 {
+		t.Errorf("the path turns from %v to %v where the curves meet", in, out)
+	}
+	if n := len(BezierPathPoints(path[:3], 10)); n != 3 {
+		t.Errorf("the path of too few points has %d points instead of them", n)
+	}
+}
+
+func TestBSplinePoints(t *testing.T) {
+	p := testCurvePoints
+	pts := BSplinePoints(p, 3, 10, false)
+	// a clamped cubic spline of 4 points is their Bezier curve
+	bez := BezierPoints(p[0], p[1], p[2], p[3], 10)
+	if len(pts) != len(bez) {
+		t.Fatalf("the spline has %d points instead of %d", len(pts), len(bez))
+	}
+	for i := range pts {
+		if !nearVec(pts[i], bez[i]) {
+			t.Errorf("point %d of the spline is %v instead of %v", i, pts[i], bez[i])
+		}
+	}
+	// degree 1 is the lines between the points
+	lin := BSplinePoints(p, 1, 2, false)
+	if len(lin) != 7 || !nearVec(lin[1], p[0].Add(p[1]).MulScalar(0.5)) || !nearVec(lin[6], p[3]) {
+		t.Errorf("the spline of degree 1 is %v", lin)
+	}
+}
+
+func TestPeriodicBSplinePoints(t *testing.T) {
+	p := testCurvePoints
+	pts := BSplinePoints(p, 2, 10, true)
+	if len(pts) != 4*10 {
+		t.Fatalf("the closed spline has %d points instead of %d", len(pts), 4*10)
+	}
+	// each knot of a uniform quadratic spline is at the middle of two points
+	for i := range p {
+		mid := p[i].Add(p[(i+1)%4]).MulScalar(0.5)
+		if !nearVec(pts[10*i], mid) {
+			t.Errorf("knot %d of the closed spline is %v instead of %v", i, pts[10*i], mid)
+		}
+	}
+	if step := pts[0].Sub(pts[len(pts)-1]).Length(); step > 0.5 {
+		t.Errorf("the closed spline has a gap of %g back to its start", step)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// The phong renderer has a single render pass, whose pipelines always
+// test and write depth, so solids are drawn over the rest of the scene
+// by pulling them toward the camera until they are just past its near
+// plane, where they look the same: scaled about the camera position for
+// perspective, and moved along the view direction for orthographic. Not
+// writing depth is approximated by drawing with the transparent solids,
+// back to front after the opaque ones, so that the solids behind are
+// drawn before it, and it does not hide them. The pipelines have no
+// depth bias either, so the polygon offset of a solid moves it toward
+// or away from the camera in the same way.
+
+// depthPull returns the matrix that pulls what is within the given
+// radius of the given world point toward the given camera until it is
+// just past its near plane, looking the same, and whether it is in front
+// of the camera, which it must be to be pulled. It is the identity for
+// what is already that close.
+func depthPull(cm *xyz.Camera, center math32.Vector3, radius float32) (math32.Matrix4, bool) {
+	depth := -center.MulMatrix4(&cm.ViewMatrix).Z
+	near := 2 * cm.Near
+	if !cm.Ortho && depth <= cm.Near {
+		var pull math32.Matrix4
+		pull.SetIdentity()
+		return pull, false
+	}
+	dist := float32(0)
+	if cm.Ortho {
+		dist = min(near+radius-depth, 0)
+	} else if front := depth - radius; front > near {
+		dist = depth*near/front - depth
+	}
+	return depthShift(cm, center, dist), true
+}
+
+// depthShift returns the matrix that moves what is at the given world
+// point by the given distance along the view direction of the given
+// camera, looking the same, away from it for a positive distance
+func depthShift(cm *xyz.Camera, center math32.Vector3, dist float32) math32.Matrix4 {
+	var shift math32.Matrix4
+	shift.SetIdentity()
+	depth := -center.MulMatrix4(&cm.ViewMatrix).Z
+	if cm.Ortho {
+		fwd := math32.Vec3(0, 0, -1).MulQuat(cm.Pose.Quat)
+		shift.SetTranslation(fwd.X*dist, fwd.Y*dist, fwd.Z*dist)
+	} else if depth > cm.Near && depth+dist > cm.Near {
+		k := (depth + dist) / depth
+		c := cm.Pose.Pos.MulScalar(1 - k)
+		shift.SetTransform(c, math32.NewQuat(0, 0, 0, 1), math32.Vec3(k, k, k))
+	}
+	return shift
+}
+
+// polygonOffset returns the distance that the polygon offset of the
+// solid moves it along the view direction, at the given depth. The
+// slope of each fragment is not known here, so the Factor is taken as
+// a thousandth of the depth, and the Units as the resolution of a depth
+// buffer of 24 bits there.
+func (sld *Solid) polygonOffset(depth float32) float32 {
+	cm := &sld.Scene.Camera
+	res := (cm.Far - cm.Near) / (1 << 24)
+	if !cm.Ortho {
+		res = depth * depth / (max(cm.Near, 1e-6) * (1 << 24))
+	}
+	return sld.PolygonOffsetFactor*1e-3*depth + sld.PolygonOffsetUnits*res
+}
+
+// withDepthPull calls the given function with the world matrix of the
+// solid pulled in front of the rest of the scene if it has no DepthTest,
+// or moved by its PolygonOffset, if any, for uploading it to be drawn there
+func (sld *Solid) withDepthPull(fun func()) {
+	if sld.DepthTest && !sld.PolygonOffset {
+		fun()
+		return
+	}
+	cm := &sld.Scene.Camera
+	bb := sld.WorldBBox.BBox
+	ctr := bb.Center()
+	var pull math32.Matrix4
+	if sld.DepthTest {
+		depth := -ctr.MulMatrix4(&cm.ViewMatrix).Z
+		pull = depthShift(cm, ctr, sld.polygonOffset(depth))
+	} else {
+		var ok bool
+		pull, ok = depthPull(cm, ctr, 0.5*bb.Size().Length())
+		if !ok {
+			fun()
+			return
+		}
+	}
+	wm := sld.Pose.WorldMatrix
+	sld.Pose.WorldMatrix.MulMatrices(&pull, &wm)
+	defer func() { sld.Pose.WorldMatrix = wm }()
+	fun()
+}
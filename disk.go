@@ -0,0 +1,82 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// Disk is a flat ring in the XZ plane facing up, centered on the
+// origin, between an inner and an outer radius, or a whole disk if
+// the inner radius is 0. The texture is mapped with U around the
+// ring and V from 0 at the inner edge to 1 at the outer edge.
+type Disk struct {
+	xyz.MeshBase
+
+	// InnerRadius is the radius of the hole in the middle, or 0 for none
+	InnerRadius float32
+
+	// OuterRadius is the radius of the outer edge
+	OuterRadius float32
+
+	// Segments is the number of rings of segments from
+	// the inner to the outer edge
+	Segments int
+
+	// ThetaSegments is the number of segments around the ring
+	ThetaSegments int
+}
+
+// NewDisk returns a new disk mesh between the given inner and outer
+// radii, with the given numbers of segments from the inner to the
+// outer edge and around it, set on the given scene under the given name
+func NewDisk(sc *xyz.Scene, name string, innerRadius, outerRadius float32, segments, thetaSegments int) *Disk {
+	dk := &Disk{InnerRadius: innerRadius, OuterRadius: outerRadius, Segments: segments, ThetaSegments: thetaSegments}
+	dk.Name = name
+	sc.SetMesh(dk)
+	return dk
+}
+
+func (dk *Disk) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	dk.Segments, dk.ThetaSegments = max(dk.Segments, 1), max(dk.ThetaSegments, 3)
+	dk.NumVertex = (dk.Segments + 1) * (dk.ThetaSegments + 1) // the seam is repeated for the texture
+	dk.NumIndex = dk.Segments * dk.ThetaSegments * 6
+	if dk.InnerRadius == 0 {
+		dk.NumIndex -= dk.ThetaSegments * 3 // the triangles with no area in the middle
+	}
+	return dk.NumVertex, dk.NumIndex, false
+}
+
+func (dk *Disk) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	nr, nt := dk.Segments, dk.ThetaSegments
+	for k := range nr + 1 {
+		f := float32(k) / float32(nr)
+		rad := dk.InnerRadius + f*(dk.OuterRadius-dk.InnerRadius)
+		for j := range nt + 1 {
+			a := 2 * math32.Pi * float32(j) / float32(nt)
+			i := k*(nt+1) + j
+			vertex.Set(3*i, rad*math32.Cos(a), 0, -rad*math32.Sin(a))
+			normal.Set(3*i, 0, 1, 0)
+			texcoord.Set(2*i, float32(j)/float32(nt), f)
+		}
+	}
+	ii := 0
+	for k := range nr {
+		for j := range nt {
+			a := uint32(k*(nt+1) + j)
+			b, c, d := a+uint32(nt+1), a+1, a+uint32(nt+1)+1
+			if k > 0 || dk.InnerRadius != 0 {
+				index.Set(ii, a, b, c)
+				ii += 3
+			}
+			index.Set(ii, b, d, c)
+			ii += 3
+		}
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, dk.NumVertex)
+	dk.BBox.SetBounds(bb.Min, bb.Max)
+}
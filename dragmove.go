@@ -0,0 +1,239 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// DragModes are the ways a [DragMover] moves the dragged node
+type DragModes int32
+
+const (
+	// DragFree moves the node in the plane through the point where it
+	// was grabbed that faces the camera
+	DragFree DragModes = iota
+
+	// DragX moves the node along the world X axis
+	DragX
+
+	// DragY moves the node along the world Y axis
+	DragY
+
+	// DragZ moves the node along the world Z axis
+	DragZ
+)
+
+// dragKeys are the keys held down to drag along an axis
+var dragKeys = map[key.Codes]DragModes{
+	key.CodeX: DragX,
+	key.CodeY: DragY,
+	key.CodeZ: DragZ,
+}
+
+// DragMover moves the selected node of a scene widget by dragging it
+// with the left mouse button, so that the point on it that was grabbed
+// follows the mouse. It moves in the plane facing the camera, or along
+// the X, Y or Z axis of the world while that key is held down, as
+// given by the Mode. The move is recorded in the Commands, if any, to
+// undo it, snapping to their grid if they do.
+type DragMover struct {
+	// Mode is how the node is moved, from the keys held down
+	Mode DragModes `edit:"-"`
+
+	// Commands records the moves, so that they can be undone
+	Commands *CommandStack
+
+	// Scene widget whose selected node is dragged
+	SceneWidget *xyzcore.Scene `set:"-"`
+
+	// keys held down that set the Mode
+	down map[key.Codes]bool
+
+	// node that the mouse was pressed on, and where
+	pressed xyz.Node
+	grab    math32.Vector3
+
+	// node being dragged, with its pose and world position when
+	// dragging started, and the mouse ray at the start
+	dragNode  xyz.Node
+	dragPose  xyz.Pose
+	dragWorld math32.Vector3
+	startRay  math32.Ray
+}
+
+// NewDragMover returns a new drag mover
+func NewDragMover() *DragMover {
+	return &DragMover{}
+}
+
+// Attach lets the selected node of the given scene widget be dragged
+func (dm *DragMover) Attach(sw *xyzcore.Scene) {
+	if dm.SceneWidget != nil {
+		return
+	}
+	dm.SceneWidget = sw
+	dm.down = map[key.Codes]bool{}
+	sw.On(events.KeyDown, func(e events.Event) {
+		if _, ok := dragKeys[e.KeyCode()]; ok {
+			dm.down[e.KeyCode()] = true
+			dm.updateMode()
+		}
+	})
+	sw.On(events.KeyUp, func(e events.Event) {
+		delete(dm.down, e.KeyCode())
+		dm.updateMode()
+	})
+
+	// these are added after the scene widget handlers, so they are
+	// called first, and keep it from selecting what is behind the
+	// node and from orbiting the camera while it is dragged
+	sw.On(events.MouseDown, func(e events.Event) {
+		dm.pressed = nil
+		if e.MouseButton() != events.Left || e.HasAnyModifier(key.Control, key.Alt) {
+			return
+		}
+		if grab, ok := dm.pick(e); ok {
+			e.SetHandled()
+			dm.pressed, dm.grab = sw.CurrentSelected, grab
+		}
+	})
+	sw.On(events.SlideStart, func(e events.Event) {
+		if dm.pressed == nil || dm.pressed != sw.CurrentSelected {
+			return
+		}
+		ray, ok := eventRay(sw, e)
+		if !ok {
+			return
+		}
+		e.SetHandled()
+		dm.dragNode = dm.pressed
+		nb := dm.dragNode.AsNodeBase()
+		dm.dragPose = nb.Pose
+		dm.dragWorld = nb.Pose.Pos.MulMatrix4(&nb.Pose.ParMatrix)
+		dm.startRay = ray
+	})
+	sw.On(events.SlideMove, func(e events.Event) {
+		if dm.dragNode == nil {
+			return
+		}
+		e.SetHandled()
+		if ray, ok := eventRay(sw, e); ok {
+			dm.apply(ray)
+		}
+	})
+	sw.On(events.SlideStop, func(e events.Event) {
+		if dm.dragNode == nil {
+			return
+		}
+		e.SetHandled()
+		dm.pressed = nil
+		dm.record()
+		if sw.SelectionMode == xyzcore.Manipulable {
+			sw.SceneXYZ().UpdateNodes()
+			sw.ManipBox() // around where the node is now
+		}
+		sw.NeedsRender()
+	})
+}
+
+// updateMode sets the Mode from the keys held down
+func (dm *DragMover) updateMode() {
+	dm.Mode = DragFree
+	for k, mode := range dragKeys {
+		if dm.down[k] {
+			dm.Mode = mode
+		}
+	}
+}
+
+// pick returns the world point under the mouse of the given event on
+// the selected node, or on one of its solids for a group, if it is
+// there and not behind a point of the manipulation box
+func (dm *DragMover) pick(e events.Event) (math32.Vector3, bool) {
+	sw := dm.SceneWidget
+	sel := sw.CurrentSelected
+	bb := sw.Geom.ContentBBox
+	if sel == nil || bb.Empty() || isLocked(sel) {
+		return math32.Vector3{}, false
+	}
+	sc := sw.SceneXYZ()
+	pos := e.Pos().Sub(bb.Min)
+	for _, n := range xyz.NodesUnderPoint(sc, pos) {
+		if _, ok := n.(*xyzcore.ManipPoint); ok {
+			return math32.Vector3{}, false
+		}
+	}
+	hit, _, dist, ok := PickAtPixel(sc, pos.X, pos.Y, math32.FromPoint(bb.Size()))
+	if !ok || (hit.This != sel && hit.ParentLevel(sel) < 0) {
+		return math32.Vector3{}, false
+	}
+	ray, _ := eventRay(sw, e)
+	return ray.At(dist), true
+}
+
+// dragPoint returns the point for the given mouse ray that the
+// grabbed point moves to for the Mode, and false if there is none,
+// when the plane is seen edge on or the axis end on
+func (dm *DragMover) dragPoint(ray math32.Ray) (math32.Vector3, bool) {
+	if dm.Mode == DragFree {
+		cm := &dm.SceneWidget.SceneXYZ().Camera
+		n := math32.Vec3(0, 0, -1).MulQuat(cm.Pose.Quat)
+		t, ok := RayIntersectPlane(ray, PlaneThrough(n, dm.grab))
+		return ray.At(t), ok
+	}
+	dir := gizmoAxes[dm.Mode-DragX].dir
+	w := dm.grab.Sub(ray.Origin)
+	b := dir.Dot(ray.Dir)
+	den := 1 - b*b
+	if den < 1e-4 {
+		return math32.Vector3{}, false
+	}
+	return dm.grab.Add(dir.MulScalar((b*ray.Dir.Dot(w) - dir.Dot(w)) / den)), true
+}
+
+// apply moves the dragged node for the given mouse ray, by how far the
+// grabbed point has moved from where it was at the start of the drag.
+// Both are found for the current Mode, so that it can change midway.
+func (dm *DragMover) apply(ray math32.Ray) {
+	to, ok := dm.dragPoint(ray)
+	if !ok {
+		return
+	}
+	from, ok := dm.dragPoint(dm.startRay)
+	if !ok {
+		return
+	}
+	nb := dm.dragNode.AsNodeBase()
+	inv, err := nb.Pose.ParMatrix.Inverse()
+	if err != nil {
+		return
+	}
+	pos := dm.dragWorld.Add(to.Sub(from)).MulMatrix4(inv)
+	if dm.Commands != nil {
+		pos = snapPosition(dm.dragNode, pos, dm.Commands.snapSize())
+	}
+	nb.Pose.Pos = pos
+	nb.Scene.SetNeedsUpdate()
+	octreeMoved(dm.dragNode)
+	dm.SceneWidget.NeedsRender()
+}
+
+// record adds the move of the dragged node to the Commands
+func (dm *DragMover) record() {
+	nd := dm.dragNode
+	dm.dragNode = nil
+	if dm.Commands == nil || nd == nil {
+		return
+	}
+	from, to := dm.dragPose.Pos, nd.AsNodeBase().Pose.Pos
+	if from != to {
+		dm.Commands.Push(&MoveSolidCommand{Node: nd, From: from, To: to, Snap: dm.Commands.snapSize()})
+	}
+}
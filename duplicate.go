@@ -0,0 +1,181 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// Duplicator copies the selected nodes of a scene editor with the
+// DuplicateKey, putting each copy after its original in the same
+// parent, moved by the DuplicateOffset in world space, and named after
+// it with a suffix like "_copy1". The copies share the meshes and
+// textures of the originals, and have copies of their materials.
+// Only the copies are selected afterwards. Adding them is recorded in
+// the Commands, if any, as one command, to undo it.
+type Duplicator struct {
+	// DuplicateOffset is how far the copies are from the originals
+	DuplicateOffset math32.Vector3
+
+	// DuplicateKey copies the selected nodes, as a [Shortcut]
+	// of the body of the editor registered by Attach
+	DuplicateKey key.Chord
+
+	// Commands records the copying, so that it can be undone
+	Commands *CommandStack
+
+	// Marquee is the selection of many solids, if any,
+	// which are copied along with the selected node
+	Marquee *MarqueeSelect
+
+	// Scene editor whose selected nodes are copied
+	SceneEditor *xyzcore.SceneEditor `set:"-"`
+}
+
+// NewDuplicator returns a new duplicator putting copies half a unit
+// along X and Z from the originals, with Ctrl+D
+func NewDuplicator() *Duplicator {
+	return &Duplicator{DuplicateOffset: math32.Vec3(0.5, 0, 0.5), DuplicateKey: "Control+D"}
+}
+
+// Attach lets the DuplicateKey copy the selected nodes of the given editor
+func (du *Duplicator) Attach(se *xyzcore.SceneEditor) {
+	if du.SceneEditor != nil {
+		return
+	}
+	du.SceneEditor = se
+	if du.DuplicateKey != "" {
+		RegisterShortcut(se.Scene.Body, du.DuplicateKey, "Duplicate the selected objects", func() { du.Duplicate() })
+	}
+}
+
+// Selected returns the selected nodes: the selected node of the
+// scene widget, and the solids of the Marquee selection
+func (du *Duplicator) Selected() []xyz.Node {
+	var nodes []xyz.Node
+	if du.Marquee != nil {
+		for _, sld := range du.Marquee.Selection {
+			nodes = append(nodes, sld.This.(xyz.Node))
+		}
+	}
+	if sel := du.SceneEditor.SceneWidget().CurrentSelected; sel != nil && !slices.Contains(nodes, sel) {
+		nodes = append(nodes, sel)
+	}
+	return nodes
+}
+
+// Duplicate copies the selected nodes and selects the
+// copies, returning them in the order of the originals.
+// Nodes inside another selected node are copied with it.
+func (du *Duplicator) Duplicate() []xyz.Node {
+	sw := du.SceneEditor.SceneWidget()
+	sel := sw.CurrentSelected
+	nodes := selectedRoots(du.Selected())
+	// adding the copies from the last child back keeps the
+	// indexes of the originals before them valid
+	slices.SortStableFunc(nodes, func(a, b xyz.Node) int {
+		return b.AsTree().IndexInParent() - a.AsTree().IndexInParent()
+	})
+	var cmds CommandGroup
+	var copies []xyz.Node
+	var selCopy xyz.Node
+	for _, nd := range nodes {
+		parent := nd.AsTree().Parent
+		if parent == nil {
+			continue
+		}
+		cp := du.duplicate(nd)
+		cmds = append(cmds, &AddSolidCommand{Parent: parent, Node: cp, Index: nd.AsTree().IndexInParent() + 1})
+		copies = append(copies, cp)
+		if nd == sel {
+			selCopy = cp
+		}
+	}
+	if len(copies) == 0 {
+		return nil
+	}
+	slices.Reverse(copies)
+	cmds.Execute()
+	if du.Commands != nil {
+		du.Commands.Push(cmds)
+	}
+	if du.Marquee != nil && len(du.Marquee.Selection) > 0 {
+		var slds []*xyz.Solid
+		for _, cp := range copies {
+			if sld := cp.AsSolid(); sld != nil {
+				slds = append(slds, sld)
+			}
+		}
+		du.Marquee.SetSelection(slds)
+	}
+	sc := du.SceneEditor.SceneXYZ()
+	sc.UpdateNodes()
+	sw.SetSelected(selCopy)
+	du.SceneEditor.NeedsRender()
+	return copies
+}
+
+// selectedRoots returns the given nodes without those
+// inside another one of them
+func selectedRoots(nodes []xyz.Node) []xyz.Node {
+	sel := map[tree.Node]bool{}
+	for _, nd := range nodes {
+		sel[nd.AsTree().This] = true
+	}
+	var roots []xyz.Node
+	for _, nd := range nodes {
+		inside := false
+		for p := nd.AsTree().Parent; p != nil; p = p.AsTree().Parent {
+			if sel[p] {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			roots = append(roots, nd)
+		}
+	}
+	return roots
+}
+
+// duplicate returns a copy of the given node, moved by the DuplicateOffset,
+// with the next free name among the children of its parent
+func (du *Duplicator) duplicate(nd xyz.Node) xyz.Node {
+	nb := nd.AsNodeBase()
+	sc := nb.Scene
+	cp := nd.AsTree().Clone().(xyz.Node)
+	parent := nd.AsTree().Parent
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s_copy%d", nd.AsTree().Name, i)
+		if parent.AsTree().ChildByName(name) == nil {
+			cp.AsTree().SetName(name)
+			break
+		}
+	}
+	cp.AsTree().WalkDown(func(n tree.Node) bool {
+		cn, cb := xyz.AsNode(n)
+		if cb == nil {
+			return tree.Continue
+		}
+		cb.Scene = sc
+		if sld := cn.AsSolid(); sld != nil {
+			relinkSolid(sc, sld)
+		}
+		return tree.Continue
+	})
+	cb := cp.AsNodeBase()
+	if inv, err := nb.Pose.ParMatrix.Inverse(); err == nil {
+		world := nb.Pose.Pos.MulMatrix4(&nb.Pose.ParMatrix).Add(du.DuplicateOffset)
+		cb.Pose.Pos = world.MulMatrix4(inv)
+	}
+	return cp
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// childNames returns the names of the children of the given node
+func childNames(nd tree.Node) []string {
+	var names []string
+	for _, c := range nd.AsTree().Children {
+		names = append(names, c.AsTree().Name)
+	}
+	return names
+}
+
+func TestDuplicateSiblings(t *testing.T) {
+	se := xyzcore.NewSceneEditor(core.NewBody())
+	se.Update() // make the scene
+	sc := se.SceneXYZ()
+	a := newTestBox(sc, "a", 1, math32.Vec3(0, 0, 0))
+	b := newTestBox(sc, "b", 1, math32.Vec3(2, 0, 0))
+	newTestBox(sc, "c", 1, math32.Vec3(4, 0, 0))
+	gp := xyz.NewGroup(sc)
+	gp.SetName("g")
+	inner := xyz.NewSolid(gp).SetMesh(a.Mesh)
+	inner.SetName("inner")
+	updateTestScene(sc)
+
+	du := NewDuplicator()
+	du.Commands = NewCommandStack()
+	du.Marquee = &MarqueeSelect{Selection: []*xyz.Solid{a, b, inner}}
+	du.Attach(se)
+	se.SceneWidget().CurrentSelected = gp
+
+	copies := du.Duplicate()
+	if len(copies) != 3 {
+		t.Fatalf("made %d copies of two solids and a group, not 3", len(copies))
+	}
+	want := []string{"a", "a_copy1", "b", "b_copy1", "c", "g", "g_copy1"}
+	if got := childNames(sc); !slices.Equal(got, want) {
+		t.Errorf("the scene has %v after duplicating, not %v", got, want)
+	}
+	if got := childNames(sc.ChildByName("g_copy1")); !slices.Equal(got, []string{"inner"}) {
+		t.Errorf("the copy of the group has %v, not the one copy of its child", got)
+	}
+	if got := childNames(gp); !slices.Equal(got, []string{"inner"}) {
+		t.Errorf("the selected child of the group was copied into it, which has %v", got)
+	}
+
+	du.Commands.Undo()
+	if got := childNames(sc); !slices.Equal(got, []string{"a", "b", "c", "g"}) {
+		t.Errorf("the scene has %v after undoing, not the originals", got)
+	}
+}
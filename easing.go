@@ -0,0 +1,162 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "cogentcore.org/core/math32"
+
+// Easing functions map a normalized time t in [0, 1] to an eased
+// progress value, which is 0 at t = 0 and 1 at t = 1.
+// Back and elastic curves overshoot outside of [0, 1] in between.
+
+// EaseLinear is the identity easing (constant speed)
+func EaseLinear(t float32) float32 {
+	return t
+}
+
+// EaseInQuad accelerates from zero velocity
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+// EaseOutQuad decelerates to zero velocity
+func EaseOutQuad(t float32) float32 {
+	return 1 - (1-t)*(1-t)
+}
+
+// EaseInOutQuad accelerates until halfway, then decelerates
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	u := -2*t + 2
+	return 1 - u*u/2
+}
+
+// EaseInCubic accelerates from zero velocity, more sharply than quadratic
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+// EaseOutCubic decelerates to zero velocity, more sharply than quadratic
+func EaseOutCubic(t float32) float32 {
+	u := 1 - t
+	return 1 - u*u*u
+}
+
+// EaseInOutCubic accelerates until halfway, then decelerates
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	u := -2*t + 2
+	return 1 - u*u*u/2
+}
+
+// EaseInSine accelerates along a quarter sine wave
+func EaseInSine(t float32) float32 {
+	return 1 - math32.Cos(t*math32.Pi/2)
+}
+
+// EaseOutSine decelerates along a quarter sine wave
+func EaseOutSine(t float32) float32 {
+	return math32.Sin(t * math32.Pi / 2)
+}
+
+// EaseInOutSine accelerates and decelerates along a half sine wave
+func EaseInOutSine(t float32) float32 {
+	return -(math32.Cos(math32.Pi*t) - 1) / 2
+}
+
+const (
+	// easeBackC1 is the standard overshoot amount for the back easings
+	easeBackC1 = 1.70158
+	easeBackC2 = easeBackC1 * 1.525
+	easeBackC3 = easeBackC1 + 1
+)
+
+// EaseInBack pulls back slightly before accelerating forward
+func EaseInBack(t float32) float32 {
+	return easeBackC3*t*t*t - easeBackC1*t*t
+}
+
+// EaseOutBack overshoots the end slightly before settling
+func EaseOutBack(t float32) float32 {
+	u := t - 1
+	return 1 + easeBackC3*u*u*u + easeBackC1*u*u
+}
+
+// EaseInOutBack pulls back at the start and overshoots at the end
+func EaseInOutBack(t float32) float32 {
+	if t < 0.5 {
+		u := 2 * t
+		return u * u * ((easeBackC2+1)*u - easeBackC2) / 2
+	}
+	u := 2*t - 2
+	return (u*u*((easeBackC2+1)*u+easeBackC2) + 2) / 2
+}
+
+const (
+	easeElasticC4 = (2 * math32.Pi) / 3
+	easeElasticC5 = (2 * math32.Pi) / 4.5
+)
+
+// EaseInElastic winds up with a growing oscillation
+func EaseInElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return -math32.Pow(2, 10*t-10) * math32.Sin((t*10-10.75)*easeElasticC4)
+}
+
+// EaseOutElastic springs past the end and oscillates into place
+func EaseOutElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return math32.Pow(2, -10*t)*math32.Sin((t*10-0.75)*easeElasticC4) + 1
+}
+
+// EaseInOutElastic oscillates at both the start and the end
+func EaseInOutElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	if t < 0.5 {
+		return -(math32.Pow(2, 20*t-10) * math32.Sin((20*t-11.125)*easeElasticC5)) / 2
+	}
+	return (math32.Pow(2, -20*t+10)*math32.Sin((20*t-11.125)*easeElasticC5))/2 + 1
+}
+
+// EaseOutBounce bounces against the end like a dropped ball
+func EaseOutBounce(t float32) float32 {
+	const n1 = 7.5625
+	const d1 = 2.75
+	switch {
+	case t < 1/d1:
+		return n1 * t * t
+	case t < 2/d1:
+		t -= 1.5 / d1
+		return n1*t*t + 0.75
+	case t < 2.5/d1:
+		t -= 2.25 / d1
+		return n1*t*t + 0.9375
+	default:
+		t -= 2.625 / d1
+		return n1*t*t + 0.984375
+	}
+}
+
+// EaseInBounce bounces against the start before taking off
+func EaseInBounce(t float32) float32 {
+	return 1 - EaseOutBounce(1-t)
+}
+
+// EaseInOutBounce bounces at both the start and the end
+func EaseInOutBounce(t float32) float32 {
+	if t < 0.5 {
+		return (1 - EaseOutBounce(1-2*t)) / 2
+	}
+	return (1 + EaseOutBounce(2*t-1)) / 2
+}
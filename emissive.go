@@ -0,0 +1,148 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"cogentcore.org/core/base/iox/imagex"
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// emissiveMeshPrefix starts the names of the meshes
+// that the emissive maps of solids are drawn on
+const emissiveMeshPrefix = "__Emissive"
+
+// SetEmissiveMap sets the light given off by the solid, as for neon
+// signs and screens, to the given image over the texture coordinates of
+// its mesh, with black giving off none; nil removes it. The phong shaders
+// of xyz only take a single emissive color for the whole solid, so the
+// map is drawn over the lit surface as a second transparent texture, just
+// above it, covering it as much as the brightest channel of each of its
+// pixels, times the EmissiveIntensity, which is set to 1 if it is not set
+// yet. It is bright enough to bloom in images made with a [Bloom]. The
+// solid must be in a scene.
+func (sld *Solid) SetEmissiveMap(img image.Image) *Solid {
+	sc := sld.Scene
+	name := sld.Name + "-emissive"
+	if string(sld.EmissiveMap) == name {
+		sc.Textures.DeleteKey(name)
+	}
+	sld.EmissiveMap = ""
+	if img == nil {
+		return sld
+	}
+	sld.EmissiveMap = addImageTexture(sc, name, emissionImage(img))
+	if sld.EmissiveIntensity == 0 {
+		sld.EmissiveIntensity = 1
+	}
+	sc.SetNeedsRender()
+	return sld
+}
+
+// emissionImage returns the texture drawn for the given emissive map,
+// with the hue of each pixel at full brightness, and an opacity of its
+// brightest channel, so that its black parts are clear
+func emissionImage(img image.Image) *image.RGBA {
+	src := imagex.AsRGBA(img)
+	b := src.Bounds()
+	em := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := range b.Dy() {
+		for x := range b.Dx() {
+			c := src.RGBAAt(b.Min.X+x, b.Min.Y+y) // alpha premultiplied
+			m := max(c.R, c.G, c.B)
+			if m == 0 {
+				continue
+			}
+			sc := func(v uint8) uint8 { return uint8(int(v) * 255 / int(m)) }
+			// the color is not multiplied by the opacity,
+			// as the shader multiplies it in
+			em.SetRGBA(x, y, color.RGBA{sc(c.R), sc(c.G), sc(c.B), m})
+		}
+	}
+	return em
+}
+
+// preRenderEmissive uploads the emissive map over the solid,
+// making its mesh if it is not there yet
+func (sld *Solid) preRenderEmissive() {
+	sc := sld.Scene
+	en := emissiveMeshName(sld.MeshName)
+	if _, err := sc.MeshByName(en); err != nil {
+		NewEmissiveMesh(sc, en, sld.Mesh)
+	}
+	// a Bright of 4 shows the colors as they are, as for text
+	clr := phong.NewColors(colors.White, colors.Black, 0, 0, 4*max(sld.EmissiveIntensity, 0))
+	clr.Tiling = sld.Material.Tiling
+	sc.Phong.SetObject(sld.emissivePath(), phong.NewObject(&sld.Pose.WorldMatrix, clr))
+}
+
+// renderEmissive renders the emissive map uploaded by preRenderEmissive
+func (sld *Solid) renderEmissive(rp *wgpu.RenderPassEncoder) {
+	ph := sld.Scene.Phong
+	ph.UseObject(sld.emissivePath())
+	ph.UseMesh(emissiveMeshName(sld.MeshName))
+	ph.UseTexture(string(sld.EmissiveMap))
+	ph.Render(rp)
+}
+
+// emissivePath returns the name of the render object for the emissive map
+func (sld *Solid) emissivePath() string {
+	return sld.Path() + "/__emissive"
+}
+
+// EmissiveMesh is a copy of another mesh pushed out a little along its
+// normals, with the same texture coordinates, to draw an emissive map
+// over it without being hidden by it
+type EmissiveMesh struct {
+	xyz.MeshBase
+
+	// Source mesh copied
+	Source xyz.Mesh
+}
+
+// NewEmissiveMesh returns the emissive mesh over the given mesh,
+// set on the given scene under the given name
+func NewEmissiveMesh(sc *xyz.Scene, name string, src xyz.Mesh) *EmissiveMesh {
+	em := &EmissiveMesh{Source: src}
+	em.Name = name
+	sc.SetMesh(em)
+	return em
+}
+
+func (em *EmissiveMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	em.NumVertex, em.NumIndex, _ = em.Source.MeshSize()
+	return em.NumVertex, em.NumIndex, false
+}
+
+func (em *EmissiveMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	md := shape.NewMeshData(em.Source)
+	sbb := shape.BBoxFromVtxs(md.Vertex, 0, md.NumVertex)
+	push := 0.002 * sbb.Size().Length()
+	for v := range md.NumVertex {
+		var pos, n math32.Vector3
+		md.Vertex.GetVector3(3*v, &pos)
+		md.Normal.GetVector3(3*v, &n)
+		vertex.SetVector3(3*v, pos.Add(n.MulScalar(push)))
+		normal.SetVector3(3*v, n)
+	}
+	copy(texcoord, md.TexCoord)
+	copy(index, md.Index)
+	bb := shape.BBoxFromVtxs(vertex, 0, md.NumVertex)
+	em.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// emissiveMeshName returns the name of the emissive
+// mesh over the given mesh
+func emissiveMeshName(mesh xyz.MeshName) string {
+	return fmt.Sprintf("%s:%s", emissiveMeshPrefix, mesh)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"cogentcore.org/core/base/fileinfo/mimedata"
+	"cogentcore.org/core/base/iox/imagex"
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// FileDropImporter imports the files dropped onto a scene widget from
+// the file manager with [ImportFile], and sets images dropped onto a
+// demo [Solid] as its texture, showing a snackbar for those it can not.
+// The events package has no event of its own for files dropped from
+// outside of the app; the desktop driver sends them as an [events.Drop]
+// with no source, to the widgets under the mouse, whose data is the
+// [DroppedFiles]. The browser does not give the paths of dropped files,
+// so there are none on the web.
+type FileDropImporter struct {
+	// OnImport is called after each file is imported, with its path,
+	// as for updating a list of the nodes of the scene
+	OnImport func(path string)
+
+	// Scene widget that the files are dropped onto
+	SceneWidget *xyzcore.Scene `set:"-"`
+}
+
+// NewFileDropImporter returns a new file drop importer
+func NewFileDropImporter() *FileDropImporter {
+	return &FileDropImporter{}
+}
+
+// Attach imports the files dropped onto the given scene widget
+func (fd *FileDropImporter) Attach(sw *xyzcore.Scene) {
+	if fd.SceneWidget != nil {
+		return
+	}
+	fd.SceneWidget = sw
+	sw.On(events.Drop, func(e events.Event) {
+		paths := DroppedFiles(e)
+		if len(paths) == 0 {
+			return
+		}
+		e.SetHandled()
+		for _, path := range paths {
+			if err := fd.importFile(e, path); err != nil {
+				core.ErrorSnackbar(sw, err, "Error importing "+filepath.Base(path))
+				continue
+			}
+			if fd.OnImport != nil {
+				fd.OnImport(path)
+			}
+		}
+		sw.XYZ.SetNeedsUpdate()
+		sw.NeedsRender()
+	})
+}
+
+// importFile imports the file at the given path dropped by the given
+// event, setting an image as the texture of the solid it is dropped on
+func (fd *FileDropImporter) importFile(e events.Event, path string) error {
+	if _, err := imagex.ExtToFormat(filepath.Ext(path)); err != nil {
+		return ImportFile(fd.SceneWidget.SceneXYZ(), path)
+	}
+	sw := fd.SceneWidget
+	bb := sw.Geom.ContentBBox
+	pos := e.Pos().Sub(bb.Min)
+	if hit, _, _, ok := PickAtPixel(sw.SceneXYZ(), pos.X, pos.Y, math32.FromPoint(bb.Size())); ok {
+		if ds, ok := hit.This.(interface{ asSolid() *Solid }); ok {
+			return ds.asSolid().SetTextureFromFile(path)
+		}
+	}
+	return fmt.Errorf("drop the image %s onto a solid to use it as its texture", filepath.Base(path))
+}
+
+// DroppedFiles returns the paths of the files of the given event, if it
+// is an [events.Drop] of files from outside of the app, or nil
+func DroppedFiles(e events.Event) []string {
+	de, ok := e.(*events.DragDrop)
+	if !ok || de.Source != nil {
+		return nil
+	}
+	md, ok := de.Data.(mimedata.Mimes)
+	if !ok {
+		return nil
+	}
+	var paths []string
+	for _, d := range md {
+		if d.Type == mimedata.TextPlain && len(d.Data) > 0 {
+			paths = append(paths, string(d.Data))
+		}
+	}
+	return paths
+}
+
+// ImportFile adds the objects of the file at the given path to the given
+// scene, with the importer for its extension: [ImportGLTF] for .gltf and
+// .glb, [ImportOBJ] for .obj, with the .mtl file next to it if any, and
+// [ImportSTL] for .stl. It returns an error for other extensions.
+func ImportFile(sc *xyz.Scene, path string) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".gltf", ".glb":
+		return ImportGLTF(sc, path)
+	case ".obj":
+		return ImportOBJ(sc, path, "")
+	case ".stl":
+		return ImportSTL(sc, path)
+	default:
+		return fmt.Errorf("ImportFile: unsupported file type %q", ext)
+	}
+}
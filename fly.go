@@ -0,0 +1,257 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/system"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// FlyController moves the camera of a scene widget with first person
+// controls while it is active: W, A, S and D move forward, left, back
+// and right, Q and E move down and up, Shift sprints, and mouse motion
+// turns the camera. The left stick of a gamepad moves and its right stick
+// turns, from [GamepadAxisEvent]s. The ToggleKey activates and deactivates
+// it, and Escape deactivates it. The cursor is locked while it is active.
+type FlyController struct {
+	// MoveSpeed is the speed in units per second
+	MoveSpeed float32
+
+	// SprintFactor multiplies the speed while Shift is held down
+	SprintFactor float32
+
+	// LookSpeed is the rotation in degrees per pixel of mouse motion
+	LookSpeed float32
+
+	// StickLookSpeed is the rotation in degrees per second
+	// of a gamepad stick pushed all the way
+	StickLookSpeed float32
+
+	// ToggleKey activates and deactivates the controller, as a [Shortcut]
+	// of the body of the scene widget registered by Attach
+	ToggleKey key.Chord
+
+	// Active is whether the controller is moving the camera
+	Active bool `edit:"-"`
+
+	// Scene widget that the controller is attached to
+	SceneWidget *xyzcore.Scene `set:"-"`
+
+	// keys currently held down
+	down map[key.Codes]bool
+
+	// scene widgets with event handlers added
+	hooked map[*xyzcore.Scene]bool
+
+	// animation moving the camera on each frame
+	anim *core.Animation
+
+	// camera angles in degrees
+	yaw, pitch float32
+}
+
+// NewFlyController returns a new fly controller with default settings,
+// toggled with the f key
+func NewFlyController() *FlyController {
+	return &FlyController{MoveSpeed: 3, SprintFactor: 3, LookSpeed: 0.2, StickLookSpeed: 120, ToggleKey: "f"}
+}
+
+// flyKeys are the keys that move the camera
+var flyKeys = map[key.Codes]math32.Vector3{
+	key.CodeW: {Z: -1},
+	key.CodeS: {Z: 1},
+	key.CodeA: {X: -1},
+	key.CodeD: {X: 1},
+	key.CodeQ: {Y: -1},
+	key.CodeE: {Y: 1},
+}
+
+// Attach lets the ToggleKey activate the controller on the given
+// scene widget. It works alongside an [OrbitController], which
+// handles mouse dragging while the fly controller is inactive.
+func (fc *FlyController) Attach(sw *xyzcore.Scene) {
+	if fc.SceneWidget == sw {
+		return
+	}
+	fc.Detach()
+	fc.SceneWidget = sw
+	fc.down = map[key.Codes]bool{}
+	sw.Animate(func(a *core.Animation) {
+		fc.anim = a
+		if fc.Active {
+			fc.move(a.Dt / 1000)
+		}
+	})
+	startGamepads()
+	if fc.hooked[sw] {
+		return // handlers are already checking SceneWidget
+	}
+	if fc.hooked == nil {
+		fc.hooked = map[*xyzcore.Scene]bool{}
+	}
+	fc.hooked[sw] = true
+	if fc.ToggleKey != "" {
+		RegisterShortcut(sw.Scene.Body, fc.ToggleKey, "Fly through the scene, or stop flying", func() {
+			if fc.SceneWidget == sw {
+				fc.SetActive(!fc.Active)
+			}
+		})
+	}
+	sw.On(events.KeyChord, func(e events.Event) {
+		if fc.SceneWidget != sw {
+			return
+		}
+		kc := e.KeyChord()
+		switch {
+		case fc.Active && kc == "Escape":
+			e.SetHandled()
+			fc.SetActive(false)
+		case fc.Active:
+			if _, ok := flyKeys[e.KeyCode()]; ok {
+				e.SetHandled() // moving is done on key down and up
+			}
+		}
+	})
+	sw.On(events.KeyDown, func(e events.Event) {
+		if fc.SceneWidget == sw && fc.Active {
+			fc.down[e.KeyCode()] = true
+		}
+	})
+	sw.On(events.KeyUp, func(e events.Event) {
+		if fc.SceneWidget == sw {
+			delete(fc.down, e.KeyCode())
+		}
+	})
+	sw.On(events.MouseMove, func(e events.Event) {
+		if fc.SceneWidget != sw || !fc.Active {
+			return
+		}
+		e.SetHandled()
+		del := e.PrevDelta()
+		fc.Look(float32(del.X), float32(del.Y))
+	})
+	sw.On(events.SlideMove, func(e events.Event) {
+		if fc.SceneWidget != sw || !fc.Active {
+			return
+		}
+		e.SetHandled()
+		del := e.PrevDelta()
+		fc.Look(float32(del.X), float32(del.Y))
+	})
+	sw.Scene.On(events.Custom, func(e events.Event) {
+		ge, ok := gamepadAxisEvent(e)
+		if !ok || fc.SceneWidget != sw || !fc.Active {
+			return
+		}
+		e.SetHandled()
+		fc.stick(ge.Axis, ge.Value)
+	})
+}
+
+// Detach deactivates the controller and stops the ToggleKey
+// from activating it
+func (fc *FlyController) Detach() {
+	fc.SetActive(false)
+	if fc.anim != nil {
+		fc.anim.Done = true
+		fc.anim = nil
+	}
+	fc.SceneWidget = nil
+}
+
+// SetActive activates or deactivates the controller,
+// locking the cursor while it is active
+func (fc *FlyController) SetActive(active bool) {
+	if fc.SceneWidget == nil || fc.Active == active {
+		fc.Active = active && fc.SceneWidget != nil
+		return
+	}
+	fc.Active = active
+	clear(fc.down)
+	if active {
+		fc.fromCamera()
+	}
+	if win := system.TheApp.ContextWindow(); win != nil {
+		win.SetCursorEnabled(!active, active)
+	}
+}
+
+// Look turns the camera by the given number of pixels of mouse motion
+func (fc *FlyController) Look(dx, dy float32) {
+	fc.yaw -= dx * fc.LookSpeed
+	fc.pitch = math32.Clamp(fc.pitch-dy*fc.LookSpeed, -89, 89)
+	fc.apply(math32.Vector3{})
+}
+
+// move moves the camera for the keys held down over dt seconds
+func (fc *FlyController) move(dt float32) {
+	var dir math32.Vector3
+	for k := range fc.down {
+		dir.SetAdd(flyKeys[k])
+	}
+	if dir == (math32.Vector3{}) {
+		return
+	}
+	speed := fc.MoveSpeed
+	if fc.down[key.CodeLeftShift] || fc.down[key.CodeRightShift] {
+		speed *= fc.SprintFactor
+	}
+	fc.apply(dir.Normal().MulScalar(speed * dt))
+}
+
+// stick moves or turns the camera for the given gamepad
+// axis being at the given value for one gamepad poll
+func (fc *FlyController) stick(axis int, v float32) {
+	dt := float32(gamepadPollInterval.Seconds())
+	speed := fc.MoveSpeed
+	if fc.down[key.CodeLeftShift] || fc.down[key.CodeRightShift] {
+		speed *= fc.SprintFactor
+	}
+	switch axis {
+	case GamepadLeftX:
+		fc.apply(math32.Vec3(v*speed*dt, 0, 0))
+	case GamepadLeftY:
+		fc.apply(math32.Vec3(0, 0, v*speed*dt))
+	case GamepadRightX:
+		fc.yaw -= v * fc.StickLookSpeed * dt
+		fc.apply(math32.Vector3{})
+	case GamepadRightY:
+		fc.pitch = math32.Clamp(fc.pitch-v*fc.StickLookSpeed*dt, -89, 89)
+		fc.apply(math32.Vector3{})
+	}
+}
+
+// fromCamera sets the camera angles from the camera orientation
+func (fc *FlyController) fromCamera() {
+	fwd := math32.Vec3(0, 0, -1).MulQuat(fc.SceneWidget.XYZ.Camera.Pose.Quat)
+	fc.pitch = math32.RadToDeg(math32.Asin(math32.Clamp(fwd.Y, -1, 1)))
+	fc.yaw = math32.RadToDeg(math32.Atan2(-fwd.X, -fwd.Z))
+}
+
+// apply orients the camera by the angles and moves it by the given
+// amount, with X and Z relative to the view direction and Y up
+func (fc *FlyController) apply(del math32.Vector3) {
+	sw := fc.SceneWidget
+	if sw == nil {
+		return
+	}
+	cm := &sw.XYZ.Camera
+	yq := math32.NewQuatAxisAngle(math32.Vec3(0, 1, 0), math32.DegToRad(fc.yaw))
+	pq := math32.NewQuatAxisAngle(math32.Vec3(1, 0, 0), math32.DegToRad(fc.pitch))
+	q := yq.Mul(pq)
+	cm.Pose.Pos.SetAdd(math32.Vec3(del.X, 0, del.Z).MulQuat(q))
+	cm.Pose.Pos.Y += del.Y
+	cm.Pose.Quat = q
+	dist := max(cm.DistanceTo(cm.Target), 1)
+	cm.Target = cm.Pose.Pos.Add(math32.Vec3(0, 0, -dist).MulQuat(q))
+	cm.UpDir = math32.Vec3(0, 1, 0)
+	cm.UpdateMatrix()
+	sw.XYZ.SetNeedsRender()
+	sw.NeedsRender()
+}
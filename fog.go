@@ -0,0 +1,112 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/color"
+
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// fogProperty is the scene property holding its [Fog]
+const fogProperty = "fog"
+
+// FogModes are the ways that [Fog] gets thicker with distance
+type FogModes int32
+
+const (
+	// FogOff is no fog
+	FogOff FogModes = iota
+
+	// FogLinear goes from none at Near to full at Far
+	FogLinear
+
+	// FogExp is 1 - exp(-Density * depth)
+	FogExp
+
+	// FogExp2 is 1 - exp(-(Density * depth)^2), which is
+	// clearer near the camera and thicker further away
+	FogExp2
+)
+
+// Fog blends solids toward the fog color with their depth from the
+// camera. The phong shaders can not be changed to blend each fragment,
+// so each demo [Solid] is blended as a whole by the depth of the
+// center of its bounding box, by fading its material color and
+// making it glow with the fog color.
+type Fog struct {
+	// Mode of the fog
+	Mode FogModes
+
+	// Color of the fog, usually that of the scene background
+	Color color.RGBA
+
+	// Near and Far are the depths where FogLinear starts and is full
+	Near, Far float32
+
+	// Density of FogExp and FogExp2, per unit of depth
+	Density float32 `min:"0" step:"0.01"`
+}
+
+// SetFog sets the fog of the given scene, or turns it off if nil
+func SetFog(sc *xyz.Scene, fog *Fog) {
+	if fog == nil {
+		sc.DeleteProperty(fogProperty)
+	} else {
+		sc.SetProperty(fogProperty, fog)
+	}
+	sc.SetNeedsRender()
+}
+
+// SceneFog returns the fog of the given scene, or nil if none
+func SceneFog(sc *xyz.Scene) *Fog {
+	fg, _ := sc.Property(fogProperty).(*Fog)
+	return fg
+}
+
+// Amount returns how much of the fog color is seen at
+// the given depth from the camera, from 0 to 1
+func (fg *Fog) Amount(depth float32) float32 {
+	var f float32
+	switch fg.Mode {
+	case FogLinear:
+		if fg.Far <= fg.Near {
+			if depth < fg.Near {
+				return 0
+			}
+			return 1
+		}
+		f = (depth - fg.Near) / (fg.Far - fg.Near)
+	case FogExp:
+		f = 1 - math32.Exp(-fg.Density*depth)
+	case FogExp2:
+		d := fg.Density * depth
+		f = 1 - math32.Exp(-d*d)
+	}
+	return math32.Clamp(f, 0, 1)
+}
+
+// fogColors blends the given colors of the solid with the fog of
+// its scene at its depth, returning whether there is any fog there
+func (sld *Solid) fogColors(clr *phong.Colors) bool {
+	fg := SceneFog(sld.Scene)
+	if fg == nil || fg.Mode == FogOff {
+		return false
+	}
+	cm := &sld.Scene.Camera
+	ctr := sld.WorldBBox.BBox.Center().MulMatrix4(&cm.ViewMatrix)
+	f := fg.Amount(-ctr.Z)
+	if f == 0 {
+		return false
+	}
+	fc := math32.NewVector4Color(fg.Color).SRGBToLinear()
+	a := clr.Color.W
+	clr.Color = clr.Color.MulScalar(1 - f)
+	clr.Color.W = a
+	clr.Emissive = clr.Emissive.Lerp(fc, f)
+	return true
+}
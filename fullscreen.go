@@ -0,0 +1,53 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/system"
+)
+
+// FullscreenKey is the chord that [AddFullscreenShortcut] registers
+const FullscreenKey key.Chord = "F11"
+
+// The full screen of the system package is GLFW taking over the monitor
+// on desktop, which changes its video mode and does not animate into a
+// space of its own on macOS, so the window is made full screen by the
+// platform in setFullscreen instead: with the native full screen of the
+// window on macOS, by maximizing it without its decorations on the other
+// desktop platforms, and with the full screen API of the browser on the web.
+
+// SetFullscreen makes the window of the given body full screen,
+// or back to how it was before, if it is open
+func SetFullscreen(b *core.Body, fs bool) {
+	if win := bodyWindow(b); win != nil && isFullscreen(win) != fs {
+		setFullscreen(win, fs)
+	}
+}
+
+// IsFullscreen returns whether the window of the given body is full
+// screen, which the user can also change, as with Escape on the web
+func IsFullscreen(b *core.Body) bool {
+	win := bodyWindow(b)
+	return win != nil && isFullscreen(win)
+}
+
+// AddFullscreenShortcut registers a [Shortcut] on the given body
+// that makes its window full screen, or back, with the [FullscreenKey]
+func AddFullscreenShortcut(b *core.Body) *Shortcut {
+	return RegisterShortcut(b, FullscreenKey, "Enter or leave full screen", func() {
+		SetFullscreen(b, !IsFullscreen(b))
+	})
+}
+
+// bodyWindow returns the system window of the given body, or nil if it is not open
+func bodyWindow(b *core.Body) system.Window {
+	rw := b.Scene.RenderWindow()
+	if rw == nil {
+		return nil
+	}
+	return rw.SystemWindow
+}
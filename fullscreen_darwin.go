@@ -0,0 +1,62 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin && !(ios || offscreen)
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Cocoa
+#import <Cocoa/Cocoa.h>
+
+void toggleFullScreen(void *win) {
+	NSWindow *w = (NSWindow *)win;
+	[w setCollectionBehavior:[w collectionBehavior] | NSWindowCollectionBehaviorFullScreenPrimary];
+	[w toggleFullScreen:nil];
+}
+
+int isFullScreen(void *win) {
+	return ([(NSWindow *)win styleMask] & NSWindowStyleMaskFullScreen) != 0;
+}
+*/
+import "C"
+
+import (
+	"cogentcore.org/core/system"
+	"cogentcore.org/core/system/driver/desktop"
+)
+
+// setFullscreen toggles the native full screen of the Cocoa window under
+// the given one, which hides the Dock and the menu bar and moves the
+// window into a space of its own, animating both ways as other apps do.
+// AppKit must be called on the main thread.
+func setFullscreen(win system.Window, fs bool) {
+	dw, ok := win.(*desktop.Window)
+	if !ok {
+		return
+	}
+	system.TheApp.RunOnMain(func() {
+		if dw.Glw != nil {
+			C.toggleFullScreen(dw.Glw.GetCocoaWindow())
+		}
+	})
+}
+
+// isFullscreen returns whether the Cocoa window under the given
+// one is in native full screen, which it is as soon as it starts
+// going in, and until it has come all the way out
+func isFullscreen(win system.Window) bool {
+	dw, ok := win.(*desktop.Window)
+	if !ok {
+		return false
+	}
+	fs := false
+	system.TheApp.RunOnMain(func() {
+		if dw.Glw != nil {
+			fs = C.isFullScreen(dw.Glw.GetCocoaWindow()) != 0
+		}
+	})
+	return fs
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !(darwin || android || ios || js || offscreen)
+
+package main
+
+import (
+	"cogentcore.org/core/system"
+	"cogentcore.org/core/system/driver/desktop"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// wasMaximized records the windows that were already maximized
+// when they were made full screen, to leave them so after
+var wasMaximized = map[*desktop.Window]bool{}
+
+// setFullscreen maximizes the given window without its decorations,
+// or puts them back and restores it, with GLFW on the main thread
+func setFullscreen(win system.Window, fs bool) {
+	dw, ok := win.(*desktop.Window)
+	if !ok {
+		return
+	}
+	system.TheApp.RunOnMain(func() {
+		if dw.Glw == nil {
+			return
+		}
+		if fs {
+			wasMaximized[dw] = dw.Glw.GetAttrib(glfw.Maximized) == glfw.True
+			dw.Glw.SetAttrib(glfw.Decorated, glfw.False)
+			dw.Glw.Maximize()
+			return
+		}
+		dw.Glw.SetAttrib(glfw.Decorated, glfw.True)
+		if !wasMaximized[dw] {
+			dw.Glw.Restore()
+		}
+		delete(wasMaximized, dw)
+	})
+}
+
+// isFullscreen returns whether the given window has no decorations,
+// which is only the case when it has been made full screen
+func isFullscreen(win system.Window) bool {
+	dw, ok := win.(*desktop.Window)
+	if !ok {
+		return false
+	}
+	fs := false
+	system.TheApp.RunOnMain(func() {
+		if dw.Glw != nil {
+			fs = dw.Glw.GetAttrib(glfw.Decorated) == glfw.False
+		}
+	})
+	return fs
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js && !offscreen
+
+package main
+
+import (
+	"image"
+	"syscall/js"
+
+	"cogentcore.org/core/system"
+)
+
+// setFullscreen makes the page full screen, or back, with the full
+// screen API of the browser, which the web driver calls for it. The
+// browser only allows that in response to a key press or a click.
+func setFullscreen(win system.Window, fs bool) {
+	win.SetGeometry(fs, image.Point{}, image.Point{}, nil)
+}
+
+// isFullscreen returns whether the page is full screen, from the
+// browser, as the user can also leave it with Escape
+func isFullscreen(win system.Window) bool {
+	return js.Global().Get("document").Get("fullscreenElement").Truthy()
+}
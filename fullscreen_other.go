@@ -0,0 +1,18 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build android || ios || offscreen
+
+package main
+
+import "cogentcore.org/core/system"
+
+// setFullscreen does nothing, as apps already take up the whole
+// screen on mobile, and there is no screen when offscreen
+func setFullscreen(win system.Window, fs bool) {}
+
+// isFullscreen returns false, as the window can not be made full screen
+func isFullscreen(win system.Window) bool {
+	return false
+}
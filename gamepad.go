@@ -0,0 +1,164 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/system"
+)
+
+// The events package has no gamepad events, and its event types can not
+// be extended, so gamepad events are sent as the Data of [events.Custom]
+// events, on the same event queue as the others. Core gives those to the
+// top scene of the window, so they are handled with a listener on the
+// scene of a widget, as in:
+//
+//	sw.Scene.On(events.Custom, func(e events.Event) {
+//		if ge, ok := e.(*events.CustomEvent).Data.(GamepadAxisEvent); ok {
+//			...
+//		}
+//	})
+
+const (
+	// gamepadPollInterval is the time between polls of the gamepads,
+	// which is also the time each [GamepadAxisEvent] lasts for
+	gamepadPollInterval = time.Second / 60
+
+	// gamepadIdleInterval is the time between polls while there are no
+	// gamepads, as reading them runs on the main thread of the app
+	gamepadIdleInterval = time.Second
+
+	// gamepadDeadZone is the value of an axis below which it is taken
+	// as 0, as sticks do not quite come back to the center
+	gamepadDeadZone = 0.15
+)
+
+// The axes of the sticks, which are the same on all platforms
+const (
+	GamepadLeftX = iota
+	GamepadLeftY
+	GamepadRightX
+	GamepadRightY
+)
+
+// GamepadAxisEvent is the value of an axis of a gamepad, from -1 to 1,
+// with Y going down. It is sent on each poll while the axis is away from
+// the center, as well as when it changes, so that analog controls can
+// move on each one for [gamepadPollInterval], and once with a Value of
+// 0 when it comes back.
+type GamepadAxisEvent struct {
+	// GamepadID is the index of the gamepad, from when it was connected
+	GamepadID int
+
+	// Axis is the index of the axis, such as [GamepadLeftX]
+	Axis int
+
+	// Value of the axis, from -1 to 1
+	Value float32
+}
+
+// GamepadButtonEvent is sent when a button of a gamepad
+// is pressed or released
+type GamepadButtonEvent struct {
+	// GamepadID is the index of the gamepad, from when it was connected
+	GamepadID int
+
+	// Button is the index of the button, whose
+	// layout depends on the platform
+	Button int
+
+	// Pressed is whether the button is down
+	Pressed bool
+}
+
+// gamepadState is what a gamepad was polled as
+type gamepadState struct {
+	id      int
+	axes    []float32
+	buttons []bool
+}
+
+// startGamepads starts polling the gamepads in the background, once,
+// sending their events to the current window. The gamepads are read by
+// the platform in readGamepads. While there are none, they are only
+// polled every [gamepadIdleInterval], so a gamepad connected then
+// takes up to that long to be found.
+var startGamepads = sync.OnceFunc(func() {
+	go func() {
+		last := map[int]gamepadState{}
+		for {
+			time.Sleep(gamepadWait(len(last)))
+			win := system.TheApp.ContextWindow()
+			if win == nil {
+				continue // the app is not running yet
+			}
+			pads := readGamepads()
+			now := map[int]gamepadState{}
+			for _, gp := range pads {
+				now[gp.id] = gp
+				sendGamepad(win.Events(), last[gp.id], gp)
+			}
+			for id, gp := range last {
+				if _, ok := now[id]; !ok { // disconnected
+					sendGamepad(win.Events(), gp, gamepadState{id: id})
+				}
+			}
+			last = now
+		}
+	}()
+})
+
+// gamepadWait returns the time to wait before the next poll
+// of the gamepads, after one that found the given number of them
+func gamepadWait(n int) time.Duration {
+	if n == 0 {
+		return gamepadIdleInterval
+	}
+	return gamepadPollInterval
+}
+
+// sendGamepad sends the events for the given gamepad
+// going from the last state to the new one
+func sendGamepad(es *events.Source, last, gp gamepadState) {
+	for i := range max(len(gp.axes), len(last.axes)) {
+		v, lv := gamepadAxis(gp.axes, i), gamepadAxis(last.axes, i)
+		if v != 0 || lv != 0 {
+			es.Custom(GamepadAxisEvent{GamepadID: gp.id, Axis: i, Value: v})
+		}
+	}
+	for i := range max(len(gp.buttons), len(last.buttons)) {
+		p := i < len(gp.buttons) && gp.buttons[i]
+		if p != (i < len(last.buttons) && last.buttons[i]) {
+			es.Custom(GamepadButtonEvent{GamepadID: gp.id, Button: i, Pressed: p})
+		}
+	}
+}
+
+// gamepadAxis returns the given axis of the given ones, 0 within the
+// dead zone, with the rest of the range scaled to go from 0 to 1
+func gamepadAxis(axes []float32, i int) float32 {
+	if i >= len(axes) {
+		return 0
+	}
+	v := axes[i]
+	if math32.Abs(v) < gamepadDeadZone {
+		return 0
+	}
+	return math32.Sign(v) * min((math32.Abs(v)-gamepadDeadZone)/(1-gamepadDeadZone), 1)
+}
+
+// gamepadAxisEvent returns the gamepad axis event of the given event, if it is one
+func gamepadAxisEvent(e events.Event) (GamepadAxisEvent, bool) {
+	ce, ok := e.(*events.CustomEvent)
+	if !ok {
+		return GamepadAxisEvent{}, false
+	}
+	ge, ok := ce.Data.(GamepadAxisEvent)
+	return ge, ok
+}
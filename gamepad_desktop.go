@@ -0,0 +1,41 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !(android || ios || js || offscreen)
+
+package main
+
+import (
+	"cogentcore.org/core/system"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// readGamepads returns the joysticks connected to the computer, from
+// GLFW, which must be called on the main thread. Those that GLFW knows
+// the layout of are read as its standard gamepad, and the others
+// with their own axes and buttons.
+func readGamepads() []gamepadState {
+	var pads []gamepadState
+	system.TheApp.RunOnMain(func() {
+		for joy := glfw.Joystick1; joy <= glfw.JoystickLast; joy++ {
+			if !joy.Present() {
+				continue
+			}
+			st := gamepadState{id: int(joy)}
+			if gs := joy.GetGamepadState(); gs != nil {
+				st.axes = gs.Axes[:]
+				for _, b := range gs.Buttons {
+					st.buttons = append(st.buttons, b == glfw.Press)
+				}
+			} else {
+				st.axes = joy.GetAxes()
+				for _, b := range joy.GetButtons() {
+					st.buttons = append(st.buttons, b == glfw.Press)
+				}
+			}
+			pads = append(pads, st)
+		}
+	})
+	return pads
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js && !offscreen
+
+package main
+
+import "syscall/js"
+
+// readGamepads returns the gamepads connected to the browser,
+// from the Gamepad API, which only lists them once one of their
+// buttons has been pressed on the page
+func readGamepads() []gamepadState {
+	nav := js.Global().Get("navigator")
+	if nav.Get("getGamepads").IsUndefined() {
+		return nil
+	}
+	list := nav.Call("getGamepads")
+	var pads []gamepadState
+	for i := range list.Length() {
+		gp := list.Index(i)
+		if gp.IsNull() || gp.IsUndefined() || !gp.Get("connected").Bool() {
+			continue
+		}
+		st := gamepadState{id: gp.Get("index").Int()}
+		axes := gp.Get("axes")
+		for a := range axes.Length() {
+			st.axes = append(st.axes, float32(axes.Index(a).Float()))
+		}
+		buttons := gp.Get("buttons")
+		for b := range buttons.Length() {
+			st.buttons = append(st.buttons, buttons.Index(b).Get("pressed").Bool())
+		}
+		pads = append(pads, st)
+	}
+	return pads
+}
@@ -0,0 +1,13 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build android || ios || offscreen
+
+package main
+
+// readGamepads returns no gamepads, as they
+// are not supported on this platform yet
+func readGamepads() []gamepadState {
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"cogentcore.org/core/math32"
+)
+
+func TestGamepadWait(t *testing.T) {
+	if got := gamepadWait(0); got != gamepadIdleInterval {
+		t.Errorf("waits %v between polls with no gamepads", got)
+	}
+	if got := gamepadWait(1); got != gamepadPollInterval {
+		t.Errorf("waits %v between polls with a gamepad", got)
+	}
+}
+
+func TestGamepadAxis(t *testing.T) {
+	axes := []float32{0.1, -1, 0.575}
+	for i, want := range []float32{0, -1, 0.5, 0} {
+		if got := gamepadAxis(axes, i); math32.Abs(got-want) > 1e-5 {
+			t.Errorf("axis %d is %g, not %g", i, got, want)
+		}
+	}
+}
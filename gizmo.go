@@ -0,0 +1,414 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// GizmoName is the name of the group of the handles of a [TransformGizmo]
+const GizmoName = "__Gizmo"
+
+// GizmoSpaces are the axes that a [TransformGizmo] moves and turns along
+type GizmoSpaces int32
+
+const (
+	// GizmoWorld uses the axes of the world
+	GizmoWorld GizmoSpaces = iota
+
+	// GizmoLocal uses the axes of the selected node, as turned
+	// by its pose and those of its parents
+	GizmoLocal
+)
+
+// gizmoKinds are the kinds of handles of a [TransformGizmo]
+type gizmoKinds int32
+
+const (
+	gizmoTranslate gizmoKinds = iota
+	gizmoRotate
+	gizmoScale
+)
+
+// gizmoAxes are the unit axes and the colors of their handles
+var gizmoAxes = [3]struct {
+	dir math32.Vector3
+	clr color.RGBA
+}{
+	{math32.Vec3(1, 0, 0), color.RGBA{230, 50, 50, 255}},
+	{math32.Vec3(0, 1, 0), color.RGBA{50, 200, 50, 255}},
+	{math32.Vec3(0, 0, 1), color.RGBA{50, 90, 240, 255}},
+}
+
+// TransformGizmo shows handles around the selected node of a scene
+// widget for moving, turning and scaling it: an arrow along each axis
+// to drag it along, a ring around each axis to turn it by, and a box
+// on each axis to scale it along that axis of the node, in its local
+// space even for GizmoWorld. It stays the same size on the screen.
+// The phong renderer has a single render pass, so the handles are
+// drawn on top of the scene by pulling them toward the camera until
+// they are just past its near plane, where they look the same.
+// Drags are recorded in the Commands, if any, to undo them.
+type TransformGizmo struct {
+	// Space is the axes that the handles move and turn along
+	Space GizmoSpaces
+
+	// Size is the length of the arrows, in pixels
+	Size float32
+
+	// Commands records the drags, so that they can be undone
+	Commands *CommandStack
+
+	// Scene widget whose selected node the gizmo is shown around
+	SceneWidget *xyzcore.Scene `set:"-"`
+
+	// handles of the gizmo
+	handles []*gizmoHandle
+
+	// world matrix of the gizmo on the last render, at unit size,
+	// and the scale making it Size pixels
+	frame math32.Matrix4
+	scale float32
+
+	// handle that the mouse was pressed on
+	pressed *gizmoHandle
+
+	// handle being dragged, with the pose of the node when dragging
+	// started, and where on the handle it started: the position
+	// along the axis, or the angle around it
+	drag      *gizmoHandle
+	dragNode  xyz.Node
+	dragPose  xyz.Pose
+	dragStart float32
+}
+
+// NewTransformGizmo returns a new gizmo in world space with arrows
+// 100 pixels long
+func NewTransformGizmo() *TransformGizmo {
+	return &TransformGizmo{Size: 100}
+}
+
+// Attach shows the gizmo around the selected node of the
+// given scene widget, and lets its handles be dragged
+func (tg *TransformGizmo) Attach(sw *xyzcore.Scene) {
+	if tg.SceneWidget != nil {
+		return
+	}
+	tg.SceneWidget = sw
+	sc := sw.SceneXYZ()
+	gp := xyz.NewGroup(sc)
+	gp.SetName(GizmoName)
+	shaft := xyz.NewCylinder(sc, GizmoName+"Shaft", 0.8, 0.015, 8, 1, true, true)
+	tip := xyz.NewCone(sc, GizmoName+"Tip", 0.2, 0.05, 16, 1, true)
+	ring := xyz.NewTorus(sc, GizmoName+"Ring", 0.75, 0.012, 48)
+	box := xyz.NewBox(sc, GizmoName+"Box", 0.08, 0.08, 0.08)
+	for axis, ax := range gizmoAxes {
+		var up, front math32.Quat // +Y and +Z along the axis
+		up.SetFromUnitVectors(math32.Vec3(0, 1, 0), ax.dir)
+		front.SetFromUnitVectors(math32.Vec3(0, 0, 1), ax.dir)
+		tg.addHandle(gp, gizmoTranslate, axis, shaft, ax.dir.MulScalar(0.4), up)
+		tg.addHandle(gp, gizmoTranslate, axis, tip, ax.dir.MulScalar(0.9), up)
+		tg.addHandle(gp, gizmoRotate, axis, ring, math32.Vector3{}, front)
+		tg.addHandle(gp, gizmoScale, axis, box, ax.dir.MulScalar(1.15), up)
+	}
+
+	// these are added after the scene widget handlers, so they are
+	// called first, and keep it from selecting what is behind the handles
+	sw.On(events.MouseDown, func(e events.Event) {
+		tg.pressed = tg.pick(e)
+		if tg.pressed != nil {
+			e.SetHandled()
+		}
+	})
+	sw.On(events.SlideStart, func(e events.Event) {
+		if tg.pressed == nil || tg.target() == nil {
+			return
+		}
+		e.SetHandled()
+		tg.drag = tg.pressed
+		tg.dragNode = sw.CurrentSelected
+		tg.dragPose = tg.dragNode.AsNodeBase().Pose
+		var ok bool
+		if tg.dragStart, ok = tg.dragAt(e); !ok {
+			tg.drag, tg.dragNode = nil, nil
+		}
+	})
+	sw.On(events.SlideMove, func(e events.Event) {
+		if tg.drag == nil {
+			return
+		}
+		e.SetHandled()
+		if at, ok := tg.dragAt(e); ok {
+			tg.apply(at)
+		}
+	})
+	sw.On(events.SlideStop, func(e events.Event) {
+		if tg.drag == nil {
+			return
+		}
+		e.SetHandled()
+		tg.drag = nil
+		tg.record()
+		if sw.SelectionMode == xyzcore.Manipulable {
+			sc.UpdateNodes()
+			sw.ManipBox() // around where the node is now
+		}
+		sw.NeedsRender()
+	})
+}
+
+// addHandle adds a handle with the given mesh, position and rotation
+// in the gizmo, which is turned to the Space and scaled to its Size
+func (tg *TransformGizmo) addHandle(gp *xyz.Group, kind gizmoKinds, axis int, ms xyz.Mesh, pos math32.Vector3, rot math32.Quat) {
+	h := tree.New[gizmoHandle](gp)
+	h.gizmo, h.kind, h.axis = tg, kind, axis
+	h.SetMesh(ms)
+	h.Material.Color = gizmoAxes[axis].clr
+	h.Material.Emissive = gizmoAxes[axis].clr // the same in any light
+	h.Material.Shiny, h.Material.Reflective = 0, 0
+	h.local.SetTransform(pos, rot, math32.Vec3(1, 1, 1))
+	tg.handles = append(tg.handles, h)
+}
+
+// target returns the node that the gizmo is shown around, if any
+func (tg *TransformGizmo) target() xyz.Node {
+	if tg.SceneWidget == nil || tg.SceneWidget.CurrentSelected == nil {
+		return nil
+	}
+	nd := tg.SceneWidget.CurrentSelected
+	if nd.AsTree().Parent == nil || isHidden(nd) || isLocked(nd) {
+		return nil
+	}
+	return nd
+}
+
+// updateFrame places the gizmo at the target for the camera of
+// the scene, which has just been updated, returning false if there
+// is nothing to show it around
+func (tg *TransformGizmo) updateFrame() bool {
+	nd := tg.target()
+	if nd == nil {
+		return false
+	}
+	sc := tg.SceneWidget.SceneXYZ()
+	cm := &sc.Camera
+	wm := &nd.AsNodeBase().Pose.WorldMatrix
+	origin := wm.Pos()
+	var rot math32.Quat
+	rot.SetIdentity()
+	if tg.Space == GizmoLocal {
+		_, rot, _ = wm.Decompose()
+	}
+	depth := -origin.MulMatrix4(&cm.ViewMatrix).Z
+	if !cm.Ortho && depth <= cm.Near {
+		return false
+	}
+	height := 2 * math32.Tan(math32.DegToRad(cm.FOV*0.5)) // at unit depth
+	if cm.Ortho {
+		height *= cm.Far
+	} else {
+		height *= depth
+	}
+	tg.scale = tg.Size * height / float32(max(sc.Geom.Size.Y, 1))
+	var unit math32.Matrix4
+	unit.SetTransform(origin, rot, math32.Vec3(1, 1, 1))
+
+	// pulled in front of the rest of the scene,
+	// as for solids without DepthTest
+	pull, ok := depthPull(cm, origin, 1.2*tg.scale)
+	if !ok {
+		return false
+	}
+	tg.frame.MulMatrices(&pull, &unit)
+	return true
+}
+
+// pick returns the handle under the mouse of the given event, if any
+func (tg *TransformGizmo) pick(e events.Event) *gizmoHandle {
+	if tg.target() == nil {
+		return nil
+	}
+	ray, ok := eventRay(tg.SceneWidget, e)
+	if !ok {
+		return nil
+	}
+	var hit *gizmoHandle
+	var dist float32
+	for _, h := range tg.handles {
+		if !h.shown {
+			continue
+		}
+		if _, d, ok := pickSolid(&h.Solid, ray); ok && (hit == nil || d < dist) {
+			hit, dist = h, d
+		}
+	}
+	return hit
+}
+
+// eventRay returns the world ray through the mouse
+// of the given event in the given scene widget
+func eventRay(sw *xyzcore.Scene, e events.Event) (math32.Ray, bool) {
+	bb := sw.Geom.ContentBBox
+	if bb.Empty() {
+		return math32.Ray{}, false
+	}
+	pos := e.Pos().Sub(bb.Min)
+	vp := math32.FromPoint(bb.Size())
+	return pixelRay(&sw.SceneXYZ().Camera, math32.Vec2(float32(pos.X)+0.5, float32(pos.Y)+0.5), vp), true
+}
+
+// axis returns the world origin and direction of the axis of the
+// dragged handle, from the pose of the node when dragging started
+func (tg *TransformGizmo) axis() (origin, dir math32.Vector3) {
+	nb := tg.dragNode.AsNodeBase()
+	var world math32.Matrix4
+	tg.dragPose.UpdateMatrix()
+	world.MulMatrices(&nb.Pose.ParMatrix, &tg.dragPose.Matrix)
+	origin = world.Pos()
+	dir = gizmoAxes[tg.drag.axis].dir
+	if tg.Space == GizmoLocal || tg.drag.kind == gizmoScale {
+		_, rot, _ := world.Decompose()
+		dir = dir.MulQuat(rot)
+	}
+	return origin, dir
+}
+
+// dragAt returns where on the dragged handle the mouse of the given
+// event is: the distance along the axis nearest to the mouse ray, or
+// the angle around it where the ray hits the plane of the ring. It
+// returns false when the axis is seen end on, or the ring edge on.
+func (tg *TransformGizmo) dragAt(e events.Event) (float32, bool) {
+	ray, ok := eventRay(tg.SceneWidget, e)
+	if !ok {
+		return 0, false
+	}
+	origin, dir := tg.axis()
+	w := origin.Sub(ray.Origin)
+	b := dir.Dot(ray.Dir)
+	if tg.drag.kind != gizmoRotate {
+		den := 1 - b*b
+		if den < 1e-4 {
+			return 0, false
+		}
+		return (b*ray.Dir.Dot(w) - dir.Dot(w)) / den, true
+	}
+	t, ok := RayIntersectPlane(ray, PlaneThrough(dir, origin))
+	if !ok {
+		return 0, false
+	}
+	p := ray.At(t).Sub(origin)
+	u := gizmoAxes[(tg.drag.axis+1)%3].dir
+	if tg.Space == GizmoLocal {
+		_, rot, _ := tg.dragNode.AsNodeBase().Pose.ParMatrix.Decompose()
+		u = u.MulQuat(rot.Mul(tg.dragPose.Quat))
+	}
+	v := dir.Cross(u)
+	return math32.Atan2(p.Dot(v), p.Dot(u)), true
+}
+
+// apply changes the pose of the dragged node for the mouse
+// at the given place on the dragged handle
+func (tg *TransformGizmo) apply(at float32) {
+	nb := tg.dragNode.AsNodeBase()
+	pose := tg.dragPose
+	origin, dir := tg.axis()
+	switch tg.drag.kind {
+	case gizmoTranslate:
+		world := origin.Add(dir.MulScalar(at - tg.dragStart))
+		inv, err := nb.Pose.ParMatrix.Inverse()
+		if err != nil {
+			return
+		}
+		pose.Pos = world.MulMatrix4(inv)
+	case gizmoRotate:
+		_, prot, _ := nb.Pose.ParMatrix.Decompose()
+		turn := math32.NewQuatAxisAngle(dir, at-tg.dragStart)
+		inv := prot.Inverse()
+		pose.Quat = inv.Mul(turn.Mul(prot.Mul(tg.dragPose.Quat)))
+	case gizmoScale:
+		if tg.dragStart == 0 {
+			return
+		}
+		f := max(at/tg.dragStart, 0.01)
+		pose.Scale.SetDim(math32.Dims(tg.drag.axis), tg.dragPose.Scale.Dim(math32.Dims(tg.drag.axis))*f)
+	}
+	nb.Pose.Pos, nb.Pose.Quat, nb.Pose.Scale = pose.Pos, pose.Quat, pose.Scale
+	nb.Scene.SetNeedsUpdate()
+	octreeMoved(tg.dragNode)
+	tg.SceneWidget.NeedsRender()
+}
+
+// record adds the change of the pose of the dragged node to the Commands
+func (tg *TransformGizmo) record() {
+	nd := tg.dragNode
+	tg.dragNode = nil
+	if tg.Commands == nil || nd == nil {
+		return
+	}
+	from, to := tg.dragPose, nd.AsNodeBase().Pose
+	if from.Pos != to.Pos {
+		tg.Commands.Push(&MoveSolidCommand{Node: nd, From: from.Pos, To: to.Pos})
+	}
+	if from.Quat != to.Quat {
+		tg.Commands.Push(&RotateSolidCommand{Node: nd, From: from.Quat, To: to.Quat})
+	}
+	if from.Scale != to.Scale {
+		tg.Commands.Push(&ScaleSolidCommand{Node: nd, From: from.Scale, To: to.Scale})
+	}
+}
+
+// gizmoHandle is one of the solids of a [TransformGizmo]
+type gizmoHandle struct {
+	xyz.Solid
+
+	// gizmo of the handle
+	gizmo *TransformGizmo
+
+	// what the handle does, along which axis
+	kind gizmoKinds
+	axis int
+
+	// matrix of the handle in the gizmo, at unit size
+	local math32.Matrix4
+
+	// whether it was shown on the last render
+	shown bool
+}
+
+// PreRender places the handle in the gizmo, which is placed for the
+// camera the scene has just updated, and clears its window bounding
+// box so that clicks on it do not select it
+func (h *gizmoHandle) PreRender() {
+	h.SceneBBox = image.Rectangle{}
+	tg := h.gizmo
+	h.shown = tg.updateFrame()
+	if !h.shown {
+		return
+	}
+	var sz, m math32.Matrix4
+	sz.SetScale(tg.scale, tg.scale, tg.scale)
+	m.MulMatrices(&sz, &h.local)
+	h.Pose.WorldMatrix.MulMatrices(&tg.frame, &m)
+	h.Material.Emissive = gizmoAxes[h.axis].clr
+	if tg.drag != nil && tg.drag.kind == h.kind && tg.drag.axis == h.axis {
+		h.Material.Emissive = colors.Yellow
+	}
+	h.Solid.PreRender()
+}
+
+func (h *gizmoHandle) Render(rp *wgpu.RenderPassEncoder) {
+	if h.shown {
+		h.Solid.Render(rp)
+	}
+}
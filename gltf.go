@@ -0,0 +1,578 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"strings"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// gltfDoc is the JSON document of a glTF 2.0 file,
+// with the parts of the format used here
+type gltfDoc struct {
+	Asset          gltfAsset          `json:"asset"`
+	ExtensionsUsed []string           `json:"extensionsUsed,omitempty"`
+	Extensions     *gltfDocExtensions `json:"extensions,omitempty"`
+	Scene          int                `json:"scene"`
+	Scenes         []gltfScene        `json:"scenes"`
+	Nodes          []gltfNode         `json:"nodes,omitempty"`
+	Cameras        []gltfCamera       `json:"cameras,omitempty"`
+	Meshes         []gltfMesh         `json:"meshes,omitempty"`
+	Materials      []gltfMaterial     `json:"materials,omitempty"`
+	Textures       []gltfTexture      `json:"textures,omitempty"`
+	Images         []gltfImage        `json:"images,omitempty"`
+	Samplers       []gltfSampler      `json:"samplers,omitempty"`
+	Accessors      []gltfAccessor     `json:"accessors,omitempty"`
+	BufferViews    []gltfBufferView   `json:"bufferViews,omitempty"`
+	Buffers        []gltfBuffer       `json:"buffers,omitempty"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type gltfDocExtensions struct {
+	Lights *gltfLights `json:"KHR_lights_punctual,omitempty"`
+}
+
+type gltfLights struct {
+	Lights []gltfLight `json:"lights"`
+}
+
+// gltfLight is a light of the KHR_lights_punctual extension
+type gltfLight struct {
+	Name      string         `json:"name,omitempty"`
+	Type      string         `json:"type"`
+	Color     []float32      `json:"color,omitempty"`
+	Intensity *float32       `json:"intensity,omitempty"`
+	Spot      *gltfLightSpot `json:"spot,omitempty"`
+}
+
+type gltfLightSpot struct {
+	InnerConeAngle float32 `json:"innerConeAngle"`
+	OuterConeAngle float32 `json:"outerConeAngle"`
+}
+
+// gltfSceneExtras holds what glTF has no place for: the ambient
+// lights, which KHR_lights_punctual does not have
+type gltfSceneExtras struct {
+	AmbientLights []gltfLight `json:"ambientLights,omitempty"`
+}
+
+type gltfScene struct {
+	Name   string           `json:"name,omitempty"`
+	Nodes  []int            `json:"nodes"`
+	Extras *gltfSceneExtras `json:"extras,omitempty"`
+}
+
+type gltfNode struct {
+	Name        string              `json:"name,omitempty"`
+	Children    []int               `json:"children,omitempty"`
+	Mesh        *int                `json:"mesh,omitempty"`
+	Camera      *int                `json:"camera,omitempty"`
+	Skin        *int                `json:"skin,omitempty"`
+	Matrix      []float32           `json:"matrix,omitempty"`
+	Translation []float32           `json:"translation,omitempty"`
+	Rotation    []float32           `json:"rotation,omitempty"`
+	Scale       []float32           `json:"scale,omitempty"`
+	Extensions  *gltfNodeExtensions `json:"extensions,omitempty"`
+}
+
+type gltfNodeExtensions struct {
+	Light *gltfNodeLight `json:"KHR_lights_punctual,omitempty"`
+}
+
+type gltfNodeLight struct {
+	Light int `json:"light"`
+}
+
+type gltfCamera struct {
+	Type         string                  `json:"type"`
+	Perspective  *gltfCameraPerspective  `json:"perspective,omitempty"`
+	Orthographic *gltfCameraOrthographic `json:"orthographic,omitempty"`
+}
+
+type gltfCameraPerspective struct {
+	AspectRatio float32 `json:"aspectRatio,omitempty"`
+	YFov        float32 `json:"yfov"`
+	ZNear       float32 `json:"znear"`
+	ZFar        float32 `json:"zfar,omitempty"`
+}
+
+type gltfCameraOrthographic struct {
+	XMag  float32 `json:"xmag"`
+	YMag  float32 `json:"ymag"`
+	ZNear float32 `json:"znear"`
+	ZFar  float32 `json:"zfar"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name,omitempty"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices,omitempty"`
+	Material   *int           `json:"material,omitempty"`
+	Mode       *int           `json:"mode,omitempty"`
+}
+
+type gltfMaterial struct {
+	Name           string          `json:"name,omitempty"`
+	PBR            gltfPBR         `json:"pbrMetallicRoughness"`
+	EmissiveFactor []float32       `json:"emissiveFactor,omitempty"`
+	AlphaMode      string          `json:"alphaMode,omitempty"`
+	DoubleSided    bool            `json:"doubleSided,omitempty"`
+	Extras         *gltfPhongExtra `json:"extras,omitempty"`
+}
+
+// gltfPhongExtra keeps the phong parameters of a material,
+// which do not map exactly onto metallic roughness
+type gltfPhongExtra struct {
+	Shiny      float32 `json:"shiny"`
+	Reflective float32 `json:"reflective"`
+	Bright     float32 `json:"bright"`
+}
+
+type gltfPBR struct {
+	BaseColorFactor  []float32       `json:"baseColorFactor,omitempty"`
+	BaseColorTexture *gltfTextureRef `json:"baseColorTexture,omitempty"`
+	MetallicFactor   *float32        `json:"metallicFactor,omitempty"`
+	RoughnessFactor  *float32        `json:"roughnessFactor,omitempty"`
+}
+
+type gltfTextureRef struct {
+	Index int `json:"index"`
+}
+
+type gltfTexture struct {
+	Name    string `json:"name,omitempty"`
+	Sampler *int   `json:"sampler,omitempty"`
+	Source  int    `json:"source"`
+}
+
+type gltfImage struct {
+	Name       string `json:"name,omitempty"`
+	MimeType   string `json:"mimeType,omitempty"`
+	BufferView *int   `json:"bufferView,omitempty"`
+	URI        string `json:"uri,omitempty"`
+}
+
+type gltfSampler struct {
+	WrapS int `json:"wrapS,omitempty"`
+	WrapT int `json:"wrapT,omitempty"`
+}
+
+type gltfAccessor struct {
+	BufferView    int             `json:"bufferView"`
+	ByteOffset    int             `json:"byteOffset,omitempty"`
+	ComponentType int             `json:"componentType"`
+	Normalized    bool            `json:"normalized,omitempty"`
+	Count         int             `json:"count"`
+	Type          string          `json:"type"`
+	Min           []float32       `json:"min,omitempty"`
+	Max           []float32       `json:"max,omitempty"`
+	Sparse        json.RawMessage `json:"sparse,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset,omitempty"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride,omitempty"`
+	Target     int `json:"target,omitempty"`
+}
+
+type gltfBuffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri,omitempty"`
+}
+
+// glTF constants used here
+const (
+	gltfByte          = 5120
+	gltfUnsignedByte  = 5121
+	gltfShort         = 5122
+	gltfUnsignedShort = 5123
+	gltfUnsignedInt   = 5125
+	gltfFloat         = 5126
+	gltfTriangles     = 4
+	gltfArrayBuffer   = 34962
+	gltfElementArray  = 34963
+	gltfRepeat        = 10497
+
+	glbMagic     = 0x46546C67 // "glTF"
+	glbChunkJSON = 0x4E4F534A // "JSON"
+	glbChunkBin  = 0x004E4942 // "BIN"
+)
+
+// ExportGLTF saves the given scene as a binary glTF 2.0 file at the
+// given path, with all of its meshes and textures in the one file.
+// Materials are converted to metallic roughness, using the Metallic and
+// Roughness of demo solids when set, and otherwise no metal and the
+// roughness from the shininess, and keep their phong parameters in
+// their extras. Lights are saved with the KHR_lights_punctual extension,
+// except for ambient lights, which it does not have, and which are kept
+// in the extras of the scene. The camera is saved as a camera node.
+// Nodes with reserved names, and text, are skipped.
+func ExportGLTF(sc *xyz.Scene, path string) error {
+	ge := &gltfExporter{sc: sc, meshes: map[gltfMeshKey]int{}, prims: map[xyz.MeshName]gltfPrimitive{},
+		textures: map[xyz.TextureName]int{}}
+	ge.doc.Asset = gltfAsset{Version: "2.0", Generator: "cogent-core-testing"}
+	gs := gltfScene{Name: sc.Name, Nodes: []int{}}
+	for _, k := range sc.Children {
+		if ni, ok := ge.node(k); ok {
+			gs.Nodes = append(gs.Nodes, ni)
+		}
+	}
+	gs.Nodes = append(gs.Nodes, ge.camera())
+	lns, amb := ge.lights()
+	gs.Nodes = append(gs.Nodes, lns...)
+	if len(amb) > 0 {
+		gs.Extras = &gltfSceneExtras{AmbientLights: amb}
+	}
+	ge.doc.Scenes = []gltfScene{gs}
+	if ge.bin.Len() > 0 {
+		ge.doc.Buffers = []gltfBuffer{{ByteLength: ge.bin.Len()}}
+	}
+	js, err := json.Marshal(&ge.doc)
+	if err != nil {
+		return err
+	}
+	if ge.err != nil {
+		return ge.err
+	}
+	glb, err := glbBytes(js, ge.bin.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, glb, 0666)
+}
+
+// gltfMeshKey is a mesh as used by a solid with a material,
+// which glTF keeps on each primitive of the mesh
+type gltfMeshKey struct {
+	Mesh     xyz.MeshName
+	Material int
+}
+
+// gltfExporter builds the document and binary buffer of a glTF file
+type gltfExporter struct {
+	sc  *xyz.Scene
+	doc gltfDoc
+	bin bytes.Buffer
+
+	// indexes in the document of the meshes with each material, the
+	// primitive of each mesh with its accessors, and the textures
+	meshes   map[gltfMeshKey]int
+	prims    map[xyz.MeshName]gltfPrimitive
+	textures map[xyz.TextureName]int
+
+	// first error writing to the buffer
+	err error
+}
+
+// node adds the given scene node and its children to the document,
+// returning its index, or false if it is skipped
+func (ge *gltfExporter) node(k tree.Node) (int, bool) {
+	n, nb := xyz.AsNode(k)
+	if n == nil || strings.HasPrefix(nb.Name, "__") {
+		return 0, false
+	}
+	switch k.(type) {
+	case *xyz.Text2D, *Text2D:
+		log.Printf("glTF: skipping text %s\n", nb.Name)
+		return 0, false
+	}
+	gn := gltfNode{Name: nb.Name}
+	setGLTFPose(&gn, nb.Pose.Pos, nb.Pose.Quat, nb.Pose.Scale)
+	if sld := n.AsSolid(); sld != nil && sld.Mesh != nil {
+		if mi, ok := ge.mesh(sld); ok {
+			gn.Mesh = &mi
+		}
+	}
+	for _, c := range nb.Children {
+		if ci, ok := ge.node(c); ok {
+			gn.Children = append(gn.Children, ci)
+		}
+	}
+	ge.doc.Nodes = append(ge.doc.Nodes, gn)
+	return len(ge.doc.Nodes) - 1, true
+}
+
+// setGLTFPose sets the transform of the given node,
+// leaving out the parts that have the default values
+func setGLTFPose(gn *gltfNode, pos math32.Vector3, q math32.Quat, scale math32.Vector3) {
+	if pos != (math32.Vector3{}) {
+		gn.Translation = []float32{pos.X, pos.Y, pos.Z}
+	}
+	if !q.IsNil() && !q.IsIdentity() {
+		q.Normalize()
+		gn.Rotation = []float32{q.X, q.Y, q.Z, q.W}
+	}
+	if scale != (math32.Vector3{}) && scale != math32.Vec3(1, 1, 1) {
+		gn.Scale = []float32{scale.X, scale.Y, scale.Z}
+	}
+}
+
+// mesh returns the index of the mesh for the given solid,
+// adding it with its material if needed
+func (ge *gltfExporter) mesh(sld *xyz.Solid) (int, bool) {
+	prim, ok := ge.prims[sld.MeshName]
+	if !ok {
+		prim, ok = ge.primitive(sld.Mesh)
+		if !ok {
+			return 0, false
+		}
+		ge.prims[sld.MeshName] = prim
+	}
+	key := gltfMeshKey{sld.MeshName, ge.material(sld)}
+	if mi, ok := ge.meshes[key]; ok {
+		return mi, true
+	}
+	prim.Material = &key.Material
+	ge.doc.Meshes = append(ge.doc.Meshes, gltfMesh{Name: string(sld.MeshName), Primitives: []gltfPrimitive{prim}})
+	mi := len(ge.doc.Meshes) - 1
+	ge.meshes[key] = mi
+	return mi, true
+}
+
+// primitive adds the vertex data of the given mesh to the buffer,
+// returning the primitive with the accessors for it
+func (ge *gltfExporter) primitive(ms xyz.Mesh) (gltfPrimitive, bool) {
+	md := shape.NewMeshData(ms)
+	if md.NumVertex == 0 || md.NumIndex == 0 {
+		return gltfPrimitive{}, false
+	}
+	nv := md.NumVertex
+	pa := ge.accessor(md.Vertex[:3*nv], "VEC3", gltfArrayBuffer)
+	bb := shape.BBoxFromVtxs(md.Vertex, 0, nv)
+	ge.doc.Accessors[pa].Min = []float32{bb.Min.X, bb.Min.Y, bb.Min.Z}
+	ge.doc.Accessors[pa].Max = []float32{bb.Max.X, bb.Max.Y, bb.Max.Z}
+	prim := gltfPrimitive{Attributes: map[string]int{"POSITION": pa}}
+	prim.Attributes["NORMAL"] = ge.accessor(md.Normal[:3*nv], "VEC3", gltfArrayBuffer)
+	prim.Attributes["TEXCOORD_0"] = ge.accessor(md.TexCoord[:2*nv], "VEC2", gltfArrayBuffer)
+	if md.HasColor {
+		prim.Attributes["COLOR_0"] = ge.accessor(md.Colors[:4*nv], "VEC4", gltfArrayBuffer)
+	}
+	ia := ge.accessor(md.Index[:md.NumIndex], "SCALAR", gltfElementArray)
+	prim.Indices = &ia
+	return prim, true
+}
+
+// accessor adds the given float32 or uint32 data to the buffer,
+// returning the index of the accessor for it
+func (ge *gltfExporter) accessor(data any, typ string, target int) int {
+	comps := map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4}[typ]
+	ac := gltfAccessor{BufferView: ge.bufferView(data, target), Type: typ}
+	switch d := data.(type) {
+	case math32.ArrayF32:
+		ac.ComponentType, ac.Count = gltfFloat, len(d)/comps
+	case math32.ArrayU32:
+		ac.ComponentType, ac.Count = gltfUnsignedInt, len(d)/comps
+	}
+	ge.doc.Accessors = append(ge.doc.Accessors, ac)
+	return len(ge.doc.Accessors) - 1
+}
+
+// bufferView adds the given data to the buffer, 4 byte aligned,
+// returning the index of the buffer view for it
+func (ge *gltfExporter) bufferView(data any, target int) int {
+	for ge.bin.Len()%4 != 0 {
+		ge.bin.WriteByte(0)
+	}
+	off := ge.bin.Len()
+	if err := binary.Write(&ge.bin, binary.LittleEndian, data); err != nil && ge.err == nil {
+		ge.err = fmt.Errorf("ExportGLTF: %w", err)
+	}
+	ge.doc.BufferViews = append(ge.doc.BufferViews, gltfBufferView{ByteOffset: off, ByteLength: ge.bin.Len() - off, Target: target})
+	return len(ge.doc.BufferViews) - 1
+}
+
+// material adds the material of the given solid to the document,
+// returning its index
+func (ge *gltfExporter) material(sld *xyz.Solid) int {
+	mt := &sld.Material
+	gm := gltfMaterial{DoubleSided: !mt.CullBack,
+		Extras: &gltfPhongExtra{Shiny: mt.Shiny, Reflective: mt.Reflective, Bright: mt.Bright}}
+	gm.PBR.BaseColorFactor = gltfColor(mt.Color, true)
+	metal, rough := float32(0), float32(1)
+	if mt.Reflective > 0 {
+		rough = math32.Sqrt(2 / (max(mt.Shiny, 0) + 2))
+	}
+	if ds, ok := sld.This.(interface{ asSolid() *Solid }); ok && ds.asSolid().Roughness > 0 {
+		metal, rough = ds.asSolid().Metallic, ds.asSolid().Roughness
+	}
+	gm.PBR.MetallicFactor, gm.PBR.RoughnessFactor = &metal, &rough
+	if mt.Color.A < 255 {
+		gm.AlphaMode = "BLEND"
+	}
+	if mt.Emissive.A > 0 {
+		gm.EmissiveFactor = gltfColor(mt.Emissive, false)
+	}
+	if mt.TextureName != "" {
+		if ti, ok := ge.texture(mt.TextureName); ok {
+			gm.PBR.BaseColorTexture = &gltfTextureRef{Index: ti}
+		}
+	}
+	for i := range ge.doc.Materials {
+		if gltfSameMaterial(&ge.doc.Materials[i], &gm) {
+			return i
+		}
+	}
+	ge.doc.Materials = append(ge.doc.Materials, gm)
+	return len(ge.doc.Materials) - 1
+}
+
+// gltfSameMaterial returns whether the given materials are the same
+func gltfSameMaterial(a, b *gltfMaterial) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+// gltfColor returns the given color in the linear space of glTF,
+// with alpha if requested
+func gltfColor(c color.RGBA, alpha bool) []float32 {
+	lin := math32.NewVector3Color(c).SRGBToLinear()
+	if alpha {
+		return []float32{lin.X, lin.Y, lin.Z, float32(c.A) / 255}
+	}
+	return []float32{lin.X, lin.Y, lin.Z}
+}
+
+// texture adds the texture with the given name to the document
+// as a PNG image in the buffer, returning its index
+func (ge *gltfExporter) texture(name xyz.TextureName) (int, bool) {
+	if ti, ok := ge.textures[name]; ok {
+		return ti, true
+	}
+	tx, err := ge.sc.TextureByName(string(name))
+	if err != nil || tx.Image() == nil {
+		return 0, false
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, tx.Image()); err != nil {
+		return 0, false
+	}
+	bv := ge.bufferView(b.Bytes(), 0)
+	ge.doc.Images = append(ge.doc.Images, gltfImage{Name: string(name), MimeType: "image/png", BufferView: &bv})
+	if len(ge.doc.Samplers) == 0 {
+		ge.doc.Samplers = []gltfSampler{{WrapS: gltfRepeat, WrapT: gltfRepeat}}
+	}
+	smp := 0
+	ge.doc.Textures = append(ge.doc.Textures, gltfTexture{Name: string(name), Sampler: &smp, Source: len(ge.doc.Images) - 1})
+	ti := len(ge.doc.Textures) - 1
+	ge.textures[name] = ti
+	return ti, true
+}
+
+// camera adds the camera of the scene as a node, returning its index
+func (ge *gltfExporter) camera() int {
+	cm := &ge.sc.Camera
+	gc := gltfCamera{Type: "perspective"}
+	if cm.Ortho {
+		height := 2 * cm.Far * math32.Tan(math32.DegToRad(cm.FOV*0.5))
+		gc.Type = "orthographic"
+		gc.Orthographic = &gltfCameraOrthographic{XMag: cm.Aspect * height / 2, YMag: height / 2, ZNear: cm.Near, ZFar: cm.Far}
+	} else {
+		gc.Perspective = &gltfCameraPerspective{AspectRatio: cm.Aspect, YFov: math32.DegToRad(cm.FOV), ZNear: cm.Near, ZFar: cm.Far}
+	}
+	ge.doc.Cameras = append(ge.doc.Cameras, gc)
+	ci := len(ge.doc.Cameras) - 1
+	gn := gltfNode{Name: "camera", Camera: &ci}
+	setGLTFPose(&gn, cm.Pose.Pos, cm.Pose.Quat, math32.Vec3(1, 1, 1))
+	ge.doc.Nodes = append(ge.doc.Nodes, gn)
+	return len(ge.doc.Nodes) - 1
+}
+
+// lights adds the lights of the scene as the lights of the
+// KHR_lights_punctual extension, returning the indexes of the nodes
+// placing them and the ambient lights, which are not in the extension.
+// The lights shine down the -Z axis of their nodes.
+func (ge *gltfExporter) lights() (nodes []int, ambient []gltfLight) {
+	var lts []gltfLight
+	for _, kv := range ge.sc.Lights.Order {
+		lb := kv.Value.AsLightBase()
+		lumens := lb.Lumens
+		gl := gltfLight{Name: lb.Name, Color: gltfColor(lb.Color, false), Intensity: &lumens}
+		gn := gltfNode{Name: lb.Name}
+		switch lt := kv.Value.(type) {
+		case *xyz.Ambient:
+			gl.Type = "ambient"
+			ambient = append(ambient, gl)
+			continue
+		case *xyz.Directional:
+			gl.Type = "directional"
+			var q math32.Quat
+			q.SetFromUnitVectors(math32.Vec3(0, 0, -1), lt.Pos.Negate().Normal())
+			setGLTFPose(&gn, math32.Vector3{}, q, math32.Vec3(1, 1, 1))
+		case *xyz.Point:
+			gl.Type = "point"
+			setGLTFPose(&gn, lt.Pos, math32.Quat{}, math32.Vec3(1, 1, 1))
+		case *xyz.Spot:
+			gl.Type = "spot"
+			// the inner angle must be below the outer one
+			outer := math32.DegToRad(lt.CutoffAngle)
+			gl.Spot = &gltfLightSpot{InnerConeAngle: min(math32.DegToRad(spotInnerAngle(lt)), 0.99*outer),
+				OuterConeAngle: outer}
+			setGLTFPose(&gn, lt.Pose.Pos, lt.Pose.Quat, math32.Vec3(1, 1, 1))
+		default:
+			log.Printf("glTF: skipping light %s of unsupported type %T\n", lb.Name, kv.Value)
+			continue
+		}
+		lts = append(lts, gl)
+		gn.Extensions = &gltfNodeExtensions{Light: &gltfNodeLight{Light: len(lts) - 1}}
+		ge.doc.Nodes = append(ge.doc.Nodes, gn)
+		nodes = append(nodes, len(ge.doc.Nodes)-1)
+	}
+	if len(lts) > 0 {
+		ge.doc.ExtensionsUsed = []string{"KHR_lights_punctual"}
+		ge.doc.Extensions = &gltfDocExtensions{Lights: &gltfLights{Lights: lts}}
+	}
+	return
+}
+
+// glbBytes returns a binary glTF file with the given JSON
+// document and binary buffer
+func glbBytes(js, bin []byte) ([]byte, error) {
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+	size := 12 + 8 + len(js)
+	if len(bin) > 0 {
+		size += 8 + len(bin)
+	}
+	var b bytes.Buffer
+	le := binary.LittleEndian
+	if err := binary.Write(&b, le, []uint32{glbMagic, 2, uint32(size), uint32(len(js)), glbChunkJSON}); err != nil {
+		return nil, fmt.Errorf("ExportGLTF: %w", err)
+	}
+	b.Write(js)
+	if len(bin) > 0 {
+		if err := binary.Write(&b, le, []uint32{uint32(len(bin)), glbChunkBin}); err != nil {
+			return nil, fmt.Errorf("ExportGLTF: %w", err)
+		}
+		b.Write(bin)
+	}
+	return b.Bytes(), nil
+}
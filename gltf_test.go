@@ -0,0 +1,180 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// newDemoTestScene returns a new scene with the shapes, materials and
+// lights of the demo, without its instances, text and animations
+func newDemoTestScene() *xyz.Scene {
+	sc := newTestScene()
+	xyz.NewAmbient(sc, "ambient", 0.3, xyz.DirectSun)
+	sun := xyz.NewDirectional(sc, "directional", 1, xyz.DirectSun)
+	sun.Pos.Set(0, 2, 1)
+	spot := NewSpotLight(sc, "spot", 2, xyz.DirectSun)
+	spot.Pos, spot.Dir = math32.Vec3(1, 4, 2), math32.Vec3(-0.2, -1, -0.5)
+	spot.Update(sc)
+
+	floor := xyz.NewSolid(sc).SetMesh(xyz.NewPlane(sc, "floor-plane", 10, 10)).
+		SetColor(colors.Tan).SetPos(0, -1, 0)
+	floor.SetName("floor")
+
+	cube := NewSolid(sc)
+	cube.SetName("cube")
+	cube.SetMesh(NewRoundedBox(sc, "cube-mesh", 0.45, 0.45, 0.45, 0.06, 4)).SetShiny(20).SetPos(-1.5, 0, 0)
+
+	cylinder := NewSolid(sc)
+	cylinder.SetName("cylinder")
+	cylinder.SetMesh(xyz.NewCylinder(sc, "cylinder-mesh", 1.5, 0.3, 32, 1, true, true)).SetPos(0, 0, -2)
+	cylinder.SetPBRMaterial(PBRMaterial{BaseColor: colors.Green, Metallic: 1, Roughness: 0.4})
+	cylinder.Pose.SetAxisRotation(1, 0, 0, 90)
+
+	group := xyz.NewGroup(sc)
+	group.SetName("group")
+	group.Pose.Pos.Set(1.5, 0.5, 0)
+	group.Pose.Scale.Set(2, 1, 1)
+	torus := NewSolid(group)
+	torus.SetName("torus")
+	torus.SetMesh(xyz.NewTorus(sc, "torus-mesh", 0.7, 0.1, 32)).SetColor(color.RGBA{255, 0, 255, 150}).SetPos(0, 1, 0)
+	torus.Pose.SetAxisRotation(1, 0, 0, 45)
+	sphere := NewSolid(group)
+	sphere.SetName("sphere")
+	sphere.SetMesh(xyz.NewSphere(sc, "sphere-mesh", 0.2, 16)).SetColor(colors.Orange)
+
+	sc.Camera.Pose.Pos.Set(0, 3, 8)
+	sc.Camera.LookAtOrigin()
+	updateTestScene(sc)
+	return sc
+}
+
+// testSolids returns the solids of the given scene by name
+func testSolids(sc *xyz.Scene) map[string]*xyz.Solid {
+	slds := map[string]*xyz.Solid{}
+	sc.WalkDown(func(k tree.Node) bool {
+		if n, _ := xyz.AsNode(k); n != nil && n.AsSolid() != nil {
+			sld := n.AsSolid()
+			slds[sld.Name] = sld
+		}
+		return true
+	})
+	return slds
+}
+
+// closeColors returns whether the given colors are the same
+// to within the rounding of converting them to linear and back
+func closeColors(a, b color.RGBA) bool {
+	near := func(x, y uint8) bool { return max(x, y)-min(x, y) <= 1 }
+	return near(a.R, b.R) && near(a.G, b.G) && near(a.B, b.B) && near(a.A, b.A)
+}
+
+func TestGLTFRoundTrip(t *testing.T) {
+	sc := newDemoTestScene()
+	path := filepath.Join(t.TempDir(), "demo.glb")
+	if err := ExportGLTF(sc, path); err != nil {
+		t.Fatal(err)
+	}
+	im := xyz.NewScene()
+	if err := ImportGLTF(im, path); err != nil {
+		t.Fatal(err)
+	}
+	updateTestScene(im)
+
+	want, got := testSolids(sc), testSolids(im)
+	if len(got) != len(want) {
+		t.Errorf("imported %d solids instead of %d", len(got), len(want))
+	}
+	for name, ws := range want {
+		gs := got[name]
+		if gs == nil {
+			t.Errorf("solid %s was not imported", name)
+			continue
+		}
+		if d := gs.Pose.Pos.Sub(ws.Pose.Pos).Length(); d > 1e-5 {
+			t.Errorf("solid %s is at %v instead of %v", name, gs.Pose.Pos, ws.Pose.Pos)
+		}
+		if d := gs.Pose.Quat.Dot(ws.Pose.Quat); math32.Abs(d) < 1-1e-5 {
+			t.Errorf("solid %s is turned by %v instead of %v", name, gs.Pose.Quat, ws.Pose.Quat)
+		}
+		if d := gs.WorldBBox.BBox.Min.Sub(ws.WorldBBox.BBox.Min).Length() +
+			gs.WorldBBox.BBox.Max.Sub(ws.WorldBBox.BBox.Max).Length(); d > 1e-4 {
+			t.Errorf("solid %s has the world bounding box %v instead of %v", name, gs.WorldBBox.BBox, ws.WorldBBox.BBox)
+		}
+		if !closeColors(gs.Material.Color, ws.Material.Color) {
+			t.Errorf("solid %s has the color %v instead of %v", name, gs.Material.Color, ws.Material.Color)
+		}
+		wm, gm := shape.NewMeshData(ws.Mesh), shape.NewMeshData(gs.Mesh)
+		if gm.NumVertex != wm.NumVertex || gm.NumIndex != wm.NumIndex {
+			t.Errorf("mesh of solid %s has %d vertexes and %d indexes instead of %d and %d",
+				name, gm.NumVertex, gm.NumIndex, wm.NumVertex, wm.NumIndex)
+			continue
+		}
+		for i := range 3 * wm.NumVertex {
+			if gm.Vertex[i] != wm.Vertex[i] {
+				t.Errorf("mesh of solid %s has %g at vertex value %d instead of %g", name, gm.Vertex[i], i, wm.Vertex[i])
+				break
+			}
+		}
+	}
+	if g, ok := got["torus"]; !ok || g.Parent.AsTree().Name != "group" {
+		t.Error("the torus is not in the group")
+	}
+
+	if got, want := len(im.Lights.Order), len(sc.Lights.Order); got != want {
+		t.Errorf("imported %d lights instead of %d", got, want)
+	}
+	ws := sc.Lights.ValueByKey("spot").(*xyz.Spot)
+	gs, ok := im.Lights.ValueByKey("spot").(*xyz.Spot)
+	if !ok {
+		t.Fatal("the spot light was not imported")
+	}
+	if math32.Abs(gs.CutoffAngle-ws.CutoffAngle) > 1e-3 || math32.Abs(gs.AngDecay-ws.AngDecay) > 1e-2*ws.AngDecay {
+		t.Errorf("the spot light has a cutoff of %g and decay of %g instead of %g and %g",
+			gs.CutoffAngle, gs.AngDecay, ws.CutoffAngle, ws.AngDecay)
+	}
+	if d := gs.ViewDir().Sub(ws.ViewDir()).Length(); d > 1e-4 {
+		t.Errorf("the spot light shines along %v instead of %v", gs.ViewDir(), ws.ViewDir())
+	}
+	if d := im.Camera.Pose.Pos.Sub(sc.Camera.Pose.Pos).Length(); d > 1e-4 {
+		t.Errorf("the camera is at %v instead of %v", im.Camera.Pose.Pos, sc.Camera.Pose.Pos)
+	}
+}
+
+func TestGLTFNodeCycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cycle.gltf")
+	js := `{"asset": {"version": "2.0"}, "scenes": [{"nodes": [0]}],
+		"nodes": [{"name": "a", "children": [1]}, {"name": "b", "children": [0]}]}`
+	if err := os.WriteFile(path, []byte(js), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportGLTF(xyz.NewScene(), path); err == nil {
+		t.Error("imported nodes that are their own parents")
+	}
+}
+
+func TestGLTFNegativeOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offset.gltf")
+	js := `{"asset": {"version": "2.0"}, "scenes": [{"nodes": [0]}], "nodes": [{"mesh": 0}],
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0}}]}],
+		"accessors": [{"bufferView": 0, "byteOffset": -12, "componentType": 5126, "count": 1, "type": "VEC3"}],
+		"bufferViews": [{"buffer": 0, "byteLength": 12}],
+		"buffers": [{"byteLength": 12, "uri": "data:application/octet-stream;base64,AAAAAAAAAAAAAAAA"}]}`
+	if err := os.WriteFile(path, []byte(js), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportGLTF(xyz.NewScene(), path); err == nil {
+		t.Error("imported an accessor with a negative offset")
+	}
+}
@@ -0,0 +1,660 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// ImportGLTF adds the nodes, meshes, textures and lights of the glTF
+// 2.0 file at the given path to the given scene, which can be a .gltf
+// JSON file, with its buffers and images embedded as base64 data or in
+// files next to it, or a binary .glb file. Nodes with meshes become
+// demo [Solid]s, with an [ArrayMesh] of the vertex data for each
+// primitive, and other nodes become groups. The base color of the
+// metallic roughness material sets the color of the solid, and the
+// roughness its shininess, unless the file was saved by [ExportGLTF],
+// which keeps the phong parameters. Skinned meshes are imported in
+// their bind pose with a warning. The camera is set from the first
+// camera node, and the lights of the KHR_lights_punctual extension are
+// added. Names are made unique among the nodes already in the scene.
+func ImportGLTF(sc *xyz.Scene, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	gi := &gltfImporter{sc: sc, dir: filepath.Dir(path), meshes: map[int][]gltfPrimMesh{},
+		textures: map[int]xyz.TextureName{}, visited: map[int]bool{}}
+	js := b
+	if len(b) >= 12 && binary.LittleEndian.Uint32(b) == glbMagic {
+		if js, gi.glbBin, err = glbChunks(b); err != nil {
+			return err
+		}
+	}
+	if err := json.Unmarshal(js, &gi.doc); err != nil {
+		return fmt.Errorf("ImportGLTF: %w", err)
+	}
+	if !strings.HasPrefix(gi.doc.Asset.Version, "2.") {
+		return fmt.Errorf("ImportGLTF: unsupported glTF version %q", gi.doc.Asset.Version)
+	}
+	if err := gi.loadBuffers(); err != nil {
+		return err
+	}
+	roots, extras := gi.rootNodes()
+	world := math32.Identity4()
+	for _, ni := range roots {
+		if err := gi.node(sc, ni, world); err != nil {
+			return err
+		}
+	}
+	if extras != nil {
+		for _, gl := range extras.AmbientLights {
+			lt := &xyz.Ambient{}
+			gi.setLight(&lt.LightBase, gl)
+			sc.AddLight(lt)
+		}
+	}
+	sc.SetNeedsUpdate()
+	return nil
+}
+
+// gltfImporter makes scene nodes from a glTF document
+type gltfImporter struct {
+	sc  *xyz.Scene
+	doc gltfDoc
+	dir string
+
+	// binary chunk of a glb file, and the data of the buffers
+	glbBin  []byte
+	buffers [][]byte
+
+	// names of the meshes made for the primitives of each glTF
+	// mesh, and of the textures made for each glTF texture
+	meshes   map[int][]gltfPrimMesh
+	textures map[int]xyz.TextureName
+
+	// nodes that have been added, as each node can only have one parent
+	visited map[int]bool
+
+	// whether the camera has been set from a camera node
+	camera bool
+}
+
+// gltfPrimMesh is the scene mesh made for a primitive of a glTF mesh
+type gltfPrimMesh struct {
+	name xyz.MeshName
+	prim int
+}
+
+// glbChunks returns the JSON and binary chunks of the given glb file
+func glbChunks(b []byte) (js, bin []byte, err error) {
+	le := binary.LittleEndian
+	if v := le.Uint32(b[4:]); v != 2 {
+		return nil, nil, fmt.Errorf("ImportGLTF: unsupported glb version %d", v)
+	}
+	size := min(int(le.Uint32(b[8:])), len(b))
+	for off := 12; off+8 <= size; {
+		n, typ := int(le.Uint32(b[off:])), le.Uint32(b[off+4:])
+		off += 8
+		if off+n > size {
+			return nil, nil, errors.New("ImportGLTF: truncated glb chunk")
+		}
+		switch typ {
+		case glbChunkJSON:
+			js = b[off : off+n]
+		case glbChunkBin:
+			bin = b[off : off+n]
+		}
+		off += n
+	}
+	if js == nil {
+		return nil, nil, errors.New("ImportGLTF: glb file has no JSON chunk")
+	}
+	return js, bin, nil
+}
+
+// loadBuffers loads the data of all of the buffers
+func (gi *gltfImporter) loadBuffers() error {
+	gi.buffers = make([][]byte, len(gi.doc.Buffers))
+	for i, bf := range gi.doc.Buffers {
+		var data []byte
+		var err error
+		if bf.URI == "" {
+			data = gi.glbBin
+		} else {
+			data, err = gi.loadURI(bf.URI)
+		}
+		if err != nil {
+			return err
+		}
+		if len(data) < bf.ByteLength {
+			return fmt.Errorf("ImportGLTF: buffer %d has %d bytes instead of %d", i, len(data), bf.ByteLength)
+		}
+		gi.buffers[i] = data
+	}
+	return nil
+}
+
+// loadURI returns the data at the given URI of a buffer or image,
+// which is base64 data or a file relative to the glTF file
+func (gi *gltfImporter) loadURI(uri string) ([]byte, error) {
+	if rest, ok := strings.CutPrefix(uri, "data:"); ok {
+		_, data, ok := strings.Cut(rest, ";base64,")
+		if !ok {
+			return nil, errors.New("ImportGLTF: only base64 data URIs are supported")
+		}
+		return base64.StdEncoding.DecodeString(data)
+	}
+	fn, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(gi.dir, filepath.FromSlash(fn)))
+}
+
+// rootNodes returns the root nodes of the scene of the document,
+// or all of the nodes that are not children if it has no scenes
+func (gi *gltfImporter) rootNodes() ([]int, *gltfSceneExtras) {
+	if len(gi.doc.Scenes) > 0 {
+		gs := gi.doc.Scenes[min(max(gi.doc.Scene, 0), len(gi.doc.Scenes)-1)]
+		return gs.Nodes, gs.Extras
+	}
+	child := make([]bool, len(gi.doc.Nodes))
+	for _, gn := range gi.doc.Nodes {
+		for _, c := range gn.Children {
+			if c >= 0 && c < len(child) {
+				child[c] = true
+			}
+		}
+	}
+	var roots []int
+	for i, c := range child {
+		if !c {
+			roots = append(roots, i)
+		}
+	}
+	return roots, nil
+}
+
+// node adds the glTF node with the given index to the given parent,
+// which has the given world transform, along with its children
+func (gi *gltfImporter) node(parent tree.Node, ni int, parWorld *math32.Matrix4) error {
+	if ni < 0 || ni >= len(gi.doc.Nodes) {
+		return fmt.Errorf("ImportGLTF: node index %d out of range", ni)
+	}
+	if gi.visited[ni] {
+		return fmt.Errorf("ImportGLTF: node %d is in the node tree more than once", ni)
+	}
+	gi.visited[ni] = true
+	gn := &gi.doc.Nodes[ni]
+	pos, q, scale := gltfPose(gn)
+	local := ComposeTRS(pos, q, scale)
+	var world math32.Matrix4
+	world.MulMatrices(parWorld, &local)
+
+	if gn.Camera != nil {
+		gi.setCamera(*gn.Camera, &world)
+	}
+	if gn.Extensions != nil && gn.Extensions.Light != nil {
+		gi.addLight(gn.Extensions.Light.Light, gn.Name, &world)
+	}
+	if gn.Mesh == nil && len(gn.Children) == 0 && (gn.Camera != nil || gn.Extensions != nil) {
+		return nil // only a camera or a light
+	}
+	if gn.Skin != nil {
+		log.Printf("glTF: skinning is not supported, so node %q is in its bind pose\n", gn.Name)
+	}
+
+	var nd xyz.Node
+	if gn.Mesh != nil {
+		pms, err := gi.mesh(*gn.Mesh)
+		if err != nil {
+			return err
+		}
+		switch len(pms) {
+		case 0:
+			nd = xyz.NewGroup(parent)
+		case 1:
+			nd = gi.solid(parent, *gn.Mesh, pms[0])
+		default:
+			nd = xyz.NewGroup(parent)
+			for i, pm := range pms {
+				sld := gi.solid(nd, *gn.Mesh, pm)
+				sld.SetName(fmt.Sprintf("%s-%d", gltfName(gn.Name, "node", ni), i))
+			}
+		}
+	} else {
+		nd = xyz.NewGroup(parent)
+	}
+	nb := nd.AsNodeBase()
+	nb.SetName(gltfName(gn.Name, "node", ni))
+	setUniqueName(parent, nd)
+	nb.Pose.Pos, nb.Pose.Quat, nb.Pose.Scale = pos, q, scale
+	for _, c := range gn.Children {
+		if err := gi.node(nd, c, &world); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gltfPose returns the transform of the given node
+func gltfPose(gn *gltfNode) (pos math32.Vector3, q math32.Quat, scale math32.Vector3) {
+	if len(gn.Matrix) == 16 {
+		var m math32.Matrix4
+		m.FromArray(gn.Matrix, 0) // column major, as in glTF
+		return DecomposeTRS(&m)
+	}
+	q.SetIdentity()
+	scale.Set(1, 1, 1)
+	if len(gn.Translation) == 3 {
+		pos.Set(gn.Translation[0], gn.Translation[1], gn.Translation[2])
+	}
+	if len(gn.Rotation) == 4 {
+		q = math32.NewQuat(gn.Rotation[0], gn.Rotation[1], gn.Rotation[2], gn.Rotation[3])
+	}
+	if len(gn.Scale) == 3 {
+		scale.Set(gn.Scale[0], gn.Scale[1], gn.Scale[2])
+	}
+	return
+}
+
+// gltfName returns the given name, or one made from the
+// given kind and index of the thing named if it is empty
+func gltfName(name, kind string, i int) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", kind, i)
+}
+
+// solid adds a solid with the given mesh of a primitive of the
+// glTF mesh with the given index, and its material, to the given parent
+func (gi *gltfImporter) solid(parent tree.Node, mi int, pm gltfPrimMesh) *Solid {
+	sld := NewSolid(parent)
+	sld.SetMeshName(string(pm.name))
+	if mat := gi.doc.Meshes[mi].Primitives[pm.prim].Material; mat != nil && *mat >= 0 && *mat < len(gi.doc.Materials) {
+		gi.setMaterial(sld, &gi.doc.Materials[*mat])
+	}
+	return sld
+}
+
+// setMaterial sets the material of the given solid from the given one
+func (gi *gltfImporter) setMaterial(sld *Solid, gm *gltfMaterial) {
+	mt := &sld.Material
+	clr := gltfToColor(gm.PBR.BaseColorFactor, color.RGBA{255, 255, 255, 255})
+	if gm.AlphaMode == "" || gm.AlphaMode == "OPAQUE" {
+		clr.A = 255
+	}
+	pm := PBRMaterial{BaseColor: clr, Metallic: 1, Roughness: 1} // glTF defaults
+	if gm.PBR.MetallicFactor != nil {
+		pm.Metallic = *gm.PBR.MetallicFactor
+	}
+	if gm.PBR.RoughnessFactor != nil {
+		pm.Roughness = *gm.PBR.RoughnessFactor
+	}
+	if len(gm.EmissiveFactor) == 3 {
+		pm.EmissiveColor = gltfToColor(gm.EmissiveFactor, color.RGBA{})
+	}
+	sld.SetPBRMaterial(pm)
+	if am, ok := sld.Mesh.(*ArrayMesh); ok && len(am.Colors) > 0 {
+		sld.OverrideVertexColors = false // glTF multiplies them by the base color
+	}
+	if ph := gm.Extras; ph != nil {
+		mt.Shiny, mt.Reflective, mt.Bright = ph.Shiny, ph.Reflective, ph.Bright
+	}
+	mt.CullBack = !gm.DoubleSided
+	if ref := gm.PBR.BaseColorTexture; ref != nil {
+		if name, err := gi.texture(ref.Index); err == nil {
+			mt.SetTextureName(gi.sc, string(name))
+		} else {
+			log.Printf("glTF: skipping texture %d: %v\n", ref.Index, err)
+		}
+	}
+}
+
+// gltfToColor returns the given linear glTF color as an sRGB color,
+// or the given default if it is not set
+func gltfToColor(c []float32, def color.RGBA) color.RGBA {
+	if len(c) < 3 {
+		return def
+	}
+	srgb := math32.Vec3(c[0], c[1], c[2]).SRGBFromLinear()
+	a := float32(1)
+	if len(c) == 4 {
+		a = c[3]
+	}
+	u8 := func(v float32) uint8 { return uint8(math32.Clamp(v, 0, 1)*255 + 0.5) }
+	return color.RGBA{u8(srgb.X), u8(srgb.Y), u8(srgb.Z), u8(a)}
+}
+
+// texture returns the name of the scene texture for the glTF texture
+// with the given index, decoding its image the first time
+func (gi *gltfImporter) texture(ti int) (xyz.TextureName, error) {
+	if name, ok := gi.textures[ti]; ok {
+		return name, nil
+	}
+	if ti < 0 || ti >= len(gi.doc.Textures) {
+		return "", errors.New("texture index out of range")
+	}
+	gt := gi.doc.Textures[ti]
+	if gt.Source < 0 || gt.Source >= len(gi.doc.Images) {
+		return "", errors.New("texture has no image")
+	}
+	gm := gi.doc.Images[gt.Source]
+	var data []byte
+	var err error
+	if gm.BufferView != nil {
+		data, err = gi.bufferViewData(*gm.BufferView)
+	} else {
+		data, err = gi.loadURI(gm.URI)
+	}
+	if err != nil {
+		return "", err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	name := addImageTexture(gi.sc, gltfName(gm.Name, "texture", ti), img)
+	gi.textures[ti] = name
+	return name, nil
+}
+
+// mesh returns the scene meshes for the primitives of the glTF
+// mesh with the given index, making them the first time
+func (gi *gltfImporter) mesh(mi int) ([]gltfPrimMesh, error) {
+	if pms, ok := gi.meshes[mi]; ok {
+		return pms, nil
+	}
+	if mi < 0 || mi >= len(gi.doc.Meshes) {
+		return nil, fmt.Errorf("ImportGLTF: mesh index %d out of range", mi)
+	}
+	gm := &gi.doc.Meshes[mi]
+	var pms []gltfPrimMesh
+	for pi := range gm.Primitives {
+		prim := &gm.Primitives[pi]
+		if prim.Mode != nil && *prim.Mode != gltfTriangles {
+			log.Printf("glTF: skipping primitive %d of mesh %q, which is not triangles\n", pi, gm.Name)
+			continue
+		}
+		name := gltfName(gm.Name, "mesh", mi)
+		if len(gm.Primitives) > 1 {
+			name = fmt.Sprintf("%s-%d", name, pi)
+		}
+		if _, err := gi.sc.MeshByName(name); err == nil {
+			name = fmt.Sprintf("%s-%d", name, len(gi.sc.Meshes.Order))
+		}
+		if err := gi.primitive(prim, name); err != nil {
+			return nil, fmt.Errorf("ImportGLTF: mesh %q: %w", gm.Name, err)
+		}
+		pms = append(pms, gltfPrimMesh{xyz.MeshName(name), pi})
+	}
+	gi.meshes[mi] = pms
+	return pms, nil
+}
+
+// primitive adds an [ArrayMesh] with the given name
+// for the given primitive to the scene
+func (gi *gltfImporter) primitive(prim *gltfPrimitive, name string) error {
+	pa, ok := prim.Attributes["POSITION"]
+	if !ok {
+		return errors.New("primitive has no positions")
+	}
+	vtx, _, err := gi.accessorFloats(pa)
+	if err != nil {
+		return err
+	}
+	nv := len(vtx) / 3
+	attr := func(name string, comps int) (math32.ArrayF32, error) {
+		ai, ok := prim.Attributes[name]
+		if !ok {
+			return nil, nil
+		}
+		d, n, err := gi.accessorFloats(ai)
+		if err != nil || n != comps || len(d) != comps*nv {
+			return nil, err
+		}
+		return d, nil
+	}
+	nrm, err := attr("NORMAL", 3)
+	if err != nil {
+		return err
+	}
+	tex, err := attr("TEXCOORD_0", 2)
+	if err != nil {
+		return err
+	}
+	var clrs math32.ArrayF32
+	if ci, ok := prim.Attributes["COLOR_0"]; ok {
+		d, n, err := gi.accessorFloats(ci)
+		if err != nil {
+			return err
+		}
+		if n == 3 && len(d) == 3*nv { // opaque
+			clrs = make(math32.ArrayF32, 4*nv)
+			for i := range nv {
+				clrs.Set(4*i, d[3*i], d[3*i+1], d[3*i+2], 1)
+			}
+		} else if n == 4 && len(d) == 4*nv {
+			clrs = d
+		}
+	}
+	var idx math32.ArrayU32
+	if prim.Indices != nil {
+		if idx, err = gi.accessorIndexes(*prim.Indices); err != nil {
+			return err
+		}
+		for _, i := range idx {
+			if int(i) >= nv {
+				return errors.New("vertex index out of range")
+			}
+		}
+	}
+	NewArrayMesh(gi.sc, name, vtx, nrm, tex, clrs, idx)
+	return nil
+}
+
+// bufferViewData returns the bytes of the buffer view with the given index
+func (gi *gltfImporter) bufferViewData(bi int) ([]byte, error) {
+	if bi < 0 || bi >= len(gi.doc.BufferViews) {
+		return nil, fmt.Errorf("buffer view index %d out of range", bi)
+	}
+	bv := gi.doc.BufferViews[bi]
+	if bv.Buffer < 0 || bv.Buffer >= len(gi.buffers) {
+		return nil, fmt.Errorf("buffer index %d out of range", bv.Buffer)
+	}
+	buf := gi.buffers[bv.Buffer]
+	if bv.ByteOffset < 0 || bv.ByteLength < 0 || bv.ByteOffset+bv.ByteLength > len(buf) {
+		return nil, fmt.Errorf("buffer view %d is out of its buffer", bi)
+	}
+	return buf[bv.ByteOffset : bv.ByteOffset+bv.ByteLength], nil
+}
+
+// gltfComponents is the number of components of each accessor type
+var gltfComponents = map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4}
+
+// gltfComponentSize is the size in bytes of each component type
+var gltfComponentSize = map[int]int{gltfByte: 1, gltfUnsignedByte: 1,
+	gltfShort: 2, gltfUnsignedShort: 2, gltfUnsignedInt: 4, gltfFloat: 4}
+
+// accessorValues calls the given function with each component of the
+// accessor with the given index, as a float64, returning the number
+// of components of each element
+func (gi *gltfImporter) accessorValues(ai int, fun func(i int, v float64)) (int, error) {
+	if ai < 0 || ai >= len(gi.doc.Accessors) {
+		return 0, fmt.Errorf("accessor index %d out of range", ai)
+	}
+	ac := gi.doc.Accessors[ai]
+	if len(ac.Sparse) > 0 {
+		return 0, errors.New("sparse accessors are not supported")
+	}
+	comps, csize := gltfComponents[ac.Type], gltfComponentSize[ac.ComponentType]
+	if comps == 0 || csize == 0 {
+		return 0, fmt.Errorf("unsupported accessor type %s of %d", ac.Type, ac.ComponentType)
+	}
+	if ac.ByteOffset < 0 || ac.Count < 0 {
+		return 0, fmt.Errorf("accessor %d has a negative offset or count", ai)
+	}
+	data, err := gi.bufferViewData(ac.BufferView)
+	if err != nil {
+		return 0, err
+	}
+	stride := comps * csize
+	if bv := gi.doc.BufferViews[ac.BufferView]; bv.ByteStride > 0 {
+		stride = bv.ByteStride
+	}
+	if ac.Count > 0 && ac.ByteOffset+(ac.Count-1)*stride+comps*csize > len(data) {
+		return 0, fmt.Errorf("accessor %d is out of its buffer view", ai)
+	}
+	le := binary.LittleEndian
+	for e := range ac.Count {
+		for c := range comps {
+			off := ac.ByteOffset + e*stride + c*csize
+			var v, norm float64
+			switch ac.ComponentType {
+			case gltfByte:
+				v, norm = float64(int8(data[off])), 127
+			case gltfUnsignedByte:
+				v, norm = float64(data[off]), 255
+			case gltfShort:
+				v, norm = float64(int16(le.Uint16(data[off:]))), 32767
+			case gltfUnsignedShort:
+				v, norm = float64(le.Uint16(data[off:])), 65535
+			case gltfUnsignedInt:
+				v = float64(le.Uint32(data[off:]))
+			case gltfFloat:
+				v = float64(math.Float32frombits(le.Uint32(data[off:])))
+			}
+			if ac.Normalized && norm > 0 {
+				v = max(v/norm, -1)
+			}
+			fun(e*comps+c, v)
+		}
+	}
+	return comps, nil
+}
+
+// accessorFloats returns the data of the accessor with the given index
+// as floats, and the number of components of each element. Integer
+// colors and texture coordinates are normalized.
+func (gi *gltfImporter) accessorFloats(ai int) (math32.ArrayF32, int, error) {
+	var d math32.ArrayF32
+	comps, err := gi.accessorValues(ai, func(i int, v float64) {
+		d = append(d, float32(v))
+	})
+	return d, comps, err
+}
+
+// accessorIndexes returns the data of the accessor
+// with the given index as vertex indexes
+func (gi *gltfImporter) accessorIndexes(ai int) (math32.ArrayU32, error) {
+	var d math32.ArrayU32
+	_, err := gi.accessorValues(ai, func(i int, v float64) {
+		d = append(d, uint32(v))
+	})
+	return d, err
+}
+
+// setCamera sets the camera of the scene from the glTF camera with the
+// given index at the given world transform, for the first camera node
+func (gi *gltfImporter) setCamera(ci int, world *math32.Matrix4) {
+	if gi.camera || ci < 0 || ci >= len(gi.doc.Cameras) {
+		return
+	}
+	gi.camera = true
+	gc := gi.doc.Cameras[ci]
+	cm := &gi.sc.Camera
+	pos, q, _ := DecomposeTRS(world)
+	cm.Pose.Pos, cm.Pose.Quat = pos, q
+	switch {
+	case gc.Perspective != nil:
+		cm.Ortho = false
+		cm.FOV = math32.RadToDeg(gc.Perspective.YFov)
+		cm.Near = gc.Perspective.ZNear
+		if gc.Perspective.ZFar > 0 {
+			cm.Far = gc.Perspective.ZFar
+		}
+	case gc.Orthographic != nil:
+		// the xyz orthographic view is the size of the perspective
+		// view at the far plane, so the FOV is set to match
+		o := gc.Orthographic
+		cm.Ortho = true
+		cm.Near, cm.Far = o.ZNear, o.ZFar
+		cm.FOV = math32.RadToDeg(2 * math32.Atan(o.YMag/max(o.ZFar, 1e-6)))
+	}
+	cm.UpdateMatrix()
+	cm.TargetFromView()
+}
+
+// addLight adds the KHR_lights_punctual light with the
+// given index and node name at the given world transform
+func (gi *gltfImporter) addLight(li int, nodeName string, world *math32.Matrix4) {
+	ext := gi.doc.Extensions
+	if ext == nil || ext.Lights == nil || li < 0 || li >= len(ext.Lights.Lights) {
+		return
+	}
+	gl := ext.Lights.Lights[li]
+	if gl.Name == "" {
+		gl.Name = gltfName(nodeName, "light", li)
+	}
+	pos, q, _ := DecomposeTRS(world)
+	dir := math32.Vec3(0, 0, -1).MulQuat(q)
+	var lt xyz.Light
+	switch gl.Type {
+	case "directional":
+		dl := &xyz.Directional{Pos: dir.Negate()}
+		gi.setLight(&dl.LightBase, gl)
+		lt = dl
+	case "point":
+		pl := &xyz.Point{Pos: pos, LinDecay: 0.01, QuadDecay: 0.001}
+		gi.setLight(&pl.LightBase, gl)
+		lt = pl
+	case "spot":
+		sl := &xyz.Spot{AngDecay: 15, CutoffAngle: 45, LinDecay: 0.01, QuadDecay: 0.001}
+		sl.Pose.Defaults()
+		sl.Pose.Pos, sl.Pose.Quat = pos, q
+		if gl.Spot != nil && gl.Spot.OuterConeAngle > 0 {
+			outer := math32.RadToDeg(gl.Spot.OuterConeAngle)
+			sl.CutoffAngle = outer
+			sl.AngDecay = spotAngDecay(math32.Clamp(math32.RadToDeg(gl.Spot.InnerConeAngle), 0, outer), outer)
+		}
+		gi.setLight(&sl.LightBase, gl)
+		lt = sl
+	default:
+		log.Printf("glTF: skipping light %q of unsupported type %q\n", gl.Name, gl.Type)
+		return
+	}
+	gi.sc.AddLight(lt)
+}
+
+// setLight sets the name, color and brightness of the given light
+func (gi *gltfImporter) setLight(lb *xyz.LightBase, gl gltfLight) {
+	lb.Name, lb.On = gl.Name, true
+	lb.Color = gltfToColor(gl.Color, color.RGBA{255, 255, 255, 255})
+	lb.Lumens = 1
+	if gl.Intensity != nil {
+		lb.Lumens = *gl.Intensity
+	}
+}
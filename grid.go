@@ -0,0 +1,292 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+const (
+	// GridName is the name of the solid showing the grid
+	GridName = "__Grid"
+
+	// GridMeshName is the name of the mesh of the grid
+	GridMeshName = "__GridMesh"
+)
+
+// ShowGrid shows a [Grid] on the XZ plane of the scene, with the given
+// spacing between minor lines, or removes it if the spacing is 0
+func ShowGrid(sc *xyz.Scene, spacing float32) *Grid {
+	gr := SceneGrid(sc)
+	if spacing <= 0 {
+		if gr != nil {
+			gr.Delete()
+		}
+		sc.SetNeedsUpdate()
+		return nil
+	}
+	if gr == nil {
+		gr = tree.New[Grid](sc)
+		gr.SetName(GridName)
+		gr.Material.Reflective = 0
+		gr.Material.Shiny = 0
+	}
+	gr.Spacing = spacing
+	sc.SetNeedsUpdate()
+	return gr
+}
+
+// SceneGrid returns the grid of the given scene, or nil if none
+func SceneGrid(sc *xyz.Scene) *Grid {
+	gr, _ := sc.ChildByName(GridName, 0).(*Grid)
+	return gr
+}
+
+// Grid is a reference grid centered on the origin, with major lines
+// every 10 minor lines. The xyz lines mesh has no vertex colors, so
+// the grid has its own mesh of flat strips, which fade out toward
+// the far plane of the camera. It can not be selected.
+type Grid struct {
+	xyz.Solid
+
+	// Spacing between minor lines
+	Spacing float32
+
+	// Color of the lines
+	Color color.RGBA
+
+	// MajorAlpha and MinorAlpha are the opacities of the major and minor lines
+	MajorAlpha, MinorAlpha uint8
+
+	// mesh of the grid, rebuilt when the settings or far plane change
+	mesh *GridMesh
+}
+
+func (gr *Grid) Init() {
+	gr.Solid.Init()
+	gr.Spacing = 1
+	gr.Color = color.RGBA{128, 128, 128, 255}
+	gr.MajorAlpha, gr.MinorAlpha = 160, 60
+}
+
+// PreRender rebuilds the mesh if needed and clears the window
+// bounding box so that clicks on the grid do not select it
+func (gr *Grid) PreRender() {
+	gm := GridMesh{Spacing: gr.Spacing, Extent: 0.9 * gr.Scene.Camera.Far, Color: gr.Color,
+		MajorAlpha: gr.MajorAlpha, MinorAlpha: gr.MinorAlpha}
+	if gr.mesh == nil || !gr.mesh.sameAs(&gm) {
+		gr.mesh = &gm
+		gm.Name = GridMeshName
+		gr.Scene.SetMesh(gr.mesh)
+		gr.SetMesh(gr.mesh)
+		gr.UpdateMeshBBox()
+	}
+	gr.SceneBBox = image.Rectangle{}
+	gr.Solid.PreRender()
+}
+
+const (
+	// gridMaxMajor is the maximum number of major lines on each side of the origin
+	gridMaxMajor = 100
+
+	// gridSegments is the number of segments in each line, for fading
+	gridSegments = 16
+)
+
+// GridMesh is the mesh of a [Grid]: strips of width proportional to
+// the spacing in the XZ plane facing up. Major lines reach out to the
+// Extent, or gridMaxMajor lines, and minor lines to a tenth of that.
+// Lines fade out over the outer half of their length.
+type GridMesh struct {
+	xyz.MeshBase
+
+	// Spacing between minor lines
+	Spacing float32
+
+	// Extent of the grid in each direction from the origin
+	Extent float32
+
+	// Color of the lines
+	Color color.RGBA
+
+	// MajorAlpha and MinorAlpha are the opacities of the major and minor lines
+	MajorAlpha, MinorAlpha uint8
+}
+
+// sameAs returns whether the mesh has the same settings as the other
+func (gm *GridMesh) sameAs(o *GridMesh) bool {
+	return gm.Spacing == o.Spacing && gm.Extent == o.Extent && gm.Color == o.Color &&
+		gm.MajorAlpha == o.MajorAlpha && gm.MinorAlpha == o.MinorAlpha
+}
+
+// gridLine is a line of the grid, at Offset along X or Z
+// from the origin and running from -Extent to Extent
+type gridLine struct {
+	Offset, Extent, Width float32
+	AlongX                bool
+	Alpha                 uint8
+}
+
+// lines returns all of the lines of the grid
+func (gm *GridMesh) lines() []gridLine {
+	if gm.Spacing <= 0 || gm.Extent <= 0 {
+		return nil
+	}
+	major := 10 * gm.Spacing
+	nmaj := min(int(gm.Extent/major), gridMaxMajor)
+	majExt := float32(nmaj) * major
+	nmin := 10 * max(nmaj/10, 1)
+	minExt := float32(nmin) * gm.Spacing
+	var lns []gridLine
+	for _, alongX := range []bool{true, false} {
+		for i := -nmaj; i <= nmaj; i++ {
+			lns = append(lns, gridLine{float32(i) * major, majExt, 0.04 * gm.Spacing, alongX, gm.MajorAlpha})
+		}
+		for i := -nmin; i <= nmin; i++ {
+			if i%10 != 0 {
+				lns = append(lns, gridLine{float32(i) * gm.Spacing, minExt, 0.02 * gm.Spacing, alongX, gm.MinorAlpha})
+			}
+		}
+	}
+	return lns
+}
+
+func (gm *GridMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	n := len(gm.lines())
+	gm.NumVertex = 2 * (gridSegments + 1) * n
+	gm.NumIndex = 6 * gridSegments * n
+	gm.HasColor = true
+	gm.Transparent = true // faded lines are drawn after opaque solids
+	return gm.NumVertex, gm.NumIndex, gm.HasColor
+}
+
+func (gm *GridMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	up := math32.Vec3(0, 1, 0)
+	clr := math32.NewVector4Color(gm.Color)
+	vi, ii := 0, 0
+	for _, ln := range gm.lines() {
+		hw := 0.5 * ln.Width
+		for s := 0; s <= gridSegments; s++ {
+			t := 2*float32(s)/gridSegments - 1
+			along := t * ln.Extent
+			fade := 1 - math32.Clamp(2*math32.Abs(t)-1, 0, 1)
+			c := clr
+			c.W = float32(ln.Alpha) / 255 * fade * fade
+			for side, off := range []float32{-hw, hw} {
+				v := math32.Vec3(along, 0, ln.Offset+off)
+				if !ln.AlongX {
+					v = math32.Vec3(ln.Offset+off, 0, along)
+				}
+				vertex.SetVector3(3*(vi+side), v)
+				normal.SetVector3(3*(vi+side), up)
+				texcoord.SetVector2(2*(vi+side), math32.Vec2(0.5*(t+1), float32(side)))
+				clrs.SetVector4(4*(vi+side), c)
+			}
+			if s > 0 {
+				u := uint32(vi)
+				index.Set(ii, u-2, u-1, u+1, u-2, u+1, u)
+				ii += 6
+			}
+			vi += 2
+		}
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, vi)
+	gm.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// GridLines is a mesh of the major or minor lines of a square grid in
+// the XZ plane facing up, centered on the origin, as flat strips like
+// those of a [Grid], for scenes that draw their own grids. Major lines
+// are every tenth of the Size, and minor lines every Size / Divisions,
+// except where there are major lines.
+type GridLines struct {
+	xyz.MeshBase
+
+	// Size of the grid along X and Z
+	Size float32
+
+	// Divisions is the number of minor divisions across the grid
+	Divisions int
+
+	// Major is whether the mesh has the major lines, instead of the minor ones
+	Major bool
+
+	// Width of the lines
+	Width float32
+}
+
+// NewGrid returns new meshes of the major and minor lines of a grid of
+// the given size with the given number of minor divisions across it,
+// set on the given scene under the given name with -major and -minor
+// suffixes, so they can be shown by solids of different colors
+func NewGrid(sc *xyz.Scene, name string, size float32, divisions int) (major, minor *GridLines) {
+	major = &GridLines{Size: size, Divisions: divisions, Major: true, Width: size / 250}
+	major.Name = name + "-major"
+	sc.SetMesh(major)
+	minor = &GridLines{Size: size, Divisions: divisions, Width: size / 500}
+	minor.Name = name + "-minor"
+	sc.SetMesh(minor)
+	return
+}
+
+// offsets returns the offsets of the lines from the middle of the grid
+func (gl *GridLines) offsets() []float32 {
+	var offs []float32
+	half := gl.Size / 2
+	if gl.Major {
+		for i := range 11 {
+			offs = append(offs, float32(i)*gl.Size/10-half)
+		}
+		return offs
+	}
+	n := max(gl.Divisions, 1)
+	for i := range n + 1 {
+		if i*10%n == 0 {
+			continue // a major line
+		}
+		offs = append(offs, float32(i)*gl.Size/float32(n)-half)
+	}
+	return offs
+}
+
+func (gl *GridLines) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	n := 2 * len(gl.offsets()) // along X and Z
+	gl.NumVertex = 4 * n
+	gl.NumIndex = 6 * n
+	return gl.NumVertex, gl.NumIndex, false
+}
+
+func (gl *GridLines) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	up := math32.Vec3(0, 1, 0)
+	ext, hw := gl.Size/2, gl.Width/2
+	vi, ii := 0, 0
+	for _, alongX := range []bool{true, false} {
+		for _, off := range gl.offsets() {
+			for s, along := range []float32{-ext, ext} {
+				for side, w := range []float32{-hw, hw} {
+					v := math32.Vec3(along, 0, off+w)
+					if !alongX {
+						v = math32.Vec3(off+w, 0, along)
+					}
+					vertex.SetVector3(3*vi, v)
+					normal.SetVector3(3*vi, up)
+					texcoord.Set(2*vi, float32(s), float32(side))
+					vi++
+				}
+			}
+			u := uint32(vi - 2)
+			index.Set(ii, u-2, u-1, u+1, u-2, u+1, u)
+			ii += 6
+		}
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, vi)
+	gl.BBox.SetBounds(bb.Min, bb.Max)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// HeightMapPlane is a terrain mesh: a plane in the XZ plane facing up,
+// centered on the origin, divided into a grid of cells with each vertex
+// raised by its height. Normals are made from the heights of the
+// neighboring vertices.
+type HeightMapPlane struct {
+	xyz.MeshBase
+
+	// Size of the plane along X and Z
+	Size math32.Vector2
+
+	// Cols and Rows are the number of cells along X and Z
+	Cols, Rows int
+
+	// Heights of the (Cols+1)*(Rows+1) vertices, row by row along X
+	// from -Z to +Z; missing heights are 0
+	Heights []float32
+
+	// Smooth makes each height the average of those around it,
+	// in one pass before the normals are made
+	Smooth bool
+}
+
+// NewHeightMapPlane returns a new height map mesh of the given size
+// along X and Z, with the given number of cells along each, and the
+// given heights of the (cols+1)*(rows+1) vertices, set on the given
+// scene under the given name. Set Smooth before the scene
+// is first rendered to smooth the heights.
+func NewHeightMapPlane(sc *xyz.Scene, name string, width, depth float32, cols, rows int, heights []float32) *HeightMapPlane {
+	hm := &HeightMapPlane{Size: math32.Vec2(width, depth), Cols: max(cols, 1), Rows: max(rows, 1), Heights: heights}
+	hm.Name = name
+	sc.SetMesh(hm)
+	return hm
+}
+
+func (hm *HeightMapPlane) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	hm.Cols, hm.Rows = max(hm.Cols, 1), max(hm.Rows, 1)
+	hm.NumVertex = (hm.Cols + 1) * (hm.Rows + 1)
+	hm.NumIndex = 6 * hm.Cols * hm.Rows
+	return hm.NumVertex, hm.NumIndex, false
+}
+
+func (hm *HeightMapPlane) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	nc, nr := hm.Cols+1, hm.Rows+1
+	hts := hm.heights()
+	h := func(c, r int) float32 {
+		return hts[min(max(r, 0), nr-1)*nc+min(max(c, 0), nc-1)]
+	}
+	dx, dz := hm.Size.X/float32(hm.Cols), hm.Size.Y/float32(hm.Rows)
+	for r := range nr {
+		for c := range nc {
+			i := r*nc + c
+			vertex.Set(3*i, float32(c)*dx-hm.Size.X/2, h(c, r), float32(r)*dz-hm.Size.Y/2)
+			texcoord.Set(2*i, float32(c)/float32(hm.Cols), float32(r)/float32(hm.Rows))
+			// the slopes from the neighbors, one sided at the edges
+			sx := (h(c+1, r) - h(c-1, r)) / (float32(min(c+1, nc-1)-max(c-1, 0)) * dx)
+			sz := (h(c, r+1) - h(c, r-1)) / (float32(min(r+1, nr-1)-max(r-1, 0)) * dz)
+			normal.SetVector3(3*i, math32.Vec3(-sx, 1, -sz).Normal())
+		}
+	}
+	ii := 0
+	for r := range hm.Rows {
+		for c := range hm.Cols {
+			a := uint32(r*nc + c)
+			b, cc, d := a+1, a+uint32(nc), a+uint32(nc)+1
+			index.Set(ii, a, cc, b, b, cc, d)
+			ii += 6
+		}
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, hm.NumVertex)
+	hm.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// heights returns the height of each vertex, smoothed if Smooth is set
+func (hm *HeightMapPlane) heights() []float32 {
+	nc, nr := hm.Cols+1, hm.Rows+1
+	hts := make([]float32, nc*nr)
+	copy(hts, hm.Heights)
+	if !hm.Smooth {
+		return hts
+	}
+	sm := make([]float32, len(hts))
+	for r := range nr {
+		for c := range nc {
+			sum, n := float32(0), 0
+			for _, o := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				oc, or := c+o[0], r+o[1]
+				if oc >= 0 && oc < nc && or >= 0 && or < nr {
+					sum += hts[or*nc+oc]
+					n++
+				}
+			}
+			sm[r*nc+c] = sum / float32(n)
+		}
+	}
+	return sm
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/color"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// HemisphereLight lights surfaces with a sky color from above and a
+// ground color from below, blending between them by the Y component
+// of the surface normal. The phong shader has no such light, so it is
+// made from an ambient light and two directional lights, one straight
+// down for the sky and one straight up for the ground. In each color
+// channel the ambient part is a quarter of the way from the darker to
+// the lighter color, which is exact facing the lighter color and off
+// by at most a quarter of the difference elsewhere.
+type HemisphereLight struct {
+	// Sky is the color of light from above
+	Sky color.RGBA
+
+	// Ground is the color of light from below
+	Ground color.RGBA
+
+	// Intensity of the light
+	Intensity float32
+
+	// Lights in the scene making up the hemisphere light
+	Ambient     *xyz.Ambient     `edit:"-"`
+	SkyLight    *xyz.Directional `edit:"-"`
+	GroundLight *xyz.Directional `edit:"-"`
+}
+
+// NewHemisphereLight adds a new hemisphere light with the given name,
+// sky and ground colors and intensity to the given scene. It adds
+// three lights to the scene, with the given name and -ambient, -sky
+// and -ground suffixes.
+func NewHemisphereLight(sc *xyz.Scene, name string, sky, ground color.RGBA, intensity float32) *HemisphereLight {
+	hl := &HemisphereLight{Sky: sky, Ground: ground, Intensity: intensity}
+	hl.Ambient = xyz.NewAmbient(sc, name+"-ambient", intensity, xyz.DirectSun)
+	hl.SkyLight = xyz.NewDirectional(sc, name+"-sky", intensity, xyz.DirectSun)
+	hl.SkyLight.Pos.Set(0, 1, 0)
+	hl.GroundLight = xyz.NewDirectional(sc, name+"-ground", intensity, xyz.DirectSun)
+	hl.GroundLight.Pos.Set(0, -1, 0)
+	hl.Update(sc) // the lights were added to a live scene before being set
+	return hl
+}
+
+// Update applies changes to the fields of the light to the scene
+func (hl *HemisphereLight) Update(sc *xyz.Scene) {
+	hl.apply()
+	if !sc.IsLive() {
+		return
+	}
+	sc.Phong.SetAmbient(lightIndex(sc, hl.Ambient), lightColor(hl.Ambient))
+	for _, lt := range []*xyz.Directional{hl.SkyLight, hl.GroundLight} {
+		sc.Phong.SetDirectional(lightIndex(sc, lt), lightColor(lt), lt.Pos)
+	}
+	sc.SetNeedsRender()
+}
+
+// apply sets the colors of the lights from ours, splitting the
+// light in the linear space where the renderer adds it up
+func (hl *HemisphereLight) apply() {
+	sky := math32.NewVector3Color(hl.Sky).MulScalar(hl.Intensity).SRGBToLinear()
+	ground := math32.NewVector3Color(hl.Ground).MulScalar(hl.Intensity).SRGBToLinear()
+	amb := sky.Min(ground).Add(sky.Sub(ground).Abs().MulScalar(0.25))
+	setLinearColor(hl.Ambient.AsLightBase(), amb)
+	setLinearColor(hl.SkyLight.AsLightBase(), sky.Sub(amb).Max(math32.Vector3{}))
+	setLinearColor(hl.GroundLight.AsLightBase(), ground.Sub(amb).Max(math32.Vector3{}))
+}
+
+// setLinearColor sets the color and brightness of the given light
+// so that the renderer uses the given linear color for it
+func setLinearColor(lb *xyz.LightBase, lin math32.Vector3) {
+	srgb := lin.SRGBFromLinear()
+	lb.Lumens = max(srgb.X, srgb.Y, srgb.Z, 1)
+	c := srgb.DivScalar(lb.Lumens).MulScalar(255)
+	lb.Color = color.RGBA{uint8(c.X + 0.5), uint8(c.Y + 0.5), uint8(c.Z + 0.5), 255}
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"slices"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+)
+
+// ComputeConvexHull returns the convex hull of the vertices of the mesh
+// of the solid, in the space of the mesh, as for a collision proxy under
+// the same pose: the vertices on the hull, and the vertex indexes of its
+// triangles, three per triangle, wound counterclockwise seen from
+// outside. It returns an error if the solid has no mesh, or its vertices
+// are all on a plane, which has no volume.
+func (sld *Solid) ComputeConvexHull() ([]math32.Vector3, []int, error) {
+	if sld.Mesh == nil {
+		return nil, nil, errors.New("ComputeConvexHull: solid has no mesh")
+	}
+	md := shape.NewMeshData(sld.Mesh)
+	pts := make([]math32.Vector3, md.NumVertex)
+	for i := range pts {
+		md.Vertex.GetVector3(3*i, &pts[i])
+	}
+	return ConvexHull(pts)
+}
+
+// AABB returns the axis aligned bounding box of the solid in world
+// space, which the scene updates with its world matrix
+func (sld *Solid) AABB() math32.Box3 {
+	return sld.WorldBBox.BBox
+}
+
+// hullFace is a triangle of a convex hull being built, with the
+// plane through it, and the points outside of it not yet on the hull
+type hullFace struct {
+	v       [3]int
+	normal  math32.Vector3
+	dist    float32
+	outside []int
+	dead    bool
+}
+
+// distance returns the signed distance of the given point
+// from the plane of the face, positive outside of the hull
+func (f *hullFace) distance(p math32.Vector3) float32 {
+	return f.normal.Dot(p) - f.dist
+}
+
+// ConvexHull returns the convex hull of the given points, by the
+// QuickHull algorithm, as for [Solid.ComputeConvexHull]
+func ConvexHull(points []math32.Vector3) ([]math32.Vector3, []int, error) {
+	// the same point can be on several vertices of a mesh
+	var pts []math32.Vector3
+	seen := map[math32.Vector3]bool{}
+	var bb math32.Box3
+	bb.SetEmpty()
+	for _, p := range points {
+		if !seen[p] {
+			seen[p] = true
+			pts = append(pts, p)
+			bb.ExpandByPoint(p)
+		}
+	}
+	errFlat := errors.New("ConvexHull: the points are all on a plane")
+	if len(pts) < 4 {
+		return nil, nil, errFlat
+	}
+	eps := 1e-5 * max(bb.Size().Length(), 1e-12)
+
+	// the first tetrahedron, from the points furthest apart
+	t := [4]int{}
+	for i, p := range pts {
+		if p.X < pts[t[0]].X {
+			t[0] = i
+		}
+		if p.X > pts[t[1]].X {
+			t[1] = i
+		}
+	}
+	far := func(dist func(p math32.Vector3) float32) int {
+		best, bd := -1, eps
+		for i, p := range pts {
+			if d := math32.Abs(dist(p)); d > bd {
+				best, bd = i, d
+			}
+		}
+		return best
+	}
+	a, b := pts[t[0]], pts[t[1]]
+	ab := b.Sub(a)
+	line := func(p math32.Vector3) float32 { return p.Sub(a).Cross(ab).Length() / max(ab.Length(), 1e-12) }
+	if t[2] = far(line); t[2] < 0 || t[0] == t[1] {
+		return nil, nil, errFlat
+	}
+	n := ab.Cross(pts[t[2]].Sub(a)).Normal()
+	if t[3] = far(func(p math32.Vector3) float32 { return n.Dot(p.Sub(a)) }); t[3] < 0 {
+		return nil, nil, errFlat
+	}
+	// faces by their edges, counterclockwise seen from outside,
+	// so that the face across an edge owns its reverse
+	type edge struct{ a, b int }
+	var faces []*hullFace
+	owner := map[edge]*hullFace{}
+	addFace := func(i, j, k int) *hullFace {
+		f := &hullFace{v: [3]int{i, j, k}}
+		f.normal = pts[j].Sub(pts[i]).Cross(pts[k].Sub(pts[i])).Normal()
+		f.dist = f.normal.Dot(pts[i])
+		faces = append(faces, f)
+		for e := range 3 {
+			owner[edge{f.v[e], f.v[(e+1)%3]}] = f
+		}
+		return f
+	}
+	if n.Dot(pts[t[3]].Sub(a)) > 0 { // the fourth point is above the first face
+		t[1], t[2] = t[2], t[1]
+	}
+	addFace(t[0], t[1], t[2])
+	addFace(t[0], t[3], t[1])
+	addFace(t[1], t[3], t[2])
+	addFace(t[2], t[3], t[0])
+
+	// assign gives the given points to the first of the given faces
+	// that they are outside of; the others are inside of the hull
+	assign := func(idx []int, to []*hullFace) {
+		for _, i := range idx {
+			for _, f := range to {
+				if f.distance(pts[i]) > eps {
+					f.outside = append(f.outside, i)
+					break
+				}
+			}
+		}
+	}
+	all := make([]int, 0, len(pts))
+	for i := range pts {
+		if i != t[0] && i != t[1] && i != t[2] && i != t[3] {
+			all = append(all, i)
+		}
+	}
+	assign(all, faces)
+
+	for {
+		var cur *hullFace
+		for _, f := range faces {
+			if !f.dead && len(f.outside) > 0 {
+				cur = f
+				break
+			}
+		}
+		if cur == nil {
+			break
+		}
+		// the furthest point outside of it goes on the hull
+		eye, ed := -1, float32(0)
+		for _, i := range cur.outside {
+			if d := cur.distance(pts[i]); d > ed {
+				eye, ed = i, d
+			}
+		}
+		p := pts[eye]
+		// the faces it sees, which are all connected to this one,
+		// are replaced by ones from the edges of the horizon; those
+		// it is only just in front of are seen too, as keeping them
+		// would leave the hull folded in there
+		visible := []*hullFace{cur}
+		cur.dead = true
+		var horizon []edge
+		var orphans []int
+		for len(visible) > 0 {
+			f := visible[len(visible)-1]
+			visible = visible[:len(visible)-1]
+			for _, i := range f.outside {
+				if i != eye {
+					orphans = append(orphans, i)
+				}
+			}
+			f.outside = nil
+			for e := range 3 {
+				ed := edge{f.v[e], f.v[(e+1)%3]}
+				nb := owner[edge{ed.b, ed.a}]
+				switch {
+				case nb == nil || nb.dead:
+				case nb.distance(p) > 0:
+					nb.dead = true
+					visible = append(visible, nb)
+				default:
+					horizon = append(horizon, ed)
+				}
+			}
+		}
+		var added []*hullFace
+		for _, e := range horizon {
+			added = append(added, addFace(e.a, e.b, eye))
+		}
+		assign(orphans, added)
+		faces = slices.DeleteFunc(faces, func(f *hullFace) bool { return f.dead })
+	}
+
+	// only the vertices on the hull, in the order of the points
+	remap := map[int]int{}
+	var verts []math32.Vector3
+	var index []int
+	use := func(i int) int {
+		if j, ok := remap[i]; ok {
+			return j
+		}
+		remap[i] = len(verts)
+		verts = append(verts, pts[i])
+		return remap[i]
+	}
+	for _, f := range faces {
+		index = append(index, use(f.v[0]), use(f.v[1]), use(f.v[2]))
+	}
+	return verts, index, nil
+}
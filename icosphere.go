@@ -0,0 +1,151 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// icosphereMaxSubdivisions is the most times an [Icosphere] is subdivided,
+// which gives it 20 * 4^7 = 327,680 triangles
+const icosphereMaxSubdivisions = 7
+
+// Icosphere is a geodesic sphere centered on the origin, made by
+// dividing each triangle of an icosahedron into four, Subdivisions
+// times, and moving the new vertices out onto the sphere. Its
+// triangles are all about the same size, unlike those of [xyz.Sphere],
+// which get small at the poles. The texture is mapped by longitude and
+// latitude, with the vertices along the 180 degree meridian and at the
+// poles repeated for the triangles on each side of them.
+type Icosphere struct {
+	xyz.MeshBase
+
+	// Radius of the sphere
+	Radius float32
+
+	// Subdivisions is the number of times each triangle is divided into
+	// four: 0 for an icosahedron of 20 triangles, and 4 for 5120
+	Subdivisions int `min:"0" max:"7"`
+
+	// vertex, texture and index arrays, made by MeshSize
+	vertex, texcoord math32.ArrayF32
+	index            math32.ArrayU32
+}
+
+// NewIcosphere returns a new icosphere mesh of the given radius,
+// with its triangles divided into four the given number of times,
+// set on the given scene under the given name
+func NewIcosphere(sc *xyz.Scene, name string, radius float32, subdivisions int) *Icosphere {
+	ic := &Icosphere{Radius: radius, Subdivisions: subdivisions}
+	ic.Name = name
+	sc.SetMesh(ic)
+	return ic
+}
+
+func (ic *Icosphere) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	ic.build()
+	ic.NumVertex = len(ic.vertex) / 3
+	ic.NumIndex = len(ic.index)
+	return ic.NumVertex, ic.NumIndex, false
+}
+
+func (ic *Icosphere) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	for i := range ic.NumVertex {
+		var v math32.Vector3
+		ic.vertex.GetVector3(3*i, &v)
+		vertex.SetVector3(3*i, v.MulScalar(ic.Radius))
+		normal.SetVector3(3*i, v)
+	}
+	copy(texcoord, ic.texcoord)
+	copy(index, ic.index)
+	bb := shape.BBoxFromVtxs(vertex, 0, ic.NumVertex)
+	ic.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// build makes the unit vertices, texture and index arrays of the sphere
+func (ic *Icosphere) build() {
+	t := (1 + math32.Sqrt(5)) / 2
+	vtxs := []math32.Vector3{
+		math32.Vec3(-1, t, 0), math32.Vec3(1, t, 0), math32.Vec3(-1, -t, 0), math32.Vec3(1, -t, 0),
+		math32.Vec3(0, -1, t), math32.Vec3(0, 1, t), math32.Vec3(0, -1, -t), math32.Vec3(0, 1, -t),
+		math32.Vec3(t, 0, -1), math32.Vec3(t, 0, 1), math32.Vec3(-t, 0, -1), math32.Vec3(-t, 0, 1),
+	}
+	for i := range vtxs {
+		vtxs[i] = vtxs[i].Normal()
+	}
+	tris := [][3]uint32{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+	for range min(max(ic.Subdivisions, 0), icosphereMaxSubdivisions) {
+		mids := map[[2]uint32]uint32{}
+		mid := func(a, b uint32) uint32 {
+			key := [2]uint32{min(a, b), max(a, b)}
+			if m, ok := mids[key]; ok {
+				return m
+			}
+			m := uint32(len(vtxs))
+			vtxs = append(vtxs, vtxs[a].Add(vtxs[b]).Normal())
+			mids[key] = m
+			return m
+		}
+		next := make([][3]uint32, 0, 4*len(tris))
+		for _, tr := range tris {
+			ab, bc, ca := mid(tr[0], tr[1]), mid(tr[1], tr[2]), mid(tr[2], tr[0])
+			next = append(next, [3]uint32{tr[0], ab, ca}, [3]uint32{tr[1], bc, ab},
+				[3]uint32{tr[2], ca, bc}, [3]uint32{ab, bc, ca})
+		}
+		tris = next
+	}
+
+	uvs := make([]math32.Vector2, len(vtxs))
+	for i, v := range vtxs {
+		uvs[i] = math32.Vec2(0.5-math32.Atan2(v.Z, v.X)/(2*math32.Pi), math32.Acos(math32.Clamp(v.Y, -1, 1))/math32.Pi)
+	}
+	// repeats a vertex with its texture coordinates changed
+	repeat := func(i uint32, uv math32.Vector2) uint32 {
+		vtxs = append(vtxs, vtxs[i])
+		uvs = append(uvs, uv)
+		return uint32(len(vtxs) - 1)
+	}
+	// seam has the vertices repeated on the far side of the seam
+	seam := map[uint32]uint32{}
+	for ti := range tris {
+		tr := &tris[ti]
+		u0, u1, u2 := uvs[tr[0]].X, uvs[tr[1]].X, uvs[tr[2]].X
+		if max(u0, u1, u2)-min(u0, u1, u2) > 0.5 { // across the seam
+			for k, i := range tr {
+				if uvs[i].X >= 0.5 {
+					continue
+				}
+				s, ok := seam[i]
+				if !ok {
+					s = repeat(i, math32.Vec2(uvs[i].X+1, uvs[i].Y))
+					seam[i] = s
+				}
+				tr[k] = s
+			}
+		}
+		for k, i := range tr {
+			if math32.Abs(vtxs[i].Y) > 1-1e-6 { // a pole, between the others
+				o1, o2 := tr[(k+1)%3], tr[(k+2)%3]
+				tr[k] = repeat(i, math32.Vec2((uvs[o1].X+uvs[o2].X)/2, uvs[i].Y))
+			}
+		}
+	}
+
+	ic.vertex, ic.texcoord, ic.index = nil, nil, nil
+	for i, v := range vtxs {
+		ic.vertex.AppendVector3(v)
+		ic.texcoord.Append(uvs[i].X, uvs[i].Y)
+	}
+	for _, tr := range tris {
+		ic.index.Append(tr[:]...)
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"sync"
+
+	"cogentcore.org/core/base/errors"
+	"cogentcore.org/core/gpu"
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// InstanceData is the pose and color of one instance of an [InstancedSolid],
+// relative to the solid
+type InstanceData struct {
+	Pos   math32.Vector3
+	Rot   math32.Quat
+	Scale math32.Vector3
+	Color color.RGBA
+}
+
+// NewInstance returns an instance at the given position with
+// the given color, no rotation and unit scale
+func NewInstance(pos math32.Vector3, clr color.RGBA) InstanceData {
+	return InstanceData{Pos: pos, Rot: math32.NewQuat(0, 0, 0, 1), Scale: math32.Vec3(1, 1, 1), Color: clr}
+}
+
+// InstancedSolid draws many copies of one mesh with a single draw call.
+// The phong renderer has no instanced draw, so the instances are baked
+// into one mesh with per-vertex colors. When instances change, only their
+// vertexes are posed again, and only the positions, normals and colors
+// are uploaded on the next render, unless the number of instances changed.
+type InstancedSolid struct {
+	Solid
+
+	// BaseMesh is the mesh drawn for each instance
+	BaseMesh xyz.MeshName `set:"-"`
+
+	// Instances to draw; use [InstancedSolid.SetInstances] or
+	// [InstancedSolid.SetInstance] to change them
+	Instances []InstanceData `set:"-"`
+
+	// mesh combining all of the instances
+	mesh *InstancedMesh
+
+	// indexes of the instances changed since the last PreRender
+	changed map[int]bool
+
+	// whether all of the instances were replaced since then
+	replaced bool
+
+	// meshes combining all of the instances of each of the LODs
+	lodMeshes []*InstancedMesh
+
+	// mu protects the instances from the ticker goroutine
+	mu sync.Mutex
+}
+
+// NewInstancedSolid returns a new solid with the given name drawing
+// the given instances of the given mesh, added to the given parent,
+// which must already be in a scene
+func NewInstancedSolid(parent tree.Node, name string, base xyz.Mesh, insts []InstanceData) *InstancedSolid {
+	is := tree.New[InstancedSolid](parent)
+	is.SetName(name)
+	is.BaseMesh = xyz.MeshName(base.AsMeshBase().Name)
+	is.Instances = insts
+	is.mesh = &InstancedMesh{Base: base, Instances: slices.Clone(insts)}
+	is.mesh.Name = "__Instances:" + name
+	is.Scene.SetMesh(is.mesh)
+	is.SetMesh(is.mesh)
+	return is
+}
+
+// relink gives a clone of the solid its own instances and meshes
+// combining them in the given scene, in place of those of the
+// original, which the clone shares
+func (is *InstancedSolid) relink(sc *xyz.Scene) {
+	im, ok := is.Mesh.(*InstancedMesh)
+	if !ok {
+		return
+	}
+	lods, lodMeshes := is.LODs, is.lodMeshes
+	is.Instances = slices.Clone(is.Instances)
+	is.changed, is.replaced, is.lodMeshes, is.LODs = nil, false, nil, nil
+	is.mesh = &InstancedMesh{Base: im.Base, Instances: slices.Clone(is.Instances)}
+	is.mesh.Name = "__Instances:" + is.Name
+	for i := 2; ; i++ {
+		if _, err := sc.MeshByName(is.mesh.Name); err != nil {
+			break
+		}
+		is.mesh.Name = fmt.Sprintf("__Instances:%s%d", is.Name, i)
+	}
+	sc.SetMesh(is.mesh)
+	is.SetMesh(is.mesh)
+	for _, l := range lods {
+		if i := slices.IndexFunc(lodMeshes, func(lm *InstancedMesh) bool { return xyz.MeshName(lm.Name) == l.Mesh }); i >= 0 {
+			is.AddLOD(l.Distance, lodMeshes[i].Base)
+		} else if ms, err := sc.MeshByName(string(l.Mesh)); err == nil {
+			is.Solid.AddLOD(l.Distance, ms)
+		}
+	}
+}
+
+// SetInstances replaces all of the instances
+func (is *InstancedSolid) SetInstances(insts []InstanceData) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	is.Instances = insts
+	is.replaced = true
+}
+
+// SetInstance updates the instance at the given index
+func (is *InstancedSolid) SetInstance(i int, d InstanceData) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	is.Instances[i] = d
+	if is.changed == nil {
+		is.changed = map[int]bool{}
+	}
+	is.changed[i] = true
+}
+
+// PreRender uploads the changes to the instances of the mesh
+// for the current level of detail
+func (is *InstancedSolid) PreRender() {
+	is.Solid.PreRender()
+	is.updateInstances()
+}
+
+// updateInstances poses the changed instances in the meshes
+// and uploads the mesh for the current level of detail
+func (is *InstancedSolid) updateInstances() {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	if is.mesh == nil {
+		return
+	}
+	if is.replaced || len(is.changed) > 0 {
+		for _, im := range append([]*InstancedMesh{is.mesh}, is.lodMeshes...) {
+			im.setInstances(is.Instances, is.changed, is.replaced)
+		}
+		clear(is.changed)
+		is.replaced = false
+	}
+	im := is.mesh
+	for _, l := range is.lodMeshes {
+		if xyz.MeshName(l.Name) == is.lod {
+			im = l
+		}
+	}
+	im.upload(is.Scene)
+}
+
+// InstancedMesh is a copy of the base mesh for each instance,
+// posed and colored by the instance
+type InstancedMesh struct {
+	xyz.MeshBase
+
+	// Base mesh copied for each instance
+	Base xyz.Mesh
+
+	// Instances to draw
+	Instances []InstanceData
+
+	// indexes of the instances changed since the mesh was last uploaded,
+	// which is only done when it is drawn
+	dirty map[int]bool
+
+	// whether the number of instances changed since then, so that
+	// the whole mesh must be uploaded again
+	resized bool
+
+	// data of the base mesh, and the vertex values last set,
+	// which are posed again in place for the changed instances
+	base                   *shape.MeshData
+	vertex, normal, colors math32.ArrayF32
+}
+
+// setInstances sets the instances to the given ones, of which those
+// at the given indexes, or all of them if replaced, changed
+func (im *InstancedMesh) setInstances(insts []InstanceData, changed map[int]bool, replaced bool) {
+	if len(insts) != len(im.Instances) {
+		im.resized = true
+	}
+	im.Instances = slices.Clone(insts)
+	if im.dirty == nil {
+		im.dirty = map[int]bool{}
+	}
+	if replaced {
+		for i := range insts {
+			im.dirty[i] = true
+		}
+		return
+	}
+	for i := range changed {
+		im.dirty[i] = true
+	}
+}
+
+// upload uploads the mesh to the given scene if its instances changed:
+// all of it if their number changed or it has not been set yet, and
+// otherwise only the vertex values of the changed instances
+func (im *InstancedMesh) upload(sc *xyz.Scene) {
+	switch {
+	case im.resized || im.vertex == nil:
+		sc.SetMesh(im)
+	case len(im.dirty) > 0:
+		for i := range im.dirty {
+			im.setInstance(i, im.vertex, im.normal, im.colors)
+		}
+		im.setBBox()
+		if sc.IsLive() {
+			im.uploadVertexes(sc)
+		}
+	default:
+		return
+	}
+	im.resized = false
+	clear(im.dirty)
+}
+
+// uploadVertexes uploads the positions, normals and colors of the
+// vertexes of the mesh, already set in the phong renderer, whose
+// meshes are in the same order as those of the scene
+func (im *InstancedMesh) uploadVertexes(sc *xyz.Scene) {
+	idx, ok := sc.Meshes.IndexByKeyTry(im.Name)
+	if !ok {
+		return
+	}
+	ph := sc.Phong
+	ph.Lock()
+	defer ph.Unlock()
+	vgp := ph.System.Vars().VertexGroup()
+	gpu.SetValueFrom(errors.Log1(vgp.ValueByIndex("Pos", idx)), im.vertex)
+	gpu.SetValueFrom(errors.Log1(vgp.ValueByIndex("Normal", idx)), im.normal)
+	gpu.SetValueFrom(errors.Log1(vgp.ValueByIndex("VertexColor", idx)), im.colors)
+}
+
+func (im *InstancedMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	nv, ni, _ := im.Base.MeshSize()
+	im.NumVertex = nv * len(im.Instances)
+	im.NumIndex = ni * len(im.Instances)
+	im.HasColor = true
+	return im.NumVertex, im.NumIndex, im.HasColor
+}
+
+func (im *InstancedMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	im.base = shape.NewMeshData(im.Base)
+	md := im.base
+	nv, ni := md.NumVertex, md.NumIndex
+	for i := range im.Instances {
+		im.setInstance(i, vertex, normal, clrs)
+		vo := i * nv
+		for v := range nv {
+			texcoord.Set(2*(vo+v), md.TexCoord[2*v], md.TexCoord[2*v+1])
+		}
+		for x := range ni {
+			index[i*ni+x] = md.Index[x] + uint32(vo)
+		}
+	}
+	im.vertex, im.normal, im.colors = vertex, normal, clrs
+	im.setBBox()
+}
+
+// setInstance sets the vertex values of the instance at the given index
+// to the base mesh posed and colored by it
+func (im *InstancedMesh) setInstance(i int, vertex, normal, clrs math32.ArrayF32) {
+	md := im.base
+	d := im.Instances[i]
+	if d.Rot.IsNil() {
+		d.Rot.SetIdentity()
+	}
+	if d.Scale == (math32.Vector3{}) {
+		d.Scale.Set(1, 1, 1)
+	}
+	var mtx math32.Matrix4
+	mtx.SetTransform(d.Pos, d.Rot, d.Scale)
+	clr := math32.NewVector4Color(d.Color)
+	vo := i * md.NumVertex
+	for v := range md.NumVertex {
+		var p, n math32.Vector3
+		md.Vertex.GetVector3(3*v, &p)
+		md.Normal.GetVector3(3*v, &n)
+		vertex.SetVector3(3*(vo+v), p.MulMatrix4(&mtx))
+		normal.SetVector3(3*(vo+v), n.MulQuat(d.Rot))
+		clrs.SetVector4(4*(vo+v), clr)
+	}
+}
+
+// setBBox sets the bounding box of the mesh from its vertexes
+func (im *InstancedMesh) setBBox() {
+	bb := shape.BBoxFromVtxs(im.vertex, 0, im.NumVertex)
+	im.BBox.SetBounds(bb.Min, bb.Max)
+}
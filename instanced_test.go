@@ -0,0 +1,63 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+func TestInstancedSetInstance(t *testing.T) {
+	sc := newTestScene()
+	box := xyz.NewBox(sc, "box", 1, 1, 1)
+	insts := []InstanceData{
+		NewInstance(math32.Vec3(-2, 0, 0), colors.Red),
+		NewInstance(math32.Vec3(0, 0, 0), colors.Green),
+		NewInstance(math32.Vec3(2, 0, 0), colors.Blue),
+	}
+	is := NewInstancedSolid(sc, "boxes", box, insts)
+	md := shape.NewMeshData(is.mesh) // sets the mesh, as uploading it does
+	index := slices.Clone(md.Index)
+	nv := md.NumVertex / 3
+
+	is.SetInstance(1, NewInstance(math32.Vec3(0, 3, 0), colors.Yellow))
+	is.updateInstances()
+	// the vertexes of the moved instance are posed again in place
+	var p math32.Vector3
+	for v := range nv {
+		md.Vertex.GetVector3(3*(nv+v), &p)
+		if p.Y < 2.5 || p.Y > 3.5 {
+			t.Fatalf("vertex %d of the moved instance is at %v", v, p)
+		}
+	}
+	if c := math32.NewVector4Color(colors.Yellow); md.Colors[4*nv] != c.X || md.Colors[4*nv+1] != c.Y {
+		t.Errorf("the moved instance has the color %v", md.Colors[4*nv:4*nv+4])
+	}
+	md.Vertex.GetVector3(0, &p)
+	if p.X > -1.5 {
+		t.Errorf("the first instance moved to %v", p)
+	}
+	if !slices.Equal(md.Index, index) {
+		t.Error("the indexes changed with the pose of an instance")
+	}
+	if bb := is.mesh.BBox.BBox; bb.Max.Y < 3.5-1e-5 {
+		t.Errorf("the bounding box %v does not hold the moved instance", bb)
+	}
+	if len(is.changed) != 0 || len(is.mesh.dirty) != 0 {
+		t.Error("the changed instances are still marked after updating them")
+	}
+
+	// fewer instances sets the whole mesh again
+	is.SetInstances(insts[:2])
+	is.updateInstances()
+	if n, _, _ := is.mesh.MeshSize(); n != 2*nv {
+		t.Errorf("the mesh of 2 instances has %d vertexes instead of %d", n, 2*nv)
+	}
+}
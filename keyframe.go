@@ -0,0 +1,179 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sort"
+	"sync"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// Keyframe is the pose of a solid at a given time
+type Keyframe struct {
+	// Time in seconds from the start of the animation
+	Time float32
+
+	// Position
+	Pos math32.Vector3
+
+	// Rotation
+	Rot math32.Quat
+
+	// Scale
+	Scale math32.Vector3
+}
+
+// NewKeyframe returns a keyframe at the given time and position,
+// with no rotation and unit scale
+func NewKeyframe(t float32, pos math32.Vector3) Keyframe {
+	return Keyframe{Time: t, Pos: pos, Rot: math32.NewQuat(0, 0, 0, 1), Scale: math32.Vec3(1, 1, 1)}
+}
+
+// KeyframeFromMatrix returns a keyframe at the given time with
+// the pose of the given transform, as from [DecomposeTRS]
+func KeyframeFromMatrix(t float32, m *math32.Matrix4) Keyframe {
+	pos, rot, scale := DecomposeTRS(m)
+	return Keyframe{Time: t, Pos: pos, Rot: rot, Scale: scale}
+}
+
+// Matrix returns the transform of the pose of the keyframe
+func (k Keyframe) Matrix() math32.Matrix4 {
+	return ComposeTRS(k.Pos, k.Rot, k.Scale)
+}
+
+// KeyframeAnim interpolates the pose of a solid between keyframes.
+// It is driven by the ticker of a SimpleAnim.
+type KeyframeAnim struct {
+	// Solid being animated
+	Solid *xyz.Solid
+
+	// Keyframes, sorted by Time
+	Keyframes []Keyframe
+
+	// Current time in seconds
+	Time float32 `edit:"-"`
+
+	// Whether the animation is playing
+	Playing bool `edit:"-"`
+
+	// mu protects all fields from the ticker goroutine
+	mu sync.Mutex
+}
+
+// NewKeyframeAnim returns a new keyframe animation of the given solid,
+// driven by the given SimpleAnim
+func NewKeyframeAnim(anim *SimpleAnim, sld *xyz.Solid) *KeyframeAnim {
+	ka := &KeyframeAnim{Solid: sld}
+	anim.AddAnimator(ka)
+	return ka
+}
+
+// AddKeyframe adds a keyframe, keeping the keyframes sorted by time
+func (ka *KeyframeAnim) AddKeyframe(k Keyframe) *KeyframeAnim {
+	ka.mu.Lock()
+	defer ka.mu.Unlock()
+	i := sort.Search(len(ka.Keyframes), func(i int) bool {
+		return ka.Keyframes[i].Time > k.Time
+	})
+	ka.Keyframes = append(ka.Keyframes, Keyframe{})
+	copy(ka.Keyframes[i+1:], ka.Keyframes[i:])
+	ka.Keyframes[i] = k
+	return ka
+}
+
+// Duration returns the time of the last keyframe
+func (ka *KeyframeAnim) Duration() float32 {
+	if len(ka.Keyframes) == 0 {
+		return 0
+	}
+	return ka.Keyframes[len(ka.Keyframes)-1].Time
+}
+
+// Play starts playing from the current time,
+// restarting from the beginning if already at the end
+func (ka *KeyframeAnim) Play() {
+	ka.mu.Lock()
+	defer ka.mu.Unlock()
+	if ka.Time >= ka.Duration() {
+		ka.Time = 0
+	}
+	ka.Playing = true
+}
+
+// Stop stops playing, leaving the solid at its current pose
+func (ka *KeyframeAnim) Stop() {
+	ka.mu.Lock()
+	defer ka.mu.Unlock()
+	ka.Playing = false
+}
+
+// Seek jumps to the given time and applies the pose at that time
+func (ka *KeyframeAnim) Seek(t float32) {
+	ka.mu.Lock()
+	defer ka.mu.Unlock()
+	ka.Time = math32.Clamp(t, 0, ka.Duration())
+	ka.apply()
+}
+
+// Step implements [Animator]
+func (ka *KeyframeAnim) Step(dt float32) bool {
+	ka.mu.Lock()
+	defer ka.mu.Unlock()
+	if !ka.Playing {
+		return false
+	}
+	ka.Time += dt
+	if end := ka.Duration(); ka.Time >= end {
+		ka.Time = end
+		ka.Playing = false
+	}
+	ka.apply()
+	return true
+}
+
+// PoseAt returns the interpolated keyframe at the given time
+func (ka *KeyframeAnim) PoseAt(t float32) Keyframe {
+	kfs := ka.Keyframes
+	n := len(kfs)
+	switch {
+	case n == 0:
+		return NewKeyframe(t, math32.Vector3{})
+	case t <= kfs[0].Time:
+		return kfs[0]
+	case t >= kfs[n-1].Time:
+		return kfs[n-1]
+	}
+	i := sort.Search(n, func(i int) bool {
+		return kfs[i].Time > t
+	})
+	k0, k1 := kfs[i-1], kfs[i]
+	f := (t - k0.Time) / (k1.Time - k0.Time)
+	k := Keyframe{Time: t}
+	k.Pos = k0.Pos.Lerp(k1.Pos, f)
+	k.Scale = k0.Scale.Lerp(k1.Scale, f)
+	k.Rot = Slerp(k0.Rot, k1.Rot, f)
+	return k
+}
+
+// Slerp returns the spherical linear interpolation from the given
+// rotation to the other at t from 0 to 1, turning the shortest way.
+// It is [math32.Quat.Slerp] without changing the first one in place.
+func Slerp(from, to math32.Quat, t float32) math32.Quat {
+	from.Slerp(to, t)
+	return from
+}
+
+// apply sets the pose of the solid for the current time
+func (ka *KeyframeAnim) apply() {
+	if ka.Solid == nil || len(ka.Keyframes) == 0 {
+		return
+	}
+	k := ka.PoseAt(ka.Time)
+	ka.Solid.SetPosePos(k.Pos)
+	ka.Solid.SetPoseQuat(k.Rot)
+	ka.Solid.SetPoseScale(k.Scale)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// hiddenLayersProperty is the scene property holding the
+// set of layers that are hidden
+const hiddenLayersProperty = "hiddenLayers"
+
+// DefaultLayerName is the name shown for the layer of
+// the solids with no Layer
+const DefaultLayerName = "default"
+
+// SceneLayers returns the layers of the demo solids of the given
+// scene, sorted by name, including the empty one if any have no
+// Layer. Solids with reserved names are skipped.
+func SceneLayers(sc *xyz.Scene) []string {
+	var layers []string
+	sc.WalkDown(func(k tree.Node) bool {
+		if isReservedNode(k) {
+			return tree.Break
+		}
+		if s, ok := k.(interface{ asSolid() *Solid }); ok {
+			if l := s.asSolid().Layer; !slices.Contains(layers, l) {
+				layers = append(layers, l)
+			}
+		}
+		return tree.Continue
+	})
+	slices.Sort(layers)
+	return layers
+}
+
+// SetLayerVisible shows or hides the solids of the given
+// layer of the given scene
+func SetLayerVisible(sc *xyz.Scene, layer string, visible bool) {
+	hidden := sceneHiddenLayers(sc)
+	if visible {
+		delete(hidden, layer)
+	} else {
+		hidden[layer] = true
+	}
+	setHiddenLayers(sc, hidden)
+}
+
+// LayerVisible returns whether the solids of the given
+// layer of the given scene are shown
+func LayerVisible(sc *xyz.Scene, layer string) bool {
+	if sc == nil {
+		return true
+	}
+	hidden, _ := sc.Property(hiddenLayersProperty).(map[string]bool)
+	return !hidden[layer]
+}
+
+// IsolateLayer shows the given layer of the given scene,
+// and hides all of its other layers
+func IsolateLayer(sc *xyz.Scene, layer string) {
+	hidden := map[string]bool{}
+	for _, l := range SceneLayers(sc) {
+		if l != layer {
+			hidden[l] = true
+		}
+	}
+	setHiddenLayers(sc, hidden)
+}
+
+// HiddenLayers returns the hidden layers of the given scene, sorted by name
+func HiddenLayers(sc *xyz.Scene) []string {
+	var layers []string
+	for l := range sceneHiddenLayers(sc) {
+		layers = append(layers, l)
+	}
+	slices.Sort(layers)
+	return layers
+}
+
+// SetHiddenLayers hides the given layers of the given
+// scene, and shows all of its other layers
+func SetHiddenLayers(sc *xyz.Scene, layers []string) {
+	hidden := map[string]bool{}
+	for _, l := range layers {
+		hidden[l] = true
+	}
+	setHiddenLayers(sc, hidden)
+}
+
+// setHiddenLayers sets the set of hidden layers of the given scene.
+// If it has been baked with [BakeStaticBatch], it is baked again, as its
+// batches only draw the solids that were visible when they were baked.
+func setHiddenLayers(sc *xyz.Scene, hidden map[string]bool) {
+	sc.SetProperty(hiddenLayersProperty, hidden)
+	if _, baked := sc.Property(staticBatchesProperty).(map[staticKey]*StaticBatch); baked {
+		BakeStaticBatch(sc)
+	}
+	sc.SetNeedsUpdate()
+}
+
+// sceneHiddenLayers returns the set of hidden layers of the given scene,
+// which is a new one if there is none yet
+func sceneHiddenLayers(sc *xyz.Scene) map[string]bool {
+	hidden, _ := sc.Property(hiddenLayersProperty).(map[string]bool)
+	if hidden == nil {
+		hidden = map[string]bool{}
+	}
+	return hidden
+}
+
+// layerName returns the name shown for the given layer
+func layerName(layer string) string {
+	if layer == "" {
+		return DefaultLayerName
+	}
+	return layer
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/xyz"
+)
+
+// newTestLayers returns a new scene with a solid in each of the
+// given layers, named for them
+func newTestLayers(layers ...string) *xyz.Scene {
+	sc := newTestScene()
+	box := xyz.NewBox(sc, "box", 1, 1, 1)
+	for i, l := range layers {
+		sld := NewSolid(sc)
+		sld.SetName("in-" + layerName(l))
+		sld.SetMesh(box)
+		sld.Layer = l
+		sld.Pose.Pos.Set(float32(2*i), 0, 0)
+	}
+	updateTestScene(sc)
+	return sc
+}
+
+func TestLayersVisible(t *testing.T) {
+	sc := newTestLayers("walls", "", "props")
+	if got, want := SceneLayers(sc), []string{"", "props", "walls"}; !slices.Equal(got, want) {
+		t.Errorf("the layers are %v, not %v", got, want)
+	}
+	SetLayerVisible(sc, "walls", false)
+	walls := sc.ChildByName("in-walls", 0).(*Solid)
+	if LayerVisible(sc, "walls") || !isHidden(walls) {
+		t.Error("the walls layer is shown after hiding it")
+	}
+	if isHidden(sc.ChildByName("in-props", 0).(*Solid)) {
+		t.Error("hiding the walls hid the props")
+	}
+
+	IsolateLayer(sc, "walls")
+	if got, want := HiddenLayers(sc), []string{"", "props"}; !slices.Equal(got, want) {
+		t.Errorf("isolating the walls hid %v, not %v", got, want)
+	}
+	if isHidden(walls) {
+		t.Error("the isolated walls layer is hidden")
+	}
+	SetHiddenLayers(sc, nil)
+	if len(HiddenLayers(sc)) != 0 {
+		t.Errorf("the layers %v are still hidden after showing all", HiddenLayers(sc))
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// lightIndex returns the index of the given light among the lights
+// of the same type in the given scene, which is its index in the
+// phong renderer
+func lightIndex[T xyz.Light](sc *xyz.Scene, lt T) int {
+	idx := 0
+	for _, kv := range sc.Lights.Order {
+		if kv.Value == xyz.Light(lt) {
+			break
+		}
+		if _, ok := kv.Value.(T); ok {
+			idx++
+		}
+	}
+	return idx
+}
+
+// numLights returns the number of lights of the given type in the given scene
+func numLights[T xyz.Light](sc *xyz.Scene) int {
+	n := 0
+	for _, kv := range sc.Lights.Order {
+		if _, ok := kv.Value.(T); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// lightColor returns the color of the given light as used by the phong renderer
+func lightColor(lt xyz.Light) math32.Vector3 {
+	lb := lt.AsLightBase()
+	return math32.NewVector3Color(lb.Color).MulScalar(lb.Lumens).SRGBToLinear()
+}
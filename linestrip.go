@@ -0,0 +1,475 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/color"
+	"sync"
+	"time"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// lineCapSegments is the number of segments in the round
+// cap at each end of an open [LineStrip]
+const lineCapSegments = 8
+
+// LineStrip is a line through points, drawn as a flat strip facing +Z
+// like [xyz.Lines], but joined at the points with miters instead of
+// separate boxes, so there are no gaps at the bends, and with round
+// caps at the ends of open lines. The GPU renderer has no geometry
+// shaders, so the strip is made when the mesh is set on the scene;
+// set it again with [xyz.Scene.SetMesh] after changing the fields.
+// DrawProgress draws only the first part of the line, for animating
+// it drawing itself with [SimpleAnim.AnimateDraw], and DashPattern
+// draws it dashed, as [xyz.Lines] can not be.
+type LineStrip struct {
+	xyz.MeshBase
+
+	// Points of the line (must be 2 or more)
+	Points []math32.Vector3
+
+	// Width of the line
+	Width float32
+
+	// Closed connects the last point back to the first
+	Closed bool
+
+	// MiterLimit is the longest that a miter can be, as a multiple of
+	// half of the Width. Miters at sharp bends are cut to it, so they
+	// do not spike out.
+	MiterLimit float32
+
+	// DrawProgress is the fraction of the length of the line that is
+	// drawn, from the start, with a round cap at the end. Closed lines
+	// are open until it is 1.
+	DrawProgress float32 `min:"0" max:"1" step:"0.05"`
+
+	// Colors are the colors of the line at each of the points, which
+	// are blended between them, if there is one for each point.
+	// Otherwise the line has the color of its solid.
+	Colors []color.RGBA
+
+	// DashPattern has the lengths of the dashes and the gaps between
+	// them, in turn, for dashed lines, which are solid if it is empty.
+	// Each dash has round caps, so dashes of length 0 are dots. As in
+	// SVG, a pattern of odd length is repeated to make it even.
+	DashPattern []float32
+
+	// DashOffset is how far into the DashPattern the line starts,
+	// for marching the dashes along it
+	DashOffset float32
+
+	// runs are the separate strips drawn, one for each dash
+	// of a dashed line, made by MeshSize
+	runs []lineRun
+}
+
+// lineRun is a strip of a [LineStrip], through points with colors,
+// if any. Runs of one dot have the same point twice, and the
+// direction of the line there.
+type lineRun struct {
+	points []math32.Vector3
+	colors []math32.Vector4
+	closed bool
+	dir    math32.Vector3
+}
+
+// NewLineStrip returns a new line strip mesh through the given points
+// with the given width, all drawn, set on the given scene under the given name
+func NewLineStrip(sc *xyz.Scene, name string, points []math32.Vector3, width float32, closed bool) *LineStrip {
+	ls := &LineStrip{Points: points, Width: width, Closed: closed, MiterLimit: 4, DrawProgress: 1}
+	ls.Name = name
+	sc.SetMesh(ls)
+	return ls
+}
+
+// segments returns the number of segments of the run
+func (lr *lineRun) segments() int {
+	if lr.closed {
+		return len(lr.points)
+	}
+	return len(lr.points) - 1
+}
+
+// drawn returns the part of the line that is drawn, cut off
+// at DrawProgress along it, with the colors of its points
+func (ls *LineStrip) drawn() lineRun {
+	pts := ls.Points
+	var clrs []math32.Vector4
+	if len(ls.Colors) == len(pts) && len(pts) > 0 {
+		clrs = make([]math32.Vector4, len(pts))
+		for i, c := range ls.Colors {
+			clrs[i] = math32.NewVector4Color(c)
+		}
+	}
+	if len(pts) < 2 {
+		return lineRun{}
+	}
+	lr := lineRun{points: pts, colors: clrs, closed: ls.Closed, dir: pts[1].Sub(pts[0]).Normal()}
+	if ls.DrawProgress >= 1 {
+		return lr
+	}
+	lr.closed = false
+	if ls.Closed {
+		pts = append(pts[:len(pts):len(pts)], pts[0])
+		if clrs != nil {
+			clrs = append(clrs, clrs[0])
+		}
+	}
+	total := float32(0)
+	for i := 1; i < len(pts); i++ {
+		total += pts[i].Sub(pts[i-1]).Length()
+	}
+	left := max(ls.DrawProgress, 0) * total
+	n := 1 // the number of whole points drawn
+	var cut float32
+	for ; n < len(pts) && left > 0; n++ {
+		ln := pts[n].Sub(pts[n-1]).Length()
+		if ln >= left {
+			cut = left / ln
+			break
+		}
+		left -= ln
+	}
+	lr.points = append([]math32.Vector3(nil), pts[:n]...)
+	if clrs != nil {
+		lr.colors = append([]math32.Vector4(nil), clrs[:n]...)
+	}
+	if cut > 0 {
+		lr.points = append(lr.points, pts[n-1].Lerp(pts[n], cut))
+		if clrs != nil {
+			lr.colors = append(lr.colors, clrs[n-1].Lerp(clrs[n], cut))
+		}
+	}
+	if len(lr.points) < 2 {
+		return lineRun{}
+	}
+	return lr
+}
+
+// dashes returns the dashes of the given drawn part of the line
+// for its DashPattern, or just the drawn part if it has none
+func (ls *LineStrip) dashes(lr lineRun) []lineRun {
+	pat := ls.DashPattern
+	if len(pat)%2 == 1 {
+		pat = append(pat[:len(pat):len(pat)], pat...)
+	}
+	period := float32(0)
+	for _, d := range pat {
+		period += max(d, 0)
+	}
+	if period <= 0 || len(lr.points) < 2 {
+		if len(lr.points) < 2 {
+			return nil
+		}
+		return []lineRun{lr}
+	}
+	pts, clrs := lr.points, lr.colors
+	if lr.closed {
+		pts = append(pts[:len(pts):len(pts)], pts[0])
+		if clrs != nil {
+			clrs = append(clrs[:len(clrs):len(clrs)], clrs[0])
+		}
+	}
+	// find the part of the pattern at the start of the line
+	entry, left := 0, math32.Mod(ls.DashOffset, period)
+	if left < 0 {
+		left += period
+	}
+	for left > 0 && left >= max(pat[entry], 0) {
+		left -= max(pat[entry], 0)
+		entry = (entry + 1) % len(pat)
+	}
+	left = max(pat[entry], 0) - left // the rest of the entry
+
+	var runs []lineRun
+	var cur *lineRun
+	start := func(p math32.Vector3, c math32.Vector4, dir math32.Vector3) {
+		runs = append(runs, lineRun{points: []math32.Vector3{p}, dir: dir})
+		cur = &runs[len(runs)-1]
+		if clrs != nil {
+			cur.colors = []math32.Vector4{c}
+		}
+	}
+	add := func(p math32.Vector3, c math32.Vector4) {
+		cur.points = append(cur.points, p)
+		if clrs != nil {
+			cur.colors = append(cur.colors, c)
+		}
+	}
+	var noColor math32.Vector4
+	colorAt := func(i int, t float32) math32.Vector4 {
+		if clrs == nil {
+			return noColor
+		}
+		return clrs[i].Lerp(clrs[i+1], t)
+	}
+	if entry%2 == 0 {
+		start(pts[0], colorAt(0, 0), pts[1].Sub(pts[0]).Normal())
+	}
+	for i := 0; i+1 < len(pts); i++ {
+		d := pts[i+1].Sub(pts[i])
+		ln := d.Length()
+		if ln == 0 {
+			continue
+		}
+		dir := d.DivScalar(ln)
+		at := float32(0)
+		for {
+			step := min(left, ln-at)
+			at += step
+			left -= step
+			if left > 0 {
+				break // to the next segment
+			}
+			t := at / ln
+			p, c := pts[i].Add(d.MulScalar(t)), colorAt(i, t)
+			if entry%2 == 0 { // the end of a dash
+				add(p, c)
+				if len(cur.points) == 1 {
+					add(p, c) // a dot
+				}
+				cur = nil
+			}
+			entry = (entry + 1) % len(pat)
+			left = max(pat[entry], 0)
+			if entry%2 == 0 {
+				start(p, c, dir)
+			}
+		}
+		if cur != nil && at == ln && i+2 < len(pts) {
+			add(pts[i+1], colorAt(i, 1)) // a bend within a dash
+		}
+	}
+	if cur != nil {
+		add(pts[len(pts)-1], colorAt(len(pts)-2, 1))
+	}
+	// drop the points repeated where a dash ended at a point
+	for ri := range runs {
+		rn := &runs[ri]
+		for k := len(rn.points) - 1; k > 1; k-- {
+			if rn.points[k] == rn.points[k-1] {
+				rn.points = append(rn.points[:k], rn.points[k+1:]...)
+				if rn.colors != nil {
+					rn.colors = append(rn.colors[:k], rn.colors[k+1:]...)
+				}
+			}
+		}
+	}
+	return runs
+}
+
+func (ls *LineStrip) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	ls.runs = ls.dashes(ls.drawn())
+	ls.NumVertex, ls.NumIndex = 0, 0
+	ls.HasColor, ls.Transparent = false, false
+	for _, lr := range ls.runs {
+		nseg := lr.segments()
+		ls.NumVertex += 2 * (nseg + 1) // the first pair is repeated at the end of closed runs
+		ls.NumIndex += 6 * nseg
+		if !lr.closed {
+			ls.NumVertex += 2 * (lineCapSegments + 2)
+			ls.NumIndex += 2 * 3 * lineCapSegments
+		}
+		if lr.colors != nil {
+			ls.HasColor = true
+		}
+		for _, c := range lr.colors {
+			if c.W < 1 {
+				ls.Transparent = true
+			}
+		}
+	}
+	if ls.NumVertex == 0 {
+		// one empty triangle, as meshes can not be empty
+		ls.NumVertex, ls.NumIndex = 3, 3
+	}
+	return ls.NumVertex, ls.NumIndex, ls.HasColor
+}
+
+func (ls *LineStrip) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	face := math32.Vec3(0, 0, 1)
+	if len(ls.runs) == 0 {
+		for i := range 3 {
+			normal.SetVector3(3*i, face)
+			index[i] = uint32(i)
+		}
+		return
+	}
+	total := float32(0)
+	for _, lr := range ls.runs {
+		for i := range lr.segments() {
+			total += lr.points[(i+1)%len(lr.points)].Sub(lr.points[i]).Length()
+		}
+	}
+	vi, ii := 0, 0
+	dist := float32(0)
+	for _, lr := range ls.runs {
+		vi, ii, dist = ls.setRun(&lr, vertex, normal, texcoord, clrs, index, vi, ii, dist, total)
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, ls.NumVertex)
+	ls.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// setRun sets the vertices of the given run from the given vertex and
+// index offsets, returning the offsets after it. Texture U is the given
+// distance along all of the runs, out of the given total, at its start.
+func (ls *LineStrip) setRun(lr *lineRun, vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32, vi, ii int, dist, total float32) (int, int, float32) {
+	face := math32.Vec3(0, 0, 1)
+	nseg := lr.segments()
+	np := len(lr.points)
+	pt := func(i int) math32.Vector3 { return lr.points[(i%np+np)%np] }
+	// setColor sets the color of the given vertex to that of the given point
+	setColor := func(v, i int) {
+		if lr.colors != nil {
+			clrs.SetVector4(4*v, lr.colors[(i%np+np)%np])
+		} else if ls.HasColor {
+			clrs.Set(4*v, 1, 1, 1, 1)
+		}
+	}
+	// perp returns the unit vector to the left of the segment
+	// from the point with the given index to the next one
+	perp := func(i int) math32.Vector3 {
+		d := pt(i + 1).Sub(pt(i))
+		if d.Length() == 0 {
+			d = lr.dir
+		}
+		return face.Cross(d).Normal()
+	}
+	hw := ls.Width / 2
+	limit := ls.MiterLimit
+	if limit <= 0 {
+		limit = 4
+	}
+	base := vi
+	for i := range nseg + 1 {
+		// the offset to the left of the point, along the
+		// miter between the segments on either side of it
+		var off math32.Vector3
+		switch {
+		case !lr.closed && i == 0:
+			off = perp(0).MulScalar(hw)
+		case !lr.closed && i == nseg:
+			off = perp(nseg - 1).MulScalar(hw)
+		default:
+			p0, p1 := perp(i-1), perp(i)
+			miter := p0.Add(p1)
+			if miter.Length() < 1e-6 { // the line turns back
+				off = p0.MulScalar(hw)
+				break
+			}
+			miter = miter.Normal()
+			ln := limit * hw
+			if d := miter.Dot(p1); d > hw/ln {
+				ln = hw / d
+			}
+			off = miter.MulScalar(ln)
+		}
+		if i > 0 {
+			dist += pt(i).Sub(pt(i - 1)).Length()
+		}
+		u := float32(0)
+		if total > 0 {
+			u = dist / total
+		}
+		p := pt(i)
+		vertex.SetVector3(3*vi, p.Add(off))
+		vertex.SetVector3(3*vi+3, p.Sub(off))
+		normal.SetVector3(3*vi, face)
+		normal.SetVector3(3*vi+3, face)
+		texcoord.Set(2*vi, u, 0, u, 1)
+		setColor(vi, i)
+		setColor(vi+1, i)
+		vi += 2
+	}
+	for i := range nseg {
+		l0, r0 := uint32(base+2*i), uint32(base+2*i+1)
+		l1, r1 := l0+2, r0+2
+		index.Set(ii, r0, r1, l1, r0, l1, l0)
+		ii += 6
+	}
+	if lr.closed {
+		return vi, ii, dist
+	}
+	// caps turn clockwise around +Z from one side of the line to the other
+	caps := []struct {
+		ctr, start math32.Vector3
+		point      int
+	}{
+		{pt(0), perp(0).MulScalar(-hw), 0},
+		{pt(nseg), perp(nseg - 1).MulScalar(hw), nseg},
+	}
+	for _, cp := range caps {
+		ci := uint32(vi)
+		vertex.SetVector3(3*vi, cp.ctr)
+		normal.SetVector3(3*vi, face)
+		texcoord.Set(2*vi, 0.5, 0.5)
+		setColor(vi, cp.point)
+		vi++
+		side := face.Cross(cp.start)
+		for k := range lineCapSegments + 1 {
+			a := -math32.Pi * float32(k) / lineCapSegments
+			rim := cp.start.MulScalar(math32.Cos(a)).Add(side.MulScalar(math32.Sin(a)))
+			vertex.SetVector3(3*vi, cp.ctr.Add(rim))
+			normal.SetVector3(3*vi, face)
+			texcoord.Set(2*vi, 0.5+rim.X/ls.Width, 0.5-rim.Y/ls.Width)
+			setColor(vi, cp.point)
+			if k > 0 {
+				index.Set(ii, ci, uint32(vi), uint32(vi-1))
+				ii += 3
+			}
+			vi++
+		}
+	}
+	return vi, ii, dist
+}
+
+// LineDrawAnim animates a [LineStrip] drawing itself from its start
+// to its end, by its DrawProgress. It is driven by the ticker of a
+// SimpleAnim.
+type LineDrawAnim struct {
+	// Animation driving the drawing, whose scene has the line
+	Anim *SimpleAnim
+
+	// Line being drawn
+	Line *LineStrip
+
+	// Duration of drawing the whole line
+	Duration time.Duration
+
+	// Time in seconds since drawing started
+	Time float32 `edit:"-"`
+
+	// mu protects all fields from the ticker goroutine
+	mu sync.Mutex
+}
+
+// AnimateDraw starts drawing the given line strip from its
+// start to its end over the given duration
+func (a *SimpleAnim) AnimateDraw(ls *LineStrip, duration time.Duration) *LineDrawAnim {
+	ld := &LineDrawAnim{Anim: a, Line: ls, Duration: duration}
+	ls.DrawProgress = 0
+	a.AddAnimator(ld)
+	return ld
+}
+
+// Step implements [Animator]
+func (ld *LineDrawAnim) Step(dt float32) bool {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	if ld.Line == nil || ld.Line.DrawProgress >= 1 || ld.Anim.SceneEditor == nil {
+		return false
+	}
+	ld.Time += dt
+	ld.Line.DrawProgress = 1
+	if d := float32(ld.Duration.Seconds()); d > 0 {
+		ld.Line.DrawProgress = min(ld.Time/d, 1)
+	}
+	ld.Anim.SceneEditor.SceneXYZ().SetMesh(ld.Line) // remakes the strip
+	return true
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// LockHatchName is the name of the texture of the
+// crosshatch drawn over Locked solids
+const LockHatchName = "__LockHatch"
+
+// LockHatchColor is the color of the crosshatch drawn over
+// Locked solids, which is the yellow of the selection box
+// of the scene widget by default
+var LockHatchColor = colors.Yellow
+
+// LockHatchSpacing is the distance between the lines of the crosshatch
+// drawn over Locked solids, in the units of their meshes
+var LockHatchSpacing float32 = 0.1
+
+// isLocked returns whether the given node, or one of its
+// parents, is a Locked demo [Solid]
+func isLocked(nd tree.Node) bool {
+	for k := nd; k != nil; k = k.AsTree().Parent {
+		if s, ok := k.(interface{ asSolid() *Solid }); ok && s.asSolid().Locked {
+			return true
+		}
+	}
+	return false
+}
+
+// preRenderLockHatch uploads the crosshatch over the solid,
+// making its mesh and texture if they are not there yet
+func (sld *Solid) preRenderLockHatch() {
+	sc := sld.Scene
+	hn := lockHatchMeshName(sld.MeshName)
+	if _, err := sc.MeshByName(hn); err != nil {
+		NewLockHatchMesh(sc, hn, sld.Mesh)
+	}
+	if tx, err := sc.TextureByName(LockHatchName); err != nil || tx.AsTextureBase().RGBA.RGBAAt(0, 0) != hatchColor() {
+		sc.SetTexture(&xyz.TextureBase{Name: LockHatchName, Transparent: true, RGBA: lockHatchImage(hatchColor())})
+	}
+	clr := phong.NewColors(colors.White, colors.Black, 0, 0, 1)
+	sc.Phong.SetObject(sld.lockHatchPath(), phong.NewObject(&sld.Pose.WorldMatrix, clr))
+}
+
+// renderLockHatch renders the crosshatch uploaded by preRenderLockHatch
+func (sld *Solid) renderLockHatch(rp *wgpu.RenderPassEncoder) {
+	ph := sld.Scene.Phong
+	ph.UseObject(sld.lockHatchPath())
+	ph.UseMesh(lockHatchMeshName(sld.MeshName))
+	ph.UseTexture(LockHatchName)
+	ph.Render(rp)
+}
+
+// lockHatchPath returns the name of the render object for the crosshatch
+func (sld *Solid) lockHatchPath() string {
+	return sld.Path() + "/__lockHatch"
+}
+
+// hatchColor returns the LockHatchColor, drawn
+// on the lines of the crosshatch texture
+func hatchColor() color.RGBA {
+	clr := LockHatchColor
+	clr.A = 255
+	return clr
+}
+
+// lockHatchImage returns a tile of the crosshatch, with lines of the
+// given color along both diagonals, which continue across the tiles
+func lockHatchImage(clr color.RGBA) *image.RGBA {
+	const size = 16
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := range size {
+		for x := range size {
+			d := (x - y + size) % size
+			a := (x + y + 1) % size
+			if d <= 1 || d == size-1 || a <= 1 || a == size-1 {
+				img.SetRGBA(x, y, clr)
+			}
+		}
+	}
+	return img
+}
+
+// LockHatchMesh is a copy of another mesh pushed out a little along its
+// normals, to draw a crosshatch texture over it without being hidden by
+// it. Its texture coordinates are its positions on the plane of the axes
+// most facing each vertex, in units of the LockHatchSpacing, so the
+// crosshatch is about as dense everywhere whatever the texture
+// coordinates of the mesh.
+type LockHatchMesh struct {
+	xyz.MeshBase
+
+	// Source mesh copied
+	Source xyz.Mesh
+}
+
+// NewLockHatchMesh returns the crosshatch mesh over the given mesh,
+// set on the given scene under the given name
+func NewLockHatchMesh(sc *xyz.Scene, name string, src xyz.Mesh) *LockHatchMesh {
+	hm := &LockHatchMesh{Source: src}
+	hm.Name = name
+	sc.SetMesh(hm)
+	return hm
+}
+
+func (hm *LockHatchMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	hm.NumVertex, hm.NumIndex, _ = hm.Source.MeshSize()
+	return hm.NumVertex, hm.NumIndex, false
+}
+
+func (hm *LockHatchMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	md := shape.NewMeshData(hm.Source)
+	sbb := shape.BBoxFromVtxs(md.Vertex, 0, md.NumVertex)
+	push := 0.005 * sbb.Size().Length()
+	for v := range md.NumVertex {
+		var pos, n math32.Vector3
+		md.Vertex.GetVector3(3*v, &pos)
+		md.Normal.GetVector3(3*v, &n)
+		vertex.SetVector3(3*v, pos.Add(n.MulScalar(push)))
+		normal.SetVector3(3*v, n)
+		var uv math32.Vector2
+		switch a := n.Abs(); {
+		case a.X >= a.Y && a.X >= a.Z:
+			uv = math32.Vec2(pos.Z, pos.Y)
+		case a.Y >= a.Z:
+			uv = math32.Vec2(pos.X, pos.Z)
+		default:
+			uv = math32.Vec2(pos.X, pos.Y)
+		}
+		texcoord.SetVector2(2*v, uv.DivScalar(LockHatchSpacing))
+	}
+	copy(index, md.Index)
+	bb := shape.BBoxFromVtxs(vertex, 0, md.NumVertex)
+	hm.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// lockHatchMeshName returns the name of the crosshatch
+// mesh over the given mesh
+func lockHatchMeshName(mesh xyz.MeshName) string {
+	return fmt.Sprintf("%s:%s", LockHatchName, mesh)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"cogentcore.org/core/xyz"
+)
+
+// LOD is a lower level of detail of the mesh of a [Solid],
+// drawn from the given distance from the camera on
+type LOD struct {
+	// Distance from the camera to the center of the solid,
+	// from which the mesh is drawn
+	Distance float32
+
+	// Mesh drawn from the distance on
+	Mesh xyz.MeshName
+}
+
+// AddLOD adds the given mesh to draw when the solid is at least the
+// given distance from the camera, instead of its own mesh or those of
+// the smaller distances. Its own mesh is still used for everything else,
+// such as the wireframe and picking.
+func (sld *Solid) AddLOD(distance float32, mesh xyz.Mesh) *Solid {
+	lod := LOD{Distance: distance, Mesh: xyz.MeshName(mesh.AsMeshBase().Name)}
+	i, _ := slices.BinarySearchFunc(sld.LODs, distance, func(l LOD, d float32) int {
+		switch {
+		case l.Distance < d:
+			return -1
+		case l.Distance > d:
+			return 1
+		}
+		return 0
+	})
+	sld.LODs = slices.Insert(sld.LODs, i, lod)
+	return sld
+}
+
+// selectLOD sets the mesh drawn for the current
+// distance of the solid from the camera
+func (sld *Solid) selectLOD() {
+	sld.lod = ""
+	if sld.ForceHighLOD || len(sld.LODs) == 0 {
+		return
+	}
+	d := sld.WorldBBox.BBox.Center().DistanceTo(sld.Scene.Camera.Pose.Pos)
+	for _, l := range sld.LODs {
+		if d < l.Distance {
+			break
+		}
+		sld.lod = l.Mesh
+	}
+}
+
+// withLOD calls the given function, which renders the solid,
+// with the mesh for its level of detail, if it has one
+func (sld *Solid) withLOD(fun func()) {
+	if sld.lod == "" {
+		fun()
+		return
+	}
+	ms, err := sld.Scene.MeshByName(string(sld.lod))
+	if err != nil {
+		fun()
+		return
+	}
+	mn, m := sld.MeshName, sld.Mesh
+	sld.MeshName, sld.Mesh = sld.lod, ms
+	defer func() { sld.MeshName, sld.Mesh = mn, m }()
+	fun()
+}
+
+// AddLOD adds an instanced copy of the given mesh as a level of
+// detail, like [Solid.AddLOD], for drawing all of the instances
+func (is *InstancedSolid) AddLOD(distance float32, mesh xyz.Mesh) *InstancedSolid {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	im := &InstancedMesh{Base: mesh, Instances: slices.Clone(is.Instances)}
+	im.Name = fmt.Sprintf("%s-lod%d", is.mesh.Name, len(is.lodMeshes)+1)
+	is.Scene.SetMesh(im)
+	is.lodMeshes = append(is.lodMeshes, im)
+	is.Solid.AddLOD(distance, im)
+	return is
+}
@@ -5,15 +5,19 @@
 package main
 
 import (
+	"fmt"
 	"image/color"
 	"log"
+	"sync"
 	"time"
 
 	"cogentcore.org/core/colors"
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
 	"cogentcore.org/core/styles"
 	"cogentcore.org/core/text/text"
+	"cogentcore.org/core/tree"
 	"cogentcore.org/core/xyz"
 	"cogentcore.org/core/xyz/xyzcore"
 
@@ -22,18 +26,60 @@ import (
 
 // SimpleAnim handles animation for our 3D scene
 type SimpleAnim struct {
-	// Whether animation is running
-	On bool
+	// Whether animation is paused (or ramping down to a pause)
+	Paused bool `edit:"-"`
 
-	// Animation speed
+	// Animation speed, as the angle advanced per 1/30 of a second
 	Speed float32 `min:"0.01" step:"0.01"`
 
+	// Speed of path following, in world units per second
+	PathSpeed float32 `min:"0" step:"0.1"`
+
+	// Target frame rate of the ticker; 0 means 60
+	FPS int `min:"1"`
+
+	// Time to ramp up from 0 to full Speed on Resume
+	RampUpDuration time.Duration
+
+	// Time to ramp down from full Speed to 0 on Pause,
+	// before the ticker is stopped
+	RampDownDuration time.Duration
+
 	// Current angle
 	Angle float32 `edit:"-"`
 
+	// How the animation repeats after one full cycle
+	LoopMode LoopModes
+
+	// Called when a LoopOnce animation finishes, on the ticker goroutine
+	OnComplete func() `display:"-"`
+
+	// Easing applied to the normalized time within each cycle;
+	// nil means linear motion
+	EasingFunc func(t float32) float32 `display:"-"`
+
 	// Animation ticker
 	Ticker *time.Ticker `display:"-"`
 
+	// done is closed by Stop to end the Animate goroutine
+	done chan struct{}
+
+	// time of the last processed tick
+	lastTick time.Time
+
+	// current fraction of Speed in effect, from 0 to 1
+	ramp float32
+
+	// whether ramp is decreasing toward a pause
+	rampingDown bool
+
+	// whether Angle is decreasing in LoopPingPong mode
+	reverse bool
+
+	// whether a LoopOnce cycle has finished,
+	// which stops the ticker until Resume
+	completed bool
+
 	// Scene editor reference
 	SceneEditor *xyzcore.SceneEditor
 
@@ -44,18 +90,158 @@ type SimpleAnim struct {
 	// Original positions
 	CubePosOrig   math32.Vector3
 	SpherePosOrig math32.Vector3
+
+	// Additional animations driven by the ticker
+	Animators []Animator `display:"-"`
+
+	// mu protects Paused, Animators, lastTick, ramp, rampingDown,
+	// completed and done
+	mu sync.Mutex
+}
+
+// LoopModes are the ways a SimpleAnim repeats its cycle
+type LoopModes int32
+
+const (
+	// LoopForever keeps going around the circle indefinitely
+	LoopForever LoopModes = iota
+
+	// LoopOnce stops after one full cycle
+	LoopOnce
+
+	// LoopPingPong reverses direction at the end of each cycle
+	LoopPingPong
+)
+
+// Animator is an animation that is advanced on each tick of a SimpleAnim
+type Animator interface {
+	// Step advances the animation by dt seconds, returning true
+	// if anything in the scene changed
+	Step(dt float32) bool
+}
+
+// AnimatorFunc is a function that implements [Animator]
+type AnimatorFunc func(dt float32) bool
+
+// Step implements [Animator] by calling the function
+func (f AnimatorFunc) Step(dt float32) bool {
+	return f(dt)
 }
 
-// Start initializes the animation
+// Start initializes the animation, starting paused unless on is true
 func (a *SimpleAnim) Start(se *xyzcore.SceneEditor, on bool) {
 	a.SceneEditor = se
-	a.On = on
 	a.Speed = 0.05
+	if a.PathSpeed == 0 {
+		a.PathSpeed = 1
+	}
+	if a.FPS <= 0 {
+		a.FPS = 60
+	}
 	a.GetObjects()
-	a.Ticker = time.NewTicker(time.Second / 30) // 30 fps
+	a.done = make(chan struct{})
+	a.lastTick = time.Now()
+	a.Ticker = time.NewTicker(a.interval())
+	if !on {
+		a.Pause()
+	}
+	se.OnClose(func(e events.Event) {
+		a.Stop()
+	})
 	go a.Animate()
 }
 
+// interval returns the time between ticks for the target FPS
+func (a *SimpleAnim) interval() time.Duration {
+	fps := a.FPS
+	if fps <= 0 {
+		fps = 60
+	}
+	return time.Second / time.Duration(fps)
+}
+
+// SetFPS sets the target frame rate, updating the running ticker
+func (a *SimpleAnim) SetFPS(fps int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.FPS = fps
+	if a.Ticker != nil && !a.Paused && !a.completed {
+		a.Ticker.Reset(a.interval())
+	}
+}
+
+// Pause stops the ticker so the animation goroutine sleeps until Resume,
+// first ramping down over RampDownDuration if set
+func (a *SimpleAnim) Pause() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Paused {
+		return
+	}
+	a.Paused = true
+	if a.RampDownDuration > 0 && a.ramp > 0 && !a.completed {
+		a.rampingDown = true
+		return
+	}
+	a.ramp = 0
+	a.Ticker.Stop()
+}
+
+// Resume restarts the ticker after Pause, or after a LoopOnce
+// cycle has finished, starting it again, ramping up over
+// RampUpDuration if set
+func (a *SimpleAnim) Resume() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.Paused && !a.completed {
+		return
+	}
+	a.Paused = false
+	if a.completed {
+		a.completed = false
+		a.Angle = 0
+	}
+	if !a.rampingDown {
+		a.lastTick = time.Now()
+	}
+	a.rampingDown = false
+	a.Ticker.Reset(a.interval())
+}
+
+// updateRamp advances the speed ramp by dt seconds,
+// stopping the ticker when a ramp down finishes
+func (a *SimpleAnim) updateRamp(dt float32) {
+	if a.rampingDown {
+		if a.RampDownDuration > 0 {
+			a.ramp -= dt / float32(a.RampDownDuration.Seconds())
+		}
+		if a.ramp <= 0 || a.RampDownDuration <= 0 {
+			a.ramp = 0
+			a.rampingDown = false
+			a.Ticker.Stop()
+		}
+		return
+	}
+	if a.RampUpDuration > 0 {
+		a.ramp = min(a.ramp+dt/float32(a.RampUpDuration.Seconds()), 1)
+	} else {
+		a.ramp = 1
+	}
+}
+
+// Stop stops the ticker and terminates the animation goroutine.
+// The animation cannot be resumed after Stop.
+func (a *SimpleAnim) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.done == nil {
+		return
+	}
+	a.Ticker.Stop()
+	close(a.done)
+	a.done = nil
+}
+
 // GetObjects finds the objects to animate
 func (a *SimpleAnim) GetObjects() {
 	sc := a.SceneEditor.SceneXYZ()
@@ -65,7 +251,7 @@ func (a *SimpleAnim) GetObjects() {
 		log.Println("Couldn't find cube to animate")
 		return
 	}
-	a.Cube = cubeObj.(*xyz.Solid)
+	a.Cube = cubeObj.(xyz.Node).AsSolid()
 	a.CubePosOrig = a.Cube.Pose.Pos
 
 	sphereObj := sc.ChildByName("animated-sphere", 0)
@@ -73,50 +259,169 @@ func (a *SimpleAnim) GetObjects() {
 		log.Println("Couldn't find sphere to animate")
 		return
 	}
-	a.Sphere = sphereObj.(*xyz.Solid)
+	a.Sphere = sphereObj.(xyz.Node).AsSolid()
 	a.SpherePosOrig = a.Sphere.Pose.Pos
 }
 
-// Animate runs the animation loop
+// Animate runs the animation loop until Stop is called
 func (a *SimpleAnim) Animate() {
+	a.mu.Lock()
+	done := a.done
+	a.mu.Unlock()
+	if done == nil { // already stopped
+		return
+	}
 	for {
-		if a.Ticker == nil || a.SceneEditor.This == nil {
+		select {
+		case <-done:
 			return
+		case now := <-a.Ticker.C:
+			if a.SceneEditor.This == nil {
+				a.Stop()
+				return
+			}
+			a.mu.Lock()
+			dt := float32(now.Sub(a.lastTick).Seconds())
+			a.lastTick = now
+			a.updateRamp(dt)
+			ramp := a.ramp
+			a.mu.Unlock()
+
+			changed := a.StepAnimators(dt)
+			if a.Cube != nil && a.Sphere != nil && ramp > 0 {
+				if a.MoveObjects(dt * ramp) { // ramped speed
+					changed = true
+				}
+			}
+
+			// Update scene
+			if changed {
+				a.SceneEditor.SceneWidget().UpdateWidget()
+			}
 		}
-		<-a.Ticker.C // wait for tick
-		if !a.On || a.SceneEditor.This == nil || a.Cube == nil || a.Sphere == nil {
-			continue
-		}
+	}
+}
 
-		// Calculate new positions
-		radius := float32(0.5)
+// MoveObjects advances the circular motion of the cube and sphere by dt seconds,
+// returning false if there is nothing left to move. The ticker is stopped
+// when a LoopOnce cycle finishes.
+func (a *SimpleAnim) MoveObjects(dt float32) bool {
+	a.mu.Lock()
+	if a.completed {
+		a.mu.Unlock()
+		return false
+	}
+	a.advanceAngle(dt)
+	completed := a.completed
+	if completed && a.Ticker != nil {
+		a.Ticker.Stop()
+	}
+	a.mu.Unlock()
+
+	// Calculate new positions
+	radius := float32(0.5)
+	angle := a.EasedAngle()
+
+	// Move cube in a circle
+	dx := radius * math32.Cos(angle)
+	dz := radius * math32.Sin(angle)
+	cubePos := a.CubePosOrig
+	cubePos.X += dx
+	cubePos.Z += dz
+	a.Cube.SetPosePos(cubePos)
+
+	// Move sphere in opposite direction
+	spherePos := a.SpherePosOrig
+	spherePos.X -= dx * 0.5
+	spherePos.Z -= dz * 0.5
+	a.Sphere.SetPosePos(spherePos)
+
+	// Rotate cube
+	a.Cube.Pose.SetAxisRotation(0, 1, 0, angle*180/math32.Pi)
+
+	if completed && a.OnComplete != nil {
+		a.OnComplete()
+	}
+	return true
+}
 
-		// Move cube in a circle
-		dx := radius * math32.Cos(a.Angle)
-		dz := radius * math32.Sin(a.Angle)
-		cubePos := a.CubePosOrig
-		cubePos.X += dx
-		cubePos.Z += dz
-		a.Cube.SetPosePos(cubePos)
+// advanceAngle advances Angle by dt seconds according to LoopMode
+func (a *SimpleAnim) advanceAngle(dt float32) {
+	cycle := float32(2 * math32.Pi)
+	step := a.Speed * dt * 30
+	if a.reverse {
+		step = -step
+	}
+	a.Angle += step
+	switch a.LoopMode {
+	case LoopOnce:
+		if a.Angle >= cycle {
+			a.Angle = cycle
+			a.completed = true
+		}
+	case LoopPingPong:
+		if a.Angle >= cycle {
+			a.Angle = 2*cycle - a.Angle
+			a.reverse = true
+		} else if a.Angle <= 0 {
+			a.Angle = -a.Angle
+			a.reverse = false
+		}
+	}
+}
 
-		// Move sphere in opposite direction
-		spherePos := a.SpherePosOrig
-		spherePos.X -= dx * 0.5
-		spherePos.Z -= dz * 0.5
-		a.Sphere.SetPosePos(spherePos)
+// AddAnimator adds an animation to be driven by the ticker
+func (a *SimpleAnim) AddAnimator(an Animator) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.Animators = append(a.Animators, an)
+}
 
-		// Rotate cube
-		a.Cube.Pose.SetAxisRotation(0, 1, 0, a.Angle*180/math32.Pi)
+// RemoveAnimator removes an animation added with AddAnimator
+func (a *SimpleAnim) RemoveAnimator(an Animator) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, o := range a.Animators {
+		if o == an {
+			a.Animators = append(a.Animators[:i], a.Animators[i+1:]...)
+			return
+		}
+	}
+}
 
-		// Update scene
-		a.SceneEditor.SceneWidget().UpdateWidget()
-		a.Angle += a.Speed
+// StepAnimators advances all added animations by dt seconds,
+// returning true if any of them changed the scene
+func (a *SimpleAnim) StepAnimators(dt float32) bool {
+	a.mu.Lock()
+	ans := append([]Animator(nil), a.Animators...)
+	a.mu.Unlock()
+	changed := false
+	for _, an := range ans {
+		if an.Step(dt) {
+			changed = true
+		}
 	}
+	return changed
+}
+
+// EasedAngle returns the current angle with EasingFunc applied
+// to the normalized time within the current cycle
+func (a *SimpleAnim) EasedAngle() float32 {
+	if a.EasingFunc == nil {
+		return a.Angle
+	}
+	cycle := float32(2 * math32.Pi)
+	turns := math32.Floor(a.Angle / cycle)
+	t := (a.Angle - turns*cycle) / cycle
+	return (turns + a.EasingFunc(t)) * cycle
 }
 
 func main() {
 	// Create animation controller
-	anim := &SimpleAnim{}
+	anim := &SimpleAnim{
+		RampUpDuration:   500 * time.Millisecond,
+		RampDownDuration: 500 * time.Millisecond,
+	}
 
 	// Create main body
 	b := core.NewBody("Simple XYZ Demo")
@@ -131,16 +436,38 @@ func main() {
 	// Add animation control button
 	animButton := core.NewButton(b).SetText("Start Animation")
 	animButton.OnClick(func(e events.Event) {
-		anim.On = !anim.On
-		if anim.On {
+		if anim.Paused {
+			anim.Resume()
 			animButton.SetText("Stop Animation")
 		} else {
+			anim.Pause()
 			animButton.SetText("Start Animation")
 		}
 	})
 
-	// Create scene editor
-	se := xyzcore.NewSceneEditor(b)
+	// Add keyframe animation button
+	var torusAnim *KeyframeAnim
+	core.NewButton(b).SetText("Play Keyframes").OnClick(func(e events.Event) {
+		torusAnim.Play()
+		anim.Resume() // the ticker drives the keyframes
+		animButton.SetText("Stop Animation")
+	})
+
+	// Add projection button
+	var projection *CameraProjection
+	projButton := core.NewButton(b).SetText("Orthographic")
+	projButton.OnClick(func(e events.Event) {
+		projection.Toggle()
+		if projection.Ortho {
+			projButton.SetText("Perspective")
+		} else {
+			projButton.SetText("Orthographic")
+		}
+	})
+
+	// Create scene editor, with a panel listing the scene beside it
+	split := core.NewSplits(b)
+	se := xyzcore.NewSceneEditor(split)
 	se.UpdateWidget()
 	sw := se.SceneWidget()
 	sc := se.SceneXYZ()
@@ -150,66 +477,406 @@ func main() {
 	sc.Camera.Pose.Pos.Set(0, 3, 8)
 	sc.Camera.LookAt(math32.Vector3{}, math32.Vec3(0, 1, 0))
 
+	projection = NewCameraProjection(sw)
+
+	// Orbit the camera around the center of the scene,
+	// or press f to fly through it
+	orbit := NewOrbitController()
+	orbit.Attach(sw)
+	NewFlyController().Attach(sw)
+
+	// F3 shows and hides the rendering stats
+	NewStatsOverlay().Attach(sw)
+
+	// Ctrl+Z and Ctrl+Shift+Z undo and redo dragging objects
+	commands := NewCommandStack()
+	commands.Attach(se)
+
+	// Drag the selected object to move it facing the camera,
+	// or along an axis while holding x, y or z
+	drag := NewDragMover()
+	drag.Commands = commands
+	drag.Attach(sw)
+
+	// Show handles around the selected object for moving,
+	// turning and scaling it, which can also be undone
+	gizmo := NewTransformGizmo()
+	gizmo.Commands = commands
+	gizmo.Attach(sw)
+
+	// Drag with the right button, or with Control and the left one,
+	// to select all the objects touching a rectangle, or with Shift
+	// as well to toggle them in the selection
+	marquee := NewMarqueeSelect()
+	marquee.Attach(sw)
+
+	// Ctrl+D copies the selected objects, which can be undone
+	dup := NewDuplicator()
+	dup.Commands = commands
+	dup.Marquee = marquee
+	dup.Attach(se)
+
+	// Bookmarks of camera views, kept beside the glTF file of the scene
+	bookmarks := NewCameraBookmarks(se)
+
+	// Measure distances between points clicked on objects
+	measure := NewMeasureDistanceTool()
+	measure.Attach(sw)
+
+	// Clicking an annotation edits or unpins it
+	notes := NewAnnotationTool()
+	notes.Attach(sw)
+
+	// F4 shows and hides a map of the scene seen from above,
+	// which moves the camera to where it is clicked
+	minimap := NewMiniMap()
+	minimap.Orbit = orbit
+	minimap.Attach(sw)
+
+	// Fog in the color of the background, with a linear fog that
+	// is about as thick as the exponential ones at the same density
+	fog := &Fog{Near: 2, Density: 0.1}
+	setFog := func() {
+		fog.Color = colors.ToUniform(sc.Background)
+		fog.Far = fog.Near + 2/max(fog.Density, 0.01)
+		SetFog(sc, fog)
+		se.NeedsRender()
+	}
+
+	// Add buttons to the editor toolbar showing a grid with lines
+	// every half unit and major lines every 5 units, snapping
+	// dragged objects to it, moving objects along their own axes,
+	// measuring distances, showing annotations and the map, bookmarking
+	// camera views, choosing the fog, saving an image of the scene,
+	// exporting and importing glTF and STL files, and importing OBJ files
+	var panel *SceneTreePanel // made below
+	tb := se.ChildByName("tb", 1).(*core.Toolbar)
+	tb.Maker(func(p *tree.Plan) {
+		tree.Add(p, func(w *core.Button) {
+			w.SetIcon(icons.GridOn).SetTooltip("show or hide the grid").
+				OnClick(func(e events.Event) {
+					if SceneGrid(sc) == nil {
+						ShowGrid(sc, 0.5)
+					} else {
+						ShowGrid(sc, 0)
+					}
+					se.NeedsRender()
+				})
+		})
+		tree.Add(p, func(w *core.Switch) {
+			w.SetText("Snap").SetTooltip("snap dragged objects to the grid")
+			w.OnChange(func(e events.Event) {
+				commands.SnapToGrid = w.IsChecked()
+			})
+		})
+		tree.Add(p, func(w *core.Switch) {
+			w.SetText("Local").SetTooltip("move and turn objects along their own axes instead of those of the world")
+			w.OnChange(func(e events.Event) {
+				gizmo.Space = GizmoWorld
+				if w.IsChecked() {
+					gizmo.Space = GizmoLocal
+				}
+				se.NeedsRender()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetIcon(icons.Straighten).SetTooltip("measure the distance between two points clicked on objects; escape clears it")
+			w.OnClick(func(e events.Event) {
+				measure.SetActive(!measure.Active)
+				w.SetIcon(icons.Straighten)
+				if measure.Active {
+					w.SetIcon(icons.StraightenFill)
+				}
+				w.Update()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetIcon(icons.SpeakerNotes).SetTooltip("show or hide the annotations pinned to objects").
+				OnClick(func(e events.Event) {
+					SetAnnotationsVisible(sc, !AnnotationsVisible(sc))
+					w.SetIcon(icons.SpeakerNotes)
+					if !AnnotationsVisible(sc) {
+						w.SetIcon(icons.SpeakerNotesOff)
+					}
+					w.Update()
+					se.NeedsRender()
+				})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetIcon(icons.Map).SetTooltip("show or hide the map of the scene seen from above; click on it to move the camera there").
+				OnClick(func(e events.Event) {
+					minimap.SetVisible(!minimap.Visible)
+					w.SetIcon(icons.Map)
+					if minimap.Visible {
+						w.SetIcon(icons.MapFill)
+					}
+					w.Update()
+				})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetIcon(icons.Bookmark).SetTooltip("bookmark the current camera view").
+				OnClick(func(e events.Event) {
+					saveBookmarkDialog(se, bookmarks)
+				})
+		})
+		tree.Add(p, func(w *core.Chooser) {
+			w.SetStrings(bookmarks.Names()...).SetPlaceholder("Bookmarks")
+			w.SetTooltip("return to a bookmarked camera view")
+			w.OnChange(func(e events.Event) {
+				name := w.CurrentItem.Value.(string)
+				core.ErrorSnackbar(se, bookmarks.RestoreCameraBookmark(name), "Error restoring bookmark")
+				if bm, ok := bookmarks.Bookmark(name); ok {
+					orbit.Target = bm.Target
+				}
+			})
+		})
+		tree.Add(p, func(w *core.Chooser) {
+			w.SetItems(core.ChooserItem{Value: FogOff, Text: "No fog"},
+				core.ChooserItem{Value: FogLinear, Text: "Linear fog"},
+				core.ChooserItem{Value: FogExp, Text: "Exp fog"},
+				core.ChooserItem{Value: FogExp2, Text: "Exp2 fog"})
+			w.SetTooltip("the way that the fog gets thicker with distance")
+			w.OnChange(func(e events.Event) {
+				fog.Mode = w.CurrentItem.Value.(FogModes)
+				setFog()
+			})
+		})
+		tree.Add(p, func(w *core.Slider) {
+			w.SetMin(0).SetMax(0.3).SetStep(0.01).SetValue(fog.Density)
+			w.SetTooltip("fog density")
+			w.OnInput(func(e events.Event) {
+				fog.Density = w.Value
+				setFog()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetIcon(icons.Image).SetTooltip("save an image of the scene as a PNG file").
+				OnClick(func(e events.Event) {
+					saveImageDialog(se)
+				})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("glTF").SetIcon(icons.DeployedCode).SetTooltip("export or import glTF and STL files, or import OBJ files")
+			w.SetMenu(func(m *core.Scene) {
+				core.NewButton(m).SetText("Export").SetIcon(icons.Download).OnClick(func(e events.Event) {
+					fileDialog(se, "Export glTF", "scene.glb", func(filename string) error {
+						if err := ExportGLTF(sc, filename); err != nil {
+							return err
+						}
+						return bookmarks.SaveSceneFile(filename)
+					})
+				})
+				core.NewButton(m).SetText("Import").SetIcon(icons.Upload).OnClick(func(e events.Event) {
+					fileDialog(se, "Import glTF", "", func(filename string) error {
+						err := ImportGLTF(sc, filename)
+						panel.Resync()
+						se.NeedsRender()
+						if err != nil {
+							return err
+						}
+						return bookmarks.SetSceneFile(filename)
+					})
+				})
+				core.NewButton(m).SetText("Import OBJ").SetIcon(icons.Upload).OnClick(func(e events.Event) {
+					fileDialog(se, "Import OBJ", "", func(filename string) error {
+						err := ImportOBJ(sc, filename, "")
+						panel.Resync()
+						se.NeedsRender()
+						return err
+					})
+				})
+				core.NewButton(m).SetText("Export STL").SetIcon(icons.Download).OnClick(func(e events.Event) {
+					sld := se.SceneWidget().CurrentSelected
+					if sld == nil || sld.AsSolid() == nil {
+						core.MessageSnackbar(se, "Select a solid to export as STL")
+						return
+					}
+					fileDialog(se, "Export STL", sld.AsTree().Name+".stl", func(filename string) error {
+						return ExportSTL(sld.AsSolid(), filename)
+					})
+				})
+				core.NewButton(m).SetText("Import STL").SetIcon(icons.Upload).OnClick(func(e events.Event) {
+					fileDialog(se, "Import STL", "", func(filename string) error {
+						err := ImportSTL(sc, filename)
+						panel.Resync()
+						se.NeedsRender()
+						return err
+					})
+				})
+			})
+		})
+	})
+	AddFullscreenShortcut(b)
+	tb.AddOverflowMenu(func(m *core.Scene) {
+		core.NewButton(m).SetText("Keyboard shortcuts").SetIcon(icons.Keyboard).OnClick(func(e events.Event) {
+			ShowShortcuts(b)
+		})
+	})
+	tb.Update()
+	bookmarks.OnChange = tb.Update
+
 	// Add lighting
 	xyz.NewAmbient(sc, "ambient", 0.3, xyz.DirectSun)
-	xyz.NewDirectional(sc, "directional", 1, xyz.DirectSun).Pos.Set(0, 2, 1)
+	sun := xyz.NewDirectional(sc, "directional", 1, xyz.DirectSun)
+	sun.Pos.Set(0, 2, 1)
+
+	// Blue sky light from above and brown ground light from below,
+	// tinting the floor and the tops of the spheres blue and the
+	// undersides of the shapes brown
+	NewHemisphereLight(sc, "hemisphere", color.RGBA{120, 170, 255, 255}, color.RGBA{140, 90, 50, 255}, 0.5)
 
 	// Set background color
 	se.Styler(func(s *styles.Style) {
 		sc.Background = colors.Scheme.Select.Container
 	})
 
-	// Create floor
+	// Create floor; it stays a plain xyz.Solid because the xyz debug
+	// "t" key expects the first scene child to be one
 	floorMesh := xyz.NewPlane(sc, "floor-plane", 10, 10)
 	floor := xyz.NewSolid(sc).SetMesh(floorMesh).
 		SetColor(colors.Tan).SetPos(0, -1, 0)
 	floor.SetName("floor")
 
-	// Create 3D text
-	text3D := xyz.NewText2D(sc).SetText("XYZ 3D Demo")
+	// Cast shadows from the sun onto the floor
+	shadows := NewShadows(sun)
+	shadows.Receivers = []*xyz.Solid{floor}
+	SetShadows(sc, shadows)
+
+	// Create 3D text, turning to face the camera around the Y axis
+	text3D := NewText2D(sc)
+	text3D.SetText("XYZ 3D Demo")
 	text3D.Styles.Text.Align = text.Center
+	text3D.Billboard = true
+	text3D.AxisLock = true
 	text3D.Pose.Scale.SetScalar(0.2)
 	text3D.SetPos(0, 2, 0)
 
-	// Create animated cube
-	cubeMesh := xyz.NewBox(sc, "cube-mesh", 1, 1, 1)
-	cube := xyz.NewSolid(sc).SetMesh(cubeMesh).
-		SetColor(colors.Blue).SetShiny(20).SetPos(-1.5, 0, 0)
-	cube.SetName("animated-cube")
-
-	// Create animated sphere
-	sphereMesh := xyz.NewSphere(sc, "sphere-mesh", 0.5, 32)
-	sphere := xyz.NewSolid(sc).SetMesh(sphereMesh).
-		SetColor(colors.Orange).SetPos(1.5, 0, 0)
-	sphere.SetName("animated-sphere")
+	// Create animated cluster of cubes, drawn as instances of one mesh,
+	// with less rounding further from the camera
+	cubeMesh := NewRoundedBox(sc, "cube-mesh", 0.45, 0.45, 0.45, 0.06, 4)
+	var cubes []InstanceData
+	for i := range 8 {
+		pos := math32.Vec3(float32(i&1)-0.5, float32(i>>1&1)-0.5, float32(i>>2&1)-0.5).MulScalar(0.55)
+		cubes = append(cubes, NewInstance(pos, color.RGBA{0, uint8(40 * (i % 4)), 255, 255}))
+	}
+	cube := NewInstancedSolid(sc, "animated-cube", cubeMesh, cubes)
+	cube.SetShiny(20).SetPos(-1.5, 0, 0)
+	cube.CastShadow = true
+	cube.Layer = "animated"
+	cube.AddLOD(12, NewRoundedBox(sc, "cube-mesh-lod1", 0.45, 0.45, 0.45, 0.06, 1))
+	cube.AddLOD(20, xyz.NewBox(sc, "cube-mesh-lod2", 0.45, 0.45, 0.45))
+
+	// Create animated cluster of spheres, whose instances also
+	// circle around the cluster center on each tick
+	sphereMesh := xyz.NewSphere(sc, "sphere-mesh", 0.2, 16)
+	sphereTime := float32(0)
+	placeSpheres := func() []InstanceData {
+		spheres := make([]InstanceData, 5)
+		for i := range spheres {
+			ang := sphereTime + float32(i)*2*math32.Pi/float32(len(spheres))
+			pos := math32.Vec3(0.35*math32.Cos(ang), 0.15*math32.Sin(2*ang), 0.35*math32.Sin(ang))
+			spheres[i] = NewInstance(pos, color.RGBA{255, uint8(100 + 30*i), 0, 255})
+		}
+		return spheres
+	}
+	sphere := NewInstancedSolid(sc, "animated-sphere", sphereMesh, placeSpheres())
+	sphere.SetPos(1.5, 0, 0)
+	sphere.CastShadow = true
+	sphere.Layer = "animated"
+	sphere.AddLOD(12, xyz.NewSphere(sc, "sphere-mesh-lod1", 0.2, 8))
+	sphere.AddLOD(20, xyz.NewSphere(sc, "sphere-mesh-lod2", 0.2, 4))
+	anim.AddAnimator(AnimatorFunc(func(dt float32) bool {
+		sphereTime += dt
+		sphere.SetInstances(placeSpheres())
+		return true
+	}))
 
 	// Create cylinder
 	cylinderMesh := xyz.NewCylinder(sc, "cylinder-mesh", 1.5, 0.3, 32, 1, true, true)
-	cylinder := xyz.NewSolid(sc).SetMesh(cylinderMesh).
-		SetColor(colors.Green).SetPos(0, 0, -2)
+	cylinder := NewSolid(sc)
+	cylinder.SetMesh(cylinderMesh).SetPos(0, 0, -2)
+	cylinder.SetPBRMaterial(PBRMaterial{BaseColor: colors.Green, Metallic: 1, Roughness: 0.4})
+	cylinder.CastShadow = true
+	cylinder.Layer = "shapes"
 	cylinder.Pose.SetAxisRotation(1, 0, 0, 90)
+	PinAnnotation(&cylinder.Solid, "Cylinder")
 
 	// Create semi-transparent torus
 	torusMesh := xyz.NewTorus(sc, "torus-mesh", 0.7, 0.1, 32)
-	torus := xyz.NewSolid(sc).SetMesh(torusMesh).
-		SetColor(color.RGBA{255, 0, 255, 150}).SetPos(0, 1.5, 0)
+	torus := NewSolid(sc)
+	torus.SetMesh(torusMesh).SetColor(color.RGBA{255, 0, 255, 150}).SetPos(0, 1.5, 0)
+	torus.CastShadow = true
+	torus.Layer = "shapes"
 	torus.Pose.SetAxisRotation(1, 0, 0, 45)
 
+	// Bob and spin the torus with keyframes
+	torusAnim = NewKeyframeAnim(anim, &torus.Solid)
+	for i := 0; i <= 4; i++ {
+		ang := float32(i) * math32.Pi / 2
+		k := NewKeyframe(float32(i), math32.Vec3(0, 1.5+0.25*math32.Sin(ang), 0))
+		spin := math32.NewQuatAxisAngle(math32.Vec3(0, 1, 0), ang)
+		k.Rot = spin.Mul(torus.Pose.Quat)
+		torusAnim.AddKeyframe(k)
+	}
+
 	// Create lines
 	linesMesh := xyz.NewLines(sc, "lines", []math32.Vector3{
 		{X: -2, Y: -0.5, Z: 2},
 		{X: 0, Y: 1, Z: 2},
 		{X: 2, Y: -0.5, Z: 2},
 	}, math32.Vec2(0.1, 0.05), xyz.CloseLines)
-	xyz.NewSolid(sc).SetMesh(linesMesh).SetColor(colors.Yellow)
+	NewSolid(sc).SetMesh(linesMesh).SetColor(colors.Yellow)
 
 	// Add arrow
 	xyz.NewArrow(sc, sc, "arrow", math32.Vec3(-2, 0, 0), math32.Vec3(2, 0, 0),
 		0.05, colors.Red, xyz.StartArrow, xyz.EndArrow, 4, 0.5, 8)
 
+	// List the scene in the panel
+	panel = NewSceneTreePanel(split, se)
+	notes.OnChange = panel.Resync
+
+	// Import glTF, OBJ and STL files dropped onto the scene,
+	// and use images dropped onto a solid as its texture
+	drop := NewFileDropImporter()
+	drop.OnImport = func(path string) { panel.Resync() }
+	drop.Attach(sw)
+	split.SetSplits(0.8, 0.2)
+
 	// Start animation but don't run it yet
 	anim.Start(se, false)
 
+	// Open the window where it was left
+	EnableStateRestore(b, "main")
+
 	// Run the application
 	b.RunMainWindow()
 }
+
+// saveBookmarkDialog asks for a name to bookmark the
+// current camera view of the given editor under
+func saveBookmarkDialog(se *xyzcore.SceneEditor, cb *CameraBookmarks) {
+	d := core.NewBody("Bookmark view")
+	tf := core.NewTextField(d).SetPlaceholder("Name")
+	tf.SetText(fmt.Sprintf("View %d", len(cb.Bookmarks)+1))
+	d.AddBottomBar(func(bar *core.Frame) {
+		d.AddCancel(bar)
+		d.AddOK(bar).SetText("Save").OnClick(func(e events.Event) {
+			core.ErrorSnackbar(se, cb.SaveCameraBookmark(tf.Text()), "Error saving bookmark")
+		})
+	})
+	d.RunDialog(se)
+}
+
+// fileDialog asks for a file name, starting with the given one,
+// and calls the given function with it, showing any error
+func fileDialog(ctx core.Widget, title, filename string, fun func(filename string) error) {
+	d := core.NewBody(title)
+	fp := core.NewFilePicker(d).SetFilename(filename)
+	d.AddBottomBar(func(bar *core.Frame) {
+		d.AddCancel(bar)
+		d.AddOK(bar).OnClick(func(e events.Event) {
+			core.ErrorSnackbar(ctx, fun(fp.SelectedFile()), title)
+		})
+	})
+	d.RunWindowDialog(ctx)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+func TestSimpleAnimInterval(t *testing.T) {
+	a := &SimpleAnim{}
+	if got := a.interval(); got != time.Second/60 {
+		t.Errorf("the interval with no FPS is %v, not that of 60 fps", got)
+	}
+	a.FPS = 24
+	if got := a.interval(); got != time.Second/24 {
+		t.Errorf("the interval at 24 fps is %v", got)
+	}
+}
+
+func TestSimpleAnimSetFPS(t *testing.T) {
+	a := &SimpleAnim{Ticker: time.NewTicker(time.Hour)}
+	defer a.Ticker.Stop()
+	a.SetFPS(100)
+	if a.FPS != 100 {
+		t.Errorf("the FPS is %d after setting it to 100", a.FPS)
+	}
+	select {
+	case <-a.Ticker.C:
+	case <-time.After(time.Second):
+		t.Fatal("the running ticker did not tick at the new frame rate")
+	}
+
+	// a paused ticker stays stopped until Resume
+	a.Paused = true
+	a.Ticker.Stop()
+	a.SetFPS(200)
+	select {
+	case <-a.Ticker.C:
+		t.Error("setting the FPS restarted the paused ticker")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSimpleAnimSetFPSNoLeak(t *testing.T) {
+	se := xyzcore.NewSceneEditor(core.NewBody())
+	se.Update() // make the scene
+	before := runtime.NumGoroutine()
+	a := &SimpleAnim{}
+	a.Start(se, true)
+	for fps := range 5 {
+		a.SetFPS(30 + 10*fps)
+	}
+	a.Pause()
+	a.SetFPS(120)
+	a.Resume()
+	a.Stop()
+
+	// give the animation goroutine time to return
+	after := runtime.NumGoroutine()
+	for wait := 0; after > before && wait < 100; wait++ {
+		time.Sleep(10 * time.Millisecond)
+		after = runtime.NumGoroutine()
+	}
+	if after > before {
+		t.Errorf("%d goroutines are left running after SetFPS and Stop, up from %d", after, before)
+	}
+}
+
+// newTestAnim returns a new animation of a cube and sphere, at 1 radian
+// a second, with the given loop mode
+func newTestAnim(mode LoopModes) *SimpleAnim {
+	sc := newTestScene()
+	a := &SimpleAnim{Speed: 1.0 / 30, LoopMode: mode}
+	a.Cube = xyz.NewSolid(sc)
+	a.Sphere = xyz.NewSolid(sc)
+	return a
+}
+
+func TestSimpleAnimLoopForever(t *testing.T) {
+	a := newTestAnim(LoopForever)
+	for range 8 {
+		if !a.MoveObjects(1) {
+			t.Fatal("the animation stopped looping")
+		}
+	}
+	if math32.Abs(a.Angle-8) > 1e-4 {
+		t.Errorf("the angle is %g after 8 seconds, not 8", a.Angle)
+	}
+}
+
+func TestSimpleAnimLoopOnce(t *testing.T) {
+	a := newTestAnim(LoopOnce)
+	done := 0
+	a.OnComplete = func() { done++ }
+	for range 7 { // 2 pi is just over 6 seconds
+		a.MoveObjects(1)
+	}
+	if a.Angle != 2*math32.Pi || done != 1 {
+		t.Errorf("the angle is %g and OnComplete was called %d times after one cycle", a.Angle, done)
+	}
+	if a.MoveObjects(1) || done != 1 {
+		t.Error("the animation kept moving after one cycle")
+	}
+	if a.Cube.Pose.Pos.Sub(math32.Vec3(0.5, 0, 0)).Length() > 1e-4 {
+		t.Errorf("the cube ended at %v instead of where it started the cycle", a.Cube.Pose.Pos)
+	}
+}
+
+func TestSimpleAnimLoopOnceStopsTicker(t *testing.T) {
+	a := newTestAnim(LoopOnce)
+	a.FPS = 100
+	a.Ticker = time.NewTicker(a.interval())
+	defer a.Ticker.Stop()
+	for range 7 {
+		a.MoveObjects(1)
+	}
+	// drain a tick that may have fired before the cycle finished
+	select {
+	case <-a.Ticker.C:
+	default:
+	}
+	select {
+	case <-a.Ticker.C:
+		t.Error("the ticker kept firing after the cycle finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	a.Resume()
+	if a.completed || a.Angle != 0 {
+		t.Errorf("Resume left the angle at %g with completed %v", a.Angle, a.completed)
+	}
+	select {
+	case <-a.Ticker.C:
+	case <-time.After(time.Second):
+		t.Fatal("Resume did not restart the ticker after the cycle finished")
+	}
+	if !a.MoveObjects(1) {
+		t.Error("the animation did not move again after Resume")
+	}
+}
+
+func TestSimpleAnimLoopPingPong(t *testing.T) {
+	a := newTestAnim(LoopPingPong)
+	for range 7 {
+		a.MoveObjects(1)
+	}
+	// 7 seconds is 7 - 2 pi past the end of the cycle, going back
+	if want := float32(4*math32.Pi - 7); math32.Abs(a.Angle-want) > 1e-4 || !a.reverse {
+		t.Errorf("the angle is %g after 7 seconds, not %g going back", a.Angle, want)
+	}
+	for range 7 {
+		a.MoveObjects(1)
+	}
+	if want := float32(14 - 4*math32.Pi); math32.Abs(a.Angle-want) > 1e-4 || a.reverse {
+		t.Errorf("the angle is %g after 14 seconds, not %g going forward again", a.Angle, want)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// MarqueeName is the name of the group of the rectangle and
+// selection boxes of a [MarqueeSelect]
+const MarqueeName = "__Marquee"
+
+// MarqueeSelect selects many solids of a scene widget at once by
+// dragging a rectangle over them, with the right mouse button or with
+// Control and the left button, when the widget SelectionMode is
+// Manipulable. The solids whose world bounding boxes, as projected
+// onto the screen, touch the rectangle replace the Selection, or are
+// toggled in it with Shift, and each has a selection box around it.
+// Clicking still selects a single node, as usual. The window draws
+// the scene over the widgets, so the rectangle is a quad in the
+// scene, just past the near plane of the camera.
+type MarqueeSelect struct {
+	// Selection is the solids selected by the last drags
+	Selection []*xyz.Solid `edit:"-"`
+
+	// Color of the rectangle and the selection boxes
+	Color color.RGBA
+
+	// OnChange is called after the Selection changes
+	OnChange func() `display:"-"`
+
+	// Scene widget that the selection is made in
+	SceneWidget *xyzcore.Scene `set:"-"`
+
+	// rectangle being dragged, in pixels of the scene widget,
+	// and whether the Selection is toggled by it
+	rect     image.Rectangle
+	dragging bool
+	toggle   bool
+}
+
+// NewMarqueeSelect returns a new marquee selection with no solids selected
+func NewMarqueeSelect() *MarqueeSelect {
+	return &MarqueeSelect{Color: colors.Yellow}
+}
+
+// Attach lets rectangles be dragged in the given scene widget to select solids
+func (ms *MarqueeSelect) Attach(sw *xyzcore.Scene) {
+	if ms.SceneWidget != nil {
+		return
+	}
+	ms.SceneWidget = sw
+	rect := tree.New[marqueeRect](sw.SceneXYZ())
+	rect.SetName(MarqueeName + "Rect")
+	rect.marquee = ms
+	rect.SetMesh(sw.SceneXYZ().PlaneMesh2D())
+	rect.Material.CullBack = false
+	sw.On(events.SlideStart, func(e events.Event) {
+		if sw.SelectionMode != xyzcore.Manipulable {
+			return
+		}
+		ctrlLeft := e.MouseButton() == events.Left && e.HasAnyModifier(key.Control)
+		if e.MouseButton() != events.Right && !ctrlLeft {
+			return
+		}
+		e.SetHandled()
+		start := e.StartPos().Sub(sw.Geom.ContentBBox.Min)
+		ms.rect = image.Rectangle{Min: start, Max: start}
+		ms.dragging = true
+		ms.toggle = e.HasAnyModifier(key.Shift)
+	})
+	sw.On(events.SlideMove, func(e events.Event) {
+		if !ms.dragging {
+			return
+		}
+		e.SetHandled()
+		ms.rect.Max = e.Pos().Sub(sw.Geom.ContentBBox.Min)
+		sw.NeedsRender()
+	})
+	sw.On(events.SlideStop, func(e events.Event) {
+		if !ms.dragging {
+			return
+		}
+		e.SetHandled()
+		ms.dragging = false
+		ms.rect.Max = e.Pos().Sub(sw.Geom.ContentBBox.Min)
+		hits := ms.solidsIn(ms.rect.Canon())
+		if ms.toggle {
+			for _, sld := range hits {
+				if i := slices.Index(ms.Selection, sld); i >= 0 {
+					ms.Selection = slices.Delete(ms.Selection, i, i+1)
+				} else {
+					ms.Selection = append(ms.Selection, sld)
+				}
+			}
+			ms.SetSelection(ms.Selection)
+		} else {
+			ms.SetSelection(hits)
+		}
+	})
+}
+
+// SetSelection replaces the Selection with the given solids,
+// drawing the selection boxes around them
+func (ms *MarqueeSelect) SetSelection(slds []*xyz.Solid) {
+	ms.Selection = slds
+	sw := ms.SceneWidget
+	if sw == nil {
+		return
+	}
+	sc := sw.SceneXYZ()
+	sc.DeleteChildByName(MarqueeName)
+	if len(slds) > 0 {
+		gp := xyz.NewGroup(sc)
+		gp.SetName(MarqueeName)
+		for i, sld := range slds {
+			nm := fmt.Sprintf("%sBox%d", MarqueeName, i)
+			xyz.NewLineBox(sc, gp, nm, nm, sld.WorldBBox.BBox, sw.SelectionParams.Width*max(sc.Camera.DistanceTo(sc.Camera.Target), 1), ms.Color, xyz.Inactive)
+		}
+	}
+	if ms.OnChange != nil {
+		ms.OnChange()
+	}
+	sc.SetNeedsUpdate()
+	sw.NeedsRender()
+}
+
+// solidsIn returns the visible solids whose world bounding boxes,
+// projected onto the screen, touch the given rectangle in pixels
+func (ms *MarqueeSelect) solidsIn(r image.Rectangle) []*xyz.Solid {
+	sw := ms.SceneWidget
+	sc := sw.SceneXYZ()
+	sz := sw.Geom.ContentBBox.Size()
+	if sz.X <= 0 || sz.Y <= 0 {
+		return nil
+	}
+	cm := &sc.Camera
+	var vp math32.Matrix4
+	vp.MulMatrices(&cm.ProjectionMatrix, &cm.ViewMatrix)
+	r.Max = r.Max.Add(image.Pt(1, 1)) // a click wide drag still has a pixel
+	var hits []*xyz.Solid
+	sc.WalkDown(func(k tree.Node) bool {
+		if k == sc.This {
+			return tree.Continue
+		}
+		nd, _ := xyz.AsNode(k)
+		if nd == nil || isReservedNode(k) || isHidden(nd) {
+			return tree.Break
+		}
+		sld := nd.AsSolid()
+		if sld == nil || sld.Mesh == nil || isLocked(k) {
+			return tree.Continue
+		}
+		if screenBox(sld.WorldBBox.BBox, &vp, sz).Overlaps(r) {
+			hits = append(hits, sld)
+		}
+		return tree.Continue
+	})
+	return hits
+}
+
+// screenBox returns the rectangle in pixels of a viewport of the
+// given size covered by the given world box, as projected by the
+// given view projection matrix, which is empty if it is all behind
+// the camera. Corners behind the camera widen it to the whole view.
+func screenBox(box math32.Box3, vp *math32.Matrix4, size image.Point) image.Rectangle {
+	min, max := math32.Vec2(math32.Infinity, math32.Infinity), math32.Vec2(-math32.Infinity, -math32.Infinity)
+	front := false
+	for i := range 8 {
+		c := box.Min
+		if i&1 != 0 {
+			c.X = box.Max.X
+		}
+		if i&2 != 0 {
+			c.Y = box.Max.Y
+		}
+		if i&4 != 0 {
+			c.Z = box.Max.Z
+		}
+		p := math32.Vector4FromVector3(c, 1).MulMatrix4(vp)
+		if p.W <= 0 {
+			min, max = math32.Vec2(-1, -1), math32.Vec2(1, 1)
+			continue
+		}
+		front = true
+		ndc := math32.Vec2(p.X/p.W, p.Y/p.W)
+		min.SetMin(ndc)
+		max.SetMax(ndc)
+	}
+	if !front {
+		return image.Rectangle{}
+	}
+	w, h := float32(size.X), float32(size.Y)
+	return image.Rect(int((min.X+1)/2*w), int((1-max.Y)/2*h), int((max.X+1)/2*w)+1, int((1-min.Y)/2*h)+1)
+}
+
+// marqueeRect is the rectangle of a [MarqueeSelect] being dragged
+type marqueeRect struct {
+	xyz.Solid
+
+	// marquee showing the rectangle
+	marquee *MarqueeSelect
+
+	// whether it was shown on the last render
+	shown bool
+}
+
+// PreRender places the rectangle over its pixels in the view of the
+// camera, which the scene has just updated, and clears its window
+// bounding box so that clicks on it do not select it
+func (mr *marqueeRect) PreRender() {
+	mr.SceneBBox = image.Rectangle{}
+	ms := mr.marquee
+	r := ms.rect.Canon()
+	mr.shown = ms.dragging && !r.Empty()
+	if !mr.shown {
+		return
+	}
+	mr.Pose.WorldMatrix = viewRectMatrix(mr.Scene, r)
+	clr := ms.Color
+	clr.A = 60
+	mr.Material.Color, mr.Material.Emissive = clr, ms.Color
+	mr.Solid.PreRender()
+}
+
+// viewRectMatrix returns the world matrix putting a unit quad facing the
+// camera of the given scene over the given pixels of its view, just past
+// the near plane of the camera
+func viewRectMatrix(sc *xyz.Scene, r image.Rectangle) math32.Matrix4 {
+	cm := &sc.Camera
+	sz := sc.Geom.Size
+	depth := 2 * cm.Near
+	height := 2 * math32.Tan(math32.DegToRad(cm.FOV*0.5))
+	if cm.Ortho {
+		height *= cm.Far
+	} else {
+		height *= depth
+	}
+	pixel := height / float32(max(sz.Y, 1))
+	ctr := math32.FromPoint(r.Min.Add(r.Max)).MulScalar(0.5).Sub(math32.FromPoint(sz).MulScalar(0.5))
+	pos := math32.Vec3(ctr.X*pixel, -ctr.Y*pixel, -depth).MulMatrix4(&cm.Pose.Matrix)
+	var m math32.Matrix4
+	m.SetTransform(pos, cm.Pose.Quat, math32.Vec3(float32(r.Dx())*pixel, float32(r.Dy())*pixel, 1))
+	return m
+}
+
+func (mr *marqueeRect) Render(rp *wgpu.RenderPassEncoder) {
+	if mr.shown {
+		mr.Solid.Render(rp)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/text/text"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// MeasureName is the name of the group showing a measurement
+// of a [MeasureDistanceTool]
+const MeasureName = "__Measure"
+
+// MeasureDistanceTool measures the distance between two points on the
+// solids of a scene widget while it is Active: each left click drops a
+// point on the solid under the mouse, shown as a small sphere, and once
+// there are two, a line joins them with a label of the distance between
+// them, in world units. A click after that starts a new measurement,
+// and Escape clears it, as does deactivating the tool. Clicks do not
+// select nodes while it is active.
+type MeasureDistanceTool struct {
+	// Active is whether clicks drop points
+	Active bool `edit:"-"`
+
+	// Color of the points, line and label
+	Color color.RGBA
+
+	// Points that have been dropped, in world coordinates
+	Points []math32.Vector3 `edit:"-"`
+
+	// Scene widget that the tool is attached to
+	SceneWidget *xyzcore.Scene `set:"-"`
+}
+
+// NewMeasureDistanceTool returns a new inactive measure tool
+func NewMeasureDistanceTool() *MeasureDistanceTool {
+	return &MeasureDistanceTool{Color: colors.Orange}
+}
+
+// Attach lets clicks in the given scene widget drop
+// points while the tool is active
+func (mt *MeasureDistanceTool) Attach(sw *xyzcore.Scene) {
+	if mt.SceneWidget != nil {
+		return
+	}
+	mt.SceneWidget = sw
+	sw.On(events.MouseDown, func(e events.Event) {
+		if mt.Active && e.MouseButton() == events.Left {
+			e.SetHandled() // not selecting
+		}
+	})
+	sw.On(events.Click, func(e events.Event) {
+		if !mt.Active || e.MouseButton() != events.Left {
+			return
+		}
+		e.SetHandled()
+		if p, ok := mt.pointAt(e); ok {
+			if len(mt.Points) == 2 {
+				mt.Points = nil
+			}
+			mt.AddPoint(p)
+		}
+	})
+	sw.On(events.KeyChord, func(e events.Event) {
+		if mt.Active && e.KeyChord() == "Escape" {
+			e.SetHandled()
+			mt.Clear()
+		}
+	})
+}
+
+// SetActive activates or deactivates the tool,
+// clearing the measurement when deactivating
+func (mt *MeasureDistanceTool) SetActive(active bool) {
+	mt.Active = active
+	if !active {
+		mt.Clear()
+	}
+}
+
+// AddPoint drops a point at the given world position,
+// ignoring it if there are already two
+func (mt *MeasureDistanceTool) AddPoint(p math32.Vector3) {
+	if len(mt.Points) >= 2 {
+		return
+	}
+	mt.Points = append(mt.Points, p)
+	mt.update()
+}
+
+// Clear removes the points
+func (mt *MeasureDistanceTool) Clear() {
+	mt.Points = nil
+	mt.update()
+}
+
+// Distance returns the distance between the two points,
+// or false if there are not two yet
+func (mt *MeasureDistanceTool) Distance() (float32, bool) {
+	if len(mt.Points) < 2 {
+		return 0, false
+	}
+	return mt.Points[0].DistanceTo(mt.Points[1]), true
+}
+
+// pointAt returns the world position on the nearest
+// solid under the mouse of the given event, if any
+func (mt *MeasureDistanceTool) pointAt(e events.Event) (math32.Vector3, bool) {
+	sw := mt.SceneWidget
+	bb := sw.Geom.ContentBBox
+	if bb.Empty() {
+		return math32.Vector3{}, false
+	}
+	sc := sw.SceneXYZ()
+	pos := e.Pos().Sub(bb.Min)
+	vp := math32.FromPoint(bb.Size())
+	_, _, dist, ok := PickAtPixel(sc, pos.X, pos.Y, vp)
+	if !ok {
+		return math32.Vector3{}, false
+	}
+	ray := pixelRay(&sc.Camera, math32.Vec2(float32(pos.X)+0.5, float32(pos.Y)+0.5), vp)
+	return ray.At(dist), true
+}
+
+// update shows the points, and the line and label once there are two
+func (mt *MeasureDistanceTool) update() {
+	sw := mt.SceneWidget
+	if sw == nil {
+		return
+	}
+	sc := sw.SceneXYZ()
+	sc.DeleteChildByName(MeasureName)
+	defer func() {
+		sc.SetNeedsUpdate()
+		sw.NeedsRender()
+	}()
+	if len(mt.Points) == 0 {
+		return
+	}
+	gp := xyz.NewGroup(sc)
+	gp.SetName(MeasureName)
+	size := 0.01 * max(sc.Camera.DistanceTo(sc.Camera.Target), 1) // as for the manipulation box
+	pt := xyz.NewSphere(sc, MeasureName+"Point", size, 16)
+	for i, p := range mt.Points {
+		sld := xyz.NewSolid(gp).SetMesh(pt)
+		sld.SetName(fmt.Sprintf("%sPoint%d", MeasureName, i))
+		sld.Material.Color, sld.Material.Emissive = mt.Color, mt.Color
+		sld.Pose.Pos = p
+	}
+	d, ok := mt.Distance()
+	if !ok {
+		return
+	}
+	ln := xyz.NewLines(sc, MeasureName+"Line", mt.Points, math32.Vec2(size/3, size/3), xyz.OpenLines)
+	line := xyz.NewSolid(gp).SetMesh(ln)
+	line.SetName(MeasureName + "Line")
+	line.Material.Color, line.Material.Emissive = mt.Color, mt.Color
+
+	label := NewText2D(gp)
+	label.SetName(MeasureName + "Label")
+	label.Billboard = true
+	label.Styles.Text.Align = text.Center
+	label.Styles.Text.AlignV = text.End
+	label.Styles.Color = colors.Uniform(colors.White)
+	label.BackgroundColor = color.RGBA{0, 0, 0, 160}
+	label.Pose.Scale.SetScalar(4 * size)
+	label.Padding.Set(size, size)
+	label.Pose.Pos = mt.Points[0].Add(mt.Points[1]).MulScalar(0.5).Add(math32.Vec3(0, 2*size, 0))
+	label.Text = fmt.Sprintf("%.3f", d)
+	if sc.TextShaper != nil { // already configured
+		label.Config()
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// MiniMapName is the name of the view of a [MiniMap],
+// and of its texture
+const MiniMapName = "__MiniMap"
+
+// MiniMap shows a small map of the scene of a scene widget in the bottom
+// right corner of its view, looking straight down on it with the X axis
+// to the right and the Z axis down. The solids are drawn as rectangles of
+// their color over their world bounding boxes, the highest on top, and
+// the camera as a point with the edges of its view. Clicking or dragging
+// on the map moves the camera over that point, keeping its height and
+// direction. As for the [StatsOverlay], it is a quad in the scene, just
+// past the near plane of the camera, drawn on each frame tick of the
+// widget while it is Visible. The ToggleKey shows and hides it.
+type MiniMap struct {
+	// Visible is whether the map is shown
+	Visible bool
+
+	// Size is the width and height of the map, in pixels
+	Size int
+
+	// ToggleKey shows and hides the map, as a [Shortcut]
+	// of the body of the scene widget registered by Attach
+	ToggleKey key.Chord
+
+	// Orbit is the orbit controller of the camera, if any,
+	// whose target is moved along with the camera
+	Orbit *OrbitController
+
+	// Scene widget that the map is shown on
+	SceneWidget *xyzcore.Scene `set:"-"`
+
+	// view showing the map
+	view *miniMapView
+
+	// image of the map, and whether it has
+	// changed since it was uploaded
+	img     *image.RGBA
+	changed bool
+
+	// corner and size of the area of the XZ plane shown on the map
+	min  math32.Vector2
+	span float32
+
+	// whether the mouse was pressed on the map
+	pressed bool
+}
+
+// NewMiniMap returns a new hidden map of 160 pixels, toggled with F4
+func NewMiniMap() *MiniMap {
+	return &MiniMap{Size: 160, ToggleKey: "F4"}
+}
+
+// Attach adds the map to the given scene widget, lets clicks on it
+// move the camera, and lets the ToggleKey show and hide it
+func (mm *MiniMap) Attach(sw *xyzcore.Scene) {
+	if mm.SceneWidget != nil {
+		return
+	}
+	mm.SceneWidget = sw
+	sc := sw.SceneXYZ()
+	mm.view = tree.New[miniMapView](sc)
+	mm.view.SetName(MiniMapName)
+	mm.view.miniMap = mm
+	mm.view.SetMesh(sc.PlaneMesh2D())
+	mm.view.Material.Color = colors.White
+	mm.view.Material.Bright = 4 // as for text, so the colors show as they are
+	sw.Animate(func(a *core.Animation) {
+		if mm.Visible && mm.draw() {
+			sw.NeedsRender()
+		}
+	})
+	if mm.ToggleKey != "" {
+		RegisterShortcut(sw.Scene.Body, mm.ToggleKey, "Show or hide the map of the scene", func() {
+			mm.SetVisible(!mm.Visible)
+		})
+	}
+
+	// these are added after the scene widget handlers, so they are
+	// called first, and keep it from selecting what is behind the map
+	// and from orbiting the camera while dragging on it
+	sw.On(events.MouseDown, func(e events.Event) {
+		mm.pressed = false
+		pos := e.Pos().Sub(sw.Geom.ContentBBox.Min)
+		if !mm.Visible || e.MouseButton() != events.Left || !pos.In(mm.rect()) {
+			return
+		}
+		e.SetHandled()
+		mm.pressed = true
+		mm.moveTo(pos)
+	})
+	sw.On(events.SlideStart, func(e events.Event) {
+		if mm.pressed {
+			e.SetHandled()
+		}
+	})
+	sw.On(events.SlideMove, func(e events.Event) {
+		if mm.pressed {
+			e.SetHandled()
+			mm.moveTo(e.Pos().Sub(sw.Geom.ContentBBox.Min))
+		}
+	})
+	sw.On(events.SlideStop, func(e events.Event) {
+		if mm.pressed {
+			e.SetHandled()
+			mm.pressed = false
+		}
+	})
+}
+
+// SetVisible shows or hides the map
+func (mm *MiniMap) SetVisible(visible bool) {
+	mm.Visible = visible
+	if mm.SceneWidget == nil {
+		return
+	}
+	if visible {
+		mm.draw()
+	}
+	mm.SceneWidget.NeedsRender()
+}
+
+// MoveCamera moves the camera over the given point of the XZ plane,
+// keeping its height and direction, and the Orbit target with it
+func (mm *MiniMap) MoveCamera(x, z float32) {
+	sw := mm.SceneWidget
+	cm := &sw.SceneXYZ().Camera
+	del := math32.Vec3(x-cm.Pose.Pos.X, 0, z-cm.Pose.Pos.Z)
+	if mm.Orbit != nil && mm.Orbit.SceneWidget == sw {
+		mm.Orbit.SetTarget(mm.Orbit.Target.Add(del))
+		return
+	}
+	cm.Pose.Pos.SetAdd(del)
+	cm.Target.SetAdd(del)
+	cm.LookAtTarget()
+	sw.SceneXYZ().SetNeedsRender()
+	sw.NeedsRender()
+}
+
+// rect returns the pixels of the view covered by the map
+func (mm *MiniMap) rect() image.Rectangle {
+	const margin = 8
+	sz := mm.SceneWidget.SceneXYZ().Geom.Size
+	return image.Rect(sz.X-margin-mm.Size, sz.Y-margin-mm.Size, sz.X-margin, sz.Y-margin)
+}
+
+// moveTo moves the camera over the point of the map
+// at the given pixel of the view, if it is on the map
+func (mm *MiniMap) moveTo(pos image.Point) {
+	r := mm.rect()
+	if mm.span == 0 || !pos.In(r) {
+		return
+	}
+	p := math32.FromPoint(pos.Sub(r.Min)).AddScalar(0.5).MulScalar(mm.span / float32(mm.Size))
+	mm.MoveCamera(mm.min.X+p.X, mm.min.Y+p.Y)
+}
+
+// mapPixel returns the pixel of the map over the given point of the XZ plane
+func (mm *MiniMap) mapPixel(x, z float32) math32.Vector2 {
+	return math32.Vec2(x-mm.min.X, z-mm.min.Y).MulScalar(float32(mm.Size) / mm.span)
+}
+
+// draw draws the map of the scene and its camera, returning
+// whether it has changed since it was last drawn
+func (mm *MiniMap) draw() bool {
+	sc := mm.SceneWidget.SceneXYZ()
+	type footprint struct {
+		bb  math32.Box3
+		clr color.RGBA
+	}
+	var fps []footprint
+	var bounds math32.Box3
+	bounds.SetEmpty()
+	sc.WalkDown(func(k tree.Node) bool {
+		if isReservedNode(k) {
+			return tree.Break
+		}
+		switch k.(type) {
+		case *Annotation, *Text2D, *xyz.Text2D:
+			return tree.Break
+		}
+		nd, ok := k.(xyz.Node)
+		if !ok || isHidden(nd) {
+			return tree.Continue
+		}
+		if sld := nd.AsSolid(); sld != nil && !sld.WorldBBox.BBox.IsEmpty() {
+			fps = append(fps, footprint{sld.WorldBBox.BBox, sld.Material.Color})
+			bounds.ExpandByBox(sld.WorldBBox.BBox)
+		}
+		return tree.Continue
+	})
+	if bounds.IsEmpty() {
+		bounds.Set(&math32.Vector3{X: -5, Z: -5}, &math32.Vector3{X: 5, Z: 5})
+	}
+	// a square around the solids, with a margin
+	ctr := bounds.Center()
+	sz := bounds.Size()
+	mm.span = 1.2 * max(sz.X, sz.Z, 1)
+	mm.min = math32.Vec2(ctr.X-0.5*mm.span, ctr.Z-0.5*mm.span)
+
+	img := image.NewRGBA(image.Rect(0, 0, mm.Size, mm.Size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{0, 0, 0, 160}), image.Point{}, draw.Src)
+	slices.SortStableFunc(fps, func(a, b footprint) int {
+		return int(math32.Sign(a.bb.Max.Y - b.bb.Max.Y))
+	})
+	for _, fp := range fps {
+		lo, hi := mm.mapPixel(fp.bb.Min.X, fp.bb.Min.Z), mm.mapPixel(fp.bb.Max.X, fp.bb.Max.Z)
+		r := image.Rect(int(lo.X), int(lo.Y), max(int(hi.X), int(lo.X)+1), max(int(hi.Y), int(lo.Y)+1))
+		clr := fp.clr
+		clr.A = max(clr.A, 160)
+		draw.Draw(img, r, image.NewUniform(clr), image.Point{}, draw.Over)
+	}
+	mm.drawCamera(img, &sc.Camera)
+
+	if mm.img != nil && bytes.Equal(img.Pix, mm.img.Pix) {
+		return false
+	}
+	mm.img = img
+	mm.changed = true
+	return true
+}
+
+// drawCamera draws the given camera on the given image of the map,
+// as a point with the edges of its horizontal field of view
+func (mm *MiniMap) drawCamera(img *image.RGBA, cm *xyz.Camera) {
+	clr := color.RGBA{255, 255, 255, 255}
+	p := mm.mapPixel(cm.Pose.Pos.X, cm.Pose.Pos.Z)
+	fwd := math32.Vec3(0, 0, -1).MulQuat(cm.Pose.Quat)
+	dir := math32.Vec2(fwd.X, fwd.Z)
+	if dir.Length() < 1e-3 { // looking straight down or up
+		up := math32.Vec3(0, 1, 0).MulQuat(cm.Pose.Quat)
+		dir = math32.Vec2(up.X, up.Z)
+	}
+	dir = dir.Normal().MulScalar(0.4 * float32(mm.Size))
+	half := math32.Atan(math32.Tan(math32.DegToRad(cm.FOV*0.5)) * cm.Aspect)
+	c, s := math32.Cos(half), math32.Sin(half)
+	left := p.Add(math32.Vec2(dir.X*c+dir.Y*s, dir.Y*c-dir.X*s))
+	right := p.Add(math32.Vec2(dir.X*c-dir.Y*s, dir.Y*c+dir.X*s))
+	drawLine(img, p, left, clr)
+	drawLine(img, p, right, clr)
+	drawLine(img, left, right, clr)
+	x, y := int(p.X), int(p.Y)
+	draw.Draw(img, image.Rect(x-2, y-2, x+3, y+3), image.NewUniform(clr), image.Point{}, draw.Src)
+}
+
+// drawLine draws a line of the given color between the given
+// points of the given image, skipping the pixels outside of it
+func drawLine(img *image.RGBA, from, to math32.Vector2, clr color.RGBA) {
+	d := to.Sub(from)
+	n := int(max(math32.Abs(d.X), math32.Abs(d.Y))) + 1
+	for i := range n + 1 {
+		p := from.Add(d.MulScalar(float32(i) / float32(n)))
+		img.SetRGBA(int(math32.Floor(p.X)), int(math32.Floor(p.Y)), clr)
+	}
+}
+
+// miniMapView is the view of a [MiniMap], which stays in the bottom
+// right corner of the view and is only drawn while it is visible
+type miniMapView struct {
+	xyz.Solid
+
+	// map shown
+	miniMap *MiniMap
+
+	// whether it was shown on the last render
+	shown bool
+}
+
+// PreRender places the map over its pixels in the view of the camera,
+// which the scene has just updated, uploading its image if it has
+// changed, and clears its window bounding box so that clicks on it
+// do not select it
+func (mv *miniMapView) PreRender() {
+	mv.SceneBBox = image.Rectangle{}
+	mm := mv.miniMap
+	mv.shown = mm.Visible && mm.img != nil
+	if !mv.shown {
+		return
+	}
+	if mm.changed || mv.Material.Texture == nil {
+		tx := &xyz.TextureBase{Name: MiniMapName, Transparent: true, RGBA: mm.img}
+		mv.Scene.SetTexture(tx)
+		mv.Material.SetTexture(tx)
+		mm.changed = false
+	}
+	mv.Pose.WorldMatrix = viewRectMatrix(mv.Scene, mm.rect())
+	mv.Solid.PreRender()
+}
+
+func (mv *miniMapView) Render(rp *wgpu.RenderPassEncoder) {
+	if mv.shown {
+		mv.Solid.Render(rp)
+	}
+}
+
+// RenderClass draws the map with the transparent solids,
+// as its background is semitransparent
+func (mv *miniMapView) RenderClass() xyz.RenderClasses {
+	return xyz.RClassTransTexture
+}
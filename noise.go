@@ -0,0 +1,258 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "cogentcore.org/core/math32"
+
+// perlinPerm is the permutation of 0 to 255 of Ken Perlin's
+// reference implementation of his improved noise, given twice,
+// so that the hashes of neighboring lattice points need no wrapping
+var perlinPerm = func() [512]int {
+	p := [256]int{
+		151, 160, 137, 91, 90, 15, 131, 13, 201, 95, 96, 53, 194, 233, 7, 225,
+		140, 36, 103, 30, 69, 142, 8, 99, 37, 240, 21, 10, 23, 190, 6, 148,
+		247, 120, 234, 75, 0, 26, 197, 62, 94, 252, 219, 203, 117, 35, 11, 32,
+		57, 177, 33, 88, 237, 149, 56, 87, 174, 20, 125, 136, 171, 168, 68, 175,
+		74, 165, 71, 134, 139, 48, 27, 166, 77, 146, 158, 231, 83, 111, 229, 122,
+		60, 211, 133, 230, 220, 105, 92, 41, 55, 46, 245, 40, 244, 102, 143, 54,
+		65, 25, 63, 161, 1, 216, 80, 73, 209, 76, 132, 187, 208, 89, 18, 169,
+		200, 196, 135, 130, 116, 188, 159, 86, 164, 100, 109, 198, 173, 186, 3, 64,
+		52, 217, 226, 250, 124, 123, 5, 202, 38, 147, 118, 126, 255, 82, 85, 212,
+		207, 206, 59, 227, 47, 16, 58, 17, 182, 189, 28, 42, 223, 183, 170, 213,
+		119, 248, 152, 2, 44, 154, 163, 70, 221, 153, 101, 155, 167, 43, 172, 9,
+		129, 22, 39, 253, 19, 98, 108, 110, 79, 113, 224, 232, 178, 185, 112, 104,
+		218, 246, 97, 228, 251, 34, 242, 193, 238, 210, 144, 12, 191, 179, 162, 241,
+		81, 51, 145, 235, 249, 14, 239, 107, 49, 192, 214, 31, 181, 199, 106, 157,
+		184, 84, 204, 176, 115, 121, 50, 45, 127, 4, 150, 254, 138, 236, 205, 93,
+		222, 114, 67, 29, 24, 72, 243, 141, 128, 195, 78, 66, 215, 61, 156, 180,
+	}
+	var pp [512]int
+	for i := range pp {
+		pp[i] = p[i&255]
+	}
+	return pp
+}()
+
+// PerlinNoise2D returns the gradient noise of Ken Perlin at the given
+// point, which varies smoothly from about -1 to 1 over distances of
+// about 1, and is 0 at the points with integer coordinates. It repeats
+// every 256 units along each axis.
+func PerlinNoise2D(x, y float32) float32 {
+	xi, yi, xf, yf := latticeCell(x), latticeCell(y), x-math32.Floor(x), y-math32.Floor(y)
+	u, v := perlinFade(xf), perlinFade(yf)
+	p := &perlinPerm
+	a, b := p[xi]+yi, p[xi+1]+yi
+	return math32.Lerp(
+		math32.Lerp(perlinGrad2(p[a], xf, yf), perlinGrad2(p[b], xf-1, yf), u),
+		math32.Lerp(perlinGrad2(p[a+1], xf, yf-1), perlinGrad2(p[b+1], xf-1, yf-1), u), v)
+}
+
+// PerlinNoise3D returns the improved gradient noise of Ken Perlin at the
+// given point, as for [PerlinNoise2D] in three dimensions
+func PerlinNoise3D(x, y, z float32) float32 {
+	xi, yi, zi := latticeCell(x), latticeCell(y), latticeCell(z)
+	xf, yf, zf := x-math32.Floor(x), y-math32.Floor(y), z-math32.Floor(z)
+	u, v, w := perlinFade(xf), perlinFade(yf), perlinFade(zf)
+	p := &perlinPerm
+	a, b := p[xi]+yi, p[xi+1]+yi
+	aa, ab, ba, bb := p[a]+zi, p[a+1]+zi, p[b]+zi, p[b+1]+zi
+	return math32.Lerp(
+		math32.Lerp(
+			math32.Lerp(perlinGrad3(p[aa], xf, yf, zf), perlinGrad3(p[ba], xf-1, yf, zf), u),
+			math32.Lerp(perlinGrad3(p[ab], xf, yf-1, zf), perlinGrad3(p[bb], xf-1, yf-1, zf), u), v),
+		math32.Lerp(
+			math32.Lerp(perlinGrad3(p[aa+1], xf, yf, zf-1), perlinGrad3(p[ba+1], xf-1, yf, zf-1), u),
+			math32.Lerp(perlinGrad3(p[ab+1], xf, yf-1, zf-1), perlinGrad3(p[bb+1], xf-1, yf-1, zf-1), u), v), w)
+}
+
+// FractionalBrownianMotion returns the sum of the given number of octaves
+// of [PerlinNoise2D] at the given point, each at lacunarity times the
+// frequency and gain times the amplitude of the one before, as for the
+// roughness of terrain. The sum is divided by the sum of the amplitudes,
+// so it stays from about -1 to 1. It is 0 for no octaves.
+func FractionalBrownianMotion(x, y float32, octaves int, lacunarity, gain float32) float32 {
+	var sum, total float32
+	freq, amp := float32(1), float32(1)
+	for range octaves {
+		sum += amp * PerlinNoise2D(x*freq, y*freq)
+		total += amp
+		freq *= lacunarity
+		amp *= gain
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// latticeCell returns the index in the permutation of the
+// lattice cell holding the given coordinate
+func latticeCell(x float32) int {
+	return int(math32.Floor(x)) & 255
+}
+
+// perlinFade is the quintic curve easing the
+// interpolation between lattice points
+func perlinFade(t float32) float32 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// perlinGrad2 returns the dot product of the given offset from a lattice
+// point with one of eight gradients, chosen by the hash of the point
+func perlinGrad2(hash int, x, y float32) float32 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
+
+// perlinGrad3 returns the dot product of the given offset from a lattice
+// point with one of the twelve gradients to the middles of the edges of
+// a cube, chosen by the hash of the point, as in the reference
+func perlinGrad3(hash int, x, y, z float32) float32 {
+	h := hash & 15
+	u, v := y, z
+	if h < 8 {
+		u = x
+	}
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+// skewing factors from the square lattice of 2D noise to the
+// triangles of 2D simplex noise, and back, and the same for
+// cubes and tetrahedra in 3D
+var (
+	simplexF2 = 0.5 * (math32.Sqrt(3) - 1)
+	simplexG2 = (3 - math32.Sqrt(3)) / 6
+)
+
+const (
+	simplexF3 = float32(1) / 3
+	simplexG3 = float32(1) / 6
+)
+
+// SimplexNoise2D returns the simplex noise of Ken Perlin at the given
+// point, which varies smoothly from -1 to 1 over distances of about 1.
+// It sums the gradients of the three corners of the triangle holding the
+// point, rather than the four of a square, so it has less of the grid
+// showing along the axes and diagonals than [PerlinNoise2D].
+func SimplexNoise2D(x, y float32) float32 {
+	// the triangle holding the point, and the offsets from its corners
+	s := (x + y) * simplexF2
+	i, j := floorInt(x+s), floorInt(y+s)
+	t := float32(i+j) * simplexG2
+	x0, y0 := x-(float32(i)-t), y-(float32(j)-t)
+	var i1, j1 int
+	if x0 > y0 {
+		i1 = 1
+	} else {
+		j1 = 1
+	}
+	x1, y1 := x0-float32(i1)+simplexG2, y0-float32(j1)+simplexG2
+	x2, y2 := x0-1+2*simplexG2, y0-1+2*simplexG2
+
+	p := &perlinPerm
+	ii, jj := i&255, j&255
+	n := simplexCorner2(p[ii+p[jj]], x0, y0) +
+		simplexCorner2(p[ii+i1+p[jj+j1]], x1, y1) +
+		simplexCorner2(p[ii+1+p[jj+1]], x2, y2)
+	return math32.Clamp(70*n, -1, 1)
+}
+
+// SimplexNoise3D returns the simplex noise of Ken Perlin at the given
+// point, as for [SimplexNoise2D] in three dimensions, from the four
+// corners of a tetrahedron, which is less work than the eight corners
+// of a cube of [PerlinNoise3D]
+func SimplexNoise3D(x, y, z float32) float32 {
+	// the tetrahedron holding the point, and the offsets from its corners
+	s := (x + y + z) * simplexF3
+	i, j, k := floorInt(x+s), floorInt(y+s), floorInt(z+s)
+	t := float32(i+j+k) * simplexG3
+	x0, y0, z0 := x-(float32(i)-t), y-(float32(j)-t), z-(float32(k)-t)
+	var i1, j1, k1, i2, j2, k2 int
+	switch {
+	case x0 >= y0 && y0 >= z0:
+		i1, i2, j2 = 1, 1, 1
+	case x0 >= y0 && x0 >= z0:
+		i1, i2, k2 = 1, 1, 1
+	case x0 >= y0:
+		k1, i2, k2 = 1, 1, 1
+	case y0 < z0:
+		k1, j2, k2 = 1, 1, 1
+	case x0 < z0:
+		j1, j2, k2 = 1, 1, 1
+	default:
+		j1, i2, j2 = 1, 1, 1
+	}
+	x1, y1, z1 := x0-float32(i1)+simplexG3, y0-float32(j1)+simplexG3, z0-float32(k1)+simplexG3
+	x2, y2, z2 := x0-float32(i2)+2*simplexG3, y0-float32(j2)+2*simplexG3, z0-float32(k2)+2*simplexG3
+	x3, y3, z3 := x0-1+3*simplexG3, y0-1+3*simplexG3, z0-1+3*simplexG3
+
+	p := &perlinPerm
+	ii, jj, kk := i&255, j&255, k&255
+	n := simplexCorner3(p[ii+p[jj+p[kk]]], x0, y0, z0) +
+		simplexCorner3(p[ii+i1+p[jj+j1+p[kk+k1]]], x1, y1, z1) +
+		simplexCorner3(p[ii+i2+p[jj+j2+p[kk+k2]]], x2, y2, z2) +
+		simplexCorner3(p[ii+1+p[jj+1+p[kk+1]]], x3, y3, z3)
+	return math32.Clamp(32*n, -1, 1)
+}
+
+// floorInt returns the given number rounded down to an integer,
+// faster than [math32.Floor], which goes through float64
+func floorInt(x float32) int {
+	i := int(x)
+	if x < float32(i) {
+		i--
+	}
+	return i
+}
+
+// simplexCorner2 returns the part of the 2D simplex noise from the corner
+// with the given hash at the given offset, which falls to 0 at a radius
+// that keeps it inside of the triangles next to the corner
+func simplexCorner2(hash int, x, y float32) float32 {
+	t := 0.5 - x*x - y*y
+	if t < 0 {
+		return 0
+	}
+	t *= t
+	return t * t * perlinGrad2(hash, x, y)
+}
+
+// simplexCorner3 returns the part of the 3D simplex
+// noise from a corner, as for simplexCorner2
+func simplexCorner3(hash int, x, y, z float32) float32 {
+	t := 0.6 - x*x - y*y - z*z
+	if t < 0 {
+		return 0
+	}
+	t *= t
+	return t * t * perlinGrad3(hash, x, y, z)
+}
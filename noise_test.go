@@ -0,0 +1,74 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"cogentcore.org/core/math32"
+)
+
+// noiseHistogram returns the histogram in 20 bins from -1 to 1 of the
+// given noise function at random points, with its smallest and largest
+// values and its mean
+func noiseHistogram(noise func(r *rand.Rand) float32) (bins [20]int, lo, hi, mean float32) {
+	r := rand.New(rand.NewPCG(1, 2))
+	lo, hi = 1, -1
+	const n = 100000
+	for range n {
+		v := noise(r)
+		lo, hi = min(lo, v), max(hi, v)
+		mean += v / n
+		bins[min(max(int((v+1)*10), 0), 19)]++
+	}
+	return
+}
+
+func TestSimplexNoiseHistogram(t *testing.T) {
+	coord := func(r *rand.Rand) float32 { return r.Float32()*512 - 256 }
+	for name, noise := range map[string]func(r *rand.Rand) float32{
+		"2D": func(r *rand.Rand) float32 { return SimplexNoise2D(coord(r), coord(r)) },
+		"3D": func(r *rand.Rand) float32 { return SimplexNoise3D(coord(r), coord(r), coord(r)) },
+	} {
+		bins, lo, hi, mean := noiseHistogram(noise)
+		t.Logf("%s noise from %.3f to %.3f with a mean of %.4f: %v", name, lo, hi, mean, bins)
+		if lo < -1 || hi > 1 {
+			t.Errorf("%s noise goes from %g to %g, outside of -1 to 1", name, lo, hi)
+		}
+		if lo > -0.8 || hi < 0.8 {
+			t.Errorf("%s noise only goes from %g to %g", name, lo, hi)
+		}
+		if math32.Abs(mean) > 0.01 {
+			t.Errorf("%s noise has a mean of %g, not 0", name, mean)
+		}
+		// values fall off evenly on each side of 0, with
+		// all of the range used but few of them at its ends
+		for i := range 10 {
+			if d := bins[i] - bins[19-i]; float32(max(d, -d)) > 0.1*float32(bins[i]+bins[19-i])+50 {
+				t.Errorf("%s noise has %d values in bin %d but %d in bin %d across 0", name, bins[i], i, bins[19-i], 19-i)
+			}
+		}
+		for i, n := range bins {
+			if n == 0 || (i == 0 || i == 19) && n > 2000 {
+				t.Errorf("%s noise has %d of its values in bin %d", name, n, i)
+			}
+		}
+	}
+}
+
+func TestSimplexNoiseSmooth(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for range 1000 {
+		x, y, z := r.Float32()*100, r.Float32()*100, r.Float32()*100
+		const d = 1e-3
+		if dv := math32.Abs(SimplexNoise2D(x+d, y) - SimplexNoise2D(x, y)); dv > 20*d {
+			t.Fatalf("2D noise changes by %g over %g at %g, %g", dv, d, x, y)
+		}
+		if dv := math32.Abs(SimplexNoise3D(x, y, z+d) - SimplexNoise3D(x, y, z)); dv > 20*d {
+			t.Fatalf("3D noise changes by %g over %g at %g, %g, %g", dv, d, x, y, z)
+		}
+	}
+}
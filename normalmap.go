@@ -0,0 +1,145 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+
+	"cogentcore.org/core/base/iox/imagex"
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// SetNormalMap applies the given tangent space normal map to the mesh
+// of the solid, as in glTF files, with X to the right and Y up along the
+// texture coordinates and Z out of the surface. The phong shaders of xyz
+// only light the normals of the vertices, so it can not be sampled for
+// each pixel; instead, the solid gets a copy of its mesh whose normals
+// are those of the map at the texture coordinates of the vertices, turned
+// from tangent space by the [MeshTangents]. That shows detail as fine as
+// the vertices of the mesh, so it needs a finely divided one. Setting it
+// again replaces the map, and nil goes back to the mesh without it.
+func (sld *Solid) SetNormalMap(img image.Image) *Solid {
+	sc := sld.Scene
+	if sld.normalMapSource == "" {
+		sld.normalMapSource = sld.MeshName
+	}
+	src, err := sc.MeshByName(string(sld.normalMapSource))
+	if err != nil {
+		return sld
+	}
+	if img == nil {
+		sld.SetMesh(src)
+		sld.normalMapSource = ""
+		return sld
+	}
+	md := shape.NewMeshData(src)
+	tans := MeshTangents(src)
+	rgba := imagex.AsRGBA(img)
+	nrm := make(math32.ArrayF32, len(md.Normal))
+	for i, t := range tans {
+		var n math32.Vector3
+		var uv math32.Vector2
+		md.Normal.GetVector3(3*i, &n)
+		md.TexCoord.GetVector2(2*i, &uv)
+		if t.W == 0 { // no texture coordinates around it
+			nrm.SetVector3(3*i, n)
+			continue
+		}
+		tan := math32.Vec3(t.X, t.Y, t.Z)
+		bitan := n.Cross(tan).MulScalar(t.W) // along v, up the image
+		m := sampleNormalMap(rgba, uv)
+		nrm.SetVector3(3*i, tan.MulScalar(m.X).Add(bitan.MulScalar(m.Y)).Add(n.MulScalar(m.Z)).Normal())
+	}
+	am := NewArrayMesh(sc, sld.Name+"-normalmap", md.Vertex, nrm, md.TexCoord, md.Colors, md.Index)
+	sld.SetMesh(am)
+	return sld
+}
+
+// MeshTangents returns the unit tangents of the vertices of the given
+// mesh, along its U texture coordinate and at right angles to the normal
+// of each vertex, averaged over the triangles around it. W is 1 or -1,
+// the handedness of the texture coordinates, such that the direction of
+// the V texture coordinate is W times the cross product of the normal and
+// the tangent; it is 0 for the vertices whose triangles have no texture
+// coordinates, whose tangents are 0.
+func MeshTangents(mesh xyz.Mesh) []math32.Vector4 {
+	md := shape.NewMeshData(mesh)
+	nv := md.NumVertex
+	tans := make([]math32.Vector3, nv)
+	bitans := make([]math32.Vector3, nv)
+	vtx := func(i uint32) (math32.Vector3, math32.Vector2) {
+		var v math32.Vector3
+		var uv math32.Vector2
+		md.Vertex.GetVector3(3*int(i), &v)
+		md.TexCoord.GetVector2(2*int(i), &uv)
+		return v, uv
+	}
+	for t := 0; t+2 < len(md.Index); t += 3 {
+		i0, i1, i2 := md.Index[t], md.Index[t+1], md.Index[t+2]
+		if int(max(i0, i1, i2)) >= nv {
+			continue
+		}
+		p0, uv0 := vtx(i0)
+		p1, uv1 := vtx(i1)
+		p2, uv2 := vtx(i2)
+		e1, e2 := p1.Sub(p0), p2.Sub(p0)
+		d1, d2 := uv1.Sub(uv0), uv2.Sub(uv0)
+		r := d1.X*d2.Y - d2.X*d1.Y
+		if math32.Abs(r) < 1e-12 {
+			continue
+		}
+		tan := e1.MulScalar(d2.Y).Sub(e2.MulScalar(d1.Y)).DivScalar(r)
+		bitan := e2.MulScalar(d1.X).Sub(e1.MulScalar(d2.X)).DivScalar(r)
+		for _, i := range [3]uint32{i0, i1, i2} {
+			tans[i] = tans[i].Add(tan)
+			bitans[i] = bitans[i].Add(bitan)
+		}
+	}
+	res := make([]math32.Vector4, nv)
+	for i, tan := range tans {
+		var n math32.Vector3
+		md.Normal.GetVector3(3*i, &n)
+		tan = tan.Sub(n.MulScalar(n.Dot(tan))) // at right angles to the normal
+		if tan.LengthSquared() < 1e-12 {
+			continue
+		}
+		tan = tan.Normal()
+		w := float32(1)
+		if n.Cross(tan).Dot(bitans[i]) < 0 {
+			w = -1
+		}
+		res[i] = math32.Vec4(tan.X, tan.Y, tan.Z, w)
+	}
+	return res
+}
+
+// sampleNormalMap returns the tangent space normal of the given normal
+// map at the given texture coordinates, repeating it outside of 0 to 1,
+// interpolated between its pixels. V goes up the image, as it does for
+// the textures drawn by the phong shaders.
+func sampleNormalMap(img *image.RGBA, uv math32.Vector2) math32.Vector3 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return math32.Vec3(0, 0, 1)
+	}
+	x := (uv.X-math32.Floor(uv.X))*float32(w) - 0.5
+	y := (math32.Ceil(uv.Y)-uv.Y)*float32(h) - 0.5
+	x0, y0 := floorInt(x), floorInt(y)
+	fx, fy := x-float32(x0), y-float32(y0)
+	px := func(x, y int) math32.Vector3 {
+		c := img.RGBAAt(b.Min.X+(x%w+w)%w, b.Min.Y+(y%h+h)%h)
+		return math32.Vec3(float32(c.R), float32(c.G), float32(c.B)).DivScalar(127.5).SubScalar(1)
+	}
+	top := px(x0, y0).Lerp(px(x0+1, y0), fx)
+	bottom := px(x0, y0+1).Lerp(px(x0+1, y0+1), fx)
+	n := top.Lerp(bottom, fy)
+	if n.LengthSquared() < 1e-12 {
+		return math32.Vec3(0, 0, 1)
+	}
+	return n.Normal()
+}
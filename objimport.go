@@ -0,0 +1,385 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// ImportOBJ adds the objects of the Wavefront OBJ file at the given
+// path to the given scene, with the materials of the MTL file at the
+// given path, or if it is empty, of the files of its mtllib directives
+// and of the .mtl file with the same base name next to it if there is
+// one. Each o or g directive starts a demo [Solid] with that name, with
+// an [ArrayMesh] of the vertex positions, normals and texture
+// coordinates of its faces, which are triangulated as fans, and the
+// diffuse color, opacity and shininess of the material set by usemtl.
+// An object whose faces use several materials becomes a group with a
+// solid for each. Names are made unique among the nodes already in the
+// scene.
+func ImportOBJ(sc *xyz.Scene, objPath, mtlPath string) error {
+	oi := &objImporter{sc: sc, dir: filepath.Dir(objPath), mtlGiven: mtlPath != "",
+		mats: map[string]*objMaterial{}}
+	if mtlPath == "" {
+		mtlPath = strings.TrimSuffix(objPath, filepath.Ext(objPath)) + ".mtl"
+		if err := oi.readFile(mtlPath, oi.mtlLine); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	} else if err := oi.readFile(mtlPath, oi.mtlLine); err != nil {
+		return err
+	}
+	if err := oi.readFile(objPath, oi.objLine); err != nil {
+		return err
+	}
+	if err := oi.readMtllibs(); err != nil {
+		return err
+	}
+	base := filepath.Base(objPath)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	for i, ob := range oi.objects {
+		oi.object(ob, base, i)
+	}
+	sc.SetNeedsUpdate()
+	return nil
+}
+
+// objImporter has the state of reading an OBJ file into a scene
+type objImporter struct {
+	sc *xyz.Scene
+
+	// directory of the OBJ file, and the MTL files of its mtllib
+	// directives, which are not read if the MTL file was given
+	dir      string
+	mtlGiven bool
+	mtllibs  []string
+
+	// vertex positions, normals and texture coordinates,
+	// which the faces index
+	pos, nrm, tex math32.ArrayF32
+
+	// materials of the MTL file, and the one being read
+	mats map[string]*objMaterial
+	mat  *objMaterial
+
+	// objects read, the current one, and its current material
+	objects []*objObject
+	cur     *objObject
+	usemtl  string
+
+	// file and line being read, for errors
+	file string
+	line int
+}
+
+// objMaterial is a material of an MTL file
+type objMaterial struct {
+	diffuse color.RGBA
+	shiny   float32
+}
+
+// objObject is an object or group of an OBJ file, with
+// the faces for each material that it uses
+type objObject struct {
+	name  string
+	parts []*objPart
+}
+
+// objPart is the triangles of an object that use one material
+type objPart struct {
+	mat string
+
+	vertex, normal, texcoord math32.ArrayF32
+	index                    math32.ArrayU32
+
+	// verts has the index of each distinct position,
+	// texture coordinate and normal in the mesh
+	verts map[[3]int]uint32
+
+	// noNormal is whether some of the faces have no normals,
+	// so smooth ones are made for all of them
+	noNormal bool
+}
+
+// readFile calls the given function on each line of the given
+// file that is not empty or a comment, split into fields
+func (oi *objImporter) readFile(path string, fun func(fields []string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	oi.file, oi.line = filepath.Base(path), 0
+	return oi.read(f, fun)
+}
+
+// read calls the given function on each line from the given
+// reader that is not empty or a comment, split into fields
+func (oi *objImporter) read(r io.Reader, fun func(fields []string) error) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		oi.line++
+		line, _, _ := strings.Cut(s.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if err := fun(fields); err != nil {
+			return fmt.Errorf("ImportOBJ: %s:%d: %w", oi.file, oi.line, err)
+		}
+	}
+	return s.Err()
+}
+
+// readMtllibs reads the materials of the MTL files of the mtllib
+// directives, relative to the OBJ file, unless the MTL file was given.
+// Missing files are skipped with a warning, as OBJ files are often
+// copied without them.
+func (oi *objImporter) readMtllibs() error {
+	if oi.mtlGiven {
+		return nil
+	}
+	for _, fn := range oi.mtllibs {
+		err := oi.readFile(filepath.Join(oi.dir, filepath.FromSlash(fn)), oi.mtlLine)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Printf("ImportOBJ: skipping missing material file %s\n", fn)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// objFloats returns the fields after the directive as numbers,
+// of which there must be at least the given number
+func objFloats(fields []string, n int) ([]float32, error) {
+	if len(fields)-1 < n {
+		return nil, fmt.Errorf("%s needs %d numbers", fields[0], n)
+	}
+	vs := make([]float32, len(fields)-1)
+	for i, f := range fields[1:] {
+		v, err := strconv.ParseFloat(f, 32)
+		if err != nil {
+			return nil, err
+		}
+		vs[i] = float32(v)
+	}
+	return vs, nil
+}
+
+// mtlLine reads a line of an MTL file
+func (oi *objImporter) mtlLine(fields []string) error {
+	if fields[0] == "newmtl" {
+		oi.mat = &objMaterial{diffuse: color.RGBA{255, 255, 255, 255}}
+		oi.mats[strings.Join(fields[1:], " ")] = oi.mat
+		return nil
+	}
+	if oi.mat == nil {
+		return nil
+	}
+	switch fields[0] {
+	case "Kd":
+		vs, err := objFloats(fields, 3)
+		if err != nil {
+			return err
+		}
+		oi.mat.diffuse = colors.FromFloat32(vs[0], vs[1], vs[2], float32(oi.mat.diffuse.A)/255)
+	case "d", "Tr":
+		vs, err := objFloats(fields, 1)
+		if err != nil {
+			return err
+		}
+		if fields[0] == "Tr" {
+			vs[0] = 1 - vs[0]
+		}
+		oi.mat.diffuse.A = uint8(math32.Clamp(vs[0], 0, 1) * 255)
+	case "Ns":
+		vs, err := objFloats(fields, 1)
+		if err != nil {
+			return err
+		}
+		oi.mat.shiny = vs[0]
+	}
+	return nil
+}
+
+// objLine reads a line of an OBJ file
+func (oi *objImporter) objLine(fields []string) error {
+	switch fields[0] {
+	case "v":
+		vs, err := objFloats(fields, 3)
+		if err != nil {
+			return err
+		}
+		oi.pos.Append(vs[:3]...)
+	case "vn":
+		vs, err := objFloats(fields, 3)
+		if err != nil {
+			return err
+		}
+		oi.nrm.Append(vs[:3]...)
+	case "vt":
+		vs, err := objFloats(fields, 1)
+		if err != nil {
+			return err
+		}
+		if len(vs) < 2 {
+			vs = append(vs, 0)
+		}
+		oi.tex.Append(vs[:2]...)
+	case "o", "g":
+		oi.cur = &objObject{name: strings.Join(fields[1:], " ")}
+		oi.objects = append(oi.objects, oi.cur)
+	case "mtllib":
+		oi.mtllibs = append(oi.mtllibs, fields[1:]...)
+	case "usemtl":
+		oi.usemtl = strings.Join(fields[1:], " ")
+	case "f":
+		return oi.face(fields[1:])
+	}
+	return nil
+}
+
+// face adds the triangles of the face with the given vertices
+// to the current object, as a fan around the first vertex
+func (oi *objImporter) face(verts []string) error {
+	if len(verts) < 3 {
+		return errors.New("face has fewer than 3 vertices")
+	}
+	if oi.cur == nil {
+		oi.cur = &objObject{}
+		oi.objects = append(oi.objects, oi.cur)
+	}
+	ob := oi.cur
+	var pt *objPart
+	for _, p := range ob.parts {
+		if p.mat == oi.usemtl {
+			pt = p
+		}
+	}
+	if pt == nil {
+		pt = &objPart{mat: oi.usemtl, verts: map[[3]int]uint32{}}
+		ob.parts = append(ob.parts, pt)
+	}
+	idx := make([]uint32, len(verts))
+	for i, v := range verts {
+		var err error
+		if idx[i], err = oi.vertex(pt, v); err != nil {
+			return err
+		}
+	}
+	for i := 2; i < len(idx); i++ {
+		pt.index.Append(idx[0], idx[i-1], idx[i])
+	}
+	return nil
+}
+
+// vertex returns the index in the given part of the face
+// vertex given as position/texcoord/normal indexes
+func (oi *objImporter) vertex(pt *objPart, v string) (uint32, error) {
+	key := [3]int{-1, -1, -1}
+	lens := [3]int{len(oi.pos) / 3, len(oi.tex) / 2, len(oi.nrm) / 3}
+	for i, f := range strings.SplitN(v, "/", 3) {
+		if f == "" {
+			continue
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return 0, err
+		}
+		if n < 0 {
+			n += lens[i] // relative to the end
+		} else {
+			n--
+		}
+		if n < 0 || n >= lens[i] {
+			return 0, fmt.Errorf("vertex index %q out of range", v)
+		}
+		key[i] = n
+	}
+	if key[0] < 0 {
+		return 0, fmt.Errorf("vertex %q has no position", v)
+	}
+	if vi, ok := pt.verts[key]; ok {
+		return vi, nil
+	}
+	vi := uint32(len(pt.vertex) / 3)
+	pt.verts[key] = vi
+	pt.vertex.Append(oi.pos[3*key[0] : 3*key[0]+3]...)
+	if key[1] >= 0 {
+		pt.texcoord.Append(oi.tex[2*key[1] : 2*key[1]+2]...)
+	} else {
+		pt.texcoord.Append(0, 0)
+	}
+	if key[2] >= 0 {
+		pt.normal.Append(oi.nrm[3*key[2] : 3*key[2]+3]...)
+	} else {
+		pt.normal.Append(0, 0, 0)
+		pt.noNormal = true
+	}
+	return vi, nil
+}
+
+// object adds the given object, the one with the given index
+// in the file with the given base name, to the scene
+func (oi *objImporter) object(ob *objObject, base string, i int) {
+	if len(ob.parts) == 0 {
+		return
+	}
+	name := ob.name
+	if name == "" {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	var nd xyz.Node
+	if len(ob.parts) == 1 {
+		nd = oi.solid(oi.sc, ob.parts[0], name)
+	} else {
+		nd = xyz.NewGroup(oi.sc)
+		for pi, pt := range ob.parts {
+			oi.solid(nd, pt, fmt.Sprintf("%s-%d", name, pi))
+		}
+	}
+	nd.AsTree().SetName(name)
+	setUniqueName(oi.sc, nd)
+}
+
+// solid adds a solid with the given name to the given parent, with
+// a mesh of the triangles of the given part and its material
+func (oi *objImporter) solid(parent tree.Node, pt *objPart, name string) *Solid {
+	meshName := name
+	if _, err := oi.sc.MeshByName(meshName); err == nil {
+		meshName = fmt.Sprintf("%s-%d", meshName, len(oi.sc.Meshes.Order))
+	}
+	nrm := pt.normal
+	if pt.noNormal {
+		nrm = nil
+	}
+	NewArrayMesh(oi.sc, meshName, pt.vertex, nrm, pt.texcoord, nil, pt.index)
+	sld := NewSolid(parent)
+	sld.SetName(name)
+	sld.SetMeshName(meshName)
+	sld.Material.CullBack = false // the winding of faces in OBJ files is often inconsistent
+	if mt := oi.mats[pt.mat]; mt != nil {
+		sld.SetColor(mt.diffuse)
+		if mt.shiny > 0 {
+			sld.Material.Shiny = mt.shiny
+		}
+	}
+	return sld
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// testOBJ is a unit cube of two objects, the bottom half in red and
+// the top half in two materials, with the materials in testMTL
+const testOBJ = `mtllib test.mtl
+v 0 0 0
+v 1 0 0
+v 1 0 1
+v 0 0 1
+v 0 1 0
+v 1 1 0
+v 1 1 1
+v 0 1 1
+o bottom
+usemtl red
+f 1 2 3 4
+f 1 2 6 5
+o top
+usemtl red
+f 5 6 7 8
+usemtl blue
+f 4 3 7 8
+`
+
+const testMTL = `newmtl red
+Kd 1 0 0
+Ns 40
+newmtl blue
+Kd 0 0 1
+d 0.5
+`
+
+// writeTestFiles writes the given files to a new directory,
+// returning the path of the first one
+func writeTestFiles(t *testing.T, files ...string) string {
+	dir := t.TempDir()
+	for i := 0; i < len(files); i += 2 {
+		if err := os.WriteFile(filepath.Join(dir, files[i]), []byte(files[i+1]), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, files[0])
+}
+
+func TestImportOBJ(t *testing.T) {
+	path := writeTestFiles(t, "cube.obj", testOBJ, "test.mtl", testMTL)
+	sc := newTestScene()
+	if err := ImportOBJ(sc, path, ""); err != nil {
+		t.Fatal(err)
+	}
+	updateTestScene(sc)
+	if n := sc.NumChildren(); n != 2 {
+		t.Fatalf("imported %d objects instead of 2", n)
+	}
+
+	bottom, ok := sc.ChildByName("bottom", 0).(*Solid)
+	if !ok {
+		t.Fatal("the bottom object is not a solid")
+	}
+	if bb, want := bottom.WorldBBox.BBox, math32.B3(0, 0, 0, 1, 1, 1); bb != want {
+		t.Errorf("the bottom has the bounding box %v instead of %v", bb, want)
+	}
+	if c := bottom.Material.Color; c.R != 255 || c.G != 0 || c.B != 0 || bottom.Material.Shiny != 40 {
+		t.Errorf("the bottom has the color %v and shininess %g, not the red material", c, bottom.Material.Shiny)
+	}
+
+	top, ok := sc.ChildByName("top", 0).(*xyz.Group)
+	if !ok || top.NumChildren() != 2 {
+		t.Fatal("the top object is not a group of a solid for each material")
+	}
+	if bb, want := top.WorldBBox.BBox, math32.B3(0, 0, 0, 1, 1, 1); bb != want {
+		t.Errorf("the top has the bounding box %v instead of %v", bb, want)
+	}
+	blue := top.Child(1).(*Solid)
+	if c := blue.Material.Color; c.B != 255 || c.A != 127 {
+		t.Errorf("the second part of the top has the color %v, not the blue material", c)
+	}
+}
+
+func TestImportOBJNames(t *testing.T) {
+	path := writeTestFiles(t, "cube.obj", testOBJ)
+	sc := newTestScene()
+	for range 2 {
+		if err := ImportOBJ(sc, path, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var names []string
+	for _, k := range sc.Children {
+		names = append(names, k.AsTree().Name)
+	}
+	if len(names) != 4 || names[0] != "bottom" || names[1] != "top" || names[2] == "bottom" || names[3] == "top" {
+		t.Errorf("importing the file twice made the objects %v", names)
+	}
+}
+
+func TestImportOBJErrors(t *testing.T) {
+	for _, tc := range []struct{ obj, err string }{
+		{"v 1 2\n", "cube.obj:1: v needs 3 numbers"},
+		{"v 1 2 3\nvt\n", "cube.obj:2: vt needs 1 numbers"},
+		{"v 1 2 3\nf 1 2\n", "cube.obj:2: face has fewer than 3 vertices"},
+		{"v 1 2 3\nf 1 2 4\n", `cube.obj:2: vertex index "2" out of range`},
+	} {
+		err := ImportOBJ(newTestScene(), writeTestFiles(t, "cube.obj", tc.obj), "")
+		if err == nil || !strings.HasSuffix(err.Error(), tc.err) {
+			t.Errorf("importing %q gave the error %v, not %q", tc.obj, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// octreeProperty is the scene property holding its [Octree]
+const octreeProperty = "octree"
+
+const (
+	// octreeMaxDepth is the maximum depth of the cells of an octree
+	octreeMaxDepth = 10
+
+	// octreeMaxItems is the number of solids a cell holds before it is split
+	octreeMaxItems = 8
+)
+
+// Octree is a spatial index of the world bounding boxes of the solids
+// of a scene, for finding the solids along a ray or in a box without
+// testing all of them. Solids are kept in the smallest cell that their
+// center is in whose loose bounds hold their whole box, which reach out
+// by half of the cell on each side, so that solids across the edges of
+// cells still go down, while large solids stay near the root, which also
+// holds those outside of the space it covers. Demo [Solid]s are added and
+// reinserted when they are next rendered after being added or moved,
+// and other nodes when they are reported with Moved, on the next query.
+// The whole octree is rebuilt once RebuildAfter solids have moved, as
+// the cells only cover the solids as they were when it was built.
+// Solids with reserved names are left out.
+type Octree struct {
+	// RebuildAfter is the number of moved solids after which
+	// the octree is rebuilt instead of updated
+	RebuildAfter int
+
+	// Scene indexed by the octree
+	Scene *xyz.Scene `set:"-"`
+
+	// root cell, covering all of the solids when built
+	root *octreeCell
+
+	// cells holding each solid, and the boxes they are held with
+	cells map[*xyz.Solid]*octreeCell
+	boxes map[*xyz.Solid]math32.Box3
+
+	// nodes moved since the last query, and the number of
+	// solids updated since the octree was built
+	moved   []xyz.Node
+	updated int
+}
+
+// octreeCell is a cube of space in an [Octree], which is split
+// into eight cells once it holds too many solids
+type octreeCell struct {
+	// bounds is the cube of the cell, and loose is the space
+	// that the boxes of its solids are in, twice as wide
+	bounds, loose math32.Box3
+
+	depth int
+	items []*xyz.Solid
+	kids  []*octreeCell
+}
+
+// BuildOctree builds an octree of the solids of the given scene,
+// replacing any it already has, and keeps it up to date after
+// solids move
+func BuildOctree(sc *xyz.Scene) *Octree {
+	ot := SceneOctree(sc)
+	if ot == nil {
+		ot = &Octree{RebuildAfter: 256, Scene: sc}
+		sc.SetProperty(octreeProperty, ot)
+	}
+	ot.build()
+	return ot
+}
+
+// SceneOctree returns the octree of the given scene, or nil if none
+func SceneOctree(sc *xyz.Scene) *Octree {
+	ot, _ := sc.Property(octreeProperty).(*Octree)
+	return ot
+}
+
+// QueryRay returns the solids of the given scene whose world bounding
+// boxes the given ray hits, nearest first, building an octree of the
+// scene if it has none
+func QueryRay(sc *xyz.Scene, ray math32.Ray) []*xyz.Solid {
+	ot := SceneOctree(sc)
+	if ot == nil {
+		ot = BuildOctree(sc)
+	}
+	return ot.QueryRay(ray)
+}
+
+// QueryRay returns the solids whose world bounding boxes
+// the given ray hits, nearest first
+func (ot *Octree) QueryRay(ray math32.Ray) []*xyz.Solid {
+	hits := ot.rayHits(ray)
+	slds := make([]*xyz.Solid, len(hits))
+	for i, h := range hits {
+		slds[i] = h.sld
+	}
+	return slds
+}
+
+// QueryBox returns the solids whose world bounding
+// boxes overlap the given box
+func (ot *Octree) QueryBox(box math32.Box3) []*xyz.Solid {
+	ot.update()
+	var slds []*xyz.Solid
+	ot.root.walk(func(c *octreeCell) bool {
+		in := c.loose.IntersectsBox(box)
+		if !in && c != ot.root { // the root also holds the solids outside of it
+			return false
+		}
+		for _, sld := range c.items {
+			if ot.boxes[sld].IntersectsBox(box) {
+				slds = append(slds, sld)
+			}
+		}
+		return in
+	})
+	return slds
+}
+
+// Moved records that the given node, and so all of the solids under
+// it, have moved, or been added to or removed from the scene
+func (ot *Octree) Moved(nd xyz.Node) {
+	ot.moved = append(ot.moved, nd)
+}
+
+// octreeMoved records that the given node has moved
+// in the octree of its scene, if it has one
+func octreeMoved(nd xyz.Node) {
+	if sc := nd.AsNodeBase().Scene; sc != nil {
+		if ot := SceneOctree(sc); ot != nil {
+			ot.Moved(nd)
+		}
+	}
+}
+
+// rendered updates the given solid from the world bounding box that
+// the scene has just set for rendering it, if it has moved, or adds
+// it if it was added to the scene after the octree was built
+func (ot *Octree) rendered(sld *xyz.Solid) {
+	if ot.root == nil || sld.Mesh == nil || isReservedNode(sld) {
+		return
+	}
+	if ot.cells[sld] != nil && ot.boxes[sld] == sld.WorldBBox.BBox {
+		return
+	}
+	ot.remove(sld)
+	ot.boxes[sld] = sld.WorldBBox.BBox
+	ot.insert(sld)
+	ot.updated++
+}
+
+// octreeHit is a solid hit by a ray at a distance
+type octreeHit struct {
+	sld  *xyz.Solid
+	dist float32
+}
+
+// rayHits returns the solids whose world bounding boxes the given
+// ray hits, with the distance to where it enters them, nearest first
+func (ot *Octree) rayHits(ray math32.Ray) []octreeHit {
+	ot.update()
+	var hits []octreeHit
+	ot.root.walk(func(c *octreeCell) bool {
+		_, in := RayIntersectAABB(ray, c.loose.Min, c.loose.Max)
+		if !in && c != ot.root { // the root also holds the solids outside of it
+			return false
+		}
+		for _, sld := range c.items {
+			bb := ot.boxes[sld]
+			if t, hit := RayIntersectAABB(ray, bb.Min, bb.Max); hit {
+				hits = append(hits, octreeHit{sld, t})
+			}
+		}
+		return in
+	})
+	slices.SortFunc(hits, func(a, b octreeHit) int {
+		return int(math32.Sign(a.dist - b.dist))
+	})
+	return hits
+}
+
+// build indexes all of the solids of the scene
+func (ot *Octree) build() {
+	ot.moved = nil
+	ot.updated = 0
+	ot.cells = map[*xyz.Solid]*octreeCell{}
+	ot.boxes = map[*xyz.Solid]math32.Box3{}
+	var slds []*xyz.Solid
+	bounds := math32.B3Empty()
+	ot.Scene.WalkDown(func(k tree.Node) bool {
+		if k == ot.Scene.This {
+			return tree.Continue
+		}
+		nd, _ := xyz.AsNode(k)
+		if nd == nil || isReservedNode(k) {
+			return tree.Break
+		}
+		if sld := nd.AsSolid(); sld != nil && sld.Mesh != nil {
+			box := solidWorldBox(sld)
+			ot.boxes[sld] = box
+			bounds.ExpandByBox(box)
+			slds = append(slds, sld)
+		}
+		return tree.Continue
+	})
+	if bounds.IsEmpty() {
+		bounds = math32.B3(-1, -1, -1, 1, 1, 1)
+	}
+	// cubic cells keep the solids evenly spread
+	sz := bounds.Size()
+	half := max(sz.X, sz.Y, sz.Z) / 2
+	ctr := bounds.Center()
+	hv := math32.Vec3(half, half, half)
+	rb := math32.Box3{Min: ctr.Sub(hv), Max: ctr.Add(hv)}
+	ot.root = &octreeCell{bounds: rb, loose: rb}
+	for _, sld := range slds {
+		ot.insert(sld)
+	}
+}
+
+// update reinserts the solids under the nodes that have moved,
+// or rebuilds the octree if many solids have moved
+func (ot *Octree) update() {
+	if ot.root == nil {
+		ot.build()
+		return
+	}
+	for _, nd := range ot.moved {
+		nd.AsTree().WalkDown(func(k tree.Node) bool {
+			if nd, ok := k.(xyz.Node); ok && nd.AsSolid() != nil {
+				ot.reinsert(nd.AsSolid())
+			}
+			return tree.Continue
+		})
+	}
+	ot.moved = nil
+	if ot.RebuildAfter > 0 && ot.updated > ot.RebuildAfter {
+		ot.build()
+	}
+}
+
+// reinsert moves the given solid to the cell for its current box,
+// or removes it if it is no longer in the scene
+func (ot *Octree) reinsert(sld *xyz.Solid) {
+	ot.remove(sld)
+	if sld.Mesh == nil || isReservedNode(sld) || !isAncestor(ot.Scene, sld) {
+		return
+	}
+	ot.boxes[sld] = solidWorldBox(sld)
+	ot.insert(sld)
+	ot.updated++
+}
+
+// remove removes the given solid from its cell
+func (ot *Octree) remove(sld *xyz.Solid) {
+	if c := ot.cells[sld]; c != nil {
+		c.items = slices.DeleteFunc(c.items, func(s *xyz.Solid) bool { return s == sld })
+		delete(ot.cells, sld)
+		delete(ot.boxes, sld)
+	}
+}
+
+// insert adds the given solid, with its box already set,
+// to the smallest cell that holds it
+func (ot *Octree) insert(sld *xyz.Solid) {
+	box := ot.boxes[sld]
+	c := ot.root
+	for {
+		if c.kids == nil && len(c.items) >= octreeMaxItems && c.depth < octreeMaxDepth {
+			ot.split(c)
+		}
+		next := c.kidHolding(box)
+		if next == nil {
+			break
+		}
+		c = next
+	}
+	c.items = append(c.items, sld)
+	ot.cells[sld] = c
+}
+
+// split divides the given cell into eight,
+// moving down the solids that fit in them
+func (ot *Octree) split(c *octreeCell) {
+	ctr := c.bounds.Center()
+	c.kids = make([]*octreeCell, 8)
+	for i := range c.kids {
+		kb := c.bounds
+		for d := math32.X; d <= math32.Z; d++ {
+			if i&(1<<d) == 0 {
+				kb.Max.SetDim(d, ctr.Dim(d))
+			} else {
+				kb.Min.SetDim(d, ctr.Dim(d))
+			}
+		}
+		hs := kb.Size().MulScalar(0.5)
+		lb := math32.Box3{Min: kb.Min.Sub(hs), Max: kb.Max.Add(hs)}
+		c.kids[i] = &octreeCell{bounds: kb, loose: lb, depth: c.depth + 1}
+	}
+	items := c.items
+	c.items = nil
+	for _, sld := range items {
+		k := c.kidHolding(ot.boxes[sld])
+		if k == nil {
+			k = c
+		}
+		k.items = append(k.items, sld)
+		ot.cells[sld] = k
+	}
+}
+
+// kidHolding returns the child cell that the center of the given box
+// is in, if its loose bounds hold all of the box, or nil if not
+func (c *octreeCell) kidHolding(box math32.Box3) *octreeCell {
+	if c.kids == nil {
+		return nil
+	}
+	bc, cc := box.Center(), c.bounds.Center()
+	i := 0
+	for d := math32.X; d <= math32.Z; d++ {
+		if bc.Dim(d) >= cc.Dim(d) {
+			i |= 1 << d
+		}
+	}
+	if k := c.kids[i]; k.loose.ContainsBox(box) {
+		return k
+	}
+	return nil
+}
+
+// walk calls the given function on this cell and, while
+// it returns true, on the cells under it
+func (c *octreeCell) walk(fun func(c *octreeCell) bool) {
+	if c == nil || !fun(c) {
+		return
+	}
+	for _, k := range c.kids {
+		k.walk(fun)
+	}
+}
+
+// solidWorldBox returns the world bounding box of the given solid from
+// its current pose and those of its parents, which may have changed
+// since the scene last updated its WorldBBox
+func solidWorldBox(sld *xyz.Solid) math32.Box3 {
+	return sld.MeshBBox.BBox.MulMatrix4(poseWorldMatrix(sld))
+}
+
+// poseWorldMatrix returns the world matrix of the given node from
+// its current pose and those of its parents, which may have changed
+// since the scene last updated its WorldMatrix
+func poseWorldMatrix(nd xyz.Node) *math32.Matrix4 {
+	world := math32.Identity4()
+	for k := tree.Node(nd); k != nil; k = k.AsTree().Parent {
+		nd, ok := k.(xyz.Node)
+		if !ok {
+			break
+		}
+		ps := &nd.AsNodeBase().Pose
+		m := ComposeTRS(ps.Pos, ps.Quat, ps.Scale)
+		world.MulMatrices(&m, world)
+	}
+	return world
+}
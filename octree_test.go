@@ -0,0 +1,165 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// newTestGrid returns a new scene with a flat grid of n by n unit boxes,
+// spaced 2 apart on the X Z plane and centered on the origin, as for
+// objects on a floor, like those seen from above when picking
+func newTestGrid(n int) *xyz.Scene {
+	sc := newTestScene()
+	box := xyz.NewBox(sc, "box", 1, 1, 1)
+	shape.NewMeshData(box)
+	for i := range n {
+		for j := range n {
+			sld := NewSolid(sc)
+			sld.SetName(fmt.Sprintf("box-%d-%d", i, j))
+			sld.SetMesh(box)
+			sld.Pose.Pos.Set(float32(2*i-n), 0, float32(2*j-n))
+		}
+	}
+	updateTestScene(sc)
+	return sc
+}
+
+// downRay returns the ray going straight down onto the given point
+func downRay(x, z float32) math32.Ray {
+	return *math32.NewRay(math32.Vec3(x, 10, z), math32.Vec3(0, -1, 0))
+}
+
+// solidNames returns the names of the given solids
+func solidNames(slds []*xyz.Solid) []string {
+	names := make([]string, len(slds))
+	for i, sld := range slds {
+		names[i] = sld.Name
+	}
+	return names
+}
+
+func TestOctreeQueryRay(t *testing.T) {
+	sc := newTestGrid(16)
+	ot := BuildOctree(sc)
+	for _, ij := range [][2]int{{0, 0}, {3, 12}, {15, 15}} {
+		want := fmt.Sprintf("box-%d-%d", ij[0], ij[1])
+		ray := downRay(float32(2*ij[0]-16), float32(2*ij[1]-16))
+		got := solidNames(ot.QueryRay(ray))
+		var lin []string
+		for _, h := range rayHitsLinear(sc, ray) {
+			lin = append(lin, h.sld.Name)
+		}
+		if !slices.Equal(got, []string{want}) || !slices.Equal(got, lin) {
+			t.Errorf("the ray down onto %s hit %v, and %v testing all of the boxes", want, got, lin)
+		}
+	}
+	if got := ot.QueryRay(downRay(-17.5, 0)); len(got) != 0 {
+		t.Errorf("the ray down beside the grid hit %v", solidNames(got))
+	}
+}
+
+func TestOctreeQueryBox(t *testing.T) {
+	sc := newTestGrid(16)
+	ot := BuildOctree(sc)
+	got := solidNames(ot.QueryBox(math32.B3(-0.2, -1, -0.2, 2.2, 1, 0.2)))
+	slices.Sort(got)
+	if want := []string{"box-8-8", "box-9-8"}; !slices.Equal(got, want) {
+		t.Errorf("the box over two of the boxes overlaps %v, not %v", got, want)
+	}
+}
+
+func TestOctreeOutsideRoot(t *testing.T) {
+	sc := newTestGrid(4)
+	ot := BuildOctree(sc)
+	far := NewSolid(sc)
+	far.SetName("far")
+	far.SetMesh(sc.Meshes.ValueByKey("box"))
+	far.Pose.Pos.Set(100, 0, 0)
+	updateTestScene(sc)
+	far.PreRender() // adds it to the octree, outside of the root
+	if ot.root.bounds.ContainsBox(ot.boxes[&far.Solid]) {
+		t.Fatal("the far box is inside of the root of the octree")
+	}
+
+	if got := solidNames(ot.QueryRay(downRay(100, 0))); !slices.Equal(got, []string{"far"}) {
+		t.Errorf("the ray down onto the far box added after building hit %v", got)
+	}
+	if got := solidNames(ot.QueryBox(math32.B3(99, -1, -1, 101, 1, 1))); !slices.Equal(got, []string{"far"}) {
+		t.Errorf("the box around the far box added after building overlaps %v", got)
+	}
+}
+
+func TestOctreeMoved(t *testing.T) {
+	sc := newTestGrid(4)
+	ot := BuildOctree(sc)
+	sld := sc.ChildByName("box-0-0", 0).(*Solid)
+	sld.Pose.Pos.Set(1, 0, 1)
+	ot.Moved(sld)
+	if got := solidNames(ot.QueryRay(downRay(1, 1))); !slices.Equal(got, []string{"box-0-0"}) {
+		t.Errorf("the ray down onto the moved box hit %v", got)
+	}
+	if got := ot.QueryRay(downRay(-4, -4)); len(got) != 0 {
+		t.Errorf("the ray down onto where the box was hit %v", solidNames(got))
+	}
+}
+
+// TestOctreeScaling checks that the time to pick one of the boxes of a
+// grid grows with the log of the number of them with the octree, and in
+// proportion to it when testing all of them, from 1k to 16k boxes
+func TestOctreeScaling(t *testing.T) {
+	if testing.Short() {
+		t.Skip("times queries of large scenes")
+	}
+	small, large := newTestGrid(32), newTestGrid(128)
+	octree := func(sc *xyz.Scene) float64 {
+		ot := BuildOctree(sc)
+		ray := downRay(2, 2)
+		return float64(testing.Benchmark(func(b *testing.B) {
+			for b.Loop() {
+				ot.rayHits(ray)
+			}
+		}).NsPerOp())
+	}
+	linear := func(sc *xyz.Scene) float64 {
+		ray := downRay(2, 2)
+		return float64(testing.Benchmark(func(b *testing.B) {
+			for b.Loop() {
+				rayHitsLinear(sc, ray)
+			}
+		}).NsPerOp())
+	}
+	// 16 times the boxes is 1.4 times the depth of the octree
+	if r := octree(large) / octree(small); r > 4 {
+		t.Errorf("the octree took %.1f times as long for 16 times the boxes", r)
+	}
+	if r := linear(large) / linear(small); r < 8 {
+		t.Errorf("testing all of the boxes took only %.1f times as long for 16 times the boxes", r)
+	}
+}
+
+func BenchmarkQueryRay(b *testing.B) {
+	for _, n := range []int{32, 100, 320} {
+		sc := newTestGrid(n)
+		ray := downRay(2, 2)
+		b.Run(fmt.Sprintf("octree-%d", n*n), func(b *testing.B) {
+			ot := BuildOctree(sc)
+			for b.Loop() {
+				ot.rayHits(ray)
+			}
+		})
+		b.Run(fmt.Sprintf("linear-%d", n*n), func(b *testing.B) {
+			for b.Loop() {
+				rayHitsLinear(sc, ray)
+			}
+		})
+	}
+}
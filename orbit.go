@@ -0,0 +1,271 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// OrbitController moves the camera of a scene widget around a target
+// point: dragging rotates around the target, scrolling zooms toward
+// or away from it, and middle or shift dragging pans the target. The
+// left stick of a gamepad rotates and its right stick zooms, from
+// [GamepadAxisEvent]s. On touch screens, dragging one finger rotates,
+// and the [TouchGestures] of two fingers zoom when pinched and roll the
+// view when twisted, instead of zooming the whole window.
+// Dragging a manipulation point of the selected node still moves the
+// node, as with the default navigation.
+type OrbitController struct {
+	// Target is the point the camera orbits around and looks at
+	Target math32.Vector3
+
+	// MinRadius is the minimum distance from the camera to the target
+	MinRadius float32
+
+	// MaxRadius is the maximum distance from the camera to the target
+	MaxRadius float32
+
+	// MinElevation is the minimum angle in degrees of the camera
+	// above the horizontal plane through the target
+	MinElevation float32
+
+	// MaxElevation is the maximum angle in degrees of the camera
+	// above the horizontal plane through the target
+	MaxElevation float32
+
+	// RotateSpeed is the rotation in degrees per pixel dragged
+	RotateSpeed float32
+
+	// StickRotateSpeed is the rotation in degrees per second
+	// of a gamepad stick pushed all the way
+	StickRotateSpeed float32
+
+	// ZoomSpeed is the fraction of the distance zoomed per scroll step
+	ZoomSpeed float32
+
+	// PanSpeed is the pan distance per pixel dragged,
+	// as a fraction of the distance to the target
+	PanSpeed float32
+
+	// Scene widget that the controller is attached to
+	SceneWidget *xyzcore.Scene `set:"-"`
+
+	// position of the camera around the target
+	radius, azimuth, elevation float32
+
+	// roll of the view in degrees, counterclockwise on the screen
+	roll float32
+
+	// scene widgets with event handlers added
+	hooked map[*xyzcore.Scene]bool
+}
+
+// NewOrbitController returns a new orbit controller with default settings
+func NewOrbitController() *OrbitController {
+	return &OrbitController{MinRadius: 0.5, MaxRadius: 500, MinElevation: -89, MaxElevation: 89,
+		RotateSpeed: 0.3, StickRotateSpeed: 90, ZoomSpeed: 0.05, PanSpeed: 0.002}
+}
+
+// Attach starts controlling the camera of the given scene widget,
+// taking over the mouse navigation of the scene. The keyboard
+// navigation of the scene still works. The current camera
+// position is kept, looking at the Target.
+func (oc *OrbitController) Attach(sw *xyzcore.Scene) {
+	if oc.SceneWidget == sw {
+		return
+	}
+	oc.SceneWidget = sw
+	oc.sync()
+	oc.update()
+	startGamepads()
+	if oc.hooked[sw] {
+		return // handlers are already checking SceneWidget
+	}
+	if oc.hooked == nil {
+		oc.hooked = map[*xyzcore.Scene]bool{}
+	}
+	oc.hooked[sw] = true
+	gestures := AddTouchGestures(sw)
+	sw.On(events.SlideMove, func(e events.Event) {
+		if oc.SceneWidget != sw {
+			return
+		}
+		if gestures.Touches() >= 2 {
+			e.SetHandled() // pinching or twisting
+			return
+		}
+		if sw.CurrentManipPoint != nil && sw.CurrentSelected != nil {
+			return // let the scene widget move the selection
+		}
+		e.SetHandled()
+		del := e.PrevDelta()
+		if e.MouseButton() == events.Middle || e.HasAllModifiers(key.Shift) {
+			oc.Pan(float32(del.X), float32(del.Y))
+		} else {
+			oc.Rotate(float32(del.X), float32(del.Y))
+		}
+	})
+	sw.On(events.Scroll, func(e events.Event) {
+		if oc.SceneWidget != sw {
+			return
+		}
+		e.SetHandled()
+		oc.Zoom(float32(e.(*events.MouseScroll).Delta.Y))
+	})
+	sw.On(events.Magnify, func(e events.Event) {
+		if oc.SceneWidget == sw {
+			e.SetHandled() // zoomed by the PinchEvent instead
+		}
+	})
+	sw.On(events.Custom, func(e events.Event) {
+		if oc.SceneWidget != sw {
+			return
+		}
+		switch ev := e.(*events.CustomEvent).Data.(type) {
+		case PinchEvent:
+			e.SetHandled()
+			oc.sync()
+			oc.radius *= (ev.Scale - ev.Delta) / max(ev.Scale, 1e-6)
+			oc.update()
+		case TwistEvent:
+			e.SetHandled()
+			oc.Roll(ev.Angle)
+		}
+	})
+	sw.Scene.On(events.Custom, func(e events.Event) {
+		ge, ok := gamepadAxisEvent(e)
+		if !ok || oc.SceneWidget != sw {
+			return
+		}
+		e.SetHandled()
+		oc.stick(ge.Axis, ge.Value)
+	})
+}
+
+// Detach stops controlling the camera, restoring the default
+// mouse navigation of the scene
+func (oc *OrbitController) Detach() {
+	oc.SceneWidget = nil
+}
+
+// Rotate orbits the camera by the given number of pixels dragged
+func (oc *OrbitController) Rotate(dx, dy float32) {
+	oc.sync()
+	oc.azimuth -= dx * oc.RotateSpeed
+	oc.elevation += dy * oc.RotateSpeed
+	oc.update()
+}
+
+// Zoom moves the camera toward the target for positive
+// scroll steps and away from it for negative steps
+func (oc *OrbitController) Zoom(steps float32) {
+	oc.sync()
+	oc.radius *= 1 - steps*oc.ZoomSpeed
+	oc.update()
+}
+
+// Pan moves the target and camera together within the
+// view plane by the given number of pixels dragged
+func (oc *OrbitController) Pan(dx, dy float32) {
+	if oc.SceneWidget == nil {
+		return
+	}
+	oc.sync()
+	cm := &oc.SceneWidget.XYZ.Camera
+	del := oc.radius * oc.PanSpeed
+	right := math32.Vec3(-dx*del, 0, 0).MulQuat(cm.Pose.Quat)
+	up := math32.Vec3(0, dy*del, 0).MulQuat(cm.Pose.Quat)
+	oc.Target.SetAdd(right.Add(up))
+	oc.update()
+}
+
+// stickZoomSteps is the number of scroll steps zoomed per
+// second by a gamepad stick pushed all the way
+const stickZoomSteps = 10
+
+// stick rotates or zooms the camera for the given gamepad
+// axis being at the given value for one gamepad poll
+func (oc *OrbitController) stick(axis int, v float32) {
+	dt := float32(gamepadPollInterval.Seconds())
+	switch axis {
+	case GamepadLeftX:
+		oc.sync()
+		oc.azimuth -= v * oc.StickRotateSpeed * dt
+		oc.update()
+	case GamepadLeftY:
+		oc.sync()
+		oc.elevation += v * oc.StickRotateSpeed * dt
+		oc.update()
+	case GamepadRightY:
+		oc.Zoom(-v * stickZoomSteps * dt)
+	}
+}
+
+// Roll turns the view around the direction the camera looks
+// in by the given degrees, counterclockwise on the screen
+func (oc *OrbitController) Roll(degrees float32) {
+	oc.sync()
+	oc.roll += degrees
+	oc.update()
+}
+
+// SetTarget sets the target, keeping the camera at
+// the same distance and angle from it
+func (oc *OrbitController) SetTarget(target math32.Vector3) {
+	oc.sync()
+	oc.Target = target
+	oc.update()
+}
+
+// sync sets the orbit position from the camera, which
+// the keyboard navigation of the scene can also move
+func (oc *OrbitController) sync() {
+	if oc.SceneWidget != nil {
+		oc.fromCamera(&oc.SceneWidget.XYZ.Camera)
+	}
+}
+
+// update clamps and applies the camera position, and renders
+func (oc *OrbitController) update() {
+	if oc.SceneWidget == nil {
+		return
+	}
+	oc.apply(&oc.SceneWidget.XYZ.Camera)
+	oc.SceneWidget.XYZ.SetNeedsRender()
+	oc.SceneWidget.NeedsRender()
+}
+
+// fromCamera sets the orbit position from the camera position
+func (oc *OrbitController) fromCamera(cm *xyz.Camera) {
+	v := cm.Pose.Pos.Sub(oc.Target)
+	oc.radius = v.Length()
+	if oc.radius == 0 {
+		return
+	}
+	oc.elevation = math32.RadToDeg(math32.Asin(v.Y / oc.radius))
+	oc.azimuth = math32.RadToDeg(math32.Atan2(v.X, v.Z))
+}
+
+// apply clamps the orbit position and moves the camera to it
+func (oc *OrbitController) apply(cm *xyz.Camera) {
+	oc.radius = math32.Clamp(oc.radius, oc.MinRadius, oc.MaxRadius)
+	oc.elevation = math32.Clamp(oc.elevation, oc.MinElevation, oc.MaxElevation)
+	az, el := math32.DegToRad(oc.azimuth), math32.DegToRad(oc.elevation)
+	off := math32.Vec3(math32.Cos(el)*math32.Sin(az), math32.Sin(el), math32.Cos(el)*math32.Cos(az))
+	cm.Pose.Pos = oc.Target.Add(off.MulScalar(oc.radius))
+	cm.LookAt(oc.Target, math32.Vec3(0, 1, 0))
+	if oc.roll != 0 {
+		// the camera turns the other way around its view
+		// direction, which points out of the screen
+		q := cm.Pose.Quat.Mul(math32.NewQuatAxisAngle(math32.Vec3(0, 0, 1), -math32.DegToRad(oc.roll)))
+		cm.Pose.Quat = q
+		cm.UpDir = math32.Vec3(0, 1, 0).MulQuat(q)
+		cm.UpdateMatrix()
+	}
+}
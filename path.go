@@ -0,0 +1,134 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// PathFollower moves a solid along a path of waypoints at
+// the PathSpeed of its SimpleAnim
+type PathFollower struct {
+	// Animation providing the PathSpeed
+	Anim *SimpleAnim
+
+	// Solid being moved
+	Solid *xyz.Solid
+
+	// Waypoints of the path
+	Path []math32.Vector3
+
+	// Whether to return to the first waypoint and keep going
+	Loop bool
+
+	// Whether to turn the solid so that its forward (-Z) axis
+	// is tangent to the path
+	Orient bool
+
+	// Distance traveled along the path
+	Dist float32 `edit:"-"`
+
+	// mu protects all fields from the ticker goroutine
+	mu sync.Mutex
+}
+
+// FollowPath starts moving the given solid along the given path,
+// optionally looping back to the start
+func (a *SimpleAnim) FollowPath(sld *xyz.Solid, path []math32.Vector3, loop bool) *PathFollower {
+	pf := &PathFollower{Anim: a, Solid: sld, Path: path, Loop: loop}
+	a.AddAnimator(pf)
+	return pf
+}
+
+// AddWaypoints appends waypoints to the path, which is safe
+// to do while the animation is running
+func (pf *PathFollower) AddWaypoints(pts ...math32.Vector3) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	pf.Path = append(pf.Path, pts...)
+}
+
+// Length returns the total length of the path,
+// including the segment back to the start if looping
+func (pf *PathFollower) Length() float32 {
+	ln := float32(0)
+	n := pf.numSegments()
+	for i := range n {
+		ln += pf.Path[i].DistanceTo(pf.Path[(i+1)%len(pf.Path)])
+	}
+	return ln
+}
+
+// Progress returns the normalized distance traveled along the path
+func (pf *PathFollower) Progress() float32 {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	ln := pf.Length()
+	if ln == 0 {
+		return 0
+	}
+	return pf.Dist / ln
+}
+
+// numSegments returns the number of segments in the path
+func (pf *PathFollower) numSegments() int {
+	n := len(pf.Path)
+	if n < 2 {
+		return 0
+	}
+	if pf.Loop {
+		return n
+	}
+	return n - 1
+}
+
+// Step implements [Animator]
+func (pf *PathFollower) Step(dt float32) bool {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	if pf.Solid == nil || len(pf.Path) == 0 {
+		return false
+	}
+	ln := pf.Length()
+	if !pf.Loop && pf.Dist >= ln {
+		return false
+	}
+	pf.Dist += pf.Anim.PathSpeed * dt
+	if pf.Loop && ln > 0 {
+		pf.Dist = math32.Mod(pf.Dist, ln)
+	} else {
+		pf.Dist = min(pf.Dist, ln)
+	}
+	pos, tangent := pf.PointAt(pf.Dist)
+	pf.Solid.SetPosePos(pos)
+	if pf.Orient && tangent != (math32.Vector3{}) {
+		pf.Solid.Pose.LookAt(pos.Add(tangent), math32.Vec3(0, 1, 0))
+	}
+	return true
+}
+
+// PointAt returns the position and unit tangent at the given
+// distance along the path
+func (pf *PathFollower) PointAt(dist float32) (pos, tangent math32.Vector3) {
+	n := pf.numSegments()
+	if n == 0 {
+		return pf.Path[0], math32.Vector3{}
+	}
+	for i := range n {
+		a, b := pf.Path[i], pf.Path[(i+1)%len(pf.Path)]
+		sl := a.DistanceTo(b)
+		if dist <= sl || i == n-1 {
+			if sl == 0 {
+				return b, math32.Vector3{}
+			}
+			return a.Lerp(b, min(dist/sl, 1)), b.Sub(a).Normal()
+		}
+		dist -= sl
+	}
+	return pf.Path[len(pf.Path)-1], math32.Vector3{}
+}
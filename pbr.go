@@ -0,0 +1,63 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/color"
+
+	"cogentcore.org/core/math32"
+)
+
+// PBRMaterial is a physically based material, in the metallic roughness
+// model of glTF. The shaders of xyz only do Blinn-Phong lighting, so
+// [Solid.SetPBRMaterial] sets the nearest phong material for it, keeping
+// its Metallic and Roughness on the solid for exporting it again.
+type PBRMaterial struct {
+	// BaseColor is the diffuse color of a dielectric,
+	// and the color of the reflections of a metal
+	BaseColor color.RGBA
+
+	// Metallic is from 0 for a dielectric, such as plastic,
+	// to 1 for a metal
+	Metallic float32
+
+	// Roughness is from 0 for a mirror to 1 for a fully rough surface
+	Roughness float32
+
+	// AO is the ambient occlusion, the part of the light that reaches
+	// the surface, from 0 to 1, which darkens the base color; 0 is taken
+	// as 1, for no occlusion, so that it can be left unset
+	AO float32
+
+	// EmissiveColor is the color given off by the surface itself
+	EmissiveColor color.RGBA
+}
+
+// SetPBRMaterial sets the material of the solid to the phong one nearest
+// to the given physically based one: the roughness sets the shininess,
+// as the inverse of the roughness that [ExportGLTF] gives a shininess,
+// and metals reflect twice as much of the light as dielectrics do. There
+// is no environment for metals to reflect, so they mostly show their
+// highlights.
+func (sld *Solid) SetPBRMaterial(m PBRMaterial) *Solid {
+	clr := m.BaseColor
+	if ao := math32.Clamp(m.AO, 0, 1); ao > 0 {
+		clr.R = uint8(float32(clr.R) * ao)
+		clr.G = uint8(float32(clr.G) * ao)
+		clr.B = uint8(float32(clr.B) * ao)
+	}
+	sld.SetColor(clr)
+	mt := &sld.Material
+	mt.Emissive = m.EmissiveColor
+	sld.Metallic = math32.Clamp(m.Metallic, 0, 1)
+	sld.Roughness = math32.Clamp(m.Roughness, 0, 1)
+	r := max(sld.Roughness, 0.05)
+	mt.Shiny = min(2/(r*r)-2, 256)
+	mt.Reflective = (1 - 0.5*sld.Roughness) * (0.5 + 0.5*sld.Metallic)
+	if sld.Roughness == 0 {
+		sld.Roughness = 1e-3 // 0 means unset for export
+	}
+	return sld
+}
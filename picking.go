@@ -0,0 +1,181 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// PickAtPixel returns the nearest solid of the given scene under the
+// pixel at x, y from the top left of a viewport of the given size, as
+// seen by the camera of the scene. It also returns the barycentric
+// coordinates of the hit point in the triangle that was hit, and the
+// distance to it from the camera. The ray from the pixel is tested
+// against the world bounding boxes of the solids first, and then
+// against the triangles of the meshes of the solids that it hits, from
+// the nearest box on. The boxes are found with the [Octree] of the
+// scene if it has one, and otherwise by testing all of them. Hidden
+// solids and those with reserved names, such as the grid, are skipped.
+func PickAtPixel(sc *xyz.Scene, x, y int, viewport math32.Vector2) (hit *xyz.Solid, barycentric math32.Vector3, dist float32, ok bool) {
+	hit, _, barycentric, dist, ok = pickAtPixel(sc, x, y, viewport)
+	return
+}
+
+// PickUVAtPixel returns the nearest solid of the given scene under the
+// given pixel, as for [PickAtPixel], and the texture coordinates of its
+// mesh at the hit point, interpolated from those of the vertices of the
+// triangle that was hit
+func PickUVAtPixel(sc *xyz.Scene, x, y int, viewport math32.Vector2) (hit *xyz.Solid, uv math32.Vector2, ok bool) {
+	hit, tri, bary, _, ok := pickAtPixel(sc, x, y, viewport)
+	if !ok {
+		return
+	}
+	md := shape.NewMeshData(hit.Mesh)
+	if len(md.TexCoord) < 2*md.NumVertex {
+		return hit, uv, ok
+	}
+	tex := func(i uint32) math32.Vector3 {
+		return math32.Vec3(md.TexCoord[2*i], md.TexCoord[2*i+1], 0)
+	}
+	t := BarycentricInterpolate(bary, tex(md.Index[tri]), tex(md.Index[tri+1]), tex(md.Index[tri+2]))
+	return hit, math32.Vec2(t.X, t.Y), ok
+}
+
+// pickAtPixel does [PickAtPixel], also returning the index in the mesh
+// indexes of the first vertex of the triangle that was hit
+func pickAtPixel(sc *xyz.Scene, x, y int, viewport math32.Vector2) (hit *xyz.Solid, tri int, barycentric math32.Vector3, dist float32, ok bool) {
+	if viewport.X <= 0 || viewport.Y <= 0 {
+		return
+	}
+	ray := pixelRay(&sc.Camera, math32.Vec2(float32(x)+0.5, float32(y)+0.5), viewport)
+
+	var cands []octreeHit
+	if ot := SceneOctree(sc); ot != nil {
+		cands = slices.DeleteFunc(ot.rayHits(ray), func(h octreeHit) bool { return isHidden(h.sld) })
+	} else {
+		cands = rayHitsLinear(sc, ray)
+	}
+	for _, c := range cands {
+		if ok && c.dist > dist {
+			break // no triangle in this box can be nearer
+		}
+		if t, b, d, hitTri := pickTriangle(c.sld, ray); hitTri && (!ok || d < dist) {
+			hit, tri, barycentric, dist, ok = c.sld, t, b, d, true
+		}
+	}
+	return
+}
+
+// rayHitsLinear returns the visible solids of the given scene whose
+// world bounding boxes the given ray hits, nearest first, testing
+// all of them, for scenes without an [Octree]
+func rayHitsLinear(sc *xyz.Scene, ray math32.Ray) []octreeHit {
+	var hits []octreeHit
+	sc.WalkDown(func(k tree.Node) bool {
+		if k == sc.This {
+			return tree.Continue
+		}
+		nd, _ := xyz.AsNode(k)
+		if nd == nil || isReservedNode(k) || isHidden(nd) {
+			return tree.Break
+		}
+		sld := nd.AsSolid()
+		if sld == nil || sld.Mesh == nil {
+			return tree.Continue
+		}
+		bb := sld.WorldBBox.BBox
+		if t, hit := RayIntersectAABB(ray, bb.Min, bb.Max); hit {
+			hits = append(hits, octreeHit{sld, t})
+		}
+		return tree.Continue
+	})
+	slices.SortFunc(hits, func(a, b octreeHit) int {
+		return int(math32.Sign(a.dist - b.dist))
+	})
+	return hits
+}
+
+// pixelRay returns the world ray from the given camera through the
+// given pixel position in a viewport of the given size
+func pixelRay(cm *xyz.Camera, pos, viewport math32.Vector2) math32.Ray {
+	cam := *cm
+	cam.Aspect = viewport.X / viewport.Y
+	cam.UpdateMatrix()
+	ndc := pos.WindowToNDC(viewport, math32.Vector2{}, true)
+	unproject := func(z float32) math32.Vector3 {
+		ndc.Z = z
+		eye := math32.Vector4FromVector3(ndc, 1).MulMatrix4(&cam.InvProjectionMatrix).PerspDiv()
+		return eye.MulMatrix4(&cam.Pose.Matrix)
+	}
+	near, far := unproject(-1), unproject(1)
+	return *math32.NewRay(near, far.Sub(near).Normal())
+}
+
+// pickSolid returns the barycentric coordinates of the nearest
+// intersection of the given world ray with the triangles of the mesh
+// of the given solid, and its distance from the origin of the ray
+func pickSolid(sld *xyz.Solid, ray math32.Ray) (barycentric math32.Vector3, dist float32, ok bool) {
+	_, barycentric, dist, ok = pickTriangle(sld, ray)
+	return
+}
+
+// pickTriangle does pickSolid, also returning the index in the
+// mesh indexes of the first vertex of the triangle that was hit
+func pickTriangle(sld *xyz.Solid, ray math32.Ray) (tri int, barycentric math32.Vector3, dist float32, ok bool) {
+	inv, err := sld.Pose.WorldMatrix.Inverse()
+	if err != nil {
+		return
+	}
+	local := ray
+	local.ApplyMatrix4(inv)
+	md := shape.NewMeshData(sld.Mesh)
+	vtx := func(i uint32) math32.Vector3 {
+		var v math32.Vector3
+		md.Vertex.GetVector3(3*int(i), &v)
+		return v
+	}
+	for i := 0; i+2 < len(md.Index); i += 3 {
+		a, b, c := vtx(md.Index[i]), vtx(md.Index[i+1]), vtx(md.Index[i+2])
+		t, bary, hit := RayIntersectTriangle(local, a, b, c)
+		if !hit {
+			continue
+		}
+		d := local.At(t).MulMatrix4(&sld.Pose.WorldMatrix).Sub(ray.Origin).Length()
+		if !ok || d < dist {
+			tri, barycentric, dist, ok = i, bary, d, true
+		}
+	}
+	return
+}
+
+// Barycentric returns the weights of the vertices a, b and c of a
+// triangle that give the given point, or the nearest point to it in
+// the plane of the triangle, which add up to 1. They are all from 0
+// to 1 for a point inside of the triangle.
+func Barycentric(p, a, b, c math32.Vector3) math32.Vector3 {
+	ab, ac, ap := b.Sub(a), c.Sub(a), p.Sub(a)
+	d00, d01, d11 := ab.Dot(ab), ab.Dot(ac), ac.Dot(ac)
+	d20, d21 := ap.Dot(ab), ap.Dot(ac)
+	den := d00*d11 - d01*d01
+	if den == 0 { // degenerate triangle
+		return math32.Vec3(1, 0, 0)
+	}
+	v := (d11*d20 - d01*d21) / den
+	w := (d00*d21 - d01*d20) / den
+	return math32.Vec3(1-v-w, v, w)
+}
+
+// BarycentricInterpolate returns the value of a vertex attribute, such
+// as a position, normal, color or texture coordinates, at the point of
+// a triangle with the given barycentric weights, from its values va, vb
+// and vc at the vertices
+func BarycentricInterpolate(bary math32.Vector3, va, vb, vc math32.Vector3) math32.Vector3 {
+	return va.MulScalar(bary.X).Add(vb.MulScalar(bary.Y)).Add(vc.MulScalar(bary.Z))
+}
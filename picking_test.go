@@ -0,0 +1,135 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// testViewport is the size of the viewport that the tests pick in
+var testViewport = math32.Vec2(400, 300)
+
+// newTestScene returns a new scene, with no GPU, looking
+// at the origin from 0, 0, 10 with the default camera
+func newTestScene() *xyz.Scene {
+	sc := xyz.NewScene()
+	sc.Camera.Aspect = testViewport.X / testViewport.Y
+	sc.Camera.UpdateMatrix()
+	return sc
+}
+
+// newTestBox adds a solid box of the given size at the given position
+func newTestBox(sc *xyz.Scene, name string, size float32, pos math32.Vector3) *xyz.Solid {
+	box := xyz.NewBox(sc, name, size, size, size)
+	shape.NewMeshData(box) // sets its bounding box, as uploading it does
+	sld := xyz.NewSolid(sc).SetMesh(box)
+	sld.SetName(name)
+	sld.Pose.Pos = pos
+	return sld
+}
+
+// updateTestScene updates the bounding boxes of the meshes and the
+// world matrices and bounding boxes of the solids of the scene, as
+// rendering it does
+func updateTestScene(sc *xyz.Scene) {
+	for _, kv := range sc.Meshes.Order {
+		shape.NewMeshData(kv.Value)
+	}
+	sc.UpdateNodes()
+	sc.UpdateMVPMatrix()
+}
+
+// projectToPixel returns the pixel of the test viewport that
+// the given world point is seen at by the camera of the scene
+func projectToPixel(sc *xyz.Scene, p math32.Vector3) (x, y int) {
+	var vp math32.Matrix4
+	vp.MulMatrices(&sc.Camera.ProjectionMatrix, &sc.Camera.ViewMatrix)
+	ndc := math32.Vector4FromVector3(p, 1).MulMatrix4(&vp).PerspDiv()
+	x = int((ndc.X + 1) / 2 * testViewport.X)
+	y = int((1 - ndc.Y) / 2 * testViewport.Y)
+	return
+}
+
+func TestPickAtPixelOffAxis(t *testing.T) {
+	sc := newTestScene()
+	pos := math32.Vec3(3.2, 2.1, 0)
+	edge := newTestBox(sc, "edge", 0.5, pos)
+	newTestBox(sc, "center", 0.5, math32.Vector3{})
+	updateTestScene(sc)
+
+	x, y := projectToPixel(sc, pos)
+	if x < int(testViewport.X)*5/6 || y > int(testViewport.Y)/6 {
+		t.Fatalf("the box is at pixel %d, %d, not near the corner of the viewport", x, y)
+	}
+	hit, _, dist, ok := PickAtPixel(sc, x, y, testViewport)
+	if !ok || hit != edge {
+		t.Fatalf("picked %v at %d, %d, not the box near the edge", hit, x, y)
+	}
+	// the front face of the box is 0.25 in front of its center
+	want := sc.Camera.Pose.Pos.Sub(pos.Add(math32.Vec3(0, 0, 0.25))).Length()
+	if math32.Abs(dist-want) > 0.05 {
+		t.Errorf("picked the box at a distance of %g, not %g", dist, want)
+	}
+
+	if hit, _, _, ok := PickAtPixel(sc, 10, int(testViewport.Y)-10, testViewport); ok {
+		t.Errorf("picked %v in the empty corner of the viewport", hit)
+	}
+}
+
+func TestPickAtPixelNearest(t *testing.T) {
+	sc := newTestScene()
+	near := newTestBox(sc, "near", 0.5, math32.Vec3(-1.5, -1, 2))
+	newTestBox(sc, "far", 2, math32.Vec3(-1.5*2, -1*2, -6))
+	updateTestScene(sc)
+
+	x, y := projectToPixel(sc, near.Pose.Pos)
+	hit, _, _, ok := PickAtPixel(sc, x, y, testViewport)
+	if !ok || hit != near {
+		t.Errorf("picked %v, not the nearer of the two boxes", hit)
+	}
+}
+
+func TestPickSolidHitPoint(t *testing.T) {
+	sc := newTestScene()
+	pos := math32.Vec3(2.5, -1.5, 0)
+	sld := newTestBox(sc, "box", 1, pos)
+	updateTestScene(sc)
+
+	// a point on the front face of the box, away from its center
+	want := pos.Add(math32.Vec3(0.4, 0.3, 0.5))
+	eye := sc.Camera.Pose.Pos
+	ray := *math32.NewRay(eye, want.Sub(eye).Normal())
+	_, dist, ok := pickSolid(sld, ray)
+	if !ok {
+		t.Fatal("the ray to the front face of the box missed it")
+	}
+	if got := ray.At(dist); got.Sub(want).Length() > 1e-4 {
+		t.Errorf("the ray hit the box at %v, not %v", got, want)
+	}
+}
+
+func TestPickSolidRotatedMiss(t *testing.T) {
+	sc := newTestScene()
+	pos := math32.Vec3(-2, 1, 0)
+	sld := newTestBox(sc, "box", 1, pos)
+	sld.Pose.SetAxisRotation(0, 0, 1, 45)
+	updateTestScene(sc)
+
+	// the corner of the bounding box of the turned box is outside of it
+	bb := sld.WorldBBox.BBox
+	corner := math32.Vec3(bb.Max.X-0.05, bb.Max.Y-0.05, bb.Max.Z)
+	eye := sc.Camera.Pose.Pos
+	ray := *math32.NewRay(eye, corner.Sub(eye).Normal())
+	if _, hit := RayIntersectAABB(ray, bb.Min, bb.Max); !hit {
+		t.Fatal("the ray missed the bounding box of the box")
+	}
+	if _, _, ok := pickSolid(sld, ray); ok {
+		t.Error("the ray past the corner of the turned box hit it")
+	}
+}
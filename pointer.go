@@ -0,0 +1,75 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/system"
+)
+
+// PointerKinds are the kinds of devices that move the pointer
+type PointerKinds int32
+
+const (
+	// PointerMouse is a mouse or touchpad
+	PointerMouse PointerKinds = iota
+
+	// PointerTouch is a finger on a touch screen
+	PointerTouch
+
+	// PointerPen is a stylus on a tablet or screen
+	PointerPen
+)
+
+// PointerEvent is a mouse event with the pressure and tilt of the pen
+// or finger that made it, as for brushes whose strokes get wider when
+// pressed harder. The events package has no such details, and its mouse
+// events can not be extended, so they are added to the mouse events that
+// come through it by [NewPointerEvent], from the last pointer event of
+// the platform. Browsers give them for pens and touch screens; the
+// desktop driver of GLFW only has mouse buttons, so there the pressure
+// is 1 while one is down, with no tilt; and the same for touches on
+// mobile platforms.
+type PointerEvent struct {
+	*events.Mouse
+
+	// Pressure of the pointer, from 0 to 1. Browsers
+	// give 0.5 for a mouse while a button is down.
+	Pressure float32
+
+	// TiltX and TiltY are the angles in degrees of a pen from upright,
+	// toward the right and toward the bottom of the screen
+	TiltX, TiltY float32
+
+	// PointerType is the kind of device that made the event
+	PointerType PointerKinds
+}
+
+// NewPointerEvent returns the given event with the details of the pointer
+// that made it, if it is a mouse event, such as a press, drag or slide
+func NewPointerEvent(e events.Event) (*PointerEvent, bool) {
+	me, ok := e.(*events.Mouse)
+	if !ok {
+		return nil, false
+	}
+	pe := &PointerEvent{Mouse: me}
+	readPointer(pe)
+	return pe, true
+}
+
+// buttonPointer sets the pointer details of the given event from its
+// mouse button alone, with a pressure of 1 while it is down, for
+// platforms that give no more
+func buttonPointer(pe *PointerEvent) {
+	down := pe.MouseButton() != events.NoButton && pe.Type() != events.MouseUp
+	pe.Pressure, pe.TiltX, pe.TiltY = 0, 0, 0
+	if down {
+		pe.Pressure = 1
+	}
+	pe.PointerType = PointerMouse
+	if system.TheApp.Platform().IsMobile() {
+		pe.PointerType = PointerTouch
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js
+
+package main
+
+import (
+	"sync"
+	"syscall/js"
+)
+
+// lastPointer is the last pointer event of the browser, which it sends
+// just before the mouse and touch events that the web driver listens to
+var lastPointer struct {
+	sync.Mutex
+	pressure, tiltX, tiltY float32
+	kind                   PointerKinds
+	seen                   bool
+}
+
+// startPointers starts following the pointer events of the browser, once
+var startPointers = sync.OnceFunc(func() {
+	fn := js.FuncOf(func(this js.Value, args []js.Value) any {
+		e := args[0]
+		lastPointer.Lock()
+		defer lastPointer.Unlock()
+		lastPointer.seen = true
+		lastPointer.pressure = float32(e.Get("pressure").Float())
+		lastPointer.tiltX = float32(e.Get("tiltX").Float())
+		lastPointer.tiltY = float32(e.Get("tiltY").Float())
+		switch e.Get("pointerType").String() {
+		case "pen":
+			lastPointer.kind = PointerPen
+		case "touch":
+			lastPointer.kind = PointerTouch
+		default:
+			lastPointer.kind = PointerMouse
+		}
+		return nil
+	})
+	opts := map[string]any{"capture": true, "passive": true}
+	for _, nm := range []string{"pointerdown", "pointermove", "pointerup"} {
+		js.Global().Call("addEventListener", nm, fn, opts)
+	}
+})
+
+// readPointer sets the pointer details of the given event from the last
+// pointer event of the browser. They are followed from the first call,
+// so until the browser sends one, they are made from the mouse button.
+func readPointer(pe *PointerEvent) {
+	startPointers()
+	lastPointer.Lock()
+	defer lastPointer.Unlock()
+	if !lastPointer.seen {
+		buttonPointer(pe)
+		return
+	}
+	pe.Pressure = lastPointer.pressure
+	pe.TiltX, pe.TiltY = lastPointer.tiltX, lastPointer.tiltY
+	pe.PointerType = lastPointer.kind
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !js
+
+package main
+
+// readPointer sets the pointer details of the given event from its
+// mouse button, as the GLFW and mobile drivers give no more. Pens on
+// Windows send WM_POINTER messages, but GLFW handles the messages of the
+// window, and only turns them into mouse events.
+func readPointer(pe *PointerEvent) {
+	buttonPointer(pe)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// MaxPointLights is the maximum number of point lights in a scene,
+// which is the number that the phong shader sums over
+const MaxPointLights = phong.MaxLights
+
+// PointLight sets up an [xyz.Point] from the attenuation coefficients of
+// 1 / (Constant + Linear*d + Quadratic*d*d) at distance d. The phong
+// shader has a fixed constant term of 1, so the coefficients are divided
+// by Constant and the light intensity is scaled to match.
+type PointLight struct {
+	// Light in the scene, which is nil if the scene
+	// already had [MaxPointLights] point lights
+	Light *xyz.Point
+
+	// Pos is the position of the light
+	Pos math32.Vector3
+
+	// Intensity of the light at zero distance, for a Constant of 1
+	Intensity float32
+
+	// Constant attenuation coefficient, which must be positive
+	Constant float32
+
+	// Linear attenuation coefficient
+	Linear float32
+
+	// Quadratic attenuation coefficient
+	Quadratic float32
+}
+
+// NewPointLight adds a new point light with the given name, intensity
+// and color to the given scene, above the origin. If the scene already
+// has [MaxPointLights] point lights, the light is not added, which is
+// logged, and its Light is nil.
+func NewPointLight(sc *xyz.Scene, name string, intensity float32, color xyz.LightColors) *PointLight {
+	pl := &PointLight{Pos: math32.Vec3(0, 2, 0), Intensity: intensity, Constant: 1, Linear: 0.1, Quadratic: 0.01}
+	if numLights[*xyz.Point](sc) >= MaxPointLights {
+		log.Printf("NewPointLight: not adding %s, as the scene already has the maximum of %d point lights\n", name, MaxPointLights)
+		return pl
+	}
+	pl.Light = xyz.NewPoint(sc, name, intensity, color)
+	pl.apply()
+	return pl
+}
+
+// Attenuation returns the fraction of the intensity of the light
+// that reaches the given distance
+func (pl *PointLight) Attenuation(dist float32) float32 {
+	return 1 / (pl.Constant + pl.Linear*dist + pl.Quadratic*dist*dist)
+}
+
+// Update applies changes to the fields of the light to the scene
+func (pl *PointLight) Update(sc *xyz.Scene) {
+	if pl.Light == nil {
+		return
+	}
+	pl.apply()
+	if !sc.IsLive() {
+		return
+	}
+	lt := pl.Light
+	sc.Phong.SetPoint(lightIndex(sc, lt), lightColor(lt), lt.Pos, lt.LinDecay, lt.QuadDecay)
+	sc.SetNeedsRender()
+}
+
+// apply sets the fields of the xyz light from ours
+func (pl *PointLight) apply() {
+	lt := pl.Light
+	c := max(pl.Constant, 1e-6)
+	lt.Pos = pl.Pos
+	lt.Lumens = pl.Intensity / c
+	lt.LinDecay = pl.Linear / c
+	lt.QuadDecay = pl.Quadratic / c
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// TestPointLightAttenuation checks that the light that the phong shader
+// gives at each distance, with its constant term of 1, is the intensity
+// with the attenuation of the coefficients
+func TestPointLightAttenuation(t *testing.T) {
+	sc := xyz.NewScene()
+	for _, c := range []float32{1, 0.5, 4} {
+		pl := NewPointLight(sc, fmt.Sprint("point-", c), 2, xyz.DirectSun)
+		pl.Constant, pl.Linear, pl.Quadratic = c, 0.3, 0.05
+		pl.Pos.Set(1, 2, 3)
+		pl.Update(sc)
+		lt := pl.Light
+		if lt.Pos != pl.Pos {
+			t.Errorf("the light is at %v instead of %v", lt.Pos, pl.Pos)
+		}
+		for _, d := range []float32{0, 1, 5, 20} {
+			phong := lt.Lumens / (1 + lt.LinDecay*d + lt.QuadDecay*d*d)
+			want := pl.Intensity / (c + 0.3*d + 0.05*d*d)
+			if math32.Abs(phong-want) > 1e-5*want || math32.Abs(pl.Intensity*pl.Attenuation(d)-want) > 1e-5*want {
+				t.Errorf("with a constant of %g, the light at %g is %g instead of %g", c, d, phong, want)
+			}
+		}
+	}
+}
+
+func TestPointLightMax(t *testing.T) {
+	sc := xyz.NewScene()
+	xyz.NewDirectional(sc, "sun", 1, xyz.DirectSun)
+	var pls []*PointLight
+	for i := range MaxPointLights + 1 {
+		pls = append(pls, NewPointLight(sc, fmt.Sprint("point-", i), 1, xyz.DirectSun))
+	}
+	if pls[MaxPointLights].Light != nil || numLights[*xyz.Point](sc) != MaxPointLights {
+		t.Errorf("added %d point lights, more than the maximum of %d", numLights[*xyz.Point](sc), MaxPointLights)
+	}
+	pls[MaxPointLights].Update(sc) // does nothing without a light
+	if i := lightIndex(sc, pls[2].Light); i != 2 {
+		t.Errorf("the third point light, after a directional light, has the index %d", i)
+	}
+}
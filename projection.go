@@ -0,0 +1,144 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"time"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// orthoMinFOV is the field of view in degrees that the perspective
+// camera narrows to before switching to orthographic projection
+const orthoMinFOV = 2
+
+// CameraProjection switches the camera of a scene widget between
+// perspective and orthographic projection. The xyz camera computes
+// its own projection matrix, so the transition is a dolly zoom: the
+// field of view narrows while the camera backs away, keeping the view
+// of the target the same size, until the view is nearly parallel and
+// the camera switches to orthographic.
+type CameraProjection struct {
+	// Scene widget whose camera is switched
+	SceneWidget *xyzcore.Scene `set:"-"`
+
+	// Ortho is whether the camera is, or is switching to, orthographic
+	Ortho bool `edit:"-"`
+
+	// OrthoHeight is the height of the orthographic view in scene
+	// units; if 0, it is the height of the perspective view at the
+	// camera target when switching
+	OrthoHeight float32
+
+	// Transition is how long switching takes
+	Transition time.Duration
+
+	// Easing applied to the normalized transition time; nil means linear
+	Easing func(t float32) float32 `display:"-"`
+
+	// perspective field of view and distance to the target
+	persFOV, persDist float32
+
+	// orthoHeight is the height used for the current switch
+	orthoHeight float32
+
+	// transition animation in progress
+	anim *core.Animation
+
+	// elapsed is the time in seconds into the current switch
+	elapsed float32
+}
+
+// NewCameraProjection returns a new projection switcher for the
+// camera of the given scene widget, which starts in perspective
+func NewCameraProjection(sw *xyzcore.Scene) *CameraProjection {
+	return &CameraProjection{SceneWidget: sw, Transition: 500 * time.Millisecond, Easing: EaseInOutCubic}
+}
+
+// SetOrtho switches to orthographic projection if ortho is true,
+// and back to perspective otherwise
+func (cp *CameraProjection) SetOrtho(ortho bool) {
+	if cp.Ortho == ortho {
+		return
+	}
+	cm := &cp.SceneWidget.XYZ.Camera
+	if ortho && cp.anim == nil {
+		cp.persFOV = cm.FOV
+		cp.persDist = max(cm.DistanceTo(cm.Target), cm.Near)
+		cp.orthoHeight = cp.OrthoHeight
+		if cp.orthoHeight <= 0 {
+			cp.orthoHeight = viewHeight(cp.persFOV, cp.persDist)
+		}
+	}
+	cp.Ortho = ortho
+	elapsed := float32(0)
+	if cp.anim != nil {
+		// reverse from where the switch got to, rather than
+		// from the other end, which is as far into this one
+		cp.anim.Done = true
+		cp.anim = nil
+		elapsed = max(float32(cp.Transition.Seconds())-cp.elapsed, 0)
+	}
+	if cp.Transition <= 0 {
+		cp.set(1)
+		return
+	}
+	cp.elapsed = elapsed
+	sw := cp.SceneWidget
+	sw.Animate(func(a *core.Animation) {
+		cp.elapsed += a.Dt / 1000
+		t := min(cp.elapsed/float32(cp.Transition.Seconds()), 1)
+		cp.set(t)
+		if t >= 1 {
+			a.Done = true
+			cp.anim = nil
+		}
+	})
+	// the animation is only run at the next tick, so it is kept now
+	// for another switch before then to stop
+	cp.anim = sw.Scene.Animations[len(sw.Scene.Animations)-1]
+}
+
+// Toggle switches between perspective and orthographic projection
+func (cp *CameraProjection) Toggle() {
+	cp.SetOrtho(!cp.Ortho)
+}
+
+// set sets the camera at normalized time t of switching
+// to the current projection mode
+func (cp *CameraProjection) set(t float32) {
+	if cp.Easing != nil {
+		t = cp.Easing(t)
+	}
+	p := t // 0 is perspective and 1 is orthographic
+	if !cp.Ortho {
+		p = 1 - t
+	}
+	sw := cp.SceneWidget
+	cm := &sw.XYZ.Camera
+	dist := cp.persDist
+	if p >= 1 {
+		cm.Ortho = true
+		cm.FOV = math32.RadToDeg(2 * math32.Atan(cp.orthoHeight/(2*cm.Far)))
+	} else {
+		cm.Ortho = false
+		cm.FOV = math32.Lerp(cp.persFOV, orthoMinFOV, p)
+		ht := math32.Lerp(viewHeight(cp.persFOV, cp.persDist), cp.orthoHeight, p)
+		dist = ht / (2 * math32.Tan(math32.DegToRad(cm.FOV)/2))
+	}
+	dir := cm.Pose.Pos.Sub(cm.Target).Normal()
+	cm.Pose.Pos = cm.Target.Add(dir.MulScalar(dist))
+	cm.UpdateMatrix()
+	sw.XYZ.SetNeedsRender()
+	sw.NeedsRender()
+}
+
+// viewHeight returns the height of the view at the given distance
+// for the given field of view in degrees
+func viewHeight(fov, dist float32) float32 {
+	return 2 * dist * math32.Tan(math32.DegToRad(fov)/2)
+}
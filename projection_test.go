@@ -0,0 +1,72 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// tickAnimations runs the widget animations of the given scene
+// widget once, as if the given number of milliseconds had passed
+func tickAnimations(sw *xyzcore.Scene, dt float32) {
+	for _, a := range sw.Scene.Animations {
+		if !a.Done {
+			a.Dt = dt
+			a.Func(a)
+		}
+	}
+}
+
+func TestCameraProjectionReverse(t *testing.T) {
+	b := core.NewBody()
+	sw := xyzcore.NewScene(b)
+	sw.XYZ.Camera.Pose.Pos.Set(0, 0, 10)
+	sw.XYZ.Camera.LookAtOrigin()
+	cp := NewCameraProjection(sw)
+	cp.Transition = time.Second
+	fov := sw.XYZ.Camera.FOV
+
+	cp.SetOrtho(true)
+	tickAnimations(sw, 300)
+	mid := sw.XYZ.Camera.FOV
+	if mid >= fov || mid <= orthoMinFOV {
+		t.Fatalf("the field of view is %g partway to orthographic, from %g", mid, fov)
+	}
+	// switching back partway reverses from where the camera is
+	cp.SetOrtho(false)
+	tickAnimations(sw, 0)
+	if got := sw.XYZ.Camera.FOV; math32.Abs(got-mid) > 1e-3 {
+		t.Errorf("the field of view jumped from %g to %g on switching back", mid, got)
+	}
+	tickAnimations(sw, 300)
+	if got := sw.XYZ.Camera.FOV; math32.Abs(got-fov) > 1e-3 {
+		t.Errorf("the field of view is %g after switching back for as long, not %g", got, fov)
+	}
+	if cp.anim != nil || sw.XYZ.Camera.Ortho {
+		t.Error("the switch back to perspective did not finish")
+	}
+}
+
+func TestCameraProjectionStopsPending(t *testing.T) {
+	b := core.NewBody()
+	sw := xyzcore.NewScene(b)
+	cp := NewCameraProjection(sw)
+	cp.SetOrtho(true)
+	cp.SetOrtho(false) // before the first tick
+	n := 0
+	for _, a := range sw.Scene.Animations {
+		if !a.Done {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("%d switches are running, not 1", n)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "cogentcore.org/core/math32"
+
+// The intersections of [math32.Ray] return the points hit, which picking
+// then has to turn back into distances along the ray. These return the
+// distance t along the ray instead, which is in world units for a ray
+// of unit direction, with the point hit at ray.At(t). The distance of a
+// point from a [math32.Plane] is signed, by DistanceToPoint, positive on
+// the side its normal points to, as long as the normal is a unit one,
+// which Normalize makes it.
+
+// RayIntersectSphere returns the distance along the given ray to where
+// it enters the sphere of the given center and radius, which is 0 if it
+// starts inside of it, and whether it hits it at all
+func RayIntersectSphere(ray math32.Ray, center math32.Vector3, radius float32) (float32, bool) {
+	oc := ray.Origin.Sub(center)
+	a := ray.Dir.Dot(ray.Dir)
+	b := oc.Dot(ray.Dir)
+	c := oc.Dot(oc) - radius*radius
+	if c <= 0 {
+		return 0, true
+	}
+	disc := b*b - a*c
+	if a == 0 || b > 0 || disc < 0 {
+		return 0, false
+	}
+	return (-b - math32.Sqrt(disc)) / a, true
+}
+
+// RayIntersectAABB returns the distance along the given ray to where it
+// enters the axis aligned box of the given corners, which is 0 if it
+// starts inside of it, and whether it hits it at all
+func RayIntersectAABB(ray math32.Ray, min, max math32.Vector3) (float32, bool) {
+	tmin, tmax := float32(0), math32.Inf(1)
+	for d := range 3 {
+		o, dir := ray.Origin.Dim(math32.Dims(d)), ray.Dir.Dim(math32.Dims(d))
+		lo, hi := min.Dim(math32.Dims(d)), max.Dim(math32.Dims(d))
+		if dir == 0 {
+			if o < lo || o > hi {
+				return 0, false
+			}
+			continue
+		}
+		t0, t1 := (lo-o)/dir, (hi-o)/dir
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		tmin, tmax = math32.Max(tmin, t0), math32.Min(tmax, t1)
+		if tmin > tmax {
+			return 0, false
+		}
+	}
+	return tmin, true
+}
+
+// RayIntersectTriangle returns the distance along the given ray to where
+// it hits the triangle of the given vertices from either side, with the
+// barycentric weights of the vertices at that point, and whether it hits
+// it at all, using the algorithm of Möller and Trumbore
+func RayIntersectTriangle(ray math32.Ray, a, b, c math32.Vector3) (float32, math32.Vector3, bool) {
+	ab, ac := b.Sub(a), c.Sub(a)
+	p := ray.Dir.Cross(ac)
+	det := ab.Dot(p)
+	if math32.Abs(det) < 1e-12 { // parallel to the triangle
+		return 0, math32.Vector3{}, false
+	}
+	inv := 1 / det
+	s := ray.Origin.Sub(a)
+	v := s.Dot(p) * inv
+	if v < 0 || v > 1 {
+		return 0, math32.Vector3{}, false
+	}
+	q := s.Cross(ab)
+	w := ray.Dir.Dot(q) * inv
+	if w < 0 || v+w > 1 {
+		return 0, math32.Vector3{}, false
+	}
+	t := ac.Dot(q) * inv
+	if t < 0 {
+		return 0, math32.Vector3{}, false
+	}
+	return t, math32.Vec3(1-v-w, v, w), true
+}
+
+// RayIntersectPlane returns the distance along the given ray to where
+// it hits the given plane from either side, and whether it does, which
+// it does not when it points away from it or along it
+func RayIntersectPlane(ray math32.Ray, pl math32.Plane) (float32, bool) {
+	den := pl.Norm.Dot(ray.Dir)
+	if math32.Abs(den) < 1e-4 {
+		return 0, false
+	}
+	t := -pl.DistanceToPoint(ray.Origin) / den
+	return t, t >= 0
+}
+
+// PlaneThrough returns the plane with the given unit normal
+// through the given point
+func PlaneThrough(normal, point math32.Vector3) math32.Plane {
+	var pl math32.Plane
+	pl.SetFromNormalAndCoplanarPoint(normal, point)
+	return pl
+}
+
+// ProjectOnPlane returns the point of the given plane, which must have
+// a unit normal, nearest to the given point
+func ProjectOnPlane(p math32.Vector3, pl math32.Plane) math32.Vector3 {
+	return p.Sub(pl.Norm.MulScalar(pl.DistanceToPoint(p)))
+}
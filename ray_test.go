@@ -0,0 +1,97 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"cogentcore.org/core/math32"
+)
+
+// near returns whether the given numbers are within 1e-5 of each other
+func near(a, b float32) bool {
+	return math32.Abs(a-b) < 1e-5
+}
+
+// xRay returns the unit ray from the given origin along +X
+func xRay(x, y, z float32) math32.Ray {
+	return *math32.NewRay(math32.Vec3(x, y, z), math32.Vec3(1, 0, 0))
+}
+
+func TestRayIntersectSphere(t *testing.T) {
+	c := math32.Vec3(5, 0, 0)
+	for _, tc := range []struct {
+		ray  math32.Ray
+		t    float32
+		hits bool
+	}{
+		{xRay(0, 0, 0), 4, true},
+		{xRay(0, 0.6, 0), 5 - 0.8, true},
+		{xRay(5, 0, 0), 0, true}, // from inside
+		{xRay(0, 1.1, 0), 0, false},
+		{xRay(7, 0, 0), 0, false}, // past it
+	} {
+		d, hit := RayIntersectSphere(tc.ray, c, 1)
+		if hit != tc.hits || hit && !near(d, tc.t) {
+			t.Errorf("the ray from %v hits the sphere: %v, at %g instead of %g", tc.ray.Origin, hit, d, tc.t)
+		}
+	}
+}
+
+func TestRayIntersectAABB(t *testing.T) {
+	lo, hi := math32.Vec3(2, -1, -1), math32.Vec3(4, 1, 1)
+	for _, tc := range []struct {
+		ray  math32.Ray
+		t    float32
+		hits bool
+	}{
+		{xRay(0, 0, 0), 2, true},
+		{xRay(0, 1, 1), 2, true}, // along an edge
+		{xRay(3, 0, 0), 0, true}, // from inside
+		{xRay(0, 1.1, 0), 0, false},
+		{xRay(5, 0, 0), 0, false},
+		{*math32.NewRay(math32.Vec3(0, 3, 0), math32.Vec3(1, -1, 0).Normal()), 2 * math32.Sqrt2, true}, // onto the top,
+	} {
+		d, hit := RayIntersectAABB(tc.ray, lo, hi)
+		if hit != tc.hits || hit && !near(d, tc.t) {
+			t.Errorf("the ray from %v along %v hits the box: %v, at %g instead of %g", tc.ray.Origin, tc.ray.Dir, hit, d, tc.t)
+		}
+	}
+}
+
+func TestRayIntersectTriangle(t *testing.T) {
+	a, b, c := math32.Vec3(2, 0, 0), math32.Vec3(2, 1, 0), math32.Vec3(2, 0, 1)
+	d, bary, hit := RayIntersectTriangle(xRay(0, 0.25, 0.25), a, b, c)
+	if !hit || !near(d, 2) || !nearVec(bary, math32.Vec3(0.5, 0.25, 0.25)) {
+		t.Errorf("the ray hits the triangle: %v, at %g with the weights %v", hit, d, bary)
+	}
+	// from the back, too
+	back := *math32.NewRay(math32.Vec3(4, 0.25, 0.25), math32.Vec3(-1, 0, 0))
+	if d, _, hit := RayIntersectTriangle(back, a, b, c); !hit || !near(d, 2) {
+		t.Errorf("the ray from behind hits the triangle: %v, at %g", hit, d)
+	}
+	if _, _, hit := RayIntersectTriangle(xRay(0, 0.6, 0.6), a, b, c); hit {
+		t.Error("the ray past the long edge hits the triangle")
+	}
+	if _, _, hit := RayIntersectTriangle(xRay(3, 0.25, 0.25), a, b, c); hit {
+		t.Error("the ray going away hits the triangle")
+	}
+	if _, _, hit := RayIntersectTriangle(*math32.NewRay(math32.Vec3(2, -1, 0.5), math32.Vec3(0, 1, 0)), a, b, c); hit {
+		t.Error("the ray along the plane hits the triangle")
+	}
+}
+
+func TestRayIntersectPlane(t *testing.T) {
+	pl := PlaneThrough(math32.Vec3(1, 0, 0), math32.Vec3(3, 5, 5))
+	if d, hit := RayIntersectPlane(xRay(0, 0, 0), pl); !hit || !near(d, 3) {
+		t.Errorf("the ray hits the plane: %v, at %g instead of 3", hit, d)
+	}
+	if _, hit := RayIntersectPlane(xRay(4, 0, 0), pl); hit {
+		t.Error("the ray going away hits the plane")
+	}
+	if p := ProjectOnPlane(math32.Vec3(7, 1, 2), pl); !nearVec(p, math32.Vec3(3, 1, 2)) {
+		t.Errorf("the point is projected onto the plane at %v", p)
+	}
+}
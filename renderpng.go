@@ -0,0 +1,65 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"image"
+
+	"cogentcore.org/core/base/iox/imagex"
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// RenderToPNG renders the scene of the given scene editor at the given
+// size, as seen from its current camera with its current lights, and
+// saves it as a PNG file at the given path, with the scene [Bloom], if
+// any, and FXAA if it is the [AntiAlias] mode. The frame of the scene
+// is resized for the render and then restored.
+func RenderToPNG(se *xyzcore.SceneEditor, path string, width, height int) error {
+	if width <= 0 || height <= 0 {
+		return errors.New("RenderToPNG: width and height must be positive")
+	}
+	img, err := renderImage(se.SceneXYZ(), image.Pt(width, height))
+	se.NeedsRender() // back at the size of the viewport
+	if err != nil {
+		return err
+	}
+	if SceneAntiAlias(se.SceneXYZ()).Mode == AAFXAA {
+		fxaa(img)
+	}
+	if bl := SceneBloom(se.SceneXYZ()); bl != nil {
+		bl.Apply(img)
+	}
+	return imagex.Save(img, path)
+}
+
+// saveImageDialog asks for a file name and size,
+// and saves an image of the scene of the given editor there
+func saveImageDialog(se *xyzcore.SceneEditor) {
+	sz := se.SceneXYZ().Geom.Size
+	d := core.NewBody("Save image")
+	fp := core.NewFilePicker(d).SetFilename("scene.png")
+	size := core.NewFrame(d)
+	size.Styler(func(s *styles.Style) {
+		s.Align.Items = styles.Center
+	})
+	core.NewText(size).SetText("Width")
+	wd := core.NewSpinner(size).SetMin(1).SetStep(1).SetValue(float32(sz.X))
+	core.NewText(size).SetText("Height")
+	ht := core.NewSpinner(size).SetMin(1).SetStep(1).SetValue(float32(sz.Y))
+	d.AddBottomBar(func(bar *core.Frame) {
+		d.AddCancel(bar)
+		d.AddOK(bar).SetText("Save").OnClick(func(e events.Event) {
+			err := RenderToPNG(se, fp.SelectedFile(), int(wd.Value), int(ht.Value))
+			if err != nil {
+				core.ErrorSnackbar(se, err, "Error saving image")
+			}
+		})
+	})
+	d.RunWindowDialog(se)
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js
+
+package main
+
+import (
+	"errors"
+	"image"
+
+	"cogentcore.org/core/xyz"
+)
+
+// renderImage is not supported on the web, where the scene
+// renders straight to the surface of a canvas, which can not be read back
+func renderImage(sc *xyz.Scene, size image.Point) (*image.NRGBA, error) {
+	return nil, errors.New("RenderToPNG: rendering images is not supported on the web")
+}
+
+// renderFrameImage is not supported on the web, as for renderImage
+func renderFrameImage(sc *xyz.Scene, size image.Point) (*image.NRGBA, error) {
+	return nil, errors.New("RenderToTexture: rendering images is not supported on the web")
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"image"
+
+	"cogentcore.org/core/gpu"
+	"cogentcore.org/core/xyz"
+)
+
+// renderImage renders the given scene at the given size and reads the
+// result back from the GPU. The frame texture has no read buffer while
+// it is shown, and [xyz.Scene.Image] is not implemented, so the texture
+// is copied to one with its own command after the render.
+func renderImage(sc *xyz.Scene, size image.Point) (*image.NRGBA, error) {
+	rt, ok := sc.Frame.(*gpu.RenderTexture)
+	if !ok {
+		return nil, errors.New("RenderToPNG: the scene has not been rendered yet")
+	}
+	old := sc.Geom.Size
+	sc.SetSize(size)
+	defer func() {
+		sc.SetSize(old)
+		sc.Camera.Aspect = float32(old.X) / float32(old.Y)
+		sc.SetNeedsRender()
+	}()
+	sc.UpdateNodes()
+	sc.Render()
+	return readFrame(rt)
+}
+
+// renderFrameImage renders the given scene into its frame at the size
+// that it already has, so that it is not made again, with the camera
+// set for an image of the given size, and reads the result back from
+// the GPU. The image is stretched to the size of the frame, to be
+// scaled back to the given size.
+func renderFrameImage(sc *xyz.Scene, size image.Point) (*image.NRGBA, error) {
+	rt, ok := sc.Frame.(*gpu.RenderTexture)
+	if !ok {
+		return nil, errors.New("RenderToTexture: the scene has not been rendered yet")
+	}
+	old := sc.Geom.Size
+	sc.Geom.Size = size // only sets the aspect of the camera
+	defer func() {
+		sc.Geom.Size = old
+		sc.Camera.Aspect = float32(old.X) / float32(old.Y)
+		sc.SetNeedsRender()
+	}()
+	sc.UpdateNodes()
+	sc.Render()
+	return readFrame(rt)
+}
+
+// readFrame reads the image of the given frame back from the GPU
+func readFrame(rt *gpu.RenderTexture) (*image.NRGBA, error) {
+	tx := rt.Frames[0]
+	if err := tx.ConfigReadBuffer(); err != nil {
+		return nil, err
+	}
+	dev := rt.Device()
+	cmd, err := dev.Device.CreateCommandEncoder(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cmd.Release()
+	if err := tx.CopyToReadBuffer(cmd); err != nil {
+		return nil, err
+	}
+	buf, err := cmd.Finish(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Release()
+	dev.Queue.Submit(buf)
+
+	// ReadGoImage uses the padded row size as the stride of the unpadded data
+	img := image.NewNRGBA(image.Rectangle{Max: tx.Format.Size})
+	if err := tx.ReadData(&img.Pix, true); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
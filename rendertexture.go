@@ -0,0 +1,105 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"sync"
+
+	"cogentcore.org/core/base/iox/imagex"
+	"cogentcore.org/core/xyz"
+)
+
+// RenderTexture is a texture showing the scene as seen from its own
+// Camera, as on a security camera monitor or a TV screen in the scene,
+// for the material of a solid with [xyz.Solid.SetTexture]. There is
+// only one frame for each scene on the GPU, so [RenderTexture.Render]
+// renders the scene into it before the main render, at the size that
+// the frame already has, so that it is not made again on every render,
+// and reads the image back to scale and upload as the texture.
+// Use [SimpleAnim.AnimateRenderTexture] to render it on each tick.
+// Images can not be read back on the web, so it is not updated there.
+type RenderTexture struct {
+	xyz.TextureBase
+
+	// Scene rendered
+	Scene *xyz.Scene
+
+	// Camera the scene is seen from, which starts as
+	// a copy of the camera of the scene
+	Camera xyz.Camera
+
+	// Size of the texture in pixels
+	Size image.Point
+}
+
+// RenderToTexture returns a new [RenderTexture] of the given size,
+// added to the textures of the given scene, seen from its current camera
+func RenderToTexture(sc *xyz.Scene, width, height int) (*RenderTexture, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("RenderToTexture: width and height must be positive")
+	}
+	rt := &RenderTexture{Scene: sc, Camera: sc.Camera, Size: image.Pt(width, height)}
+	rt.Name = fmt.Sprintf("__RenderTexture%d", len(sc.Textures.Order))
+	rt.RGBA = image.NewRGBA(image.Rectangle{Max: rt.Size})
+	sc.SetTexture(rt)
+	return rt, nil
+}
+
+// Render renders the scene from the camera of the texture and uploads
+// the image as the texture. It must be called when the scene is not
+// rendering, as from an event handler or with the widget AsyncLock held.
+func (rt *RenderTexture) Render() error {
+	sc := rt.Scene
+	cam := sc.Camera
+	sc.Camera = rt.Camera
+	img, err := renderFrameImage(sc, rt.Size)
+	rt.Camera, sc.Camera = sc.Camera, cam
+	if err != nil {
+		return err
+	}
+	draw.Draw(rt.RGBA, rt.RGBA.Bounds(), imagex.Resize(img, rt.Size), image.Point{}, draw.Src)
+	sc.SetTexture(rt)
+	return nil
+}
+
+// RenderTextureAnim renders a [RenderTexture] on each tick of a SimpleAnim
+type RenderTextureAnim struct {
+	// Animation driving the rendering, whose scene has the texture
+	Anim *SimpleAnim
+
+	// Texture rendered
+	Texture *RenderTexture
+
+	// Err is the last error rendering the texture, which stops it
+	Err error `edit:"-"`
+
+	// mu protects all fields from the ticker goroutine
+	mu sync.Mutex
+}
+
+// AnimateRenderTexture starts rendering the given texture on each tick
+func (a *SimpleAnim) AnimateRenderTexture(rt *RenderTexture) *RenderTextureAnim {
+	ra := &RenderTextureAnim{Anim: a, Texture: rt}
+	a.AddAnimator(ra)
+	return ra
+}
+
+// Step implements [Animator]
+func (ra *RenderTextureAnim) Step(dt float32) bool {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	if ra.Texture == nil || ra.Err != nil || ra.Anim.SceneEditor == nil {
+		return false
+	}
+	sw := ra.Anim.SceneEditor.SceneWidget()
+	sw.AsyncLock()
+	ra.Err = ra.Texture.Render()
+	sw.AsyncUnlock()
+	return ra.Err == nil
+}
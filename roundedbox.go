@@ -0,0 +1,145 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// RoundedBox is a box centered on the origin with all of its edges and
+// corners rounded by a radius: each edge is a quarter of a cylinder,
+// each corner an eighth of a sphere, and each face a flat quad. Each
+// face is a grid out to the middle of the rounding on each side, where
+// it meets the next one, and their vertices there are shared, so the
+// mesh is closed, with one vertex for each point of the surface. With
+// a radius of 0, it is a plain box with separate vertices for each face.
+type RoundedBox struct {
+	xyz.MeshBase
+
+	// Size of the box along X, Y and Z
+	Size math32.Vector3
+
+	// Radius of the rounding of the edges and corners, which is at most
+	// half of the smallest size
+	Radius float32
+
+	// Segments is the number of segments in each quarter circle of the rounding
+	Segments int
+
+	// vertex, normal, texture and index arrays, made by MeshSize
+	vertex, normal, texcoord math32.ArrayF32
+	index                    math32.ArrayU32
+}
+
+// NewRoundedBox returns a new rounded box mesh of the given size with
+// the given radius of rounding and number of segments in each quarter
+// circle of it, set on the given scene under the given name
+func NewRoundedBox(sc *xyz.Scene, name string, width, height, depth, radius float32, segments int) *RoundedBox {
+	rb := &RoundedBox{Size: math32.Vec3(width, height, depth), Radius: radius, Segments: segments}
+	rb.Name = name
+	sc.SetMesh(rb)
+	return rb
+}
+
+func (rb *RoundedBox) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	rb.build()
+	rb.NumVertex = len(rb.vertex) / 3
+	rb.NumIndex = len(rb.index)
+	return rb.NumVertex, rb.NumIndex, false
+}
+
+func (rb *RoundedBox) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	copy(vertex, rb.vertex)
+	copy(normal, rb.normal)
+	copy(texcoord, rb.texcoord)
+	copy(index, rb.index)
+	bb := shape.BBoxFromVtxs(vertex, 0, rb.NumVertex)
+	rb.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// build makes the vertex arrays of the box
+func (rb *RoundedBox) build() {
+	rb.vertex, rb.normal, rb.texcoord, rb.index = nil, nil, nil, nil
+	half := rb.Size.Abs().MulScalar(0.5)
+	r := math32.Clamp(rb.Radius, 0, min(half.X, half.Y, half.Z))
+	inner := half.SubScalar(r) // the box of the centers of the rounding
+	// each face rounds half way around each edge, to 45 degrees
+	segs := max((rb.Segments+1)/2, 1)
+	if r == 0 {
+		segs = 0
+	}
+	samples := func(in float32) []float32 {
+		var s []float32
+		for k := segs; k > 0; k-- {
+			s = append(s, -in-r*math32.Tan(math32.Pi/4*float32(k)/float32(segs)))
+		}
+		s = append(s, -in)
+		if in > 0 {
+			s = append(s, in)
+		}
+		for k := 1; k <= segs; k++ {
+			s = append(s, in+r*math32.Tan(math32.Pi/4*float32(k)/float32(segs)))
+		}
+		return s
+	}
+	// vertices meeting at the edges of the faces are shared
+	shared := map[[3]int32]uint32{}
+	scale := 1e5 / max(half.X, half.Y, half.Z, 1e-6)
+	addVertex := func(pos, nrm math32.Vector3, u, v float32) uint32 {
+		key := [3]int32{int32(math32.Round(pos.X * scale)), int32(math32.Round(pos.Y * scale)), int32(math32.Round(pos.Z * scale))}
+		if i, ok := shared[key]; ok && r > 0 {
+			return i
+		}
+		i := uint32(len(rb.vertex) / 3)
+		shared[key] = i
+		rb.vertex.AppendVector3(pos)
+		rb.normal.AppendVector3(nrm)
+		rb.texcoord.Append(u, v)
+		return i
+	}
+	for a := math32.X; a <= math32.Z; a++ {
+		ua, va := (a+1)%3, (a+2)%3
+		us, vs := samples(inner.Dim(ua)), samples(inner.Dim(va))
+		for _, sign := range []float32{1, -1} {
+			grid := make([]uint32, len(us)*len(vs))
+			for j, sv := range vs {
+				for i, su := range us {
+					var p math32.Vector3
+					p.SetDim(a, sign*half.Dim(a))
+					p.SetDim(ua, su)
+					p.SetDim(va, sv)
+					ctr := p
+					ctr.Clamp(inner.Negate(), inner)
+					nrm := p.Sub(ctr).Normal()
+					pos := ctr.Add(nrm.MulScalar(r))
+					if r == 0 {
+						pos = p
+						nrm = math32.Vector3{}
+						nrm.SetDim(a, sign)
+					}
+					u := (su + half.Dim(ua)) / (2 * half.Dim(ua))
+					if sign < 0 {
+						u = 1 - u // as seen from outside
+					}
+					v := 1 - (sv+half.Dim(va))/(2*half.Dim(va))
+					grid[j*len(us)+i] = addVertex(pos, nrm, u, v)
+				}
+			}
+			for j := range len(vs) - 1 {
+				for i := range len(us) - 1 {
+					v0 := grid[j*len(us)+i]
+					v1, v2, v3 := grid[j*len(us)+i+1], grid[(j+1)*len(us)+i], grid[(j+1)*len(us)+i+1]
+					if sign > 0 {
+						rb.index.Append(v0, v1, v2, v1, v3, v2)
+					} else {
+						rb.index.Append(v0, v2, v1, v1, v2, v3)
+					}
+				}
+			}
+		}
+	}
+}
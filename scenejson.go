@@ -0,0 +1,398 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/text/text"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// SceneMeshTypes maps the type names used in scene JSON files to
+// functions returning a new mesh of that type. Meshes of other types
+// are skipped when saving.
+var SceneMeshTypes = map[string]func() xyz.Mesh{
+	"Box":      func() xyz.Mesh { return &xyz.Box{} },
+	"Sphere":   func() xyz.Mesh { return &xyz.Sphere{} },
+	"Cylinder": func() xyz.Mesh { return &xyz.Cylinder{} },
+	"Capsule":  func() xyz.Mesh { return &xyz.Capsule{} },
+	"Torus":    func() xyz.Mesh { return &xyz.Torus{} },
+	"Plane":    func() xyz.Mesh { return &xyz.Plane{} },
+	"Lines":    func() xyz.Mesh { return &xyz.Lines{} },
+
+	"ColoredVertices": func() xyz.Mesh { return &ColoredVertices{} },
+	"ArrayMesh":       func() xyz.Mesh { return &ArrayMesh{} },
+	"HeightMapPlane":  func() xyz.Mesh { return &HeightMapPlane{} },
+	"Tube":            func() xyz.Mesh { return &Tube{} },
+	"TorusKnot":       func() xyz.Mesh { return &TorusKnot{} },
+	"CapsuleMesh":     func() xyz.Mesh { return &CapsuleMesh{} },
+	"RoundedBox":      func() xyz.Mesh { return &RoundedBox{} },
+	"Icosphere":       func() xyz.Mesh { return &Icosphere{} },
+	"ConeMesh":        func() xyz.Mesh { return &ConeMesh{} },
+	"Disk":            func() xyz.Mesh { return &Disk{} },
+	"GridLines":       func() xyz.Mesh { return &GridLines{} },
+	"LineStrip":       func() xyz.Mesh { return &LineStrip{} },
+}
+
+// SceneLightTypes maps the type names used in scene JSON files to
+// functions returning a new light of that type
+var SceneLightTypes = map[string]func() xyz.Light{
+	"Ambient":     func() xyz.Light { return &xyz.Ambient{} },
+	"Directional": func() xyz.Light { return &xyz.Directional{} },
+	"Point":       func() xyz.Light { return &xyz.Point{} },
+	"Spot":        func() xyz.Light { return &xyz.Spot{} },
+}
+
+// SceneJSON is the JSON document for a saved scene
+type SceneJSON struct {
+	Camera   CameraJSON
+	Meshes   []TypedJSON
+	Lights   []TypedJSON
+	Children []NodeJSON
+
+	// HiddenLayers are the layers of solids that are hidden
+	HiddenLayers []string `json:",omitempty"`
+}
+
+// TypedJSON is a mesh or light, with the name of its type
+type TypedJSON struct {
+	Type  string
+	Value json.RawMessage
+}
+
+// CameraJSON is the saved state of the scene camera
+type CameraJSON struct {
+	Pose   PoseJSON
+	Target math32.Vector3
+	UpDir  math32.Vector3
+	Ortho  bool
+	FOV    float32
+	Near   float32
+	Far    float32
+}
+
+// PoseJSON is the saved position, rotation and scale of a node
+type PoseJSON struct {
+	Pos   math32.Vector3
+	Quat  math32.Quat
+	Scale math32.Vector3
+}
+
+// MaterialJSON is the saved material of a solid
+type MaterialJSON struct {
+	Color       color.RGBA
+	Emissive    color.RGBA
+	Shiny       float32
+	Reflective  float32
+	Bright      float32
+	TextureName string `json:",omitempty"`
+	Tiling      phong.Tiling
+	CullBack    bool
+	CullFront   bool
+}
+
+// NodeJSON is a saved node of the scene tree
+type NodeJSON struct {
+	// Type is Solid for a demo [Solid], InstancedSolid,
+	// xyz.Solid, Group, Text2D for a demo [Text2D],
+	// or Annotation for an [Annotation] of its parent
+	Type              string
+	Name              string
+	Invisible         bool   `json:",omitempty"`
+	Layer             string `json:",omitempty"`
+	Pose              PoseJSON
+	Mesh              string         `json:",omitempty"`
+	Material          *MaterialJSON  `json:",omitempty"`
+	Metallic          float32        `json:",omitempty"`
+	Roughness         float32        `json:",omitempty"`
+	NoDepthTest       bool           `json:",omitempty"`
+	NoDepthWrite      bool           `json:",omitempty"`
+	PolygonOffset     []float32      `json:",omitempty"`
+	BlendMode         BlendModes     `json:",omitempty"`
+	EmissiveMap       string         `json:",omitempty"`
+	EmissiveIntensity float32        `json:",omitempty"`
+	Text              string         `json:",omitempty"`
+	Align             text.Aligns    `json:",omitempty"`
+	Billboard         bool           `json:",omitempty"`
+	AxisLock          bool           `json:",omitempty"`
+	Instances         []InstanceData `json:",omitempty"`
+	Children          []NodeJSON     `json:",omitempty"`
+}
+
+// MarshalSceneJSON returns the camera, meshes, lights and all of the
+// nodes of the given scene as JSON. Nodes and meshes with reserved
+// names starting with "__", such as the editor selection box, are skipped.
+func MarshalSceneJSON(sc *xyz.Scene) ([]byte, error) {
+	sj := &SceneJSON{}
+	cm := &sc.Camera
+	sj.Camera = CameraJSON{Pose: poseToJSON(&cm.Pose), Target: cm.Target, UpDir: cm.UpDir,
+		Ortho: cm.Ortho, FOV: cm.FOV, Near: cm.Near, Far: cm.Far}
+	for _, kv := range sc.Meshes.Order {
+		if strings.HasPrefix(kv.Key, "__") { // made by nodes as needed
+			continue
+		}
+		tj, err := typedToJSON(kv.Value, SceneMeshTypes)
+		if err != nil {
+			return nil, err
+		}
+		if tj != nil {
+			sj.Meshes = append(sj.Meshes, *tj)
+		}
+	}
+	for _, kv := range sc.Lights.Order {
+		tj, err := typedToJSON(kv.Value, SceneLightTypes)
+		if err != nil {
+			return nil, err
+		}
+		if tj != nil {
+			sj.Lights = append(sj.Lights, *tj)
+		}
+	}
+	sj.Children = nodesToJSON(sc.Children)
+	sj.HiddenLayers = HiddenLayers(sc)
+	return json.MarshalIndent(sj, "", "\t")
+}
+
+// UnmarshalSceneJSON replaces the contents of the given scene with
+// the scene in the given JSON, as saved by [MarshalSceneJSON]
+func UnmarshalSceneJSON(sc *xyz.Scene, data []byte) error {
+	sj := &SceneJSON{}
+	if err := json.Unmarshal(data, sj); err != nil {
+		return err
+	}
+	sc.DeleteChildren()
+	sc.ResetMeshes()
+	sc.Lights.Reset()
+	for _, tj := range sj.Meshes {
+		nf, ok := SceneMeshTypes[tj.Type]
+		if !ok {
+			return fmt.Errorf("UnmarshalSceneJSON: unknown mesh type %q", tj.Type)
+		}
+		ms := nf()
+		if err := json.Unmarshal(tj.Value, ms); err != nil {
+			return err
+		}
+		sc.SetMesh(ms)
+	}
+	for _, tj := range sj.Lights {
+		nf, ok := SceneLightTypes[tj.Type]
+		if !ok {
+			return fmt.Errorf("UnmarshalSceneJSON: unknown light type %q", tj.Type)
+		}
+		lt := nf()
+		if err := json.Unmarshal(tj.Value, lt); err != nil {
+			return err
+		}
+		sc.AddLight(lt)
+	}
+	cm := &sc.Camera
+	poseFromJSON(&cm.Pose, sj.Camera.Pose)
+	cm.Target, cm.UpDir = sj.Camera.Target, sj.Camera.UpDir
+	cm.Ortho, cm.FOV, cm.Near, cm.Far = sj.Camera.Ortho, sj.Camera.FOV, sj.Camera.Near, sj.Camera.Far
+	cm.UpdateMatrix()
+	for _, nj := range sj.Children {
+		if err := nodeFromJSON(sc, sc, nj); err != nil {
+			return err
+		}
+	}
+	SetHiddenLayers(sc, sj.HiddenLayers)
+	sc.Rebuild()
+	sc.SetNeedsUpdate()
+	return nil
+}
+
+// SaveSceneJSON saves the given scene to the given JSON file
+func SaveSceneJSON(sc *xyz.Scene, filename string) error {
+	b, err := MarshalSceneJSON(sc)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, b, 0666)
+}
+
+// OpenSceneJSON replaces the given scene with the scene in the given JSON file
+func OpenSceneJSON(sc *xyz.Scene, filename string) error {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return UnmarshalSceneJSON(sc, b)
+}
+
+// typedToJSON returns the given mesh or light with its type name,
+// or nil if the type is not in the given map of types
+func typedToJSON[T any](v T, types map[string]func() T) (*TypedJSON, error) {
+	tn := reflect.TypeOf(v).Elem().Name()
+	if _, ok := types[tn]; !ok {
+		log.Printf("Scene JSON: skipping unsupported type %s\n", tn)
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedJSON{Type: tn, Value: b}, nil
+}
+
+func poseToJSON(ps *xyz.Pose) PoseJSON {
+	return PoseJSON{Pos: ps.Pos, Quat: ps.Quat, Scale: ps.Scale}
+}
+
+func poseFromJSON(ps *xyz.Pose, pj PoseJSON) {
+	ps.Pos, ps.Quat, ps.Scale = pj.Pos, pj.Quat, pj.Scale
+}
+
+func materialToJSON(mt *xyz.Material) *MaterialJSON {
+	return &MaterialJSON{Color: mt.Color, Emissive: mt.Emissive, Shiny: mt.Shiny,
+		Reflective: mt.Reflective, Bright: mt.Bright, TextureName: string(mt.TextureName),
+		Tiling: mt.Tiling, CullBack: mt.CullBack, CullFront: mt.CullFront}
+}
+
+func materialFromJSON(sc *xyz.Scene, mt *xyz.Material, mj *MaterialJSON) {
+	mt.Color, mt.Emissive, mt.Shiny = mj.Color, mj.Emissive, mj.Shiny
+	mt.Reflective, mt.Bright, mt.Tiling = mj.Reflective, mj.Bright, mj.Tiling
+	mt.CullBack, mt.CullFront = mj.CullBack, mj.CullFront
+	if mj.TextureName != "" {
+		mt.SetTextureName(sc, mj.TextureName)
+	}
+}
+
+// nodesToJSON returns the given xyz nodes, skipping reserved names
+func nodesToJSON(nodes []tree.Node) []NodeJSON {
+	var njs []NodeJSON
+	for _, k := range nodes {
+		n, nb := xyz.AsNode(k)
+		if n == nil || strings.HasPrefix(nb.Name, "__") {
+			continue
+		}
+		nj := NodeJSON{Name: nb.Name, Invisible: nb.Invisible, Pose: poseToJSON(&nb.Pose)}
+		switch x := k.(type) {
+		case *Annotation:
+			nj.Type = "Annotation"
+			nj.Text = x.Text
+		case *Text2D:
+			nj.Type = "Text2D"
+			nj.Text = x.Text
+			nj.Align = x.Styles.Text.Align
+			nj.Billboard, nj.AxisLock = x.Billboard, x.AxisLock
+			nj.NoDepthTest = !x.DepthTest
+		case *xyz.Text2D:
+			nj.Type = "Text2D"
+			nj.Text = x.Text
+			nj.Align = x.Styles.Text.Align
+		case *InstancedSolid:
+			nj.Type = "InstancedSolid"
+			x.mu.Lock()
+			nj.Instances = x.Instances
+			x.mu.Unlock()
+		case *Solid:
+			nj.Type = "Solid"
+			nj.Metallic, nj.Roughness = x.Metallic, x.Roughness
+			nj.NoDepthTest, nj.NoDepthWrite = !x.DepthTest, !x.DepthWrite
+			if x.PolygonOffset {
+				nj.PolygonOffset = []float32{x.PolygonOffsetFactor, x.PolygonOffsetUnits}
+			}
+			nj.BlendMode = x.BlendMode
+			nj.EmissiveMap, nj.EmissiveIntensity = string(x.EmissiveMap), x.EmissiveIntensity
+		case *xyz.Solid:
+			nj.Type = "xyz.Solid"
+		case *xyz.Group:
+			nj.Type = "Group"
+		default:
+			log.Printf("Scene JSON: skipping node %s of unsupported type %T\n", nb.Name, k)
+			continue
+		}
+		if sld := n.AsSolid(); sld != nil && nj.Type != "Text2D" && nj.Type != "Annotation" {
+			nj.Mesh = string(sld.MeshName)
+			if is, ok := k.(*InstancedSolid); ok {
+				nj.Mesh = string(is.BaseMesh)
+			}
+			nj.Material = materialToJSON(&sld.Material)
+		}
+		if s, ok := k.(interface{ asSolid() *Solid }); ok {
+			nj.Layer = s.asSolid().Layer
+		}
+		nj.Children = nodesToJSON(nb.Children)
+		njs = append(njs, nj)
+	}
+	return njs
+}
+
+// nodeFromJSON adds the given saved node to the given parent
+func nodeFromJSON(sc *xyz.Scene, parent tree.Node, nj NodeJSON) error {
+	var n xyz.Node
+	switch nj.Type {
+	case "Annotation":
+		psld, ok := parent.(xyz.Node)
+		if !ok || psld.AsSolid() == nil {
+			return fmt.Errorf("UnmarshalSceneJSON: annotation %q is not on a solid", nj.Name)
+		}
+		n = PinAnnotation(psld.AsSolid(), nj.Text)
+	case "Text2D":
+		txt := NewText2D(parent)
+		txt.Styles.Text.Align = nj.Align
+		txt.Billboard, txt.AxisLock = nj.Billboard, nj.AxisLock
+		txt.DepthTest = !nj.NoDepthTest
+		txt.SetText(nj.Text)
+		n = txt
+	case "Solid":
+		sld := NewSolid(parent)
+		sld.Metallic, sld.Roughness = nj.Metallic, nj.Roughness
+		sld.DepthTest, sld.DepthWrite = !nj.NoDepthTest, !nj.NoDepthWrite
+		if len(nj.PolygonOffset) == 2 {
+			sld.PolygonOffset = true
+			sld.PolygonOffsetFactor, sld.PolygonOffsetUnits = nj.PolygonOffset[0], nj.PolygonOffset[1]
+		}
+		sld.BlendMode = nj.BlendMode
+		sld.EmissiveMap, sld.EmissiveIntensity = xyz.TextureName(nj.EmissiveMap), nj.EmissiveIntensity
+		n = sld
+	case "InstancedSolid":
+		base, err := sc.MeshByName(nj.Mesh)
+		if err != nil {
+			return err
+		}
+		n = NewInstancedSolid(parent, nj.Name, base, nj.Instances)
+		nj.Mesh = ""
+	case "xyz.Solid":
+		n = xyz.NewSolid(parent)
+	case "Group":
+		n = xyz.NewGroup(parent)
+	default:
+		return fmt.Errorf("UnmarshalSceneJSON: unknown node type %q", nj.Type)
+	}
+	nb := n.AsNodeBase()
+	nb.SetName(nj.Name)
+	nb.Invisible = nj.Invisible
+	if s, ok := n.(interface{ asSolid() *Solid }); ok {
+		s.asSolid().Layer = nj.Layer
+	}
+	poseFromJSON(&nb.Pose, nj.Pose)
+	if sld := n.AsSolid(); sld != nil && nj.Type != "Text2D" && nj.Type != "Annotation" {
+		if nj.Mesh != "" {
+			if err := sld.SetMeshName(nj.Mesh); err != nil {
+				return err
+			}
+		}
+		if nj.Material != nil {
+			materialFromJSON(sc, &sld.Material, nj.Material)
+		}
+	}
+	for _, cj := range nj.Children {
+		if err := nodeFromJSON(sc, n, cj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
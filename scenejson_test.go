@@ -0,0 +1,83 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"cogentcore.org/core/xyz"
+)
+
+func TestSceneJSONRoundTrip(t *testing.T) {
+	sc := newDemoTestScene()
+	sc.ChildByName("cube", 0).(*Solid).Layer = "shapes"
+	SetLayerVisible(sc, "shapes", false)
+	path := filepath.Join(t.TempDir(), "scene.json")
+	if err := SaveSceneJSON(sc, path); err != nil {
+		t.Fatal(err)
+	}
+	op := newTestScene()
+	NewSolid(op).SetName("old") // replaced by the opened scene
+	if err := OpenSceneJSON(op, path); err != nil {
+		t.Fatal(err)
+	}
+	updateTestScene(op)
+
+	want, got := testSolids(sc), testSolids(op)
+	if len(got) != len(want) {
+		t.Errorf("opened %d solids instead of %d", len(got), len(want))
+	}
+	for name, ws := range want {
+		gs := got[name]
+		if gs == nil {
+			t.Errorf("solid %s was not opened", name)
+			continue
+		}
+		if gs.Pose.Pos != ws.Pose.Pos || gs.Pose.Quat != ws.Pose.Quat || gs.Pose.Scale != ws.Pose.Scale {
+			t.Errorf("solid %s has the pose %v instead of %v", name, gs.Pose, ws.Pose)
+		}
+		if gs.MeshName != ws.MeshName || gs.WorldBBox.BBox != ws.WorldBBox.BBox {
+			t.Errorf("solid %s has the mesh %s with the bounds %v instead of %s with %v",
+				name, gs.MeshName, gs.WorldBBox.BBox, ws.MeshName, ws.WorldBBox.BBox)
+		}
+		if gs.Material.Color != ws.Material.Color || gs.Material.Shiny != ws.Material.Shiny {
+			t.Errorf("solid %s has the material %v instead of %v", name, gs.Material, ws.Material)
+		}
+		if reflect.TypeOf(gs.This) != reflect.TypeOf(ws.This) {
+			t.Errorf("solid %s is a %T instead of a %T", name, gs.This, ws.This)
+		}
+	}
+	if cyl := got["cylinder"]; cyl == nil || cyl.This.(*Solid).Metallic != 1 {
+		t.Error("the cylinder lost its metallic material")
+	}
+	if hl := HiddenLayers(op); !reflect.DeepEqual(hl, []string{"shapes"}) {
+		t.Errorf("the hidden layers are %v instead of shapes", hl)
+	}
+	if len(op.Lights.Order) != len(sc.Lights.Order) {
+		t.Errorf("opened %d lights instead of %d", len(op.Lights.Order), len(sc.Lights.Order))
+	}
+	for _, kv := range sc.Lights.Order {
+		if lt := op.Lights.ValueByKey(kv.Key); !reflect.DeepEqual(lt, kv.Value) {
+			t.Errorf("light %s is %+v instead of %+v", kv.Key, lt, kv.Value)
+		}
+	}
+	if op.Camera.Pose.Pos != sc.Camera.Pose.Pos || op.Camera.FOV != sc.Camera.FOV {
+		t.Errorf("the camera is at %v with a FOV of %g instead of %v and %g",
+			op.Camera.Pose.Pos, op.Camera.FOV, sc.Camera.Pose.Pos, sc.Camera.FOV)
+	}
+}
+
+func TestSceneJSONUnknownType(t *testing.T) {
+	js := `{"Children": [{"Type": "Spaceship", "Name": "ship"}]}`
+	if err := UnmarshalSceneJSON(xyz.NewScene(), []byte(js)); err == nil {
+		t.Error("opened a node of an unknown type")
+	}
+	js = `{"Meshes": [{"Type": "Teapot", "Value": {}}]}`
+	if err := UnmarshalSceneJSON(xyz.NewScene(), []byte(js)); err == nil {
+		t.Error("opened a mesh of an unknown type")
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+)
+
+// SceneTreePanel is a sidebar listing the nodes of the scene of a
+// scene editor, indented under their parents. Clicking a node selects
+// it in the scene, unless it is Locked, double clicking renames it, the
+// eye button hides and shows it, the lock button locks and unlocks it,
+// and dragging a node onto another moves it there.
+// Nodes with reserved names, such as the grid and the manipulation
+// box, are not listed. Above the tree, the layers of the solids are
+// listed with a checkbox to show and hide each, and a button to
+// isolate it; the tree tooltip of a solid gives its layer.
+type SceneTreePanel struct {
+	core.Frame
+
+	// SceneEditor whose scene is listed
+	SceneEditor *xyzcore.SceneEditor `set:"-"`
+
+	// Layers lists the layers of the solids of the scene
+	Layers *core.Frame `set:"-"`
+
+	// Tree listing the nodes of the scene
+	Tree *SceneTree `set:"-"`
+}
+
+// NewSceneTreePanel adds a new scene tree panel for the given
+// scene editor to the given parent
+func NewSceneTreePanel(parent core.Widget, se *xyzcore.SceneEditor) *SceneTreePanel {
+	sp := tree.New[SceneTreePanel](parent)
+	sp.SceneEditor = se
+	sp.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.Overflow.Set(styles.OverflowAuto)
+	})
+	sp.Layers = core.NewFrame(sp)
+	sp.Layers.SetName("layers")
+	sp.Layers.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+	sp.Layers.Maker(sp.makeLayers)
+	sp.Tree = tree.New[SceneTree](sp)
+	sp.Tree.SetName("scene")
+	sp.Tree.OpenDepth = 2
+	sp.Tree.OnSelect(func(e events.Event) {
+		sels := sp.Tree.SelectedNodes
+		if len(sels) == 0 {
+			return
+		}
+		if nd, ok := sels[0].AsCoreTree().SyncNode.(xyz.Node); ok && !isLocked(nd) {
+			sw := se.SceneWidget()
+			sw.SetSelected(nil) // rebuilds the manipulation box
+			sw.SetSelected(nd)
+			sw.NeedsRender()
+		}
+	})
+	sp.Resync()
+	return sp
+}
+
+// Resync updates the panel to match the scene
+// after nodes are added, removed or renamed
+func (sp *SceneTreePanel) Resync() {
+	sp.Tree.SyncTree(sp.SceneEditor.SceneXYZ())
+	sp.Layers.Update()
+}
+
+// makeLayers adds a row for each layer of the scene
+func (sp *SceneTreePanel) makeLayers(p *tree.Plan) {
+	sc := sp.SceneEditor.SceneXYZ()
+	for _, layer := range SceneLayers(sc) {
+		tree.AddAt(p, "layer-"+layer, func(w *core.Frame) {
+			w.Styler(func(s *styles.Style) {
+				s.Align.Items = styles.Center
+			})
+			tree.AddChild(w, func(w *core.Switch) {
+				w.SetType(core.SwitchCheckbox).SetText(layerName(layer)).
+					SetTooltip("show or hide the solids in this layer")
+				w.Updater(func() {
+					w.SetChecked(LayerVisible(sc, layer))
+				})
+				w.OnChange(func(e events.Event) {
+					SetLayerVisible(sc, layer, w.IsChecked())
+					sp.layersChanged()
+				})
+			})
+			tree.AddChild(w, func(w *core.Button) {
+				w.SetType(core.ButtonAction).SetIcon(icons.FilterCenterFocus).
+					SetTooltip("show only the solids in this layer")
+				w.OnClick(func(e events.Event) {
+					IsolateLayer(sc, layer)
+					sp.layersChanged()
+				})
+			})
+		})
+	}
+}
+
+// layersChanged updates the panel and scene after layers are shown or hidden
+func (sp *SceneTreePanel) layersChanged() {
+	sp.Layers.Update()
+	sw := sp.SceneEditor.SceneWidget()
+	sw.SetSelected(nil) // it may be hidden now
+	sw.NeedsRender()
+}
+
+// SceneTree is a node of a [SceneTreePanel], which shows the type of
+// its scene node with an icon, and can hide it with an eye button
+type SceneTree struct {
+	core.Tree
+}
+
+func (st *SceneTree) Init() {
+	st.Tree.Init()
+	st.Styler(func(s *styles.Style) {
+		if isReservedNode(st.SyncNode) {
+			s.Display = styles.DisplayNone
+		}
+	})
+	st.Updater(func() {
+		switch st.SyncNode.(type) {
+		case *xyz.Scene:
+			st.Icon = icons.Landscape
+		case *xyz.Group:
+			st.Icon = icons.Folder
+		default:
+			st.Icon = icons.DeployedCode
+		}
+		if s, ok := st.SyncNode.(interface{ asSolid() *Solid }); ok {
+			st.Tooltip = "layer: " + layerName(s.asSolid().Layer)
+		}
+	})
+	st.Parts.OnDoubleClick(func(e events.Event) {
+		if _, ok := st.SyncNode.(xyz.Node); ok {
+			e.SetHandled() // instead of opening and closing
+			st.rename()
+		}
+	})
+	st.Parts.Maker(func(p *tree.Plan) {
+		if !canHide(st.SyncNode) {
+			return
+		}
+		tree.AddAt(p, "visible", func(w *core.Button) {
+			w.SetType(core.ButtonAction).SetTooltip("hide or show")
+			w.Styler(func(s *styles.Style) {
+				s.Padding.Zero()
+				s.IconSize = st.Styles.IconSize
+			})
+			w.Updater(func() {
+				if st.SyncNode.(xyz.Node).AsNodeBase().Invisible {
+					w.SetIcon(icons.VisibilityOff)
+				} else {
+					w.SetIcon(icons.Visibility)
+				}
+			})
+			w.OnClick(func(e events.Event) {
+				nb := st.SyncNode.(xyz.Node).AsNodeBase()
+				nb.Invisible = !nb.Invisible
+				e.SetHandled()
+				w.Update()
+				st.sceneChanged()
+			})
+		})
+		ds, ok := st.SyncNode.(interface{ asSolid() *Solid })
+		if !ok {
+			return
+		}
+		tree.AddAt(p, "locked", func(w *core.Button) {
+			w.SetType(core.ButtonAction).SetTooltip("lock or unlock, keeping it from being selected and moved")
+			w.Styler(func(s *styles.Style) {
+				s.Padding.Zero()
+				s.IconSize = st.Styles.IconSize
+			})
+			w.Updater(func() {
+				if ds.asSolid().Locked {
+					w.SetIcon(icons.Lock)
+				} else {
+					w.SetIcon(icons.LockOpen)
+				}
+			})
+			w.OnClick(func(e events.Event) {
+				sld := ds.asSolid()
+				sld.Locked = !sld.Locked
+				e.SetHandled()
+				w.Update()
+				st.sceneChanged()
+			})
+		})
+	})
+}
+
+// rename asks for a new name for the scene node
+func (st *SceneTree) rename() {
+	nd := st.SyncNode
+	d := core.NewBody("Rename")
+	tf := core.NewTextField(d).SetText(nd.AsTree().Name)
+	tf.Styler(func(s *styles.Style) {
+		s.Min.X.Ch(30)
+	})
+	d.AddBottomBar(func(bar *core.Frame) {
+		d.AddCancel(bar)
+		d.AddOK(bar).OnClick(func(e events.Event) {
+			name := strings.TrimSpace(tf.Text())
+			if name == "" || name == nd.AsTree().Name {
+				return
+			}
+			nd.AsTree().SetName(name)
+			if par := nd.AsTree().Parent; par != nil {
+				setUniqueName(par, nd)
+			}
+			st.sceneChanged()
+			st.Root.AsCoreTree().Resync()
+		})
+	})
+	d.RunDialog(st)
+}
+
+// DragDrop moves the dragged scene node into the group or scene
+// dropped on, or after the solid dropped on, keeping where they
+// are in the world. The default tree drop pastes copies made from
+// JSON, which would lose the meshes and other state of the nodes.
+func (st *SceneTree) DragDrop(e events.Event) {
+	de := e.(*events.DragDrop)
+	src := core.AsTree(de.Source.(core.Widget))
+	if src == nil {
+		return
+	}
+	src.Root.AsCoreTree().UnselectAll()
+	var parent tree.Node
+	index := -1
+	switch st.SyncNode.(type) {
+	case *xyz.Scene, *xyz.Group:
+		parent = st.SyncNode
+	default:
+		parent = st.SyncNode.AsTree().Parent
+		index = st.SyncNode.AsTree().IndexInParent() + 1
+	}
+	nd, ok := src.SyncNode.(xyz.Node)
+	if !ok || parent == nil || isAncestor(nd, parent) {
+		return
+	}
+	moveNode(nd, parent, index)
+	st.sceneChanged()
+	st.Root.AsCoreTree().Resync()
+}
+
+// DropDeleteSource does nothing, as [SceneTree.DragDrop]
+// moves the source nodes itself
+func (st *SceneTree) DropDeleteSource(e events.Event) {}
+
+// sceneChanged updates the scene after the tree changes it
+func (st *SceneTree) sceneChanged() {
+	nd, ok := st.SyncNode.(xyz.Node)
+	if !ok {
+		if sc, ok := st.SyncNode.(*xyz.Scene); ok {
+			sc.SetNeedsUpdate()
+		}
+		return
+	}
+	if sc := nd.AsNodeBase().Scene; sc != nil {
+		sc.SetNeedsUpdate()
+	}
+	st.NeedsRender()
+	if sp := tree.ParentByType[*SceneTreePanel](st); sp != nil {
+		sw := sp.SceneEditor.SceneWidget()
+		sw.SetSelected(nil) // the manipulation box may be out of place
+		sw.NeedsRender()
+	}
+}
+
+// moveNode moves the given node to the given index in the children of
+// the given parent, or the end if index is negative, keeping its world
+// transform
+func moveNode(nd xyz.Node, parent tree.Node, index int) {
+	nb := nd.AsNodeBase()
+	world := nb.Pose.WorldMatrix
+	var parWorld math32.Matrix4
+	parWorld.SetIdentity()
+	if pn, ok := parent.(xyz.Node); ok {
+		parWorld = pn.AsNodeBase().Pose.WorldMatrix
+	}
+	if inv, err := parWorld.Inverse(); err == nil {
+		var local math32.Matrix4
+		local.MulMatrices(inv, &world)
+		nb.Pose.Pos, nb.Pose.Quat, nb.Pose.Scale = DecomposeTRS(&local)
+	}
+	if nb.Parent == parent && index > nb.IndexInParent() {
+		index-- // after removing the node below
+	}
+	removeNode(nb.Parent, nd)
+	insertNode(parent, nd, index)
+}
+
+// isAncestor returns whether the given node is the given other
+// node or one of its parents
+func isAncestor(nd tree.Node, other tree.Node) bool {
+	for k := other; k != nil; k = k.AsTree().Parent {
+		if k == nd {
+			return true
+		}
+	}
+	return false
+}
+
+// isReservedNode returns whether the given node, or one of its
+// parents, has a reserved name starting with __
+func isReservedNode(nd tree.Node) bool {
+	for k := nd; k != nil; k = k.AsTree().Parent {
+		if strings.HasPrefix(k.AsTree().Name, "__") {
+			return true
+		}
+	}
+	return false
+}
+
+// canHide returns whether the given node can be hidden, which needs
+// the demo [Solid] to render, or a group holding some
+func canHide(nd tree.Node) bool {
+	switch nd.(type) {
+	case *xyz.Group:
+		found := false
+		nd.AsTree().WalkDown(func(k tree.Node) bool {
+			if _, ok := k.(interface{ asSolid() *Solid }); ok {
+				found = true
+				return tree.Break
+			}
+			return tree.Continue
+		})
+		return found
+	case interface{ asSolid() *Solid }:
+		return true
+	}
+	return false
+}
+
+// setUniqueName makes the name of the given child of the given parent
+// unique if another of its children has the same name, which
+// [tree.SetUniqueNameIfDuplicate] does whenever the child has been
+// added, as it finds the child itself
+func setUniqueName(par, child tree.Node) {
+	name := child.AsTree().Name
+	for _, k := range par.AsTree().Children {
+		if k != child && k.AsTree().Name == name {
+			tree.SetUniqueNameIfDuplicate(par, child)
+			return
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"cogentcore.org/core/xyz"
+)
+
+// AnimStep moves a solid from one pose to another over a duration
+type AnimStep struct {
+	// Solid being animated
+	Solid *xyz.Solid
+
+	// Pose at the start of the step
+	Start xyz.Pose
+
+	// Pose at the end of the step
+	End xyz.Pose
+
+	// How long the step takes
+	Duration time.Duration
+
+	// Easing applied to the normalized step time; nil means linear
+	Easing func(t float32) float32 `display:"-"`
+}
+
+// apply sets the pose of the solid at normalized step time t
+func (st *AnimStep) apply(t float32) {
+	if st.Solid == nil {
+		return
+	}
+	if st.Easing != nil {
+		t = st.Easing(t)
+	}
+	st.Solid.SetPosePos(st.Start.Pos.Lerp(st.End.Pos, t))
+	st.Solid.SetPoseScale(st.Start.Scale.Lerp(st.End.Scale, t))
+	st.Solid.SetPoseQuat(Slerp(st.Start.Quat, st.End.Quat, t))
+}
+
+// AnimSequence plays a list of steps one after another,
+// driven by the ticker of a SimpleAnim
+type AnimSequence struct {
+	// Steps to play in order
+	Steps []AnimStep
+
+	// Called after the last step finishes, on the ticker goroutine
+	OnComplete func() `display:"-"`
+
+	// Time in seconds since Play
+	Elapsed float32 `edit:"-"`
+
+	// Whether the sequence is playing
+	Playing bool `edit:"-"`
+
+	// index of the step in progress
+	current int
+
+	// mu protects all fields from the ticker goroutine
+	mu sync.Mutex
+}
+
+// NewAnimSequence returns a new sequence driven by the given SimpleAnim
+func NewAnimSequence(anim *SimpleAnim) *AnimSequence {
+	sq := &AnimSequence{}
+	anim.AddAnimator(sq)
+	return sq
+}
+
+// AddStep appends a step to the sequence
+func (sq *AnimSequence) AddStep(st AnimStep) *AnimSequence {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	sq.Steps = append(sq.Steps, st)
+	return sq
+}
+
+// Play starts the sequence from the first step
+func (sq *AnimSequence) Play() {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	sq.Elapsed = 0
+	sq.current = 0
+	sq.Playing = true
+}
+
+// Stop stops the sequence, leaving solids at their current poses
+func (sq *AnimSequence) Stop() {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	sq.Playing = false
+}
+
+// Step implements [Animator]
+func (sq *AnimSequence) Step(dt float32) bool {
+	sq.mu.Lock()
+	if !sq.Playing {
+		sq.mu.Unlock()
+		return false
+	}
+	sq.Elapsed += dt
+	start := float32(0) // start time of the current step
+	for i := range sq.current {
+		start += float32(sq.Steps[i].Duration.Seconds())
+	}
+	for sq.current < len(sq.Steps) {
+		st := &sq.Steps[sq.current]
+		dur := float32(st.Duration.Seconds())
+		if sq.Elapsed < start+dur {
+			st.apply((sq.Elapsed - start) / dur)
+			sq.mu.Unlock()
+			return true
+		}
+		st.apply(1) // finish steps that ended since the last tick
+		start += dur
+		sq.current++
+	}
+	sq.Playing = false
+	done := sq.OnComplete
+	sq.mu.Unlock()
+	if done != nil {
+		done()
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/color"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// shadowsProperty is the scene property holding its [Shadows]
+const shadowsProperty = "shadows"
+
+// Shadows are planar shadows cast by a directional light. The phong
+// renderer has no depth pass or shadow map to sample, so each solid
+// with [Solid.CastShadow] is drawn again, flattened along the light
+// direction onto each receiver in the given shadow color. Receivers
+// are flat: they are treated as the plane through their origin with
+// their local Y axis as normal, which suits floors and walls made
+// from xyz.Plane meshes.
+type Shadows struct {
+	// Light casting the shadows
+	Light *xyz.Directional
+
+	// Receivers are additional receivers, such as plain xyz solids,
+	// besides the solids with [Solid.ReceiveShadow]
+	Receivers []*xyz.Solid
+
+	// Color of the shadows. It is blended with the receiver,
+	// which the renderer usually draws first as it is nearer
+	// the camera than the casters.
+	Color color.RGBA
+
+	// ShadowBias is the height of the shadows above the receivers,
+	// to keep them from fighting with the receivers in the depth buffer
+	ShadowBias float32
+
+	// receivers found for the casters of the frame being rendered, which
+	// are found again for the next frame once the first solid renders
+	frameReceivers []*xyz.Solid
+	haveReceivers  bool
+}
+
+// NewShadows returns new shadows cast by the given light
+func NewShadows(light *xyz.Directional) *Shadows {
+	return &Shadows{Light: light, Color: color.RGBA{0, 0, 0, 110}, ShadowBias: 0.005}
+}
+
+// SetShadows sets the shadows of the given scene, or turns them off if nil
+func SetShadows(sc *xyz.Scene, sh *Shadows) {
+	if sh == nil {
+		sc.DeleteProperty(shadowsProperty)
+	} else {
+		sc.SetProperty(shadowsProperty, sh)
+	}
+	sc.SetNeedsRender()
+}
+
+// SceneShadows returns the shadows of the given scene, or nil if none
+func SceneShadows(sc *xyz.Scene) *Shadows {
+	sh, _ := sc.Property(shadowsProperty).(*Shadows)
+	return sh
+}
+
+// receivers returns all of the receivers in the given scene
+func (sh *Shadows) receivers(sc *xyz.Scene) []*xyz.Solid {
+	rcvs := append([]*xyz.Solid(nil), sh.Receivers...)
+	sc.WalkDown(func(k tree.Node) bool {
+		if ds, ok := k.(interface{ asSolid() *Solid }); ok && ds.asSolid().ReceiveShadow {
+			rcvs = append(rcvs, &ds.asSolid().Solid)
+		}
+		return tree.Continue
+	})
+	return rcvs
+}
+
+// receiversForFrame returns the receivers in the given scene for the
+// frame being rendered, finding them on the first call for the frame
+func (sh *Shadows) receiversForFrame(sc *xyz.Scene) []*xyz.Solid {
+	if !sh.haveReceivers {
+		sh.frameReceivers = sh.receivers(sc)
+		sh.haveReceivers = true
+	}
+	return sh.frameReceivers
+}
+
+// matrix returns the matrix flattening world coordinates onto the
+// plane of the given receiver along the light direction, and
+// false if the light does not shine on the plane
+func (sh *Shadows) matrix(rcv *xyz.Solid) (math32.Matrix4, bool) {
+	var m math32.Matrix4
+	if sh.Light == nil || sh.Light.Pos == (math32.Vector3{}) {
+		return m, false
+	}
+	ld := sh.Light.Pos.Normal().Negate() // direction of the light rays
+	n := math32.Vec3(0, 1, 0).MulQuat(rcv.Pose.WorldQuat())
+	ln := ld.Dot(n)
+	if ln > -1e-3 {
+		return m, false
+	}
+	d := n.Dot(rcv.Pose.WorldPos()) + sh.ShadowBias
+	// p' = p - ld * (n.p - d) / (ld.n)
+	m.Set(
+		1-ld.X*n.X/ln, -ld.X*n.Y/ln, -ld.X*n.Z/ln, ld.X*d/ln,
+		-ld.Y*n.X/ln, 1-ld.Y*n.Y/ln, -ld.Y*n.Z/ln, ld.Y*d/ln,
+		-ld.Z*n.X/ln, -ld.Z*n.Y/ln, 1-ld.Z*n.Z/ln, ld.Z*d/ln,
+		0, 0, 0, 1,
+	)
+	return m, true
+}
+
+// preRenderShadows uploads the shadows that the solid casts onto
+// each receiver, returning the number of shadows
+func (sld *Solid) preRenderShadows() int {
+	sh := SceneShadows(sld.Scene)
+	if sh == nil || !sld.CastShadow || sld.Mesh == nil {
+		return 0
+	}
+	clr := phong.NewColors(sh.Color, colors.Black, 0, 0, 1)
+	ns := 0
+	for _, rcv := range sh.receiversForFrame(sld.Scene) {
+		if rcv == &sld.Solid {
+			continue
+		}
+		pm, ok := sh.matrix(rcv)
+		if !ok {
+			continue
+		}
+		var wm math32.Matrix4
+		wm.MulMatrices(&pm, &sld.Pose.WorldMatrix)
+		sld.Scene.Phong.SetObject(sld.shadowPath(ns), phong.NewObject(&wm, clr))
+		ns++
+	}
+	return ns
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+func TestShadowsMatrix(t *testing.T) {
+	sc := newTestScene()
+	sun := xyz.NewDirectional(sc, "sun", 1, xyz.DirectSun)
+	sun.Pos.Set(1, 2, 0)
+	floor := newTestBox(sc, "floor", 1, math32.Vec3(0, -1, 0))
+	updateTestScene(sc)
+	sh := NewShadows(sun)
+	sh.ShadowBias = 0
+	m, ok := sh.matrix(floor)
+	if !ok {
+		t.Fatal("the sun does not shine on the floor")
+	}
+	// the point 2 above the floor goes 1 along X for each 2 down
+	if got := math32.Vec3(0, 1, 0).MulMatrix4(&m); !nearVec(got, math32.Vec3(-1, -1, 0)) {
+		t.Errorf("the shadow of the point is at %v instead of -1, -1, 0", got)
+	}
+	sun.Pos.Set(1, -2, 0) // from below
+	if _, ok := sh.matrix(floor); ok {
+		t.Error("the sun shines on the floor from below")
+	}
+}
+
+func TestShadowsReceiversForFrame(t *testing.T) {
+	sc := newTestScene()
+	sh := NewShadows(xyz.NewDirectional(sc, "sun", 1, xyz.DirectSun))
+	SetShadows(sc, sh)
+	floor := NewSolid(sc)
+	floor.ReceiveShadow = true
+	if rcvs := sh.receiversForFrame(sc); len(rcvs) != 1 || rcvs[0] != &floor.Solid {
+		t.Fatalf("the receivers are %v instead of the floor", rcvs)
+	}
+	wall := NewSolid(sc)
+	wall.ReceiveShadow = true
+	if n := len(sh.receiversForFrame(sc)); n != 1 {
+		t.Errorf("the receivers were found again in the same frame, with %d of them", n)
+	}
+	wall.renderShadows(nil) // the frame renders
+	if n := len(sh.receiversForFrame(sc)); n != 2 {
+		t.Errorf("the next frame has %d receivers instead of 2", n)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/keymap"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/text/rich"
+)
+
+// shortcutsProperty is the body property holding its [Shortcut]s
+const shortcutsProperty = "shortcuts"
+
+// ShortcutsHelpKey shows the [ShowShortcuts] dialog of a body that has
+// shortcuts, as ? does, which is typed with Shift
+const ShortcutsHelpKey key.Chord = "Shift+?"
+
+// Shortcut is a key chord that calls a function wherever the focus is
+// in a body, registered with [RegisterShortcut]
+type Shortcut struct {
+	// Chord that calls the function, in which Command
+	// is Control, or Meta on macOS
+	Chord key.Chord
+
+	// Description of what the shortcut does, for [ShowShortcuts]
+	Description string
+
+	// Func called by the Chord
+	Func func() `display:"-"`
+}
+
+// RegisterShortcut adds a [Shortcut] to the given body, calling the given
+// function for the given key chord. The focused widget and those around
+// it get the chord first, so that text fields can still type it, and the
+// last shortcut registered for a chord is the one called. The first one
+// registered on a body also adds the [ShortcutsHelpKey].
+func RegisterShortcut(b *core.Body, chord key.Chord, description string, fn func()) *Shortcut {
+	scs := BodyShortcuts(b)
+	if scs == nil {
+		b.OnFinal(events.KeyChord, func(e events.Event) {
+			kc := e.KeyChord()
+			scs := BodyShortcuts(b)
+			for i := len(scs) - 1; i >= 0; i-- {
+				if sc := scs[i]; sc.Chord.PlatformChord() == kc {
+					e.SetHandled()
+					sc.Func()
+					return
+				}
+			}
+		})
+		scs = append(scs, &Shortcut{Chord: ShortcutsHelpKey, Description: "Show the keyboard shortcuts",
+			Func: func() { ShowShortcuts(b) }})
+	}
+	sc := &Shortcut{Chord: chord, Description: description, Func: fn}
+	b.SetProperty(shortcutsProperty, append(scs, sc))
+	return sc
+}
+
+// RegisterKeymapShortcut registers a [Shortcut] on the given body for
+// each of the chords of the given key function in the active key map
+func RegisterKeymapShortcut(b *core.Body, kf keymap.Functions, description string, fn func()) {
+	for _, ch := range strings.Split(string(kf.Chord()), "\n") {
+		if ch != "" {
+			RegisterShortcut(b, key.Chord(ch), description, fn)
+		}
+	}
+}
+
+// BodyShortcuts returns the shortcuts of the given body, in the order registered
+func BodyShortcuts(b *core.Body) []*Shortcut {
+	scs, _ := b.Property(shortcutsProperty).([]*Shortcut)
+	return scs
+}
+
+// ShowShortcuts shows a dialog listing the shortcuts of the given body
+func ShowShortcuts(b *core.Body) {
+	d := core.NewBody("Keyboard shortcuts")
+	list := core.NewFrame(d)
+	list.Styler(func(s *styles.Style) {
+		s.Display = styles.Grid
+		s.Columns = 2
+	})
+	for _, sc := range BodyShortcuts(b) {
+		core.NewText(list).SetText(sc.Chord.PlatformChord().Label()).Styler(func(s *styles.Style) {
+			s.Font.Weight = rich.Bold
+		})
+		core.NewText(list).SetText(sc.Description)
+	}
+	d.AddBottomBar(func(bar *core.Frame) {
+		d.AddOK(bar)
+	})
+	d.RunDialog(b)
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"cogentcore.org/core/base/iox/imagex"
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+const (
+	// SkyboxName is the name of the group of the skybox faces
+	SkyboxName = "__Skybox"
+
+	// SkyboxMeshName is the name of the mesh of each skybox face
+	SkyboxMeshName = "__SkyboxMesh"
+)
+
+// skyboxFaces are the direction of each face of a [Skybox] from its
+// center, in order +X, -X, +Y, -Y, +Z, -Z, and the direction of the top
+// of the face image, as seen from the center
+var skyboxFaces = [6][2]math32.Vector3{
+	{math32.Vec3(1, 0, 0), math32.Vec3(0, 1, 0)},
+	{math32.Vec3(-1, 0, 0), math32.Vec3(0, 1, 0)},
+	{math32.Vec3(0, 1, 0), math32.Vec3(0, 0, 1)},
+	{math32.Vec3(0, -1, 0), math32.Vec3(0, 0, -1)},
+	{math32.Vec3(0, 0, 1), math32.Vec3(0, 1, 0)},
+	{math32.Vec3(0, 0, -1), math32.Vec3(0, 1, 0)},
+}
+
+// Skybox is a cube of images around the camera showing the
+// distant surroundings behind everything else in the scene.
+// Like a [Sky], it is drawn just inside the far plane of the
+// camera, and moves with it so that it never gets closer,
+// as the xyz renderer has no cubemap textures. The phong
+// shaders always light what they draw, so the faces are
+// made as bright as the light falling on them takes away,
+// to show their images as they are.
+type Skybox struct {
+	xyz.Group
+
+	// RotateWithCamera keeps the skybox fixed to the world when set,
+	// so that it turns in view as the camera turns. By default, it
+	// turns with the yaw of the camera instead, so that the same part
+	// of it is always in view, as for a backdrop.
+	RotateWithCamera bool
+}
+
+// SetSkybox shows the given face images in a [Skybox] around the
+// scene, in order +X, -X, +Y, -Y, +Z, -Z, each as seen from the center
+// looking along its axis, with its top toward +Y, or toward +Z for the
+// +Y face and -Z for the -Y face, so that the faces meet for a camera
+// looking along -Z. Nil faces remove the skybox.
+func SetSkybox(sc *xyz.Scene, faces [6]image.Image) *Skybox {
+	sb, _ := sc.ChildByName(SkyboxName, 0).(*Skybox)
+	if faces[0] == nil {
+		if sb != nil {
+			sb.Delete()
+		}
+		sc.SetNeedsUpdate()
+		return nil
+	}
+	if sb == nil {
+		sb = tree.New[Skybox](sc)
+		sb.SetName(SkyboxName)
+	}
+	sm := &SkyboxMesh{}
+	sm.Name = SkyboxMeshName
+	sc.SetMesh(sm)
+	for i, img := range faces {
+		tx := &xyz.TextureBase{Name: fmt.Sprintf("%s%d", SkyboxName, i)}
+		tx.RGBA = imagex.AsRGBA(img)
+		sc.SetTexture(tx)
+		name := fmt.Sprintf("%sFace%d", SkyboxName, i)
+		sf, _ := sb.ChildByName(name, 0).(*SkyboxFace)
+		if sf == nil {
+			sf = tree.New[SkyboxFace](sb)
+			sf.SetName(name)
+			sf.Face = i
+			sf.Material.Reflective = 0
+			sf.Material.Shiny = 0
+		}
+		sf.SetMesh(sm)
+		sf.Material.SetTexture(tx)
+	}
+	sc.SetNeedsUpdate()
+	return sb
+}
+
+// SetSkyboxHDR shows a [Skybox] around the scene from the given
+// equirectangular image, such as an HDRI panorama, split into faces
+// of the given size in pixels. The center of the image is toward -Z,
+// and its top is straight up. High dynamic range colors are clipped.
+func SetSkyboxHDR(sc *xyz.Scene, equirect image.Image, resolution int) *Skybox {
+	var faces [6]image.Image
+	for i := range faces {
+		faces[i] = equirectFace(equirect, i, max(resolution, 1))
+	}
+	return SetSkybox(sc, faces)
+}
+
+// equirectFace returns the skybox face with the given index
+// and size, sampled from the given equirectangular image
+func equirectFace(src image.Image, face, res int) *image.RGBA {
+	b := src.Bounds()
+	fwd, up := skyboxFaces[face][0], skyboxFaces[face][1]
+	right := fwd.Cross(up)
+	img := image.NewRGBA(image.Rect(0, 0, res, res))
+	for y := range res {
+		v := 2*(float32(y)+0.5)/float32(res) - 1
+		for x := range res {
+			u := 2*(float32(x)+0.5)/float32(res) - 1
+			d := fwd.Add(right.MulScalar(u)).Sub(up.MulScalar(v)).Normal()
+			lon := math32.Atan2(d.X, -d.Z)
+			lat := math32.Asin(math32.Clamp(d.Y, -1, 1))
+			sx := int((lon/(2*math32.Pi) + 0.5) * float32(b.Dx()))
+			sy := int((0.5 - lat/math32.Pi) * float32(b.Dy()))
+			sx, sy = min(max(sx, 0), b.Dx()-1), min(max(sy, 0), b.Dy()-1)
+			img.Set(x, y, color.RGBAModel.Convert(src.At(b.Min.X+sx, b.Min.Y+sy)))
+		}
+	}
+	return img
+}
+
+// SkyboxFace is the solid of one face of a [Skybox]. It can
+// not be selected.
+type SkyboxFace struct {
+	xyz.Solid
+
+	// Face is the index of the face, in order +X, -X, +Y, -Y, +Z, -Z
+	Face int
+}
+
+// PreRender moves the face around the camera, which the scene has
+// just updated, and clears its window bounding box so that clicks
+// on it do not select it
+func (sf *SkyboxFace) PreRender() {
+	sf.update()
+	sf.SceneBBox = image.Rectangle{}
+	sf.Solid.PreRender()
+}
+
+// update sets the pose of the face around the camera, and its
+// brightness for the light falling on it to show its image as it is
+func (sf *SkyboxFace) update() {
+	cm := &sf.Scene.Camera
+	half := 0.95 * cm.Far / math32.Sqrt(3) // the corners are inside the far plane
+	fwd, up := skyboxFaces[sf.Face][0], skyboxFaces[sf.Face][1]
+	var m math32.Matrix4
+	m.SetBasis(fwd.Cross(up), up, fwd.Negate()) // facing the center
+	sf.Pose.Quat.SetFromRotationMatrix(&m)
+	if sb, ok := sf.Parent.(*Skybox); ok && !sb.RotateWithCamera {
+		look := math32.Vec3(0, 0, -1).MulQuat(cm.Pose.Quat)
+		yaw := math32.NewQuatAxisAngle(math32.Vec3(0, 1, 0), math32.Atan2(-look.X, -look.Z))
+		sf.Pose.Quat = yaw.Mul(sf.Pose.Quat)
+		fwd = fwd.MulQuat(yaw)
+	}
+	sf.Pose.Pos = cm.Pose.Pos.Add(fwd.MulScalar(half))
+	sf.Pose.Scale.Set(2.002*half, 2.002*half, 1) // overlapping a little at the edges
+	sf.Pose.UpdateMatrix()
+	sf.Pose.UpdateWorldMatrix(nil)
+	sf.Material.Bright = 1 / max(skyboxLight(sf.Scene, sf.Pose.Pos, fwd.Negate()), 1e-3)
+}
+
+// skyboxLight returns the brightest channel of the light that the
+// phong shaders give a face of a [Skybox], or the [Sky], at the given
+// position, facing along the given normal, from the ambient,
+// directional and point lights of the given scene. The face is flat,
+// so the light is the same over all of it but for the point lights,
+// which are taken at its center. Spot lights are left out, as their
+// cones rarely reach the far plane.
+func skyboxLight(sc *xyz.Scene, pos, normal math32.Vector3) float32 {
+	var tot math32.Vector3
+	for _, kv := range sc.Lights.Order {
+		lb := kv.Value.AsLightBase()
+		clr := math32.NewVector3Color(lb.Color).MulScalar(lb.Lumens).SRGBToLinear()
+		switch lt := kv.Value.(type) {
+		case *xyz.Ambient:
+			tot = tot.Add(clr)
+		case *xyz.Directional:
+			tot = tot.Add(clr.MulScalar(max(lt.Pos.Normal().Dot(normal), 0)))
+		case *xyz.Point:
+			d := lt.Pos.Sub(pos)
+			dist := d.Length()
+			att := 1 / (1 + dist*(lt.LinDecay+lt.QuadDecay*dist))
+			tot = tot.Add(clr.MulScalar(att * max(d.Normal().Dot(normal), 0)))
+		}
+	}
+	return max(tot.X, tot.Y, tot.Z)
+}
+
+// SkyboxMesh is a unit quad in the XY plane facing +Z, with the
+// top of its texture at +Y
+type SkyboxMesh struct {
+	xyz.MeshBase
+}
+
+func (sm *SkyboxMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	sm.NumVertex, sm.NumIndex = 4, 6
+	return sm.NumVertex, sm.NumIndex, false
+}
+
+func (sm *SkyboxMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	vtxs := []math32.Vector3{{X: -0.5, Y: -0.5}, {X: 0.5, Y: -0.5}, {X: 0.5, Y: 0.5}, {X: -0.5, Y: 0.5}}
+	for i, v := range vtxs {
+		vertex.SetVector3(3*i, v)
+		normal.SetVector3(3*i, math32.Vec3(0, 0, 1))
+		texcoord.SetVector2(2*i, math32.Vec2(v.X+0.5, 0.5-v.Y))
+	}
+	index.Set(0, 0, 1, 2, 0, 2, 3)
+	bb := shape.BBoxFromVtxs(vertex, 0, 4)
+	sm.BBox.SetBounds(bb.Min, bb.Max)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"testing"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// newTestSkybox returns a new scene with a skybox of blank faces,
+// with the camera looking along +X
+func newTestSkybox() (*xyz.Scene, *Skybox) {
+	sc := newTestScene()
+	var faces [6]image.Image
+	for i := range faces {
+		faces[i] = image.NewRGBA(image.Rect(0, 0, 2, 2))
+	}
+	sb := SetSkybox(sc, faces)
+	sc.Camera.Pose.Pos.Set(0, 0, 0)
+	sc.Camera.LookAt(math32.Vec3(1, 0, 0), math32.Vec3(0, 1, 0))
+	return sc, sb
+}
+
+func TestSkyboxRotateWithCamera(t *testing.T) {
+	_, sb := newTestSkybox()
+	front := sb.Child(5).(*SkyboxFace) // -Z, in front of the default camera
+	front.update()
+	// by default the skybox turns with the camera, keeping the -Z face in front
+	if p := front.Pose.Pos.Normal(); p.Sub(math32.Vec3(1, 0, 0)).Length() > 1e-4 {
+		t.Errorf("the front face is toward %v with the camera looking along +X", p)
+	}
+	sb.RotateWithCamera = true
+	front.update()
+	if p := front.Pose.Pos.Normal(); p.Sub(math32.Vec3(0, 0, -1)).Length() > 1e-4 {
+		t.Errorf("the front face fixed to the world is toward %v", p)
+	}
+}
+
+func TestSkyboxUnlit(t *testing.T) {
+	sc, sb := newTestSkybox()
+	xyz.NewAmbient(sc, "ambient", 0.3, xyz.DirectSun)
+	sun := xyz.NewDirectional(sc, "sun", 1, xyz.DirectSun)
+	sun.Pos.Set(0, 1, 0)
+	for i := range 6 {
+		sf := sb.Child(i).(*SkyboxFace)
+		sf.update()
+		n := sf.Pose.Pos.Normal().Negate()
+		// the brightness makes up for the light on the face, as its
+		// material color is white, and it has no specular highlights
+		light := skyboxLight(sc, sf.Pose.Pos, n)
+		if lit := sf.Material.Bright * light; math32.Abs(lit-1) > 1e-4 || sf.Material.Reflective != 0 {
+			t.Errorf("face %d is lit %g times as bright as its image", i, lit)
+		}
+	}
+	// the sun is overhead, so it lights the bottom face, facing up, and not the top
+	top, bottom := sb.Child(2).(*SkyboxFace), sb.Child(3).(*SkyboxFace)
+	if top.Material.Bright <= bottom.Material.Bright {
+		t.Errorf("the top face has a brightness of %g, not more than %g for the bottom", top.Material.Bright, bottom.Material.Bright)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// SnapGhostName is the name of the solid showing where a dragged
+// solid would be without snapping
+const SnapGhostName = "__SnapGhost"
+
+// snapPosition returns the local position of the given node that puts
+// it at the world position nearest to the given local position with
+// each component a multiple of the given size. The manipulation box
+// moves nodes by deltas in the plane facing the camera rather than by
+// a mouse ray, so this is the world position that the drag reached.
+func snapPosition(nd xyz.Node, pos math32.Vector3, size float32) math32.Vector3 {
+	if size <= 0 {
+		return pos
+	}
+	par := &nd.AsNodeBase().Pose.ParMatrix
+	w := pos.MulMatrix4(par)
+	w.Set(math32.Round(w.X/size)*size, math32.Round(w.Y/size)*size, math32.Round(w.Z/size)*size)
+	inv, err := par.Inverse()
+	if err != nil {
+		return pos
+	}
+	return w.MulMatrix4(inv)
+}
+
+// SnapGhost is a translucent copy of a solid being dragged with
+// snapping, at the position it would have without snapping.
+// It can not be selected.
+type SnapGhost struct {
+	xyz.Solid
+
+	// Target is the solid being dragged
+	Target xyz.Node `set:"-"`
+
+	// Pos is the local position of the target without snapping
+	Pos math32.Vector3
+}
+
+// newSnapGhost adds a ghost of the given solid to its scene
+func newSnapGhost(sld *xyz.Solid) *SnapGhost {
+	sc := sld.Scene
+	if old := sc.ChildByName(SnapGhostName, 0); old != nil {
+		old.AsTree().Delete()
+	}
+	g := tree.New[SnapGhost](sc)
+	g.SetName(SnapGhostName)
+	g.Target = sld.This.(xyz.Node)
+	g.Pos = sld.Pose.Pos
+	g.SetMesh(sld.Mesh)
+	g.Material.Color = colors.WithAF32(sld.Material.Color, 0.5)
+	g.Material.Reflective = 0
+	g.Material.Shiny = 0
+	return g
+}
+
+// PreRender places the ghost at the unsnapped pose of the target
+func (g *SnapGhost) PreRender() {
+	tb := g.Target.AsNodeBase()
+	g.Pose.Pos, g.Pose.Quat, g.Pose.Scale = g.Pos, tb.Pose.Quat, tb.Pose.Scale
+	g.Pose.UpdateMatrix()
+	g.Pose.UpdateWorldMatrix(&tb.Pose.ParMatrix)
+	g.SceneBBox = image.Rectangle{}
+	g.Solid.PreRender()
+}
+
+// RenderClass sorts the ghost with the transparent solids,
+// even if its mesh has opaque vertex colors
+func (g *SnapGhost) RenderClass() xyz.RenderClasses {
+	return xyz.RClassTransUniform
+}
+
+// Render renders the ghost in its material color,
+// ignoring any vertex colors or texture of the target
+func (g *SnapGhost) Render(rp *wgpu.RenderPassEncoder) {
+	ph := g.Scene.Phong
+	ph.UseObject(g.Path())
+	ph.UseMesh(string(g.MeshName))
+	ph.UseNoTexture()
+	ph.RenderOneColor(rp)
+}
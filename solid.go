@@ -0,0 +1,311 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// Solid is an [xyz.Solid] with additional per-object rendering
+// controls used by this demo. It is not rendered when its world
+// bounding box is entirely outside of the camera frustum, when it
+// or one of its parents is Invisible, or when its Layer is hidden,
+// and it can be drawn as a wireframe of its triangle edges.
+type Solid struct {
+	xyz.Solid
+
+	// Wireframe draws the triangle edges of the mesh
+	Wireframe bool
+
+	// WireframeOverlay draws the wireframe on top of the shaded
+	// surface instead of replacing it
+	WireframeOverlay bool
+
+	// WireframeColor is the color of the wireframe edges
+	WireframeColor color.RGBA
+
+	// WireframeWidth is the width of the wireframe edges,
+	// in the units of the mesh
+	WireframeWidth float32
+
+	// OverrideVertexColors renders the solid with its material
+	// color even if its mesh has per-vertex colors. It is set
+	// by [Solid.SetColor].
+	OverrideVertexColors bool
+
+	// CastShadow casts the solid's shadow onto the receivers
+	// of the scene [Shadows]
+	CastShadow bool
+
+	// ReceiveShadow makes the solid a receiver of the scene [Shadows]
+	ReceiveShadow bool
+
+	// Metallic and Roughness are the physically based material
+	// parameters of the solid, as in glTF files. The phong renderer
+	// only uses the shininess set from them by [Solid.SetPBRMaterial];
+	// they are kept for exporting the solid again. Roughness 0 means unset.
+	Metallic, Roughness float32
+
+	// DepthTest hides the solid behind what is in front of it, which
+	// it does by default; without it, the solid is drawn over the rest
+	// of the scene, as for overlays and labels
+	DepthTest bool
+
+	// DepthWrite hides what is behind the solid, which it does by
+	// default; without it, the solid is sorted with the transparent
+	// solids, drawn back to front after the opaque ones
+	DepthWrite bool
+
+	// PolygonOffset moves the depth of the solid by the PolygonOffsetFactor
+	// and PolygonOffsetUnits, as for decals on the surfaces they lie on,
+	// with negative values toward the camera, which wins over the surface
+	PolygonOffset bool
+
+	// PolygonOffsetFactor is the polygon offset in
+	// thousandths of the depth of the solid
+	PolygonOffsetFactor float32
+
+	// PolygonOffsetUnits is the polygon offset in the
+	// resolution of the depth buffer at the depth of the solid
+	PolygonOffsetUnits float32
+
+	// BlendMode is how the material color of the solid is blended
+	// with what is behind it
+	BlendMode BlendModes
+
+	// EmissiveMap is the texture of the light given off by the
+	// solid, set by [Solid.SetEmissiveMap], if any
+	EmissiveMap xyz.TextureName `set:"-"`
+
+	// EmissiveIntensity scales the light of the EmissiveMap
+	EmissiveIntensity float32
+
+	// LODs are the lower levels of detail of the mesh, drawn further
+	// from the camera, in order of distance; use [Solid.AddLOD] to add them
+	LODs []LOD `set:"-"`
+
+	// ForceHighLOD always draws the solid with its own mesh,
+	// as for close-up screenshots
+	ForceHighLOD bool
+
+	// Layer is the layer of the solid, which can be hidden or isolated
+	// with the others in it by [SetLayerVisible] and [IsolateLayer]
+	Layer string
+
+	// Locked keeps the solid from being selected and dragged in the
+	// scene editor, and draws a crosshatch over it in the LockHatchColor;
+	// it can still be changed by code
+	Locked bool
+
+	// Static marks the solid as never moving, for [BakeStaticBatch]
+	// to draw it along with the others of the same material
+	Static bool
+
+	// Culled is whether the solid was outside of the camera
+	// frustum or hidden on the last render
+	Culled bool `edit:"-" copier:"-" json:"-"`
+
+	// number of shadows uploaded on the last render
+	shadows int
+
+	// mesh of the level of detail drawn on the last render,
+	// or empty for its own mesh
+	lod xyz.MeshName
+
+	// batch drawing the solid, if it is baked into one
+	batch *StaticBatch
+
+	// mesh that the normal map of [Solid.SetNormalMap]
+	// was applied to, if it has one
+	normalMapSource xyz.MeshName
+}
+
+// NewSolid returns a new [Solid] with the given optional parent
+func NewSolid(parent ...tree.Node) *Solid {
+	return tree.New[Solid](parent...)
+}
+
+// SetColor sets the material color, which overrides
+// any per-vertex colors of the mesh
+func (sld *Solid) SetColor(v color.RGBA) *Solid {
+	sld.Material.Color = v
+	sld.OverrideVertexColors = true
+	return sld
+}
+
+// asSolid returns the demo solid, for types embedding it
+func (sld *Solid) asSolid() *Solid {
+	return sld
+}
+
+func (sld *Solid) Init() {
+	sld.Solid.Init()
+	sld.WireframeColor = colors.Black
+	sld.WireframeWidth = 0.01
+	sld.DepthTest = true
+	sld.DepthWrite = true
+	sld.PolygonOffsetFactor = -1
+	sld.PolygonOffsetUnits = -1
+}
+
+// PreRender checks the solid against the camera frustum, which
+// the scene has just updated along with the world bounding box,
+// and uploads the solid for rendering if it is visible. If the
+// box has changed, as when it is animated, the [Octree] follows it. Shadows
+// are uploaded even if it is not, as they may still be in view. Its colors
+// are blended with the scene [Fog], if any, and set up for its
+// BlendMode, and the mesh for its distance
+// from the camera is chosen from its LODs. Solids baked into a
+// [StaticBatch] are drawn by it instead, without their EmissiveMap.
+// Locked solids can not be selected, and have a crosshatch over them.
+// Solids that are not Static fire the [Trigger]s they enter or leave,
+// even when they are hidden.
+func (sld *Solid) PreRender() {
+	sld.updateTriggers()
+	if isHidden(sld) {
+		sld.Culled = true
+		sld.shadows = 0
+		sld.SceneBBox = image.Rectangle{} // can not be selected
+		return
+	}
+	if isLocked(sld) {
+		sld.SceneBBox = image.Rectangle{} // can not be selected
+	}
+	if ot := SceneOctree(sld.Scene); ot != nil {
+		ot.rendered(&sld.Solid)
+	}
+	sld.selectLOD()
+	sld.shadows = sld.preRenderShadows()
+	sld.Culled = sld.outsideFrustum()
+	if sld.Culled || sld.batch != nil {
+		return
+	}
+	sld.withDepthPull(func() {
+		if sld.showSurface() {
+			sld.Solid.PreRender()
+			sld.preRenderColors()
+			if sld.EmissiveMap != "" && sld.Mesh != nil {
+				sld.preRenderEmissive()
+			}
+		}
+		if sld.Wireframe && sld.Mesh != nil {
+			wn := wireframeMeshName(sld.MeshName, sld.WireframeWidth)
+			if _, err := sld.Scene.MeshByName(wn); err != nil {
+				NewWireframeMesh(sld.Scene, wn, sld.Mesh, sld.WireframeWidth)
+			}
+			clr := phong.NewColors(sld.WireframeColor, colors.Black, 0, 0, 1)
+			sld.Scene.Phong.SetObject(sld.wireframePath(), phong.NewObject(&sld.Pose.WorldMatrix, clr))
+		}
+		if sld.Locked && sld.Mesh != nil {
+			sld.preRenderLockHatch()
+		}
+	})
+}
+
+// outsideFrustum returns whether the world bounding box of
+// the solid is entirely outside of the camera frustum
+func (sld *Solid) outsideFrustum() bool {
+	fr := sld.Scene.Camera.Frustum
+	return fr != nil && !fr.IntersectsBox(sld.WorldBBox.BBox)
+}
+
+// Render renders the solid unless it was culled, and its shadows,
+// with the mesh of its level of detail
+func (sld *Solid) Render(rp *wgpu.RenderPassEncoder) {
+	sld.withLOD(func() {
+		sld.renderShadows(rp)
+		if !sld.Culled && sld.batch == nil && sld.showSurface() {
+			sld.renderSurface(rp)
+		}
+	})
+	if sld.Culled || sld.batch != nil {
+		return
+	}
+	if sld.EmissiveMap != "" && sld.Mesh != nil && sld.showSurface() {
+		sld.renderEmissive(rp)
+	}
+	if sld.Wireframe && sld.Mesh != nil {
+		ph := sld.Scene.Phong
+		ph.UseObject(sld.wireframePath())
+		ph.UseMesh(wireframeMeshName(sld.MeshName, sld.WireframeWidth))
+		ph.UseNoTexture()
+		ph.Render(rp)
+	}
+	if sld.Locked && sld.Mesh != nil {
+		sld.renderLockHatch(rp)
+	}
+}
+
+// renderSurface renders the shaded surface, using the material
+// color instead of the mesh vertex colors if they are overridden
+func (sld *Solid) renderSurface(rp *wgpu.RenderPassEncoder) {
+	if !sld.OverrideVertexColors || sld.Mesh == nil || !sld.Mesh.AsMeshBase().HasColor {
+		sld.Solid.Render(rp)
+		return
+	}
+	ph := sld.Scene.Phong
+	ph.UseObject(sld.Path())
+	ph.UseMesh(string(sld.MeshName))
+	if sld.Material.TextureName != "" {
+		ph.UseTexture(string(sld.Material.TextureName))
+		ph.Render(rp)
+		return
+	}
+	ph.UseNoTexture()
+	ph.RenderOneColor(rp)
+}
+
+// isHidden returns whether the given node or one of its parents
+// is Invisible, or it is a demo [Solid] in a hidden layer. Reserved
+// nodes, such as a [StaticBatch], are in no layer.
+func isHidden(nd xyz.Node) bool {
+	if s, ok := nd.(interface{ asSolid() *Solid }); ok && !isReservedNode(nd) && !LayerVisible(nd.AsNodeBase().Scene, s.asSolid().Layer) {
+		return true
+	}
+	for k := tree.Node(nd); k != nil; k = k.AsTree().Parent {
+		if xn, ok := k.(xyz.Node); ok && xn.AsNodeBase().Invisible {
+			return true
+		}
+	}
+	return false
+}
+
+// showSurface returns whether the shaded surface is rendered
+func (sld *Solid) showSurface() bool {
+	return !sld.Wireframe || sld.WireframeOverlay || sld.Mesh == nil
+}
+
+// wireframePath returns the name of the render object for the wireframe
+func (sld *Solid) wireframePath() string {
+	return sld.Path() + "/__wireframe"
+}
+
+// renderShadows renders the shadows uploaded by PreRender
+func (sld *Solid) renderShadows(rp *wgpu.RenderPassEncoder) {
+	if sh := SceneShadows(sld.Scene); sh != nil {
+		sh.haveReceivers = false // all of the casters have pre-rendered
+	}
+	ph := sld.Scene.Phong
+	for i := range sld.shadows {
+		ph.UseObject(sld.shadowPath(i))
+		ph.UseMesh(string(sld.MeshName))
+		ph.UseNoTexture()
+		ph.RenderOneColor(rp)
+	}
+}
+
+// shadowPath returns the name of the render object for the shadow
+// on the receiver at the given index
+func (sld *Solid) shadowPath(i int) string {
+	return fmt.Sprintf("%s/__shadow%d", sld.Path(), i)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"cogentcore.org/core/math32"
+)
+
+func TestSolidOutsideFrustum(t *testing.T) {
+	sc := newTestGrid(2)
+	box := sc.Meshes.ValueByKey("box")
+	for _, tc := range []struct {
+		pos     math32.Vector3
+		outside bool
+	}{
+		{math32.Vec3(0, 0, 0), false},
+		{math32.Vec3(3, 2, 0), false},
+		{math32.Vec3(0, 0, 20), true},    // behind the camera
+		{math32.Vec3(30, 0, 0), true},    // off to the side
+		{math32.Vec3(0, 0, -2000), true}, // beyond the far plane
+		{math32.Vec3(3.9, 0, 0), false},  // partly in view
+	} {
+		sld := NewSolid(sc)
+		sld.SetMesh(box)
+		sld.Pose.Pos = tc.pos
+		updateTestScene(sc)
+		if got := sld.outsideFrustum(); got != tc.outside {
+			t.Errorf("the box at %v is outside of the frustum: %v", tc.pos, got)
+		}
+	}
+}
+
+func TestSolidCulled(t *testing.T) {
+	sc := newTestGrid(2)
+	sld := NewSolid(sc)
+	sld.SetMesh(sc.Meshes.ValueByKey("box"))
+	sld.Pose.Pos.Set(0, 0, 20)
+	updateTestScene(sc)
+	sld.PreRender()
+	if !sld.Culled {
+		t.Error("the box behind the camera was not culled")
+	}
+}
+
+// BenchmarkFrustumCull times checking all of the solids of a grid,
+// about half of which are in view, against the camera frustum
+func BenchmarkFrustumCull(b *testing.B) {
+	for _, n := range []int{32, 100} {
+		sc := newTestGrid(n)
+		var slds []*Solid
+		for _, k := range sc.Children {
+			slds = append(slds, k.(*Solid))
+		}
+		b.Run(fmt.Sprint(n*n), func(b *testing.B) {
+			for b.Loop() {
+				for _, sld := range slds {
+					sld.outsideFrustum()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// SpotLight sets up an [xyz.Spot] from a cone with a soft edge
+// between an inner and an outer angle. The phong shader scales
+// spot lights by a power of the cosine of the angle from the
+// spot direction, so the power is chosen to give half of the light
+// halfway between the inner and outer angles, as a smoothstep
+// between them would, with no light beyond the outer angle.
+type SpotLight struct {
+	// Light in the scene
+	Light *xyz.Spot
+
+	// Pos is the position of the light
+	Pos math32.Vector3
+
+	// Dir is the direction the light shines in
+	Dir math32.Vector3
+
+	// InnerAngle is the angle in degrees from Dir within
+	// which the light is close to full strength
+	InnerAngle float32
+
+	// OuterAngle is the angle in degrees from Dir beyond which
+	// there is no light; the maximum is 90
+	OuterAngle float32 `max:"90"`
+
+	// Attenuation is the quadratic decay of the light with distance
+	Attenuation float32
+}
+
+// NewSpotLight adds a new spot light with the given name, intensity
+// and color to the given scene, shining down from above the origin
+func NewSpotLight(sc *xyz.Scene, name string, intensity float32, color xyz.LightColors) *SpotLight {
+	sl := &SpotLight{Pos: math32.Vec3(0, 5, 0), Dir: math32.Vec3(0, -1, 0),
+		InnerAngle: 20, OuterAngle: 35, Attenuation: 0.001}
+	sl.Light = xyz.NewSpot(sc, name, intensity, color)
+	sl.apply()
+	return sl
+}
+
+// Update applies changes to the fields of the light to the scene
+func (sl *SpotLight) Update(sc *xyz.Scene) {
+	sl.apply()
+	if !sc.IsLive() {
+		return
+	}
+	lt := sl.Light
+	sc.Phong.SetSpot(lightIndex(sc, lt), lightColor(lt), lt.Pose.Pos, lt.ViewDir(), lt.AngDecay, lt.CutoffAngle, lt.LinDecay, lt.QuadDecay)
+	sc.SetNeedsRender()
+}
+
+// apply sets the fields of the xyz light from ours
+func (sl *SpotLight) apply() {
+	lt := sl.Light
+	lt.Pose.Pos = sl.Pos
+	if sl.Dir != (math32.Vector3{}) {
+		up := math32.Vec3(0, 1, 0)
+		if math32.Abs(sl.Dir.Normal().Dot(up)) > 0.99 {
+			up = math32.Vec3(0, 0, -1)
+		}
+		lt.Pose.LookAt(sl.Pos.Add(sl.Dir), up)
+	}
+	outer := math32.Clamp(sl.OuterAngle, 1, 90)
+	inner := math32.Clamp(sl.InnerAngle, 0, outer)
+	lt.CutoffAngle = outer
+	lt.AngDecay = spotAngDecay(inner, outer)
+	lt.LinDecay = 0
+	lt.QuadDecay = sl.Attenuation
+}
+
+// spotAngDecay returns the angular decay of an xyz spot light with the
+// given inner and outer cone angles in degrees, which halves the light
+// half way between them
+func spotAngDecay(inner, outer float32) float32 {
+	mid := math32.Cos(math32.DegToRad(0.5 * (inner + outer)))
+	return math32.Log(0.5) / math32.Log(max(mid, 1e-3))
+}
+
+// spotInnerAngle returns the inner cone angle in degrees of the given
+// xyz spot light, from its angular decay, as set by [spotAngDecay]
+func spotInnerAngle(lt *xyz.Spot) float32 {
+	if lt.AngDecay <= 0 {
+		return lt.CutoffAngle
+	}
+	mid := math32.RadToDeg(math32.Acos(math32.Pow(0.5, 1/lt.AngDecay)))
+	return math32.Clamp(2*mid-lt.CutoffAngle, 0, lt.CutoffAngle)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// staticBatchesProperty is the scene property holding
+// its [StaticBatch] nodes by material
+const staticBatchesProperty = "static-batches"
+
+// staticKey is what solids must share to be drawn in the same [StaticBatch]
+type staticKey struct {
+	material xyz.Material
+
+	// vertexColors is whether the mesh vertex colors are drawn
+	vertexColors bool
+}
+
+// staticPart is one solid of a [StaticBatch], as it was baked
+type staticPart struct {
+	solid *Solid
+	mesh  xyz.Mesh
+	world math32.Matrix4
+}
+
+// StaticBatch draws all of the visible [Solid]s of a scene that are
+// Static and have the same material with a single draw call, from one
+// mesh of their vertices transformed into world coordinates. The solids
+// keep their own shadows and picking. Use [BakeStaticBatch]
+// to make and update the batches.
+type StaticBatch struct {
+	Solid
+
+	// mesh combining all of the solids
+	mesh *StaticBatchMesh
+}
+
+// BakeStaticBatch combines the visible Static solids of the given scene
+// into a [StaticBatch] for each material, and stops drawing them on their
+// own. Calling it again after solids are made Static or not, are moved
+// or deleted, or change their mesh or material, only rebakes the batches
+// they were or are now in. Static solids are not expected to move; those
+// that do are drawn where they were until the next bake.
+func BakeStaticBatch(sc *xyz.Scene) {
+	xyz.UpdateWorldMatrix(sc)
+	groups := map[staticKey][]staticPart{}
+	var keys []staticKey
+	sc.WalkDown(func(k tree.Node) bool {
+		if k == sc.This {
+			return tree.Continue
+		}
+		if isReservedNode(k) {
+			return tree.Break
+		}
+		ds, ok := k.(interface{ asSolid() *Solid })
+		if !ok {
+			return tree.Continue
+		}
+		sld := ds.asSolid()
+		if !sld.Static || sld.Mesh == nil || isHidden(sld) {
+			return tree.Continue
+		}
+		key := staticKey{material: sld.Material}
+		key.vertexColors = sld.Mesh.AsMeshBase().HasColor && !sld.OverrideVertexColors
+		if _, has := groups[key]; !has {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], staticPart{solid: sld, mesh: sld.Mesh, world: sld.Pose.WorldMatrix})
+		return tree.Continue
+	})
+
+	batches, _ := sc.Property(staticBatchesProperty).(map[staticKey]*StaticBatch)
+	if batches == nil {
+		batches = map[staticKey]*StaticBatch{}
+		sc.SetProperty(staticBatchesProperty, batches)
+	}
+	for key, sb := range batches {
+		if _, has := groups[key]; !has {
+			sb.unbatch()
+			sb.Delete()
+			delete(batches, key)
+		}
+	}
+	for _, key := range keys {
+		parts := groups[key]
+		sb := batches[key]
+		if sb != nil && slices.Equal(sb.mesh.parts, parts) {
+			continue
+		}
+		if sb == nil {
+			sb = tree.New[StaticBatch](sc)
+			sb.SetName(fmt.Sprintf("__StaticBatch%d", len(sc.Meshes.Order)))
+			sb.mesh = &StaticBatchMesh{}
+			sb.mesh.Name = sb.Name
+			batches[key] = sb
+		}
+		sb.unbatch()
+		sb.Material = key.material
+		sb.OverrideVertexColors = !key.vertexColors
+		sb.mesh.parts = parts
+		sb.mesh.HasColor = key.vertexColors
+		sc.SetMesh(sb.mesh)
+		sb.SetMesh(sb.mesh)
+		for _, p := range parts {
+			p.solid.batch = sb
+		}
+	}
+	sc.SetNeedsUpdate()
+}
+
+// unbatch goes back to drawing the solids of the batch on their own
+func (sb *StaticBatch) unbatch() {
+	if sb.mesh == nil {
+		return
+	}
+	for _, p := range sb.mesh.parts {
+		if p.solid.batch == sb {
+			p.solid.batch = nil
+		}
+	}
+}
+
+// StaticBatchMesh is the meshes of the solids of a
+// [StaticBatch], in world coordinates
+type StaticBatchMesh struct {
+	xyz.MeshBase
+
+	// solids combined
+	parts []staticPart
+}
+
+func (sm *StaticBatchMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	sm.NumVertex, sm.NumIndex = 0, 0
+	for _, p := range sm.parts {
+		nv, ni, _ := p.mesh.MeshSize()
+		sm.NumVertex += nv
+		sm.NumIndex += ni
+	}
+	return sm.NumVertex, sm.NumIndex, sm.HasColor
+}
+
+func (sm *StaticBatchMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	vo, io := 0, 0
+	for _, p := range sm.parts {
+		md := shape.NewMeshData(p.mesh)
+		var nm math32.Matrix3
+		nm.SetNormalMatrix(&p.world)
+		for v := range md.NumVertex {
+			var pos, n math32.Vector3
+			md.Vertex.GetVector3(3*v, &pos)
+			md.Normal.GetVector3(3*v, &n)
+			vertex.SetVector3(3*(vo+v), pos.MulMatrix4(&p.world))
+			normal.SetVector3(3*(vo+v), n.MulMatrix3(&nm).Normal())
+			texcoord.Set(2*(vo+v), md.TexCoord[2*v], md.TexCoord[2*v+1])
+			if sm.HasColor {
+				copy(clrs[4*(vo+v):4*(vo+v)+4], md.Colors[4*v:4*v+4])
+			}
+		}
+		for x := range md.NumIndex {
+			index[io+x] = md.Index[x] + uint32(vo)
+		}
+		vo += md.NumVertex
+		io += md.NumIndex
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, sm.NumVertex)
+	sm.BBox.SetBounds(bb.Min, bb.Max)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/xyz"
+)
+
+// testBatches returns the static batches of the given scene,
+// with the sorted names of the solids that each one draws
+func testBatches(sc *xyz.Scene) map[*StaticBatch][]string {
+	batches, _ := sc.Property(staticBatchesProperty).(map[staticKey]*StaticBatch)
+	parts := map[*StaticBatch][]string{}
+	for _, sb := range batches {
+		var names []string
+		for _, p := range sb.mesh.parts {
+			names = append(names, p.solid.Name)
+		}
+		slices.Sort(names)
+		parts[sb] = names
+	}
+	return parts
+}
+
+// staticTestLayers returns a new scene with Static solids of the same
+// material in the given layers, baked into a batch
+func staticTestLayers(layers ...string) *xyz.Scene {
+	sc := newTestLayers(layers...)
+	for _, k := range sc.Children {
+		k.(*Solid).Static = true
+	}
+	BakeStaticBatch(sc)
+	return sc
+}
+
+func TestStaticBatchMaterials(t *testing.T) {
+	sc := staticTestLayers("a", "b", "c")
+	sc.ChildByName("in-c", 0).(*Solid).SetColor(colors.Red)
+	BakeStaticBatch(sc)
+	var got [][]string
+	for _, names := range testBatches(sc) {
+		got = append(got, names)
+	}
+	slices.SortFunc(got, func(a, b []string) int { return len(b) - len(a) })
+	if len(got) != 2 || !slices.Equal(got[0], []string{"in-a", "in-b"}) || !slices.Equal(got[1], []string{"in-c"}) {
+		t.Errorf("baked the batches %v, not one for each material", got)
+	}
+	for _, k := range sc.Children {
+		if sld, ok := k.(*Solid); ok && sld.Static && sld.batch == nil {
+			t.Errorf("the solid %s is not drawn by a batch", sld.Name)
+		}
+	}
+}
+
+func TestStaticBatchHiddenLayer(t *testing.T) {
+	sc := staticTestLayers("walls", "props")
+	SetLayerVisible(sc, "props", false)
+	batches := testBatches(sc)
+	if len(batches) != 1 {
+		t.Fatalf("there are %d batches instead of 1", len(batches))
+	}
+	for sb, names := range batches {
+		if !slices.Equal(names, []string{"in-walls"}) {
+			t.Errorf("hiding the props layer after baking left the batch drawing %v", names)
+		}
+		if isHidden(sb) {
+			t.Error("the batch of the walls is hidden")
+		}
+	}
+	if sld := sc.ChildByName("in-props", 0).(*Solid); sld.batch != nil {
+		t.Error("the hidden props solid is still in a batch")
+	}
+
+	SetLayerVisible(sc, "props", true)
+	for _, names := range testBatches(sc) {
+		if !slices.Equal(names, []string{"in-props", "in-walls"}) {
+			t.Errorf("showing the props layer again left the batch drawing %v", names)
+		}
+	}
+}
+
+func TestStaticBatchIsolateLayer(t *testing.T) {
+	// the batches are in no layer, so hiding the default one
+	// by isolating another does not hide them
+	sc := staticTestLayers("walls", "", "props")
+	IsolateLayer(sc, "walls")
+	batches := testBatches(sc)
+	if len(batches) != 1 {
+		t.Fatalf("there are %d batches instead of 1", len(batches))
+	}
+	for sb, names := range batches {
+		if isHidden(sb) || !slices.Equal(names, []string{"in-walls"}) {
+			t.Errorf("isolating the walls layer left the batch drawing %v, hidden: %v", names, isHidden(sb))
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/text/text"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"cogentcore.org/core/xyz/xyzcore"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// statsName is the name of the text of a [StatsOverlay]
+const statsName = "__Stats"
+
+// SceneStats are the rendering statistics of one frame of a scene
+type SceneStats struct {
+	// Objects is the number of solids drawn
+	Objects int
+
+	// Triangles is the number of triangles drawn,
+	// including those of shadows and wireframes
+	Triangles int
+
+	// DrawCalls is the number of draw calls
+	DrawCalls int
+
+	// FrameTime is the time since the last frame, in milliseconds
+	FrameTime float32
+}
+
+// StatsOverlay shows the [SceneStats] of the last frame of a scene
+// widget in the top left corner of its view, over a semitransparent
+// background. The window draws the scene over the widgets, so it is
+// a billboard [Text2D] in the scene, just past the near plane of the
+// camera. The stats are counted on each frame tick of the widget,
+// while it is Visible, from what the solids drew on the last render.
+// The ToggleKey shows and hides it.
+type StatsOverlay struct {
+	// Visible is whether the stats are shown
+	Visible bool
+
+	// ToggleKey shows and hides the stats, as a [Shortcut]
+	// of the body of the scene widget registered by Attach
+	ToggleKey key.Chord
+
+	// Stats of the last frame
+	Stats SceneStats `edit:"-"`
+
+	// Scene widget that the stats are shown on
+	SceneWidget *xyzcore.Scene `set:"-"`
+
+	// text showing the stats
+	text *statsText
+}
+
+// NewStatsOverlay returns a new hidden stats overlay, toggled with F3
+func NewStatsOverlay() *StatsOverlay {
+	return &StatsOverlay{ToggleKey: "F3"}
+}
+
+// Attach adds the stats to the given scene widget,
+// and lets the ToggleKey show and hide them
+func (so *StatsOverlay) Attach(sw *xyzcore.Scene) {
+	if so.SceneWidget != nil {
+		return
+	}
+	so.SceneWidget = sw
+	sc := sw.SceneXYZ()
+	so.text = tree.New[statsText](sc)
+	so.text.SetName(statsName)
+	so.text.overlay = so
+	so.text.Billboard = true
+	so.text.Styles.Font.Size.Pt(14)
+	so.text.Styles.Color = colors.Uniform(colors.White)
+	so.text.Styles.Text.Align = text.Start
+	so.text.Styles.Text.AlignV = text.Start
+	so.text.BackgroundColor = color.RGBA{0, 0, 0, 160}
+	so.text.Text = so.Stats.String()
+	if sc.TextShaper != nil { // already configured
+		so.text.Config()
+	}
+	sw.Animate(func(a *core.Animation) {
+		if !so.Visible {
+			return
+		}
+		so.Stats = sceneStats(sc, so.text)
+		so.Stats.FrameTime = a.Dt
+		so.text.SetText(so.Stats.String())
+		so.text.RenderText()
+		sw.NeedsRender()
+	})
+	if so.ToggleKey != "" {
+		RegisterShortcut(sw.Scene.Body, so.ToggleKey, "Show or hide the rendering stats", func() {
+			so.SetVisible(!so.Visible)
+		})
+	}
+}
+
+// SetVisible shows or hides the stats
+func (so *StatsOverlay) SetVisible(visible bool) {
+	so.Visible = visible
+	if so.SceneWidget != nil {
+		so.SceneWidget.NeedsRender()
+	}
+}
+
+// String returns the stats as lines of text
+func (st SceneStats) String() string {
+	return fmt.Sprintf("Objects: %d\nTriangles: %d\nDraw calls: %d\nFrame: %.1f ms",
+		st.Objects, st.Triangles, st.DrawCalls, st.FrameTime)
+}
+
+// sceneStats returns the stats of the last render of
+// the given scene, apart from the given stats text
+func sceneStats(sc *xyz.Scene, skip xyz.Node) SceneStats {
+	var st SceneStats
+	sc.WalkDown(func(k tree.Node) bool {
+		if k == sc.This {
+			return tree.Continue
+		}
+		nd, _ := xyz.AsNode(k)
+		if nd == nil {
+			return tree.Break
+		}
+		if nd == skip || !nd.IsSolid() {
+			return tree.Continue
+		}
+		var drawn bool
+		var draws, tris int
+		switch x := k.(type) {
+		case interface{ asSolid() *Solid }:
+			drawn, draws, tris = x.asSolid().renderStats()
+		case *gizmoHandle:
+			if x.shown {
+				drawn, draws, tris = true, 1, x.Mesh.AsMeshBase().NumIndex/3
+			}
+		case *marqueeRect:
+			if x.shown {
+				drawn, draws, tris = true, 1, 2
+			}
+		case *miniMapView:
+			if x.shown {
+				drawn, draws, tris = true, 1, 2
+			}
+		case *Annotation:
+			if x.shown {
+				drawn, draws, tris = true, 2, 2+x.Scene.Meshes.ValueByKey(annotationTailName).AsMeshBase().NumIndex/3
+				if x.drawBackground {
+					draws, tris = 3, tris+2
+				}
+			}
+		case *Text2D:
+			drawn, draws, tris = true, 1, 2
+			if x.drawBackground {
+				draws, tris = 2, 4
+			}
+		default:
+			if ms := nd.AsSolid().Mesh; ms != nil {
+				drawn, draws, tris = true, 1, ms.AsMeshBase().NumIndex/3
+			}
+		}
+		if drawn {
+			st.Objects++
+		}
+		st.DrawCalls += draws
+		st.Triangles += tris
+		return tree.Continue
+	})
+	return st
+}
+
+// renderStats returns whether the solid was drawn on the last
+// render, and the number of draw calls and triangles it took
+func (sld *Solid) renderStats() (drawn bool, draws, tris int) {
+	ms := sld.Mesh
+	if sld.lod != "" {
+		if lm, err := sld.Scene.MeshByName(string(sld.lod)); err == nil {
+			ms = lm
+		}
+	}
+	if ms == nil {
+		return
+	}
+	mtris := ms.AsMeshBase().NumIndex / 3
+	draws, tris = sld.shadows, sld.shadows*mtris
+	if sld.Culled || sld.batch != nil {
+		return
+	}
+	drawn = true
+	if sld.showSurface() {
+		draws++
+		tris += mtris
+	}
+	if sld.Wireframe {
+		if wm, err := sld.Scene.MeshByName(wireframeMeshName(sld.MeshName, sld.WireframeWidth)); err == nil {
+			draws++
+			tris += wm.AsMeshBase().NumIndex / 3
+		}
+	}
+	return
+}
+
+// statsText is the text of a [StatsOverlay], which stays in the top
+// left corner of the view and is only drawn while it is visible
+type statsText struct {
+	Text2D
+
+	// overlay showing the text
+	overlay *StatsOverlay
+}
+
+// PreRender places the text in the corner of the view of the camera,
+// which the scene has just updated, at the size of its pixels, and
+// clears its window bounding box so that clicks on it do not select it
+func (st *statsText) PreRender() {
+	st.SceneBBox = image.Rectangle{}
+	if !st.overlay.Visible {
+		return
+	}
+	cm := &st.Scene.Camera
+	depth := 2 * cm.Near
+	halfFOV := math32.Tan(math32.DegToRad(cm.FOV * 0.5))
+	height := 2 * depth * halfFOV
+	if cm.Ortho { // as in the camera projection
+		height = 2 * cm.Far * halfFOV
+	}
+	pixel := height / float32(max(st.Scene.Geom.Size.Y, 1))
+	margin := 8 * pixel
+	st.Pose.Scale.SetScalar(st.Styles.Font.FontHeight() * pixel)
+	st.Padding.Set(4*pixel, 4*pixel)
+	corner := math32.Vec3(-0.5*cm.Aspect*height+margin, 0.5*height-margin, -depth)
+	st.Pose.Pos = corner.MulMatrix4(&cm.Pose.Matrix)
+	st.Text2D.PreRender()
+}
+
+func (st *statsText) Render(rp *wgpu.RenderPassEncoder) {
+	if st.overlay.Visible {
+		st.Text2D.Render(rp)
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+const (
+	// stlHeaderSize is the size of the header of a binary STL file,
+	// with the number of triangles after it
+	stlHeaderSize = 80
+
+	// stlTriangleSize is the size of each triangle of a binary STL
+	// file: a normal, three vertices and a two byte attribute
+	stlTriangleSize = 50
+)
+
+// ExportSTL writes the triangles of the mesh of the given solid to
+// the file at the given path as binary STL, for 3D printing, in world
+// coordinates from its current pose and those of its parents. The
+// normal of each triangle is made from its vertices, and degenerate
+// triangles with no area are skipped with a warning.
+func ExportSTL(solid *xyz.Solid, path string) error {
+	if solid.Mesh == nil {
+		return fmt.Errorf("ExportSTL: solid %q has no mesh", solid.Name)
+	}
+	md := shape.NewMeshData(solid.Mesh)
+	world := poseWorldMatrix(solid)
+	vtx := func(i uint32) math32.Vector3 {
+		var v math32.Vector3
+		md.Vertex.GetVector3(3*int(i), &v)
+		return v.MulMatrix4(world)
+	}
+	var tris bytes.Buffer
+	n, skipped := 0, 0
+	for t := 0; t+2 < len(md.Index); t += 3 {
+		a, b, c := vtx(md.Index[t]), vtx(md.Index[t+1]), vtx(md.Index[t+2])
+		nrm, ok := stlNormal(a, b, c)
+		if !ok {
+			skipped++
+			continue
+		}
+		binary.Write(&tris, binary.LittleEndian, [12]float32{nrm.X, nrm.Y, nrm.Z, a.X, a.Y, a.Z, b.X, b.Y, b.Z, c.X, c.Y, c.Z})
+		binary.Write(&tris, binary.LittleEndian, uint16(0))
+		n++
+	}
+	if skipped > 0 {
+		log.Printf("STL: skipping %d degenerate triangles of solid %q\n", skipped, solid.Name)
+	}
+	var b bytes.Buffer
+	hdr := make([]byte, stlHeaderSize)
+	copy(hdr, "binary STL of "+solid.Name)
+	b.Write(hdr)
+	binary.Write(&b, binary.LittleEndian, uint32(n))
+	b.Write(tris.Bytes())
+	return os.WriteFile(path, b.Bytes(), 0666)
+}
+
+// stlNormal returns the normal of the triangle with the given
+// vertices, and false if it is degenerate, with no area
+func stlNormal(a, b, c math32.Vector3) (math32.Vector3, bool) {
+	n := b.Sub(a).Cross(c.Sub(a))
+	l := n.Length()
+	if l == 0 || math32.IsNaN(l) {
+		return n, false
+	}
+	return n.DivScalar(l), true
+}
+
+// ImportSTL adds a demo [Solid] to the given scene with an
+// [ArrayMesh] of the triangles of the binary or ASCII STL file at the
+// given path, telling the format from the size and header of the
+// file. The triangles have flat normals, made from their vertices if
+// the file has none, and degenerate triangles with no area are
+// skipped with a warning. The solid is named from the ASCII solid
+// name, or the name of the file, made unique in the scene.
+func ImportSTL(sc *xyz.Scene, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	base := filepath.Base(path)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	var vtx, nrm math32.ArrayF32
+	if stlIsBinary(b) {
+		vtx, nrm = stlBinary(b)
+	} else {
+		var solidName string
+		if vtx, nrm, solidName, err = stlASCII(b); err != nil {
+			return fmt.Errorf("ImportSTL: %s: %w", base, err)
+		}
+		if solidName != "" {
+			name = solidName
+		}
+	}
+	if skipped := stlSetNormals(&vtx, &nrm); skipped > 0 {
+		log.Printf("STL: skipping %d degenerate triangles of %s\n", skipped, base)
+	}
+	if len(vtx) == 0 {
+		return fmt.Errorf("ImportSTL: %s has no triangles", base)
+	}
+	meshName := name
+	if _, err := sc.MeshByName(meshName); err == nil {
+		meshName = fmt.Sprintf("%s-%d", meshName, len(sc.Meshes.Order))
+	}
+	NewArrayMesh(sc, meshName, vtx, nrm, nil, nil, nil)
+	sld := NewSolid(sc)
+	sld.SetName(name)
+	sld.SetMeshName(meshName)
+	setUniqueName(sc, sld)
+	sc.SetNeedsUpdate()
+	return nil
+}
+
+// stlIsBinary returns whether the given STL file data is binary.
+// ASCII files start with "solid", but so do some binary ones,
+// so binary files are told from their size first.
+func stlIsBinary(b []byte) bool {
+	if len(b) >= stlHeaderSize+4 {
+		n := binary.LittleEndian.Uint32(b[stlHeaderSize:])
+		if uint64(len(b)) == stlHeaderSize+4+stlTriangleSize*uint64(n) {
+			return true
+		}
+	}
+	return !bytes.HasPrefix(bytes.TrimLeft(b, " \t\r\n"), []byte("solid"))
+}
+
+// stlBinary returns the vertices and normals of the
+// triangles of the given binary STL file data
+func stlBinary(b []byte) (vtx, nrm math32.ArrayF32) {
+	if len(b) < stlHeaderSize+4 {
+		return
+	}
+	n := int(binary.LittleEndian.Uint32(b[stlHeaderSize:]))
+	n = min(n, (len(b)-stlHeaderSize-4)/stlTriangleSize)
+	tri := make([]float32, 12)
+	r := bytes.NewReader(b[stlHeaderSize+4:])
+	for range n {
+		binary.Read(r, binary.LittleEndian, tri)
+		r.Seek(2, io.SeekCurrent) // attribute
+		for range 3 {
+			nrm.Append(tri[:3]...)
+		}
+		vtx.Append(tri[3:]...)
+	}
+	return
+}
+
+// stlASCII returns the vertices and normals of the triangles
+// of the given ASCII STL file data, and the name of its solid
+func stlASCII(b []byte) (vtx, nrm math32.ArrayF32, name string, err error) {
+	s := bufio.NewScanner(bytes.NewReader(b))
+	var n math32.Vector3
+	line := 0
+	floats := func(fields []string) (math32.Vector3, error) {
+		if len(fields) != 3 {
+			return math32.Vector3{}, fmt.Errorf("line %d: needs 3 numbers", line)
+		}
+		var v [3]float32
+		for i, f := range fields {
+			x, err := strconv.ParseFloat(f, 32)
+			if err != nil {
+				return math32.Vector3{}, fmt.Errorf("line %d: %w", line, err)
+			}
+			v[i] = float32(x)
+		}
+		return math32.Vec3(v[0], v[1], v[2]), nil
+	}
+	for s.Scan() {
+		line++
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "solid":
+			if name == "" {
+				name = strings.Join(fields[1:], " ")
+			}
+		case "facet":
+			if len(fields) < 2 || fields[1] != "normal" {
+				return nil, nil, "", fmt.Errorf("line %d: facet has no normal", line)
+			}
+			if n, err = floats(fields[2:]); err != nil {
+				return nil, nil, "", err
+			}
+		case "vertex":
+			v, err := floats(fields[1:])
+			if err != nil {
+				return nil, nil, "", err
+			}
+			vtx.AppendVector3(v)
+			nrm.AppendVector3(n)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, nil, "", err
+	}
+	if len(vtx)%9 != 0 {
+		return nil, nil, "", errors.New("facets do not all have 3 vertices")
+	}
+	return vtx, nrm, name, nil
+}
+
+// stlSetNormals removes the degenerate triangles from the given
+// vertices and normals, and sets the normals of those that have
+// none from their vertices, returning the number removed
+func stlSetNormals(vtx, nrm *math32.ArrayF32) (skipped int) {
+	var ov, on math32.ArrayF32
+	for t := 0; t+9 <= len(*vtx); t += 9 {
+		var a, b, c, n math32.Vector3
+		vtx.GetVector3(t, &a)
+		vtx.GetVector3(t+3, &b)
+		vtx.GetVector3(t+6, &c)
+		fn, ok := stlNormal(a, b, c)
+		if !ok {
+			skipped++
+			continue
+		}
+		nrm.GetVector3(t, &n)
+		if l := n.Length(); l == 0 || math32.IsNaN(l) {
+			n = fn
+		} else {
+			n = n.DivScalar(l)
+		}
+		ov.AppendVector3(a, b, c)
+		on.AppendVector3(n, n, n)
+	}
+	*vtx, *nrm = ov, on
+	return
+}
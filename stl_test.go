@@ -0,0 +1,46 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"cogentcore.org/core/math32"
+)
+
+func TestSTLRoundTrip(t *testing.T) {
+	sc := newTestScene()
+	box := newTestBox(sc, "box", 2, math32.Vec3(1, 2, 3))
+	box.Pose.Scale.Set(1, 0.5, 1)
+	updateTestScene(sc)
+	path := filepath.Join(t.TempDir(), "box.stl")
+	if err := ExportSTL(box, path); err != nil {
+		t.Fatal(err)
+	}
+
+	im := newTestScene()
+	for range 2 {
+		if err := ImportSTL(im, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updateTestScene(im)
+	if n := im.NumChildren(); n != 2 {
+		t.Fatalf("imported %d solids instead of 2", n)
+	}
+	sld := im.Child(0).(*Solid)
+	if sld.Name != "box" || im.Child(1).AsTree().Name == "box" {
+		t.Errorf("importing the file twice made the solids %s and %s", sld.Name, im.Child(1).AsTree().Name)
+	}
+	// the exported triangles are in world coordinates
+	want := box.WorldBBox.BBox
+	if d := sld.WorldBBox.BBox.Min.Sub(want.Min).Length() + sld.WorldBBox.BBox.Max.Sub(want.Max).Length(); d > 1e-5 {
+		t.Errorf("the imported box has the bounding box %v instead of %v", sld.WorldBBox.BBox, want)
+	}
+	if n := len(sld.Mesh.(*ArrayMesh).Vertex) / 9; n != 12 {
+		t.Errorf("the imported box has %d triangles instead of 12", n)
+	}
+}
@@ -0,0 +1,405 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"cogentcore.org/core/colors"
+	"cogentcore.org/core/gpu/phong"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/text/text"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+	"github.com/cogentcore/webgpu/wgpu"
+)
+
+// textBackgroundDepth is how far behind the text of a [Text2D]
+// its background quad is, in world units
+const textBackgroundDepth = 0.002
+
+// Text2D is an [xyz.Text2D] with additional label controls used by
+// this demo. Its text is a subset of HTML, as for core.Text, with
+// b, i and span tags with style or color attributes, and each line
+// break in it starts a new line, as does a br tag. As a billboard,
+// it turns to face the camera on each render, so labels stay
+// readable as the camera orbits, and it can have a background quad
+// behind it, for contrast with the scene.
+type Text2D struct {
+	xyz.Text2D
+
+	// Billboard turns the text to face the camera on each render,
+	// in place of the rotation of its pose
+	Billboard bool
+
+	// AxisLock only turns a billboard around the Y axis, toward
+	// the camera position, so the text stays upright
+	AxisLock bool
+
+	// BackgroundColor is the color of a quad behind the text,
+	// which is only drawn if it is not fully transparent
+	BackgroundColor color.RGBA
+
+	// Padding is how far the background quad extends past the
+	// text on each side, in the units of the text size
+	Padding math32.Vector2
+
+	// OutlineColor is the color of an outline around the glyphs,
+	// drawn behind the text if OutlineWidth is not 0
+	OutlineColor color.RGBA
+
+	// OutlineWidth is the width of the outline, in pixels of the
+	// text texture, which is drawn as the text moved that far in
+	// each of eight directions
+	OutlineWidth float32
+
+	// ShadowColor is the color of a drop shadow of the glyphs,
+	// a cheaper alternative to an outline, drawn behind the text
+	// if ShadowOffset is not 0
+	ShadowColor color.RGBA
+
+	// ShadowOffset is how far the shadow is moved from the text,
+	// in pixels of the text texture, with Y down
+	ShadowOffset math32.Vector2
+
+	// DepthTest hides the text behind what is in front of it, which
+	// it does by default; without it, the text and its background
+	// are drawn over the rest of the scene, as for labels
+	DepthTest bool
+
+	// pull is the matrix moving the text in front of the rest
+	// of the scene on the last render, if it has no DepthTest
+	pull math32.Matrix4
+
+	// background is the world matrix of the background quad
+	// made by PreRender, and whether it is drawn
+	background     math32.Matrix4
+	drawBackground bool
+}
+
+// NewText2D returns a new [Text2D] with the given optional parent
+func NewText2D(parent ...tree.Node) *Text2D {
+	return tree.New[Text2D](parent...)
+}
+
+func (txt *Text2D) Init() {
+	txt.Text2D.Init()
+	txt.DepthTest = true
+}
+
+func (txt *Text2D) Config() {
+	txt.withLineBreaks(txt.Text2D.Config)
+	txt.renderOutline()
+}
+
+// RenderText renders the text to its texture, which must be
+// done again after the text or its styles have changed
+func (txt *Text2D) RenderText() {
+	txt.withLineBreaks(txt.Text2D.RenderText)
+	txt.renderOutline()
+}
+
+// withLineBreaks calls the given function, which renders the text,
+// with br tags for its line breaks, as HTML collapses them to spaces
+func (txt *Text2D) withLineBreaks(fun func()) {
+	if !strings.Contains(txt.Text, "\n") {
+		fun()
+		return
+	}
+	src := txt.Text
+	txt.Text = strings.ReplaceAll(strings.ReplaceAll(src, "\r\n", "\n"), "\n", "<br>")
+	defer func() { txt.Text = src }()
+	fun()
+}
+
+// renderOutline draws the shadow and outline, if any, behind the
+// rendered text, in a texture made bigger to fit them. They are
+// the shape of the opaque parts of the texture, so they need the
+// background to be transparent, as it is by default.
+func (txt *Text2D) renderOutline() {
+	hasOutline := txt.OutlineWidth != 0 && txt.OutlineColor.A > 0
+	hasShadow := txt.ShadowOffset != (math32.Vector2{}) && txt.ShadowColor.A > 0
+	tx := txt.Material.Texture
+	if (!hasOutline && !hasShadow) || tx == nil || txt.Scene == nil {
+		return
+	}
+	img := tx.Image()
+	ow := int(math32.Ceil(math32.Abs(txt.OutlineWidth)))
+	sx, sy := int(math32.Round(txt.ShadowOffset.X)), int(math32.Round(txt.ShadowOffset.Y))
+	pad := 0
+	if hasOutline {
+		pad = ow
+	}
+	if hasShadow {
+		pad = max(pad, sx, -sx, sy, -sy)
+	}
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx()+2*pad, b.Dy()+2*pad))
+	at := func(dx, dy int) image.Rectangle {
+		return image.Rect(pad+dx, pad+dy, pad+dx+b.Dx(), pad+dy+b.Dy())
+	}
+	if hasShadow {
+		draw.DrawMask(out, at(sx, sy), image.NewUniform(txt.ShadowColor), image.Point{}, img, b.Min, draw.Over)
+	}
+	if hasOutline {
+		oc := image.NewUniform(txt.OutlineColor)
+		for _, d := range [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+			draw.DrawMask(out, at(d[0]*ow, d[1]*ow), oc, image.Point{}, img, b.Min, draw.Over)
+		}
+	}
+	draw.Draw(out, at(0, 0), img, b.Min, draw.Over)
+	tx.AsTextureBase().RGBA = out
+	txt.Scene.SetTexture(tx)
+}
+
+func (txt *Text2D) UpdateWorldMatrix(parWorld *math32.Matrix4) {
+	if !txt.Billboard {
+		txt.Text2D.UpdateWorldMatrix(parWorld)
+		return
+	}
+	txt.Pose.ParMatrix.CopyFrom(parWorld)
+	txt.updateBillboard()
+}
+
+// PreRender turns a billboard to face the camera, which may have
+// moved without the scene updating the world matrices, before the
+// text and its background are uploaded for rendering, pulled in
+// front of the rest of the scene if it has no DepthTest
+func (txt *Text2D) PreRender() {
+	if txt.Billboard {
+		txt.updateBillboard()
+	}
+	txt.pull.SetIdentity()
+	if !txt.DepthTest {
+		_, _, ws := txt.Pose.WorldMatrix.Decompose()
+		if pull, ok := depthPull(&txt.Scene.Camera, txt.Pose.WorldMatrix.Pos(), 0.5*math32.Vec2(ws.X, ws.Y).Length()); ok {
+			txt.pull = pull
+		}
+		wm := txt.Pose.WorldMatrix
+		txt.Pose.WorldMatrix.MulMatrices(&txt.pull, &wm)
+		defer func() { txt.Pose.WorldMatrix = wm }()
+	}
+	txt.Text2D.PreRender()
+	txt.drawBackground = false
+	sz, ok := txt.TextSize()
+	if !ok || txt.BackgroundColor.A == 0 || sz.X == 0 || sz.Y == 0 {
+		return
+	}
+	// the quad has the pose of the text, scaled up by the padding,
+	// and moved back, along +Z for the turn of the text plane
+	depth := float32(textBackgroundDepth)
+	if _, _, ws := txt.Pose.WorldMatrix.Decompose(); ws.Z != 0 {
+		depth /= ws.Z
+	}
+	var m math32.Matrix4
+	m.SetTransform(math32.Vec3(0, 0, depth), math32.NewQuat(0, 0, 0, 1),
+		math32.Vec3(1+2*txt.Padding.X/sz.X, 1+2*txt.Padding.Y/sz.Y, 1))
+	txt.background.MulMatrices(&txt.Pose.WorldMatrix, &m)
+	clr := phong.NewColors(txt.BackgroundColor, colors.Black, txt.Material.Shiny, txt.Material.Reflective, txt.Material.Bright)
+	txt.Scene.Phong.SetObject(txt.backgroundPath(), phong.NewObject(&txt.background, clr))
+	txt.drawBackground = true
+}
+
+// Render renders the background quad, if any, and then the text
+func (txt *Text2D) Render(rp *wgpu.RenderPassEncoder) {
+	if txt.drawBackground {
+		ph := txt.Scene.Phong
+		ph.UseObject(txt.backgroundPath())
+		ph.UseMesh(string(txt.MeshName))
+		ph.UseNoTexture()
+		ph.Render(rp)
+	}
+	txt.Text2D.Render(rp)
+}
+
+// backgroundPath returns the name of the render object for the background
+func (txt *Text2D) backgroundPath() string {
+	return txt.Path() + "/__background"
+}
+
+// updateBillboard sets the matrices of a billboard so that it faces the
+// camera, with the alignment, position and scale of [xyz.Text2D]
+func (txt *Text2D) updateBillboard() {
+	sz, ok := txt.TextSize()
+	if !ok {
+		txt.Pose.UpdateMatrix()
+		txt.Pose.UpdateWorldMatrix(nil)
+		return
+	}
+	cam := &txt.Scene.Camera
+	var quat math32.Quat
+	if txt.AxisLock {
+		d := cam.Pose.Pos.Sub(txt.Pose.Pos.MulMatrix4(&txt.Pose.ParMatrix))
+		quat = math32.NewQuatAxisAngle(math32.Vec3(0, 1, 0), math32.Atan2(d.X, d.Z))
+	} else {
+		quat = cam.Pose.Quat
+	}
+	// relative to the rotation of the parent
+	_, pq, _ := txt.Pose.ParMatrix.Decompose()
+	pq.SetInverse()
+	quat = pq.Mul(quat)
+
+	ax := txt.Styles.Text.Align.Factor()
+	ay := float32(0)
+	switch txt.Styles.Text.AlignV {
+	case text.Start:
+		ay = -0.5
+	case text.End:
+		ay = 0.5
+	}
+	// the alignment offset turns with the text
+	off := math32.Vec3((0.5-ax)*sz.X, ay*sz.Y, 0).MulQuat(quat)
+	quat.SetMul(math32.NewQuatAxisAngle(math32.Vec3(0, 1, 0), math32.DegToRad(180)))
+	txt.Pose.Matrix.SetTransform(txt.Pose.Pos.Add(off), quat, math32.Vec3(sz.X, sz.Y, txt.Pose.Scale.Z))
+	txt.Pose.UpdateWorldMatrix(nil)
+}
+
+// Typewriter animates a [Text2D] typing out its text, one character at
+// a time, with a blinking cursor after it. It is driven by the ticker
+// of a SimpleAnim. Markup tags are shown all at once, as they are not
+// drawn as characters, and entities such as &amp; are typed as the
+// one character they are drawn as.
+type Typewriter struct {
+	// Animation driving the typing
+	Anim *SimpleAnim
+
+	// Text being typed out
+	Text *Text2D
+
+	// Full is the whole text, which Text shows a growing start of
+	Full string
+
+	// Duration of typing the whole text
+	Duration time.Duration
+
+	// Cursor is shown after the typed text, blinking once a
+	// second, until the typing is done
+	Cursor string
+
+	// OnComplete is called when all of the text is shown,
+	// on the ticker goroutine
+	OnComplete func() `display:"-"`
+
+	// Time in seconds since typing started
+	Time float32 `edit:"-"`
+
+	// done is whether all of the text has been shown
+	done bool
+
+	// mu protects all fields from the ticker goroutine
+	mu sync.Mutex
+}
+
+// TypewriterAnim starts typing out the text over the given duration,
+// driven by the given animation, with a "|" cursor
+func (txt *Text2D) TypewriterAnim(a *SimpleAnim, duration time.Duration) *Typewriter {
+	tw := &Typewriter{Anim: a, Text: txt, Full: txt.Text, Duration: duration, Cursor: "|"}
+	tw.show(0, true)
+	a.AddAnimator(tw)
+	return tw
+}
+
+// Step implements [Animator]
+func (tw *Typewriter) Step(dt float32) bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.done || tw.Text == nil || tw.Text.Scene == nil {
+		return false
+	}
+	tw.Time += dt
+	frac := float32(1)
+	if d := float32(tw.Duration.Seconds()); d > 0 {
+		frac = min(tw.Time/d, 1)
+	}
+	tw.show(frac, int(2*tw.Time)%2 == 0)
+	if frac < 1 {
+		return true
+	}
+	tw.done = true
+	if tw.OnComplete != nil {
+		tw.OnComplete()
+	}
+	return true
+}
+
+// show shows the given fraction of the characters of the full text,
+// with the cursor if it is on and the text is not all shown
+func (tw *Typewriter) show(frac float32, cursor bool) {
+	ends := typewriterEnds(tw.Full)
+	nchar := len(ends) - 1
+	n := int(frac * float32(nchar))
+	s := tw.Full[:ends[n]]
+	if n < nchar && cursor {
+		s += tw.Cursor
+	}
+	txt := tw.Text
+	if s == txt.Text {
+		return
+	}
+	txt.Text = s
+	txt.RenderText()
+	if txt.Scene != nil {
+		txt.Scene.SetNeedsUpdate() // for the new size
+	}
+}
+
+// typewriterEnds returns the byte offsets in the given markup where
+// each number of its characters has been typed, from none to all
+// of them, including the tags just after each, so that it is only
+// cut between whole runes, entities and tags
+func typewriterEnds(s string) []int {
+	var ends []int
+	i := 0
+	skipTags := func() {
+		for i < len(s) && s[i] == '<' {
+			if e := strings.IndexByte(s[i:], '>'); e >= 0 {
+				i += e + 1
+			} else {
+				i = len(s)
+			}
+		}
+	}
+	skipTags()
+	ends = append(ends, i)
+	for i < len(s) {
+		if n := entityLen(s[i:]); n > 0 {
+			i += n
+		} else {
+			_, n := utf8.DecodeRuneInString(s[i:])
+			i += n
+		}
+		skipTags()
+		ends = append(ends, i)
+	}
+	return ends
+}
+
+// entityLen returns the length of the HTML character entity, such as
+// &amp; or &#39;, at the start of the given string, or 0 if there is none
+func entityLen(s string) int {
+	if len(s) < 3 || s[0] != '&' {
+		return 0
+	}
+	for i := 1; i < len(s) && i < 32; i++ {
+		c := s[i]
+		switch {
+		case c == ';':
+			if i == 1 {
+				return 0
+			}
+			return i + 1
+		case c == '#' && i == 1, c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		default:
+			return 0
+		}
+	}
+	return 0
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTypewriterEnds(t *testing.T) {
+	full := "<b>a&amp;é</b> &#39;&x"
+	var cuts []string
+	for _, e := range typewriterEnds(full) {
+		cuts = append(cuts, full[:e])
+	}
+	want := []string{
+		"<b>",
+		"<b>a",
+		"<b>a&amp;",
+		"<b>a&amp;é</b>",
+		"<b>a&amp;é</b> ",
+		"<b>a&amp;é</b> &#39;",
+		"<b>a&amp;é</b> &#39;&",
+		"<b>a&amp;é</b> &#39;&x",
+	}
+	if !slices.Equal(cuts, want) {
+		t.Errorf("typing %q is cut as\n%q, not\n%q", full, cuts, want)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+
+	"cogentcore.org/core/base/iox/imagex"
+	"cogentcore.org/core/xyz"
+)
+
+// SetTextureFromImage adds the given image to the scene of the solid as a
+// texture, named after the solid, and sets it as the texture of its
+// material, which is drawn over the UV coordinates of its mesh, as the
+// meshes of xyz.NewPlane and xyz.NewBox have: the whole image on the
+// plane, and on each face of the box. The texture is transparent if the
+// image is, and replaces the one set before by this, if any. The phong
+// renderer samples it linearly at its full size, as it has no mipmaps.
+// The solid must be in a scene.
+func (sld *Solid) SetTextureFromImage(img image.Image) *Solid {
+	name := sld.Name + "-texture"
+	if string(sld.Material.TextureName) == name {
+		sld.Scene.Textures.DeleteKey(name)
+	}
+	sld.Material.SetTextureName(sld.Scene, string(addImageTexture(sld.Scene, name, img)))
+	return sld
+}
+
+// SetTextureFromFile sets the texture of the solid to the PNG or
+// JPEG image of the given file, as for [Solid.SetTextureFromImage]
+func (sld *Solid) SetTextureFromFile(path string) error {
+	img, _, err := imagex.Open(path)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	sld.Material.SetTextureName(sld.Scene, string(addImageTexture(sld.Scene, name, img)))
+	return nil
+}
+
+// addImageTexture adds the given image to the given scene as a texture
+// of the given name, with a number added if it is already taken, and
+// returns its name
+func addImageTexture(sc *xyz.Scene, name string, img image.Image) xyz.TextureName {
+	if _, err := sc.TextureByName(name); err == nil {
+		name = fmt.Sprintf("%s-%d", name, len(sc.Textures.Order))
+	}
+	tx := &xyz.TextureBase{Name: name, RGBA: imagex.AsRGBA(img)}
+	tx.Transparent = !tx.RGBA.Opaque()
+	sc.SetTexture(tx)
+	return xyz.TextureName(name)
+}
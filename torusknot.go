@@ -0,0 +1,98 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// TorusKnot is a tube along a (P, Q) torus knot, which winds P times
+// around the axis of a torus and Q times through its hole, such as
+// the trefoil knot for 2, 3. The tube is swept along the Frenet frame
+// of the curve.
+type TorusKnot struct {
+	xyz.MeshBase
+
+	// P and Q are the winding numbers of the knot
+	P, Q int
+
+	// TubeRadius is the radius of the tube
+	TubeRadius float32
+
+	// Radius of the torus that the knot winds around
+	Radius float32
+
+	// TubularSegments is the number of segments along the
+	// tube, and RadialSegments around it
+	TubularSegments, RadialSegments int
+}
+
+// NewTorusKnot returns a new torus knot mesh with the given winding
+// numbers, tube and torus radii and numbers of segments along and
+// around the tube, set on the given scene under the given name
+func NewTorusKnot(sc *xyz.Scene, name string, p, q int, tubeRadius, torusRadius float32, tubularSegments, radialSegments int) *TorusKnot {
+	tk := &TorusKnot{P: p, Q: q, TubeRadius: tubeRadius, Radius: torusRadius,
+		TubularSegments: tubularSegments, RadialSegments: radialSegments}
+	tk.Name = name
+	sc.SetMesh(tk)
+	return tk
+}
+
+func (tk *TorusKnot) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	tk.TubularSegments, tk.RadialSegments = max(tk.TubularSegments, 3), max(tk.RadialSegments, 3)
+	tk.NumVertex = (tk.TubularSegments + 1) * (tk.RadialSegments + 1) // seams are repeated for the texture
+	tk.NumIndex = tk.TubularSegments * tk.RadialSegments * 6
+	return tk.NumVertex, tk.NumIndex, false
+}
+
+// knotCurve returns the point of the knot curve at the given angle,
+// which goes from 0 to 2πP around it, and its first and second
+// derivatives by the angle
+func (tk *TorusKnot) knotCurve(u float32) (pt, d1, d2 math32.Vector3) {
+	k := float32(tk.Q) / float32(max(tk.P, 1))
+	R := tk.Radius / 2
+	cu, su := math32.Cos(u), math32.Sin(u)
+	ck, sk := math32.Cos(k*u), math32.Sin(k*u)
+	// the distance from the axis, and its derivatives
+	r, r1, r2 := R*(2+ck), -R*k*sk, -R*k*k*ck
+	pt = math32.Vec3(r*cu, r*su, R*sk)
+	d1 = math32.Vec3(r1*cu-r*su, r1*su+r*cu, R*k*ck)
+	d2 = math32.Vec3(r2*cu-2*r1*su-r*cu, r2*su+2*r1*cu-r*su, -R*k*k*sk)
+	return
+}
+
+func (tk *TorusKnot) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	nt, nr := tk.TubularSegments, tk.RadialSegments
+	end := 2 * math32.Pi * float32(max(tk.P, 1))
+	for i := range nt + 1 {
+		u := end * float32(i) / float32(nt)
+		ctr, d1, d2 := tk.knotCurve(u)
+		// the Frenet frame, from the derivatives of the curve
+		tan := d1.Normal()
+		bin := d1.Cross(d2).Normal()
+		nrm := bin.Cross(tan)
+		for j := range nr + 1 {
+			v := 2 * math32.Pi * float32(j) / float32(nr)
+			dir := nrm.MulScalar(math32.Cos(v)).Add(bin.MulScalar(math32.Sin(v)))
+			k := i*(nr+1) + j
+			vertex.SetVector3(3*k, ctr.Add(dir.MulScalar(tk.TubeRadius)))
+			normal.SetVector3(3*k, dir)
+			texcoord.Set(2*k, float32(i)/float32(nt), float32(j)/float32(nr))
+		}
+	}
+	ii := 0
+	for i := range nt {
+		for j := range nr {
+			a := uint32(i*(nr+1) + j)
+			b, c, d := a+uint32(nr+1), a+1, a+uint32(nr+1)+1
+			index.Set(ii, a, c, b, b, c, d)
+			ii += 6
+		}
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, tk.NumVertex)
+	tk.BBox.SetBounds(bb.Min, bb.Max)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/math32"
+)
+
+// touchGesturesProperty is the widget property holding its [TouchGestures]
+const touchGesturesProperty = "touchGestures"
+
+// PinchEvent is two fingers moving apart or together on a widget,
+// sent by its [TouchGestures] as the Data of an [events.Custom] event
+type PinchEvent struct {
+	// Scale is the distance between the fingers,
+	// over what it was when the second one touched
+	Scale float32
+
+	// Delta is the change in the Scale since the last PinchEvent
+	Delta float32
+}
+
+// TwistEvent is two fingers turning around each other on a widget,
+// sent by its [TouchGestures] as the Data of an [events.Custom] event
+type TwistEvent struct {
+	// Angle is the turn in degrees of the line between the fingers
+	// since the last TwistEvent, counterclockwise on the screen
+	Angle float32
+}
+
+// TouchGestures follows the touches on a widget, and sends it a
+// [PinchEvent] and a [TwistEvent] each time two fingers move on it. The
+// events package only has a Magnify event for pinches, which some
+// platforms send, and none for twisting, so they are made from the raw
+// touches, which are also sent as mouse events, so one finger drags.
+// The gesture events are sent straight to the widget, with the position
+// between the fingers. A finger lifted outside of the widget is taken as
+// lifted when another touches.
+type TouchGestures struct {
+	// Widget that the touches are followed on
+	Widget core.Widget `set:"-"`
+
+	// positions of the fingers down on the widget
+	points map[events.Sequence]image.Point
+
+	// distance between the fingers when the second one touched,
+	// and the scale and angle in degrees of the last events
+	start, scale, angle float32
+}
+
+// AddTouchGestures returns the touch gestures of the given widget,
+// adding them if it does not have them yet
+func AddTouchGestures(w core.Widget) *TouchGestures {
+	wb := w.AsWidget()
+	if tg, ok := wb.Property(touchGesturesProperty).(*TouchGestures); ok {
+		return tg
+	}
+	tg := &TouchGestures{Widget: w, points: map[events.Sequence]image.Point{}}
+	wb.SetProperty(touchGesturesProperty, tg)
+	wb.On(events.TouchStart, func(e events.Event) {
+		if len(tg.points) >= 2 { // a finger was lifted elsewhere
+			clear(tg.points)
+		}
+		tg.points[e.(*events.Touch).Sequence] = e.Pos()
+		tg.reset()
+	})
+	wb.On(events.TouchMove, func(e events.Event) {
+		seq := e.(*events.Touch).Sequence
+		if _, ok := tg.points[seq]; !ok {
+			return
+		}
+		tg.points[seq] = e.Pos()
+		tg.move()
+	})
+	wb.On(events.TouchEnd, func(e events.Event) {
+		delete(tg.points, e.(*events.Touch).Sequence)
+		tg.reset()
+	})
+	return tg
+}
+
+// Touches returns the number of fingers down on the widget
+func (tg *TouchGestures) Touches() int {
+	return len(tg.points)
+}
+
+// pair returns the positions of the two fingers down, if there are two
+func (tg *TouchGestures) pair() (a, b math32.Vector2, ok bool) {
+	if len(tg.points) != 2 {
+		return a, b, false
+	}
+	var ps []math32.Vector2
+	for _, p := range tg.points {
+		ps = append(ps, math32.FromPoint(p))
+	}
+	return ps[0], ps[1], true
+}
+
+// reset starts the gestures again from where the fingers are now
+func (tg *TouchGestures) reset() {
+	a, b, ok := tg.pair()
+	if !ok {
+		return
+	}
+	d := b.Sub(a)
+	tg.start = max(d.Length(), 1)
+	tg.scale = 1
+	tg.angle = tg.twist(d)
+}
+
+// twist returns the angle in degrees counterclockwise on the screen,
+// where Y goes down, of the given line between the fingers. The order
+// of the fingers is not kept, so the angle is the same both ways.
+func (tg *TouchGestures) twist(d math32.Vector2) float32 {
+	if d.X < 0 || (d.X == 0 && d.Y < 0) {
+		d = d.Negate()
+	}
+	return math32.RadToDeg(math32.Atan2(-d.Y, d.X))
+}
+
+// move sends the gesture events for the fingers having moved
+func (tg *TouchGestures) move() {
+	a, b, ok := tg.pair()
+	if !ok {
+		return
+	}
+	d := b.Sub(a)
+	mid := a.Add(b).MulScalar(0.5).ToPoint()
+	if scale := d.Length() / tg.start; scale != tg.scale {
+		tg.send(PinchEvent{Scale: scale, Delta: scale - tg.scale}, mid)
+		tg.scale = scale
+	}
+	angle := tg.twist(d)
+	da := angle - tg.angle
+	// the line turned half way around when it went past upright
+	if da > 90 {
+		da -= 180
+	} else if da < -90 {
+		da += 180
+	}
+	if da != 0 {
+		tg.send(TwistEvent{Angle: da}, mid)
+		tg.angle = angle
+	}
+}
+
+// send sends the given gesture to the widget at the given position
+func (tg *TouchGestures) send(data any, where image.Point) {
+	ce := &events.CustomEvent{PosAvail: true}
+	ce.Typ = events.Custom
+	ce.Data = data
+	ce.Where = where
+	ce.Init()
+	tg.Widget.AsWidget().HandleEvent(ce)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "cogentcore.org/core/math32"
+
+// ComposeTRS returns the matrix that scales, then rotates,
+// then translates by the given transform, as a Pose does
+func ComposeTRS(t math32.Vector3, r math32.Quat, s math32.Vector3) math32.Matrix4 {
+	var m math32.Matrix4
+	m.SetTransform(t, r, s)
+	return m
+}
+
+// DecomposeTRS returns the translation, rotation and scale of the given
+// matrix, the inverse of [ComposeTRS]. The rotation is the one nearest
+// to the upper 3x3 part of the matrix, by polar decomposition, so it is
+// always a unit quaternion, even when the matrix has shear, as the world
+// matrix of a rotated solid under a parent with an uneven scale does, or
+// a zero scale on an axis. [math32.Matrix4.Decompose] only divides the
+// axes by their lengths, which gives a skewed rotation for those. The
+// scale is of the matrix along the axes of the rotation, with a
+// reflection taken as a negative X scale.
+func DecomposeTRS(m *math32.Matrix4) (translation math32.Vector3, rotation math32.Quat, scale math32.Vector3) {
+	translation = math32.Vec3(m[12], m[13], m[14])
+	cols := [3]math32.Vector3{
+		math32.Vec3(m[0], m[1], m[2]),
+		math32.Vec3(m[4], m[5], m[6]),
+		math32.Vec3(m[8], m[9], m[10]),
+	}
+	basis := fullBasis(cols)
+	if basis[0].Dot(basis[1].Cross(basis[2])) < 0 {
+		basis[0] = basis[0].Negate()
+	}
+	rot := polarRotation(basis)
+	var rm math32.Matrix4
+	rm.SetIdentity()
+	for c := range 3 {
+		rm[4*c], rm[4*c+1], rm[4*c+2] = rot[3*c], rot[3*c+1], rot[3*c+2]
+		axis := math32.Vec3(rot[3*c], rot[3*c+1], rot[3*c+2])
+		scale.SetDim(math32.Dims(c), axis.Dot(cols[c]))
+	}
+	rotation.SetFromRotationMatrix(&rm)
+	rotation.Normalize()
+	return
+}
+
+// fullBasis returns the given axes with any of zero length replaced,
+// so that they span space: by the cross product of the other two, or
+// by axes at right angles to the only one left, or by the unit axes
+func fullBasis(cols [3]math32.Vector3) [3]math32.Vector3 {
+	const eps = 1e-12
+	var zero []int
+	for c, v := range cols {
+		if v.LengthSquared() < eps {
+			zero = append(zero, c)
+		}
+	}
+	switch len(zero) {
+	case 0:
+	case 1:
+		i := zero[0]
+		cols[i] = cols[(i+1)%3].Cross(cols[(i+2)%3])
+		if cols[i].LengthSquared() < eps { // the other two are parallel
+			cols[i], cols[(i+2)%3] = math32.Vector3{}, math32.Vector3{}
+			return fullBasis(cols)
+		}
+	case 2:
+		i := 3 - zero[0] - zero[1]
+		v := cols[i].Normal()
+		u := math32.Vec3(1, 0, 0)
+		if math32.Abs(v.X) > 0.9 {
+			u = math32.Vec3(0, 1, 0)
+		}
+		cols[(i+1)%3] = v.Cross(u).Normal()
+		cols[(i+2)%3] = v.Cross(cols[(i+1)%3])
+	default:
+		return [3]math32.Vector3{{X: 1}, {Y: 1}, {Z: 1}}
+	}
+	return cols
+}
+
+// polarRotation returns the rotation nearest to the matrix of the given
+// columns, which must span space with a positive determinant, by
+// averaging it with its inverse transpose until they agree
+func polarRotation(cols [3]math32.Vector3) math32.Matrix3 {
+	var q math32.Matrix3
+	for c, v := range cols {
+		q[3*c], q[3*c+1], q[3*c+2] = v.X, v.Y, v.Z
+	}
+	for range 30 {
+		inv, err := q.InverseTry()
+		if err != nil {
+			break
+		}
+		it := inv.Transpose()
+		diff := float32(0)
+		for i := range q {
+			next := 0.5 * (q[i] + it[i])
+			diff = max(diff, math32.Abs(next-q[i]))
+			q[i] = next
+		}
+		if diff < 1e-6 {
+			break
+		}
+	}
+	return q
+}
+
+// QuatFromEuler returns the rotation of the given Tait-Bryan angles, in
+// radians, in the ZYX convention of aircraft: turning by roll around the
+// X axis, then by pitch around the Y axis, then by yaw around the Z axis,
+// all of them axes of the parent. It is [math32.NewQuatEuler] of roll,
+// pitch and yaw, which takes them in that order.
+func QuatFromEuler(pitch, yaw, roll float32) math32.Quat {
+	return math32.NewQuatEuler(math32.Vec3(roll, pitch, yaw))
+}
+
+// QuatToEuler returns the Tait-Bryan angles of the given rotation, in
+// radians, as for [QuatFromEuler], with pitch from -π/2 to π/2 and yaw
+// and roll from -π to π. At a pitch of ±π/2, roll and yaw turn around
+// the same axis, which is gimbal lock, so only their sum or difference
+// is known there, and it returns a roll of 0 with all of the turn in
+// yaw. Angles near that lose precision, so they do not come back from
+// a round trip as exactly as others do. [math32.Quat.ToEuler] is not its
+// inverse, as it returns angles in the XYZ convention instead.
+func QuatToEuler(q math32.Quat) (pitch, yaw, roll float32) {
+	q.Normalize()
+	sinp := 2 * (q.W*q.Y - q.Z*q.X)
+	if math32.Abs(sinp) >= 0.99999 {
+		pitch = math32.Copysign(math32.Pi/2, sinp)
+		yaw = -2 * math32.Atan2(q.X, q.W) * math32.Copysign(1, sinp)
+		return pitch, wrapAngle(yaw), 0
+	}
+	pitch = math32.Asin(sinp)
+	roll = math32.Atan2(2*(q.W*q.X+q.Y*q.Z), 1-2*(q.X*q.X+q.Y*q.Y))
+	yaw = math32.Atan2(2*(q.W*q.Z+q.X*q.Y), 1-2*(q.Y*q.Y+q.Z*q.Z))
+	return
+}
+
+// wrapAngle returns the given angle in radians, from -π to π
+func wrapAngle(a float32) float32 {
+	return math32.Atan2(math32.Sin(a), math32.Cos(a))
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"cogentcore.org/core/math32"
+)
+
+func TestQuatFromEulerAxes(t *testing.T) {
+	// each angle alone turns around its own axis
+	for _, tc := range []struct {
+		pitch, yaw, roll float32
+		axis             math32.Vector3
+	}{
+		{0.5, 0, 0, math32.Vec3(0, 1, 0)},
+		{0, 0.5, 0, math32.Vec3(0, 0, 1)},
+		{0, 0, 0.5, math32.Vec3(1, 0, 0)},
+	} {
+		got := QuatFromEuler(tc.pitch, tc.yaw, tc.roll)
+		want := math32.NewQuatAxisAngle(tc.axis, 0.5)
+		if math32.Abs(got.Dot(want)) < 1-1e-6 {
+			t.Errorf("the rotation of %g, %g, %g is %v instead of %v", tc.pitch, tc.yaw, tc.roll, got, want)
+		}
+	}
+
+	// roll is applied first, then pitch, then yaw, around parent axes
+	q := QuatFromEuler(math32.Pi/2, math32.Pi/2, math32.Pi/2)
+	if got := math32.Vec3(1, 0, 0).MulQuat(q); got.Sub(math32.Vec3(0, 0, -1)).Length() > 1e-5 {
+		t.Errorf("the turn of a quarter around each axis moves the X axis to %v instead of -Z", got)
+	}
+}
+
+func TestQuatEulerRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	angle := func(lim float32) float32 { return (2*r.Float32() - 1) * lim }
+	for range 1000 {
+		pitch, yaw, roll := angle(math32.Pi/2*0.99), angle(math32.Pi), angle(math32.Pi)
+		p, y, rl := QuatToEuler(QuatFromEuler(pitch, yaw, roll))
+		if math32.Abs(p-pitch) > 1e-3 || math32.Abs(wrapAngle(y-yaw)) > 1e-3 || math32.Abs(wrapAngle(rl-roll)) > 1e-3 {
+			t.Fatalf("the angles %g, %g, %g came back as %g, %g, %g", pitch, yaw, roll, p, y, rl)
+		}
+	}
+}
+
+func TestQuatToEulerGimbalLock(t *testing.T) {
+	for _, pitch := range []float32{math32.Pi / 2, -math32.Pi / 2} {
+		q := QuatFromEuler(pitch, 0.3, 0.2)
+		p, y, r := QuatToEuler(q)
+		if math32.Abs(p-pitch) > 1e-3 || r != 0 {
+			t.Errorf("the gimbal locked angles came back as %g, %g, %g", p, y, r)
+		}
+		// the angles give the same rotation, with all of the turn in yaw
+		if back := QuatFromEuler(p, y, r); math32.Abs(back.Dot(q)) < 1-1e-5 {
+			t.Errorf("the gimbal locked angles %g, %g, %g give %v instead of %v", p, y, r, back, q)
+		}
+	}
+}
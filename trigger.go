@@ -0,0 +1,128 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/tree"
+	"cogentcore.org/core/xyz"
+)
+
+// triggersProperty is the scene property holding its [Trigger]s
+const triggersProperty = "triggers"
+
+// Trigger is a box of world space that calls its functions when solids
+// enter and leave it, as for game events, without a physics library.
+// Each demo [Solid] that is not Static is tested against the triggers of
+// its scene every frame, just before it is rendered, by its world
+// bounding box, which is inside once it overlaps the Volume at all.
+// Triggers are only updated when a frame of the scene is rendered, so
+// solids moved while none is, as when the scene is not shown or does
+// not need rendering, only fire them on the next frame, and one that
+// passes all of the way through a trigger between frames fires nothing.
+// Solids deleted or removed from the scene while inside of a trigger
+// fire its OnExit on the next frame.
+type Trigger struct {
+	// Volume of the trigger, in world space
+	Volume math32.Box3
+
+	// OnEnter is called with each solid that was not
+	// inside of the Volume on the last frame and now is
+	OnEnter func(sld *xyz.Solid)
+
+	// OnExit is called with each solid that was
+	// inside of the Volume on the last frame and no longer is
+	OnExit func(sld *xyz.Solid)
+
+	// solids inside of it on the last frame
+	inside map[*xyz.Solid]bool
+}
+
+// RegisterTrigger adds a [Trigger] over the given volume of the given
+// scene, calling the given functions, either of which can be nil
+func RegisterTrigger(sc *xyz.Scene, volume math32.Box3, onEnter, onExit func(sld *xyz.Solid)) *Trigger {
+	tg := &Trigger{Volume: volume, OnEnter: onEnter, OnExit: onExit, inside: map[*xyz.Solid]bool{}}
+	sc.SetProperty(triggersProperty, append(SceneTriggers(sc), tg))
+	return tg
+}
+
+// RemoveTrigger removes the given [Trigger] from the given scene,
+// without calling its OnExit for the solids still inside of it
+func RemoveTrigger(sc *xyz.Scene, tg *Trigger) {
+	tgs := slices.DeleteFunc(SceneTriggers(sc), func(t *Trigger) bool { return t == tg })
+	if len(tgs) == 0 {
+		sc.DeleteProperty(triggersProperty)
+		return
+	}
+	sc.SetProperty(triggersProperty, tgs)
+}
+
+// SceneTriggers returns the triggers of the given scene
+func SceneTriggers(sc *xyz.Scene) []*Trigger {
+	tgs, _ := sc.Property(triggersProperty).([]*Trigger)
+	return tgs
+}
+
+// updateTriggers tests the solid against the triggers of its scene by
+// the world bounding box that the scene has just set for rendering it,
+// calling those it has entered or left since the last frame
+func (sld *Solid) updateTriggers() {
+	tgs := SceneTriggers(sld.Scene)
+	for _, tg := range tgs {
+		tg.exitRemoved(sld.Scene)
+	}
+	if sld.Static {
+		return
+	}
+	bb := sld.WorldBBox.BBox
+	for _, tg := range tgs {
+		in := !bb.IsEmpty() && tg.Volume.IntersectsBox(bb)
+		if in == tg.inside[&sld.Solid] {
+			continue
+		}
+		if in {
+			tg.inside[&sld.Solid] = true
+			if tg.OnEnter != nil {
+				tg.OnEnter(&sld.Solid)
+			}
+		} else {
+			delete(tg.inside, &sld.Solid)
+			if tg.OnExit != nil {
+				tg.OnExit(&sld.Solid)
+			}
+		}
+	}
+}
+
+// exitRemoved calls OnExit for the solids inside of the trigger that are
+// no longer in the given scene, as they were deleted or removed from it,
+// and forgets them
+func (tg *Trigger) exitRemoved(sc *xyz.Scene) {
+	for sld := range tg.inside {
+		if inScene(sc, sld) {
+			continue
+		}
+		delete(tg.inside, sld)
+		if tg.OnExit != nil {
+			tg.OnExit(sld)
+		}
+	}
+}
+
+// inScene returns whether the given node is in the given scene,
+// and has not been destroyed
+func inScene(sc *xyz.Scene, nd tree.Node) bool {
+	for k := nd; k != nil; k = k.AsTree().Parent {
+		if k.AsTree().This == nil {
+			return false
+		}
+		if k.AsTree() == sc.AsTree() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+func TestTriggerEnterExit(t *testing.T) {
+	sc := newTestScene()
+	box := xyz.NewBox(sc, "box", 1, 1, 1)
+	sld := NewSolid(sc)
+	sld.SetName("mover")
+	sld.SetMesh(box)
+	var events []string
+	RegisterTrigger(sc, math32.B3(4, -1, -1, 6, 1, 1),
+		func(s *xyz.Solid) { events = append(events, "enter "+s.Name) },
+		func(s *xyz.Solid) { events = append(events, "exit "+s.Name) })
+
+	for _, x := range []float32{0, 5, 5, 10} {
+		sld.Pose.Pos.X = x
+		updateTestScene(sc)
+		sld.updateTriggers()
+	}
+	if want := []string{"enter mover", "exit mover"}; !slices.Equal(events, want) {
+		t.Errorf("moving through the trigger fired %v, not %v", events, want)
+	}
+}
+
+func TestTriggerRemoved(t *testing.T) {
+	sc := newTestScene()
+	box := xyz.NewBox(sc, "box", 1, 1, 1)
+	var slds []*Solid
+	for _, name := range []string{"deleted", "removed", "other"} {
+		sld := NewSolid(sc)
+		sld.SetName(name)
+		sld.SetMesh(box)
+		slds = append(slds, sld)
+	}
+	var exits []string
+	RegisterTrigger(sc, math32.B3(-1, -1, -1, 1, 1, 1), nil,
+		func(s *xyz.Solid) { exits = append(exits, s.Name) })
+	updateTestScene(sc)
+	for _, sld := range slds {
+		sld.updateTriggers()
+	}
+
+	slds[0].Delete()
+	removeNode(sc, slds[1]) // as the delete command does, for undo
+	slds[2].updateTriggers()
+	slices.Sort(exits)
+	if want := []string{"deleted", "removed"}; !slices.Equal(exits, want) {
+		t.Errorf("deleting solids inside of the trigger exited %v, not %v", exits, want)
+	}
+	if tg := SceneTriggers(sc)[0]; len(tg.inside) != 1 {
+		t.Errorf("the trigger still has %d solids inside of it, not 1", len(tg.inside))
+	}
+}
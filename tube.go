@@ -0,0 +1,195 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// Tube is a round tube of a radius along a path of points in 3D, as
+// an alternative to [xyz.Lines], which are flat boxes. The rings of
+// the tube are turned along the path as little as possible, so it does
+// not twist. The radius can change along the path, and the ends of
+// open tubes can be closed with flat caps.
+type Tube struct {
+	xyz.MeshBase
+
+	// Points of the path along the middle of the tube (must be 2 or more)
+	Points []math32.Vector3
+
+	// Radius of the tube
+	Radius float32
+
+	// Radii are the radii of the tube at each of the points, if set,
+	// instead of Radius, which is used for any points after them
+	Radii []float32
+
+	// Sides is the number of sides around the tube
+	Sides int
+
+	// Closed connects the last point back to the first
+	Closed bool
+
+	// EndCaps closes the ends of open tubes with flat circles
+	EndCaps bool
+}
+
+// NewTube returns a new tube mesh along the given points with the given
+// radius and number of sides, set on the given scene under the given
+// name. Set Radii for a tube changing in width, and EndCaps to close
+// the ends of open tubes.
+func NewTube(sc *xyz.Scene, name string, points []math32.Vector3, radius float32, sides int, closed bool) *Tube {
+	tb := &Tube{Points: points, Radius: radius, Sides: sides, Closed: closed}
+	tb.Name = name
+	sc.SetMesh(tb)
+	return tb
+}
+
+// rings returns the number of rings of vertices around the tube,
+// with the first one repeated at the end of closed tubes
+func (tb *Tube) rings() int {
+	if len(tb.Points) < 2 {
+		return 0
+	}
+	if tb.Closed {
+		return len(tb.Points) + 1
+	}
+	return len(tb.Points)
+}
+
+// hasCaps returns whether the tube has end caps
+func (tb *Tube) hasCaps() bool {
+	return tb.EndCaps && !tb.Closed && len(tb.Points) >= 2
+}
+
+// radius returns the radius of the tube at the point with the given index
+func (tb *Tube) radius(i int) float32 {
+	if i < len(tb.Radii) {
+		return tb.Radii[i]
+	}
+	return tb.Radius
+}
+
+func (tb *Tube) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	tb.Sides = max(tb.Sides, 3)
+	nr := tb.rings()
+	tb.NumVertex = nr * (tb.Sides + 1) // the seam is repeated for the texture
+	tb.NumIndex = max(nr-1, 0) * tb.Sides * 6
+	if tb.hasCaps() {
+		tb.NumVertex += 2 * (tb.Sides + 2)
+		tb.NumIndex += 2 * tb.Sides * 3
+	}
+	return tb.NumVertex, tb.NumIndex, false
+}
+
+func (tb *Tube) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	nr := tb.rings()
+	if nr == 0 {
+		return
+	}
+	np := len(tb.Points)
+	// pi returns the index of the point for the given ring
+	pi := func(r int) int {
+		if tb.Closed {
+			return (r%np + np) % np
+		}
+		return min(max(r, 0), np-1)
+	}
+	pt := func(r int) math32.Vector3 { return tb.Points[pi(r)] }
+	nv := tb.Sides + 1
+	var nrm math32.Vector3
+	var frames [2][2]math32.Vector3 // first and last normal and binormal, for the caps
+	for r := range nr {
+		tan := pt(r + 1).Sub(pt(r - 1)).Normal()
+		if r == 0 {
+			nrm = tubePerpendicular(tan)
+		} else if r == nr-1 && tb.Closed {
+			tan = pt(1).Sub(pt(-1)).Normal()
+			nrm = tubePerpendicular(tan) // so the ends meet
+		} else {
+			// turn the last normal as little as possible to be
+			// perpendicular to the new tangent
+			next := nrm.Sub(tan.MulScalar(tan.Dot(nrm)))
+			if next.Length() < 1e-6 {
+				next = tubePerpendicular(tan)
+			}
+			nrm = next.Normal()
+		}
+		bin := tan.Cross(nrm)
+		if r == 0 {
+			frames[0] = [2]math32.Vector3{nrm, bin}
+		}
+		frames[1] = [2]math32.Vector3{nrm, bin}
+		ctr, rad := pt(r), tb.radius(pi(r))
+		// the surface slopes in by the change in radius along the path
+		slope := float32(0)
+		if ln := pt(r + 1).Sub(pt(r - 1)).Length(); ln > 0 {
+			slope = (tb.radius(pi(r+1)) - tb.radius(pi(r-1))) / ln
+		}
+		for s := range nv {
+			a := 2 * math32.Pi * float32(s) / float32(tb.Sides)
+			dir := nrm.MulScalar(math32.Cos(a)).Add(bin.MulScalar(math32.Sin(a)))
+			i := r*nv + s
+			vertex.SetVector3(3*i, ctr.Add(dir.MulScalar(rad)))
+			normal.SetVector3(3*i, dir.Sub(tan.MulScalar(slope)).Normal())
+			texcoord.Set(2*i, float32(s)/float32(tb.Sides), float32(r)/float32(nr-1))
+		}
+	}
+	ii := 0
+	for r := range nr - 1 {
+		for s := range tb.Sides {
+			a := uint32(r*nv + s)
+			b, c, d := a+uint32(nv), a+1, a+uint32(nv)+1
+			index.Set(ii, a, c, b, b, c, d)
+			ii += 6
+		}
+	}
+	if tb.hasCaps() {
+		vi := nr * nv
+		for e, r := range []int{0, nr - 1} {
+			ctr, rad := pt(r), tb.radius(r)
+			face := pt(r + 1).Sub(pt(r - 1)).Normal()
+			if e == 0 {
+				face = face.Negate()
+			}
+			ci := uint32(vi)
+			vertex.SetVector3(3*vi, ctr)
+			normal.SetVector3(3*vi, face)
+			texcoord.Set(2*vi, 0.5, 0.5)
+			vi++
+			for s := range nv {
+				a := 2 * math32.Pi * float32(s) / float32(tb.Sides)
+				cs, sn := math32.Cos(a), math32.Sin(a)
+				dir := frames[e][0].MulScalar(cs).Add(frames[e][1].MulScalar(sn))
+				vertex.SetVector3(3*vi, ctr.Add(dir.MulScalar(rad)))
+				normal.SetVector3(3*vi, face)
+				texcoord.Set(2*vi, 0.5+cs/2, 0.5-sn/2)
+				if s < tb.Sides {
+					k := uint32(vi)
+					if e == 0 {
+						index.Set(ii, ci, k+1, k)
+					} else {
+						index.Set(ii, ci, k, k+1)
+					}
+					ii += 3
+				}
+				vi++
+			}
+		}
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, tb.NumVertex)
+	tb.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// tubePerpendicular returns a unit vector perpendicular to the given one
+func tubePerpendicular(v math32.Vector3) math32.Vector3 {
+	ax := math32.Vec3(1, 0, 0)
+	if math32.Abs(v.X) > 0.9 {
+		ax = math32.Vec3(0, 1, 0)
+	}
+	return v.Cross(ax).Normal()
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/color"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// ColoredVertices is a triangle mesh with a color for each vertex,
+// which is interpolated across the triangles. Solids using it are
+// rendered with the per-vertex color shader automatically, unless
+// [Solid.OverrideVertexColors] is set.
+type ColoredVertices struct {
+	xyz.MeshBase
+
+	// Vertices of the mesh
+	Vertices []math32.Vector3
+
+	// VertexColors has one color per vertex
+	VertexColors []color.RGBA
+
+	// Indexes of the triangle vertices, three per triangle.
+	// If empty, each three vertices in order make a triangle.
+	Indexes []uint32
+}
+
+// NewColoredVertices returns a new mesh of triangles made by each
+// three of the given vertices, with the given per-vertex colors,
+// set on the given scene under the given name. Missing colors
+// are white.
+func NewColoredVertices(sc *xyz.Scene, name string, vtxs []math32.Vector3, clrs []color.RGBA) *ColoredVertices {
+	cv := &ColoredVertices{Vertices: vtxs, VertexColors: clrs}
+	cv.Name = name
+	sc.SetMesh(cv)
+	return cv
+}
+
+func (cv *ColoredVertices) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	cv.NumVertex = len(cv.Vertices)
+	cv.NumIndex = len(cv.Indexes)
+	if cv.NumIndex == 0 {
+		cv.NumIndex = cv.NumVertex - cv.NumVertex%3
+	}
+	cv.HasColor = true
+	return cv.NumVertex, cv.NumIndex, cv.HasColor
+}
+
+func (cv *ColoredVertices) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	for i, v := range cv.Vertices {
+		vertex.SetVector3(3*i, v)
+		clr := math32.Vec4(1, 1, 1, 1)
+		if i < len(cv.VertexColors) {
+			clr = math32.NewVector4Color(cv.VertexColors[i])
+		}
+		clrs.SetVector4(4*i, clr)
+	}
+	if len(cv.Indexes) > 0 {
+		copy(index, cv.Indexes)
+	} else {
+		for i := range cv.NumIndex {
+			index[i] = uint32(i)
+		}
+	}
+	// smooth normals from the sum of the adjacent face normals
+	nrms := make([]math32.Vector3, cv.NumVertex)
+	for t := 0; t+2 < cv.NumIndex; t += 3 {
+		i0, i1, i2 := index[t], index[t+1], index[t+2]
+		fn := math32.Normal(cv.Vertices[i0], cv.Vertices[i1], cv.Vertices[i2])
+		nrms[i0] = nrms[i0].Add(fn)
+		nrms[i1] = nrms[i1].Add(fn)
+		nrms[i2] = nrms[i2].Add(fn)
+	}
+	for i, n := range nrms {
+		if n != (math32.Vector3{}) {
+			n = n.Normal()
+		}
+		normal.SetVector3(3*i, n)
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, cv.NumVertex)
+	cv.BBox.SetBounds(bb.Min, bb.Max)
+}
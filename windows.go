@@ -0,0 +1,44 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/styles/units"
+	"cogentcore.org/core/system"
+)
+
+// NewBodyWindow returns a new body with the given title, shown in a
+// window of its own, apart from the main one, as for a panel detached
+// from it or for another screen. The events of all of the windows come
+// from the one main loop of the app, but each window handles its own on
+// its own goroutine, so a change made from one window to the widgets of
+// another must be done under its AsyncLock. The window is shown right
+// away, at a default size, so widgets added to the body after must be
+// shown with its Update. The body can be closed with its Close, which
+// only closes its window; closing the last window quits the app, calling
+// the functions given to [OnAllWindowsClosed]. On the web and mobile,
+// where there is one window, the body fills the window of the app instead.
+func NewBodyWindow(title string) *core.Body {
+	b := core.NewBody(title)
+	b.Styler(func(s *styles.Style) {
+		s.Min.Set(units.Em(30), units.Em(20))
+	})
+	b.RunWindow()
+	return b
+}
+
+// OnAllWindowsClosed adds a function called when the last window of the
+// app has been closed, just before the app quits, as for saving what was
+// open. It is not called when the app is quit with windows still open,
+// which are closed then with each of their own Close events.
+func OnAllWindowsClosed(fun func()) {
+	system.TheApp.AddQuitCleanFunc(func() {
+		if len(core.AllRenderWindows) == 0 {
+			fun()
+		}
+	})
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/system"
+)
+
+// WindowState is where a window was, how big it was,
+// and whether it was full screen, as [EnableStateRestore] saves it
+type WindowState struct {
+	// Pos of the window, in screen pixels
+	Pos image.Point
+
+	// Size of the window, in device pixels
+	Size image.Point
+
+	// Fullscreen is whether the window was full screen, in which case Pos
+	// and Size are where it was before, so that it can go back there
+	Fullscreen bool
+}
+
+// EnableStateRestore saves the position, size, and full screen state of
+// the window of the given body under the given key when it is closed, and
+// restores them when it is next opened, as with RunMainWindow. The states
+// of all the windows of the app are saved in the [WindowStateFilename].
+// Core also remembers window geometries on its own, which are applied
+// first, when the window is created; these are applied once it is shown.
+// Only desktop windows are restored, as the others fill the page or screen.
+func EnableStateRestore(b *core.Body, storageKey string) {
+	st, restore := WindowState{}, false
+	if sts, err := loadWindowStates(); err == nil {
+		st, restore = sts[storageKey]
+	} else {
+		log.Printf("EnableStateRestore: %v\n", err)
+	}
+	b.OnShow(func(e events.Event) {
+		win := bodyWindow(b)
+		if !restore || win == nil || !restorableWindows() {
+			return
+		}
+		if st.Size != (image.Point{}) && onScreen(st.Pos) {
+			win.SetGeometry(false, st.Pos, st.Size, nil)
+		}
+		if st.Fullscreen {
+			SetFullscreen(b, true)
+		}
+	})
+	b.OnClose(func(e events.Event) {
+		win := bodyWindow(b)
+		if win == nil || !restorableWindows() {
+			return
+		}
+		if IsFullscreen(b) {
+			st.Fullscreen = true // keep where it was before
+		} else {
+			st = WindowState{Pos: win.Position(nil), Size: win.Size()}
+		}
+		if err := saveWindowState(storageKey, st); err != nil {
+			log.Printf("EnableStateRestore: %v\n", err)
+		}
+	})
+}
+
+// WindowStateFilename returns the name of the file holding the
+// window states of the app, in its directory of the user config directory
+func WindowStateFilename() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	name := system.TheApp.Name()
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+	return filepath.Join(dir, name, "window.json"), nil
+}
+
+// loadWindowStates returns the saved window states, by their keys;
+// a missing file means there are none
+func loadWindowStates() (map[string]WindowState, error) {
+	sts := map[string]WindowState{}
+	filename, err := WindowStateFilename()
+	if err != nil {
+		return sts, err
+	}
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sts, nil
+	}
+	if err != nil {
+		return sts, err
+	}
+	return sts, json.Unmarshal(b, &sts)
+}
+
+// saveWindowState saves the given window state under the given
+// key, keeping those of the other windows in the file
+func saveWindowState(storageKey string, st WindowState) error {
+	sts, err := loadWindowStates()
+	if err != nil {
+		return err
+	}
+	sts[storageKey] = st
+	filename, err := WindowStateFilename()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(sts, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, b, 0666)
+}
+
+// onScreen returns whether the given position is on one of the screens,
+// as the one that a window was on may since have been disconnected
+func onScreen(pos image.Point) bool {
+	for i := range system.TheApp.NScreens() {
+		if pos.In(system.TheApp.Screen(i).Geometry) {
+			return true
+		}
+	}
+	return false
+}
+
+// restorableWindows returns whether the platform has windows that can
+// be moved and sized, which are those of the desktop platforms
+func restorableWindows() bool {
+	p := system.TheApp.Platform()
+	return !p.IsMobile() && p != system.Offscreen
+}
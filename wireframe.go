@@ -0,0 +1,109 @@
+// Copyright (c) 2024, Samuel Title. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"cogentcore.org/core/gpu/shape"
+	"cogentcore.org/core/math32"
+	"cogentcore.org/core/xyz"
+)
+
+// WireframeMesh draws the triangle edges of another mesh as thin
+// square beams, because WebGPU does not support a line polygon
+// fill mode. Edges shared between triangles are only drawn once.
+type WireframeMesh struct {
+	xyz.MeshBase
+
+	// Edges as pairs of end points, set by [WireframeMesh.SetEdges]
+	Edges [][2]math32.Vector3
+
+	// Width of the beams
+	Width float32
+}
+
+// NewWireframeMesh returns the wireframe of the given mesh, with
+// the given edge width, set on the given scene under the given name
+func NewWireframeMesh(sc *xyz.Scene, name string, src xyz.Mesh, width float32) *WireframeMesh {
+	wm := &WireframeMesh{Width: width}
+	wm.Name = name
+	wm.SetEdges(src)
+	sc.SetMesh(wm)
+	return wm
+}
+
+// SetEdges sets the edges from the triangles of the given mesh
+func (wm *WireframeMesh) SetEdges(src xyz.Mesh) {
+	md := shape.NewMeshData(src)
+	wm.Edges = wm.Edges[:0]
+	seen := map[[2]math32.Vector3]bool{}
+	vtx := func(i uint32) math32.Vector3 {
+		var v math32.Vector3
+		md.Vertex.GetVector3(3*int(i), &v)
+		return v
+	}
+	for t := 0; t+2 < len(md.Index); t += 3 {
+		for e := range 3 {
+			a, b := vtx(md.Index[t+e]), vtx(md.Index[t+(e+1)%3])
+			if a == b {
+				continue
+			}
+			if b.X < a.X || (b.X == a.X && (b.Y < a.Y || (b.Y == a.Y && b.Z < a.Z))) {
+				a, b = b, a
+			}
+			k := [2]math32.Vector3{a, b}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			wm.Edges = append(wm.Edges, k)
+		}
+	}
+}
+
+func (wm *WireframeMesh) MeshSize() (numVertex, nIndex int, hasColor bool) {
+	wm.NumVertex = 16 * len(wm.Edges)
+	wm.NumIndex = 24 * len(wm.Edges)
+	return wm.NumVertex, wm.NumIndex, false
+}
+
+func (wm *WireframeMesh) Set(vertex, normal, texcoord, clrs math32.ArrayF32, index math32.ArrayU32) {
+	hw := 0.5 * wm.Width
+	vi, ii := 0, 0
+	for _, e := range wm.Edges {
+		dir := e[1].Sub(e[0]).Normal()
+		up := math32.Vec3(0, 1, 0)
+		if math32.Abs(dir.Dot(up)) > 0.9 {
+			up = math32.Vec3(1, 0, 0)
+		}
+		sa := dir.Cross(up).Normal()
+		sb := dir.Cross(sa)
+		sides := [4]math32.Vector3{sa, sb, sa.Negate(), sb.Negate()}
+		for s, nrm := range sides {
+			nx := sides[(s+1)%4]
+			off0 := nrm.Add(nx.Negate()).MulScalar(hw) // corner before this side
+			off1 := nrm.Add(nx).MulScalar(hw)          // corner after this side
+			pts := [4]math32.Vector3{e[0].Add(off0), e[1].Add(off0), e[1].Add(off1), e[0].Add(off1)}
+			for p := range pts {
+				vertex.SetVector3(3*(vi+p), pts[p])
+				normal.SetVector3(3*(vi+p), nrm)
+				texcoord.SetVector2(2*(vi+p), math32.Vec2(float32(p&1), float32(p>>1)))
+			}
+			u := uint32(vi)
+			index.Set(ii, u, u+1, u+2, u, u+2, u+3)
+			vi += 4
+			ii += 6
+		}
+	}
+	bb := shape.BBoxFromVtxs(vertex, 0, vi)
+	wm.BBox.SetBounds(bb.Min, bb.Max)
+}
+
+// wireframeMeshName returns the name of the wireframe mesh
+// for the given mesh and edge width
+func wireframeMeshName(mesh xyz.MeshName, width float32) string {
+	return fmt.Sprintf("__Wireframe:%s:%g", mesh, width)
+}